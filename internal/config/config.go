@@ -1,7 +1,11 @@
 package config
 
 import (
+	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // Config содержит все конфигурационные параметры приложения
@@ -10,15 +14,52 @@ type Config struct {
 	Port        string
 	Environment string
 
+	// Адреса и подсети обратных прокси, которым доверяются заголовки X-Forwarded-For и X-Real-IP
+	TrustedProxies []string
+
 	// Database configuration
 	DatabaseURL string
 	RedisURL    string
 
 	// Security
 	JWTSecret string
+	// Абсолютный срок жизни сессии: после него нужен повторный вход, даже если токены обновлялись
+	SessionMaxLifetime time.Duration
 
 	// Telegram
-	TelegramBotToken string
+	TelegramBotToken             string
+	TelegramPaymentProviderToken string
+	TelegramWebhookSecret        string
+
+	// Часовой пояс барбершопа (IANA), в нем задаются рабочие часы барберов без собственного пояса
+	Timezone string
+
+	// Payments
+	Currency             string
+	PaymentWebhookSecret string
+
+	// Политика паролей: минимальная длина и файл с дополнительными запрещенными паролями
+	// (к встроенному списку распространенных паролей)
+	PasswordMinLength     int
+	PasswordBlocklistFile string
+
+	// Защита входа по паролю от подбора: число неудачных попыток до блокировки
+	// для email и для IP адреса и срок блокировки
+	LoginMaxAttempts     int
+	LoginIPMaxAttempts   int
+	LoginLockoutDuration time.Duration
+
+	// Обязательная двухфакторная аутентификация для роли admin
+	RequireAdminTwoFactor bool
+
+	// Почта: страница сброса пароля и каталог для писем (пустой - письма пишутся в лог)
+	PasswordResetURL string
+	MailDir          string
+
+	// Подтверждение email: адрес ссылки из письма и действия "ресурс:действие",
+	// недоступные пользователям с неподтвержденным email
+	EmailVerifyURL              string
+	UnverifiedEmailRestrictions []string
 }
 
 // LoadConfig загружает конфигурацию из переменных окружения
@@ -27,11 +68,37 @@ func LoadConfig() *Config {
 		Port:        getEnv("PORT", "8080"),
 		Environment: getEnv("ENVIRONMENT", "development"),
 
+		TrustedProxies: getList("TRUSTED_PROXIES", "none"),
+
 		DatabaseURL: os.Getenv("DATABASE_URL"),
 		RedisURL:    os.Getenv("REDIS_URL"),
 
-		JWTSecret:        os.Getenv("JWT_SECRET"),
-		TelegramBotToken: os.Getenv("TELEGRAM_BOT_TOKEN"),
+		JWTSecret:          os.Getenv("JWT_SECRET"),
+		SessionMaxLifetime: getDuration("SESSION_MAX_LIFETIME", 30*24*time.Hour),
+		TelegramBotToken:   os.Getenv("TELEGRAM_BOT_TOKEN"),
+
+		TelegramPaymentProviderToken: os.Getenv("TELEGRAM_PAYMENT_PROVIDER_TOKEN"),
+		TelegramWebhookSecret:        os.Getenv("TELEGRAM_WEBHOOK_SECRET"),
+
+		Timezone: getEnv("TIMEZONE", "Europe/Moscow"),
+
+		Currency:             getEnv("CURRENCY", "RUB"),
+		PaymentWebhookSecret: os.Getenv("PAYMENT_WEBHOOK_SECRET"),
+
+		PasswordMinLength:     getInt("PASSWORD_MIN_LENGTH", 8),
+		PasswordBlocklistFile: os.Getenv("PASSWORD_BLOCKLIST_FILE"),
+
+		LoginMaxAttempts:     getInt("LOGIN_MAX_ATTEMPTS", 10),
+		LoginIPMaxAttempts:   getInt("LOGIN_IP_MAX_ATTEMPTS", 100),
+		LoginLockoutDuration: getDuration("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+
+		RequireAdminTwoFactor: getBool("REQUIRE_ADMIN_2FA", false),
+
+		PasswordResetURL: getEnv("PASSWORD_RESET_URL", "http://localhost:8080/reset-password"),
+		MailDir:          os.Getenv("MAIL_DIR"),
+
+		EmailVerifyURL:              getEnv("EMAIL_VERIFY_URL", "http://localhost:8080/api/auth/email/verify"),
+		UnverifiedEmailRestrictions: getList("UNVERIFIED_EMAIL_RESTRICTIONS", "appointments:create"),
 	}
 }
 
@@ -43,6 +110,71 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// getDuration возвращает длительность из переменной окружения (например, "720h")
+// или значение по умолчанию, если переменная не задана или задана неверно
+func getDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		log.Printf("⚠️  Неверное значение %s=%q, используем %s", key, value, defaultValue)
+		return defaultValue
+	}
+	return duration
+}
+
+// getInt возвращает положительное число из переменной окружения
+// или значение по умолчанию, если переменная не задана или задана неверно
+func getInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number <= 0 {
+		log.Printf("⚠️  Неверное значение %s=%q, используем %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return number
+}
+
+// getBool возвращает флаг из переменной окружения ("true", "1" и т.п.)
+// или значение по умолчанию, если переменная не задана или задана неверно
+func getBool(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	flag, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("⚠️  Неверное значение %s=%q, используем %t", key, value, defaultValue)
+		return defaultValue
+	}
+	return flag
+}
+
+// getList возвращает список из переменной окружения, разделенный запятыми.
+// Значение "none" задает пустой список
+func getList(key, defaultValue string) []string {
+	value := getEnv(key, defaultValue)
+	if value == "none" {
+		return nil
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // IsProduction проверяет, запущено ли приложение в production режиме
 func (c *Config) IsProduction() bool {
 	return c.Environment == "production"
@@ -49,20 +49,39 @@ func (d *Database) Migrate(modelList ...interface{}) error {
 		return fmt.Errorf("база данных не инициализирована")
 	}
 
+	// Определяем переданные модели, для которых нужны дополнительные миграции
+	hasRoleModel, hasUserModel, hasWorkingHoursModel := false, false, false
+	for _, model := range modelList {
+		switch model.(type) {
+		case *models.Role:
+			hasRoleModel = true
+		case *models.User:
+			hasUserModel = true
+		case *models.WorkingHours:
+			hasWorkingHoursModel = true
+		}
+	}
+
+	// Дубли дней недели не дадут создать уникальный индекс рабочих часов
+	if hasWorkingHoursModel {
+		if err := migrations.DedupeWorkingHours(d.DB); err != nil {
+			return fmt.Errorf("ошибка удаления дублей рабочих часов: %v", err)
+		}
+	}
+
 	err := d.DB.AutoMigrate(modelList...)
 	if err != nil {
 		return fmt.Errorf("ошибка миграции: %v", err)
 	}
 
-	// Создаем начальные роли, если переданы модели ролей
-	hasRoleModel := false
-	for _, model := range modelList {
-		if _, ok := model.(*models.Role); ok {
-			hasRoleModel = true
-			break
+	// Удаляем прежние уникальные индексы пользователей, мешавшие отвязке Telegram
+	if hasUserModel {
+		if err := migrations.RelaxUserUniqueIndexes(d.DB); err != nil {
+			return fmt.Errorf("ошибка замены индексов пользователей: %v", err)
 		}
 	}
 
+	// Создаем начальные роли, если переданы модели ролей
 	if hasRoleModel {
 		if err := CreateInitialRoles(d.DB); err != nil {
 			return fmt.Errorf("ошибка создания начальных ролей: %v", err)
@@ -73,6 +92,11 @@ func (d *Database) Migrate(modelList ...interface{}) error {
 		if err := migrations.MigrateExistingUserRoles(d.DB); err != nil {
 			return fmt.Errorf("ошибка миграции ролей существующих пользователей: %v", err)
 		}
+
+		// Обновляем разрешения ролей, созданных со старыми значениями по умолчанию
+		if err := migrations.UpgradeDefaultRolePermissions(d.DB); err != nil {
+			return fmt.Errorf("ошибка обновления разрешений ролей: %v", err)
+		}
 	}
 
 	log.Println("✅ Миграция базы данных выполнена успешно")
@@ -102,7 +126,7 @@ func CreateInitialRoles(db *gorm.DB) error {
 			DisplayName: "Администратор",
 			Description: "Полный доступ к системе",
 			IsActive:    true,
-			Permissions: `{"users": ["create", "read", "update", "delete"], "barbers": ["create", "read", "update", "delete"], "appointments": ["create", "read", "update", "delete"]}`,
+			Permissions: `{"*": ["*"]}`,
 		},
 		{
 			Name:        "barber",
@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/services"
+)
+
+// AppointmentHandler обрабатывает HTTP запросы, связанные с записями
+type AppointmentHandler struct {
+	appointmentService services.AppointmentService
+}
+
+// NewAppointmentHandler создает новый экземпляр AppointmentHandler
+func NewAppointmentHandler(appointmentService services.AppointmentService) *AppointmentHandler {
+	return &AppointmentHandler{appointmentService: appointmentService}
+}
+
+// ClientBook создает запись текущего клиента
+// POST /api/appointments
+func (h *AppointmentHandler) ClientBook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	clientID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.AppointmentCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверные данные: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	appointment, err := h.appointmentService.BookAppointment(clientID, req)
+	if err != nil {
+		http.Error(w, "Ошибка записи: "+err.Error(), appointmentErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(appointment)
+}
+
+// ClientGetAppointments возвращает записи текущего клиента
+// GET /api/appointments
+func (h *AppointmentHandler) ClientGetAppointments(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	clientID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
+	}
+
+	appointments, err := h.appointmentService.GetClientAppointments(clientID)
+	if err != nil {
+		http.Error(w, "Ошибка получения записей: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"appointments": appointments,
+		"count":        len(appointments),
+	})
+}
+
+// ClientAction выполняет действие клиента над записью
+// POST /api/appointments/{id}/cancel
+func (h *AppointmentHandler) ClientAction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	clientID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
+	}
+
+	appointmentID, action, err := extractIDAndAction(r.URL.Path, "/api/appointments/")
+	if err != nil {
+		http.Error(w, "Неверный ID записи: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if action != "cancel" {
+		http.Error(w, "Неизвестное действие", http.StatusNotFound)
+		return
+	}
+
+	appointment, err := h.appointmentService.CancelByClient(clientID, appointmentID)
+	if err != nil {
+		http.Error(w, "Ошибка отмены записи: "+err.Error(), appointmentErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(appointment)
+}
+
+// BarberGetAppointments возвращает записи к текущему барберу
+// GET /api/barber/appointments
+func (h *AppointmentHandler) BarberGetAppointments(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	barberID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
+	}
+
+	appointments, err := h.appointmentService.GetBarberAppointments(barberID)
+	if err != nil {
+		http.Error(w, "Ошибка получения записей: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"appointments": appointments,
+		"count":        len(appointments),
+	})
+}
+
+// BarberAction выполняет действие барбера над записью
+// POST /api/barber/appointments/{id}/confirm|complete|no-show
+func (h *AppointmentHandler) BarberAction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	barberID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
+	}
+
+	appointmentID, action, err := extractIDAndAction(r.URL.Path, "/api/barber/appointments/")
+	if err != nil {
+		http.Error(w, "Неверный ID записи: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var appointment *models.Appointment
+	switch action {
+	case "confirm":
+		appointment, err = h.appointmentService.ConfirmAppointment(barberID, appointmentID)
+	case "complete":
+		appointment, err = h.appointmentService.CompleteAppointment(barberID, appointmentID)
+	case "no-show":
+		appointment, err = h.appointmentService.MarkNoShow(barberID, appointmentID)
+	default:
+		http.Error(w, "Неизвестное действие", http.StatusNotFound)
+		return
+	}
+
+	if err != nil {
+		http.Error(w, "Ошибка изменения записи: "+err.Error(), appointmentErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(appointment)
+}
+
+// appointmentErrorStatus возвращает HTTP статус для ошибки записи
+func appointmentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrAppointmentNotFound), errors.Is(err, services.ErrServiceNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidStatusTransition), errors.Is(err, services.ErrSlotUnavailable),
+		errors.Is(err, services.ErrOutsideWorkingHours):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
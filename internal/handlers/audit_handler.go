@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/services"
+)
+
+// AuditHandler обрабатывает HTTP запросы журнала аудита
+type AuditHandler struct {
+	auditService services.AuditService
+}
+
+// NewAuditHandler создает новый экземпляр AuditHandler
+func NewAuditHandler(auditService services.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// ListEvents возвращает страницу журнала аудита.
+// Фильтры: actor_id, target_type, target_id, action, from, to (RFC3339), page, page_size
+// GET /api/admin/audit
+func (h *AuditHandler) ListEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	filter, err := parseAuditFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	events, total, err := h.auditService.List(filter)
+	if err != nil {
+		http.Error(w, "Ошибка получения журнала аудита: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"events":    events,
+		"count":     len(events),
+		"total":     total,
+		"page":      filter.Page,
+		"page_size": filter.PageSize,
+	})
+}
+
+// parseAuditFilter разбирает фильтры журнала аудита из query-параметров
+func parseAuditFilter(r *http.Request) (models.AuditFilter, error) {
+	query := r.URL.Query()
+	filter := models.AuditFilter{
+		TargetType: query.Get("target_type"),
+		Action:     query.Get("action"),
+	}
+
+	var err error
+	if filter.ActorID, err = parseOptionalUint(r, "actor_id"); err != nil {
+		return filter, err
+	}
+	if filter.TargetID, err = parseOptionalUint(r, "target_id"); err != nil {
+		return filter, err
+	}
+	if filter.From, err = parseOptionalTime(r, "from"); err != nil {
+		return filter, err
+	}
+	if filter.To, err = parseOptionalTime(r, "to"); err != nil {
+		return filter, err
+	}
+
+	page, err := parseOptionalInt(r, "page")
+	if err != nil {
+		return filter, err
+	}
+	pageSize, err := parseOptionalInt(r, "page_size")
+	if err != nil {
+		return filter, err
+	}
+	if page != nil {
+		filter.Page = *page
+	}
+	if pageSize != nil {
+		filter.PageSize = *pageSize
+	}
+	filter.Normalize()
+	return filter, nil
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
@@ -13,70 +14,74 @@ import (
 // AuthHandler обработчик для аутентификации
 type AuthHandler struct {
 	authService services.AuthService
+	twoFactor   services.TwoFactorService
 }
 
-// NewAuthHandler создает новый обработчик аутентификации
-func NewAuthHandler(authService services.AuthService) *AuthHandler {
+// NewAuthHandler создает новый обработчик аутентификации.
+// Вход открывает сессию устройства так же, как AuthHTTPHandler; при включенной
+// или обязательной 2FA вместо токенов выдается токен второго шага входа
+func NewAuthHandler(authService services.AuthService, twoFactor services.TwoFactorService) *AuthHandler {
 	return &AuthHandler{
 		authService: authService,
+		twoFactor:   twoFactor,
 	}
 }
 
 // TelegramAuth обрабатывает аутентификацию через Telegram
 func (h *AuthHandler) TelegramAuth(c *gin.Context) {
-	var authData models.TelegramAuthData
-	if err := c.ShouldBindJSON(&authData); err != nil {
+	var loginData models.TelegramLoginData
+	if err := c.ShouldBindJSON(&loginData); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Неверные данные"})
 		return
 	}
 
-	// Валидируем Telegram данные
-	// Получаем bot token из конфигурации (упрощенная версия)
-	botToken := "your_bot_token_here" // В реальном приложении получать из конфигурации
-	if !h.authService.ValidateTelegramAuth(authData, botToken) {
+	// Проверяем подпись Telegram токеном бота из конфигурации
+	authData, err := h.authService.ValidateTelegramAuth(loginData)
+	if err != nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Невалидная аутентификация Telegram"})
 		return
 	}
 
 	// Находим или создаем пользователя
-	user, err := h.authService.AuthenticateUser(authData)
+	user, err := h.authService.AuthenticateUser(*authData)
 	if err != nil {
+		if errors.Is(err, services.ErrUserInactive) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка аутентификации пользователя"})
 		return
 	}
 
-	// Генерируем access token
-	accessToken, err := h.authService.GenerateAccessToken(user)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка генерации токена"})
-		return
+	// Токены выдаются только после второго шага входа (/api/auth/login/2fa)
+	purpose := ""
+	switch {
+	case user.HasTwoFactor():
+		purpose = services.MFAChallengeLogin
+	case h.twoFactor.IsRequired(user.ID):
+		purpose = services.MFAChallengeEnroll
 	}
-
-	// Генерируем refresh token
-	refreshToken, err := h.authService.GenerateRefreshToken(user)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка генерации refresh токена"})
+	if purpose != "" {
+		c.JSON(http.StatusOK, models.MFAChallengeResponse{
+			MFARequired:        true,
+			EnrollmentRequired: purpose == services.MFAChallengeEnroll,
+			ChallengeToken:     h.twoFactor.IssueChallenge(user, purpose),
+			ExpiresIn:          int(services.MFAChallengeTTL.Seconds()),
+		})
 		return
 	}
 
-	// Сохраняем refresh token в Redis
-	if err := h.authService.StoreRefreshToken(user.ID, refreshToken); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка сохранения токена"})
+	// Открываем сессию устройства и выдаем токены
+	response, err := h.authService.CreateSession(user, deviceFromRequest(c.Request))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка создания сессии"})
 		return
 	}
 
-	// Возвращаем ответ
-	response := models.AuthResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresIn:    15 * 60, // 15 минут в секундах
-		User:         *user,
-	}
-
 	c.JSON(http.StatusOK, response)
 }
 
-// RefreshToken обновляет токены
+// RefreshToken обновляет токены сессии
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	var req models.RefreshTokenRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -84,78 +89,44 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	// Парсим refresh token
-	claims, err := h.authService.ParseJWT(req.RefreshToken)
-	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Невалидный refresh token"})
-		return
+	// Сессия, открытая без 2FA, не продлевается, если 2FA стала обязательной
+	if claims, err := h.authService.ParseJWT(req.RefreshToken); err == nil && h.twoFactor.IsRequired(claims.UserID) {
+		if user, err := h.authService.GetUserByID(claims.UserID); err == nil && !user.HasTwoFactor() {
+			c.JSON(http.StatusForbidden, gin.H{"error": services.ErrTwoFactorRequired.Error() + ", войдите заново"})
+			return
+		}
 	}
 
-	// Проверяем тип токена
-	if !claims.IsRefreshToken() {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Неверный тип токена"})
-		return
-	}
-
-	// Проверяем срок действия
-	if claims.IsExpired() {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token истек"})
-		return
-	}
-
-	// Проверяем, что токен существует в Redis
-	if !h.authService.IsRefreshTokenValid(claims.UserID, req.RefreshToken) {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Refresh token не найден"})
-		return
-	}
-
-	// Получаем пользователя из БД
-	user, err := h.authService.GetUserByID(claims.UserID)
-	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Пользователь не найден"})
-		return
-	}
-
-	// Генерируем новую пару токенов
-	newAccessToken, err := h.authService.GenerateAccessToken(user)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка генерации токена"})
-		return
-	}
-
-	newRefreshToken, err := h.authService.GenerateRefreshToken(user)
+	// Старый refresh token перестает действовать
+	response, err := h.authService.RefreshSession(req.RefreshToken, deviceFromRequest(c.Request))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка генерации refresh токена"})
-		return
-	}
-
-	// Обновляем refresh token в Redis
-	if err := h.authService.UpdateRefreshToken(claims.UserID, req.RefreshToken, newRefreshToken); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка обновления токена"})
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Невалидный refresh token"})
 		return
 	}
 
-	// Возвращаем новые токены
-	response := models.AuthResponse{
-		AccessToken:  newAccessToken,
-		RefreshToken: newRefreshToken,
-		ExpiresIn:    15 * 60, // 15 минут в секундах
-		User:         *user,
-	}
-
 	c.JSON(http.StatusOK, response)
 }
 
-// Logout выходит из системы
+// Logout выходит из системы: завершает сессию устройства и отзывает текущий access token
 func (h *AuthHandler) Logout(c *gin.Context) {
-	userID, exists := c.Get("user_id")
+	value, exists := c.Get("jwt_claims")
 	if !exists {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Пользователь не аутентифицирован"})
 		return
 	}
+	claims := value.(*models.TokenClaims)
+
+	// Остальные сессии пользователя остаются активными; сессия могла быть уже отозвана
+	if claims.SessionID != 0 {
+		err := h.authService.RevokeSession(claims.UserID, claims.SessionID)
+		if err != nil && !errors.Is(err, services.ErrSessionNotFound) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка выхода из системы"})
+			return
+		}
+	}
 
-	// Отзываем refresh token
-	if err := h.authService.RevokeRefreshToken(userID.(uint)); err != nil {
+	// Отзываем текущий access token
+	if err := h.authService.RevokeAccessToken(claims); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка выхода из системы"})
 		return
 	}
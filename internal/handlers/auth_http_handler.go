@@ -2,7 +2,11 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"garage-barbershop/internal/models"
@@ -11,13 +15,24 @@ import (
 
 // AuthHTTPHandler HTTP обработчик для аутентификации (без Gin)
 type AuthHTTPHandler struct {
-	authService services.AuthService
+	authService       services.AuthService
+	auditService      services.AuditService
+	emailVerification services.EmailVerificationService
+	loginThrottle     services.LoginThrottleService
+	twoFactor         services.TwoFactorService
 }
 
-// NewAuthHTTPHandler создает новый HTTP обработчик аутентификации
-func NewAuthHTTPHandler(authService services.AuthService) *AuthHTTPHandler {
+// NewAuthHTTPHandler создает новый HTTP обработчик аутентификации.
+// Входы и обновления токенов записываются в журнал аудита,
+// после прямой регистрации отправляется письмо подтверждения email,
+// вход по паролю ограничивается loginThrottle, при включенной 2FA токены выдаются после второго шага
+func NewAuthHTTPHandler(authService services.AuthService, auditService services.AuditService, emailVerification services.EmailVerificationService, loginThrottle services.LoginThrottleService, twoFactor services.TwoFactorService) *AuthHTTPHandler {
 	return &AuthHTTPHandler{
-		authService: authService,
+		authService:       authService,
+		auditService:      auditService,
+		emailVerification: emailVerification,
+		loginThrottle:     loginThrottle,
+		twoFactor:         twoFactor,
 	}
 }
 
@@ -28,56 +43,69 @@ func (h *AuthHTTPHandler) TelegramAuth(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var authData models.TelegramAuthData
-	if err := json.NewDecoder(r.Body).Decode(&authData); err != nil {
+	var loginData models.TelegramLoginData
+	if err := json.NewDecoder(r.Body).Decode(&loginData); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
 
-	// Валидируем Telegram данные
-	botToken := "your_bot_token_here" // В реальном приложении получать из конфигурации
-	if !h.authService.ValidateTelegramAuth(authData, botToken) {
+	// Проверяем подпись Telegram токеном бота из конфигурации
+	authData, err := h.authService.ValidateTelegramAuth(loginData)
+	if err != nil {
+		h.recordAuthFailure(r, models.AuditActionLoginFailure, 0, "telegram_id: "+loginData["id"])
 		http.Error(w, "Invalid Telegram authentication", http.StatusUnauthorized)
 		return
 	}
 
 	// Находим или создаем пользователя
-	user, err := h.authService.AuthenticateUser(authData)
+	user, err := h.authService.AuthenticateUser(*authData)
 	if err != nil {
+		if errors.Is(err, services.ErrUserInactive) {
+			h.recordAuthFailure(r, models.AuditActionLoginFailure, 0, "telegram_id: "+strconv.FormatInt(authData.ID, 10))
+			http.Error(w, "User is deactivated", http.StatusForbidden)
+			return
+		}
 		http.Error(w, "Authentication failed", http.StatusInternalServerError)
 		return
 	}
 
-	// Генерируем access token
-	accessToken, err := h.authService.GenerateAccessToken(user)
-	if err != nil {
-		http.Error(w, "Token generation failed", http.StatusInternalServerError)
+	h.completeLogin(w, r, user, "telegram")
+}
+
+// TelegramWebAppAuth обрабатывает вход из Telegram WebApp по подписанным данным initData
+// POST /api/auth/telegram/webapp
+func (h *AuthHTTPHandler) TelegramWebAppAuth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// Генерируем refresh token
-	refreshToken, err := h.authService.GenerateRefreshToken(user)
-	if err != nil {
-		http.Error(w, "Refresh token generation failed", http.StatusInternalServerError)
+	var req models.TelegramWebAppAuthRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверные данные: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// Сохраняем refresh token в Redis
-	if err := h.authService.StoreRefreshToken(user.ID, refreshToken); err != nil {
-		http.Error(w, "Token storage failed", http.StatusInternalServerError)
+	initData, err := h.authService.ValidateWebAppInitData(req.InitData)
+	if err != nil {
+		h.recordAuthFailure(r, models.AuditActionLoginFailure, 0, "telegram_webapp: "+err.Error())
+		http.Error(w, "Ошибка авторизации: "+err.Error(), http.StatusUnauthorized)
 		return
 	}
 
-	// Возвращаем ответ
-	response := models.AuthResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresIn:    15 * 60, // 15 минут в секундах
-		User:         *user,
+	// Находим или создаем пользователя так же, как при входе через Telegram Login Widget
+	user, err := h.authService.AuthenticateUser(initData.AuthData())
+	if err != nil {
+		if errors.Is(err, services.ErrUserInactive) {
+			h.recordAuthFailure(r, models.AuditActionLoginFailure, 0, "telegram_id: "+strconv.FormatInt(initData.User.ID, 10))
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		http.Error(w, "Ошибка авторизации: "+err.Error(), http.StatusInternalServerError)
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	h.completeLogin(w, r, user, "telegram_webapp")
 }
 
 // RefreshToken обновляет токены
@@ -93,100 +121,127 @@ func (h *AuthHTTPHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Парсим refresh token
-	claims, err := h.authService.ParseJWT(req.RefreshToken)
+	// Сессия администратора, открытая без 2FA, не продлевается, если 2FA стала обязательной
+	if claims, err := h.authService.ParseJWT(req.RefreshToken); err == nil && h.twoFactor.IsRequired(claims.UserID) {
+		if user, err := h.authService.GetUserByID(claims.UserID); err == nil && !user.HasTwoFactor() {
+			h.recordAuthFailure(r, models.AuditActionTokenRefreshFail, user.ID, services.ErrTwoFactorRequired.Error())
+			http.Error(w, services.ErrTwoFactorRequired.Error()+", войдите заново", http.StatusForbidden)
+			return
+		}
+	}
+
+	// Обновляем токены сессии; старый refresh token перестает действовать
+	response, err := h.authService.RefreshSession(req.RefreshToken, deviceFromRequest(r))
 	if err != nil {
+		var userID uint
+		if claims, parseErr := h.authService.ParseJWT(req.RefreshToken); parseErr == nil {
+			userID = claims.UserID
+		}
+		action := models.AuditActionTokenRefreshFail
+		if errors.Is(err, services.ErrRefreshTokenReused) {
+			// Замененный токен предъявлен повторно: семейство токенов уже отозвано
+			action = models.AuditActionTokenReuse
+		}
+		h.recordAuthFailure(r, action, userID, err.Error())
 		http.Error(w, "Invalid refresh token", http.StatusUnauthorized)
 		return
 	}
+	h.recordAuthSuccess(r, models.AuditActionTokenRefresh, response.User.ID, "")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
 
-	// Проверяем тип токена
-	if !claims.IsRefreshToken() {
-		http.Error(w, "Invalid token type", http.StatusUnauthorized)
+// Logout выходит из системы: завершает сессию устройства и отзывает текущий access token
+func (h *AuthHTTPHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// Проверяем срок действия
-	if claims.IsExpired() {
-		http.Error(w, "Refresh token expired", http.StatusUnauthorized)
+	claims, ok := r.Context().Value("tokenClaims").(*models.TokenClaims)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
 		return
 	}
 
-	// Проверяем, что токен существует в Redis
-	if !h.authService.IsRefreshTokenValid(claims.UserID, req.RefreshToken) {
-		http.Error(w, "Refresh token not found", http.StatusUnauthorized)
-		return
+	// Refresh token сессии больше не обновится; сессия могла быть уже отозвана
+	if claims.SessionID != 0 {
+		err := h.authService.RevokeSession(claims.UserID, claims.SessionID)
+		if err != nil && !errors.Is(err, services.ErrSessionNotFound) {
+			http.Error(w, "Ошибка выхода из системы: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
 	}
 
-	// Получаем пользователя из БД
-	user, err := h.authService.GetUserByID(claims.UserID)
-	if err != nil {
-		http.Error(w, "User not found", http.StatusNotFound)
+	// Текущий access token перестает действовать сразу, не дожидаясь истечения срока
+	if err := h.authService.RevokeAccessToken(claims); err != nil {
+		http.Error(w, "Ошибка выхода из системы: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// Генерируем новую пару токенов
-	newAccessToken, err := h.authService.GenerateAccessToken(user)
-	if err != nil {
-		http.Error(w, "Token generation failed", http.StatusInternalServerError)
-		return
+	response := map[string]string{
+		"message": "Logged out successfully",
 	}
 
-	newRefreshToken, err := h.authService.GenerateRefreshToken(user)
-	if err != nil {
-		http.Error(w, "Refresh token generation failed", http.StatusInternalServerError)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetProfile возвращает профиль текущего пользователя
+func (h *AuthHTTPHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// Обновляем refresh token в Redis
-	if err := h.authService.UpdateRefreshToken(claims.UserID, req.RefreshToken, newRefreshToken); err != nil {
-		http.Error(w, "Token update failed", http.StatusInternalServerError)
+	userID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
 		return
 	}
 
-	// Возвращаем новые токены
-	response := models.AuthResponse{
-		AccessToken:  newAccessToken,
-		RefreshToken: newRefreshToken,
-		ExpiresIn:    15 * 60, // 15 минут в секундах
-		User:         *user,
+	profile, err := h.authService.GetProfile(userID)
+	if err != nil {
+		http.Error(w, "Пользователь не найден", http.StatusNotFound)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	json.NewEncoder(w).Encode(profile)
 }
 
-// Logout выходит из системы
-func (h *AuthHTTPHandler) Logout(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+// UpdateProfile изменяет имя, телефон и предпочтения текущего пользователя
+// PATCH /api/auth/profile
+func (h *AuthHTTPHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPatch {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
 		return
 	}
 
-	// В реальном приложении нужно извлечь user_id из JWT токена
-	// Для упрощения возвращаем успех
-	response := map[string]string{
-		"message": "Logged out successfully",
+	userID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
-}
-
-// GetProfile возвращает профиль текущего пользователя
-func (h *AuthHTTPHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	var req models.ProfileUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверные данные: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// В реальном приложении нужно извлечь данные из JWT токена
-	// Для упрощения возвращаем базовую информацию
-	profile := map[string]interface{}{
-		"user_id":          1,
-		"telegram_id":      12345,
-		"role":             "client",
-		"authenticated_at": time.Now().Format(time.RFC3339),
+	profile, err := h.authService.UpdateProfile(userID, req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, services.ErrInvalidProfile):
+			status = http.StatusBadRequest
+		case errors.Is(err, services.ErrPreferencesClientOnly):
+			status = http.StatusForbidden
+		}
+		http.Error(w, "Ошибка обновления профиля: "+err.Error(), status)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -212,79 +267,273 @@ func (h *AuthHTTPHandler) RegisterDirect(w http.ResponseWriter, r *http.Request)
 		http.Error(w, "Ошибка регистрации: "+err.Error(), http.StatusBadRequest)
 		return
 	}
+	sendVerificationEmail(h.emailVerification, user)
 
-	// Генерируем токены
-	accessToken, err := h.authService.GenerateAccessToken(user)
+	// Открываем сессию устройства и выдаем токены
+	response, err := h.authService.CreateSession(user, deviceFromRequest(r))
 	if err != nil {
-		http.Error(w, "Ошибка генерации access token: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, "Ошибка создания сессии: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// LoginDirect обрабатывает прямую авторизацию пользователя
+func (h *AuthHTTPHandler) LoginDirect(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.DirectLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверные данные: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Попытка учитывается до проверки пароля; после серии неудачных попыток
+	// пароль не проверяется до истечения задержки
+	ip := clientIP(r)
+	if wait := h.loginThrottle.Reserve(req.Email, ip); wait > 0 {
+		h.recordAuthFailure(r, models.AuditActionLoginFailure, 0, "email: "+req.Email+", вход временно заблокирован")
+		writeTooManyAttempts(w, wait)
 		return
 	}
 
-	refreshToken, err := h.authService.GenerateRefreshToken(user)
+	// Авторизуем пользователя; ошибка одинакова для неизвестного email и неверного пароля
+	user, err := h.authService.LoginDirect(req)
 	if err != nil {
-		http.Error(w, "Ошибка генерации refresh token: "+err.Error(), http.StatusInternalServerError)
+		h.recordAuthFailure(r, models.AuditActionLoginFailure, 0, "email: "+req.Email)
+		http.Error(w, "Ошибка авторизации: "+services.ErrInvalidCredentials.Error(), http.StatusUnauthorized)
+		return
+	}
+	h.loginThrottle.RecordSuccess(req.Email, ip)
+
+	h.completeLogin(w, r, user, "direct")
+}
+
+// completeLogin завершает вход после проверки первого фактора: открывает сессию устройства
+// и выдает токены. Если у пользователя включена или обязательна по политике 2FA,
+// вместо токенов выдается токен второго шага входа
+func (h *AuthHTTPHandler) completeLogin(w http.ResponseWriter, r *http.Request, user *models.User, method string) {
+	purpose := ""
+	switch {
+	case user.HasTwoFactor():
+		purpose = services.MFAChallengeLogin
+	case h.twoFactor.IsRequired(user.ID):
+		purpose = services.MFAChallengeEnroll
+	}
+	if purpose != "" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(models.MFAChallengeResponse{
+			MFARequired:        true,
+			EnrollmentRequired: purpose == services.MFAChallengeEnroll,
+			ChallengeToken:     h.twoFactor.IssueChallenge(user, purpose),
+			ExpiresIn:          int(services.MFAChallengeTTL.Seconds()),
+		})
 		return
 	}
 
-	// Сохраняем refresh token
-	if err := h.authService.StoreRefreshToken(user.ID, refreshToken); err != nil {
-		http.Error(w, "Ошибка сохранения refresh token: "+err.Error(), http.StatusInternalServerError)
+	response, err := h.authService.CreateSession(user, deviceFromRequest(r))
+	if err != nil {
+		http.Error(w, "Ошибка создания сессии: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+	h.recordAuthSuccess(r, models.AuditActionLoginSuccess, user.ID, method)
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(models.AuthResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresIn:    900, // 15 минут
-		User:         *user,
-	})
+	json.NewEncoder(w).Encode(response)
 }
 
-// LoginDirect обрабатывает прямую авторизацию пользователя
-func (h *AuthHTTPHandler) LoginDirect(w http.ResponseWriter, r *http.Request) {
+// LoginTwoFactor второй шаг входа: проверяет код из приложения или код восстановления и выдает токены
+// POST /api/auth/login/2fa
+func (h *AuthHTTPHandler) LoginTwoFactor(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
 		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
 		return
 	}
 
-	var req models.DirectLoginRequest
+	var req models.MFAVerifyRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Неверные данные: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// Авторизуем пользователя
-	user, err := h.authService.LoginDirect(req)
-	if err != nil {
-		http.Error(w, "Ошибка авторизации: "+err.Error(), http.StatusUnauthorized)
+	user, ok := h.challengeUser(w, r, req.ChallengeToken, services.MFAChallengeLogin)
+	if !ok || !h.reserveTwoFactorAttempt(w, r, user) {
+		return
+	}
+	if !h.checkTwoFactorCode(w, r, user, h.twoFactor.VerifyCode(user, req.Code)) ||
+		!h.useChallenge(w, req.ChallengeToken, services.MFAChallengeLogin) {
 		return
 	}
 
-	// Генерируем токены
-	accessToken, err := h.authService.GenerateAccessToken(user)
+	response, err := h.authService.CreateSession(user, deviceFromRequest(r))
 	if err != nil {
-		http.Error(w, "Ошибка генерации access token: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, "Ошибка создания сессии: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.recordAuthSuccess(r, models.AuditActionLoginSuccess, user.ID, "2fa")
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// LoginTwoFactorSetup начинает подключение обязательной 2FA по токену второго шага входа
+// POST /api/auth/login/2fa/setup
+func (h *AuthHTTPHandler) LoginTwoFactorSetup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.MFAChallengeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверные данные: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := h.challengeUser(w, r, req.ChallengeToken, services.MFAChallengeEnroll)
+	if !ok {
 		return
 	}
 
-	refreshToken, err := h.authService.GenerateRefreshToken(user)
+	setup, err := h.twoFactor.BeginEnrollment(user.ID)
 	if err != nil {
-		http.Error(w, "Ошибка генерации refresh token: "+err.Error(), http.StatusInternalServerError)
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(setup)
+}
+
+// LoginTwoFactorConfirm завершает подключение обязательной 2FA и выдает токены и коды восстановления
+// POST /api/auth/login/2fa/confirm
+func (h *AuthHTTPHandler) LoginTwoFactorConfirm(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.MFAVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверные данные: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, ok := h.challengeUser(w, r, req.ChallengeToken, services.MFAChallengeEnroll)
+	if !ok || !h.reserveTwoFactorAttempt(w, r, user) {
 		return
 	}
+	codes, err := h.twoFactor.ConfirmEnrollment(user.ID, req.Code)
+	if !h.checkTwoFactorCode(w, r, user, err) ||
+		!h.useChallenge(w, req.ChallengeToken, services.MFAChallengeEnroll) {
+		return
+	}
+	actor := auditActorFromRequest(r)
+	actor.UserID = user.ID
+	h.auditService.Record(actor, models.AuditEntry{
+		Action:     models.AuditActionTwoFactorEnable,
+		TargetType: models.AuditTargetUser,
+		TargetID:   user.ID,
+	})
 
-	// Сохраняем refresh token
-	if err := h.authService.StoreRefreshToken(user.ID, refreshToken); err != nil {
-		http.Error(w, "Ошибка сохранения refresh token: "+err.Error(), http.StatusInternalServerError)
+	response, err := h.authService.CreateSession(user, deviceFromRequest(r))
+	if err != nil {
+		http.Error(w, "Ошибка создания сессии: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
+	h.recordAuthSuccess(r, models.AuditActionLoginSuccess, user.ID, "2fa")
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(models.AuthResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresIn:    900, // 15 минут
-		User:         *user,
+	json.NewEncoder(w).Encode(models.MFAEnrollmentResponse{AuthResponse: *response, RecoveryCodes: codes})
+}
+
+// challengeUser проверяет токен второго шага входа.
+// При ошибке сам отвечает клиенту и возвращает ok = false
+func (h *AuthHTTPHandler) challengeUser(w http.ResponseWriter, r *http.Request, token, purpose string) (*models.User, bool) {
+	user, err := h.twoFactor.ParseChallenge(token, purpose)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return nil, false
+	}
+	return user, true
+}
+
+// useChallenge погашает токен второго шага после принятого кода, чтобы по нему нельзя было войти повторно.
+// Если токен уже использован, сам отвечает клиенту и возвращает false
+func (h *AuthHTTPHandler) useChallenge(w http.ResponseWriter, token, purpose string) bool {
+	if err := h.twoFactor.UseChallenge(token, purpose); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// reserveTwoFactorAttempt учитывает попытку ввода кода второго шага до его проверки.
+// Если попытки исчерпаны, сам отвечает клиенту и возвращает false
+func (h *AuthHTTPHandler) reserveTwoFactorAttempt(w http.ResponseWriter, r *http.Request, user *models.User) bool {
+	if wait := h.loginThrottle.Reserve(twoFactorThrottleKey(user), clientIP(r)); wait > 0 {
+		writeTooManyAttempts(w, wait)
+		return false
+	}
+	return true
+}
+
+// checkTwoFactorCode учитывает результат проверки кода второго шага в ограничении попыток и журнале аудита.
+// При ошибке сам отвечает клиенту и возвращает false
+func (h *AuthHTTPHandler) checkTwoFactorCode(w http.ResponseWriter, r *http.Request, user *models.User, err error) bool {
+	if err == nil {
+		h.loginThrottle.RecordSuccess(twoFactorThrottleKey(user), clientIP(r))
+		return true
+	}
+
+	if errors.Is(err, services.ErrInvalidTwoFactorCode) {
+		h.recordAuthFailure(r, models.AuditActionLoginFailure, user.ID, "2fa")
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return false
+	}
+	// Код не проверялся, попытка не считается
+	h.loginThrottle.Release(twoFactorThrottleKey(user), clientIP(r))
+	writeTwoFactorError(w, err)
+	return false
+}
+
+// writeTooManyAttempts отвечает 429 с заголовком Retry-After
+func writeTooManyAttempts(w http.ResponseWriter, wait time.Duration) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	http.Error(w, fmt.Sprintf("Слишком много попыток входа, повторите через %d с", seconds), http.StatusTooManyRequests)
+}
+
+// twoFactorThrottleKey ключ ограничения попыток второго шага. Отделен от ключа входа по паролю,
+// чтобы верный пароль не сбрасывал счетчик неверных кодов
+func twoFactorThrottleKey(user *models.User) string {
+	return "2fa:" + strconv.FormatUint(uint64(user.ID), 10)
+}
+
+// recordAuthSuccess записывает успешное действие аутентификации от имени пользователя
+func (h *AuthHTTPHandler) recordAuthSuccess(r *http.Request, action string, userID uint, details string) {
+	actor := auditActorFromRequest(r)
+	actor.UserID = userID
+	h.auditService.Record(actor, models.AuditEntry{
+		Action:     action,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID,
+		Details:    details,
+	})
+}
+
+// recordAuthFailure записывает неудачную попытку аутентификации; userID равен 0, если пользователь неизвестен
+func (h *AuthHTTPHandler) recordAuthFailure(r *http.Request, action string, userID uint, details string) {
+	h.auditService.Record(auditActorFromRequest(r), models.AuditEntry{
+		Action:     action,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID,
+		Failed:     true,
+		Details:    details,
 	})
 }
@@ -10,12 +10,14 @@ import (
 
 // AuthRolesHandler обрабатывает HTTP запросы с ролевой авторизацией
 type AuthRolesHandler struct {
-	authService services.AuthService
+	authService       services.AuthService
+	emailVerification services.EmailVerificationService
 }
 
-// NewAuthRolesHandler создает новый экземпляр AuthRolesHandler
-func NewAuthRolesHandler(authService services.AuthService) *AuthRolesHandler {
-	return &AuthRolesHandler{authService: authService}
+// NewAuthRolesHandler создает новый экземпляр AuthRolesHandler.
+// После регистрации пользователю отправляется письмо подтверждения email
+func NewAuthRolesHandler(authService services.AuthService, emailVerification services.EmailVerificationService) *AuthRolesHandler {
+	return &AuthRolesHandler{authService: authService, emailVerification: emailVerification}
 }
 
 // RegisterClient обрабатывает регистрацию клиента (публичный endpoint)
@@ -37,33 +39,17 @@ func (h *AuthRolesHandler) RegisterClient(w http.ResponseWriter, r *http.Request
 		http.Error(w, "Ошибка регистрации: "+err.Error(), http.StatusBadRequest)
 		return
 	}
+	sendVerificationEmail(h.emailVerification, user)
 
-	// Генерируем токены
-	accessToken, err := h.authService.GenerateAccessToken(user)
+	// Открываем сессию устройства и выдаем токены
+	response, err := h.authService.CreateSession(user, deviceFromRequest(r))
 	if err != nil {
-		http.Error(w, "Ошибка генерации access token: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	refreshToken, err := h.authService.GenerateRefreshToken(user)
-	if err != nil {
-		http.Error(w, "Ошибка генерации refresh token: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	// Сохраняем refresh token
-	if err := h.authService.StoreRefreshToken(user.ID, refreshToken); err != nil {
-		http.Error(w, "Ошибка сохранения refresh token: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, "Ошибка создания сессии: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(models.AuthResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresIn:    900, // 15 минут
-		User:         *user,
-	})
+	json.NewEncoder(w).Encode(response)
 }
 
 // RegisterBarber обрабатывает регистрацию барбера (только админ)
@@ -85,31 +71,15 @@ func (h *AuthRolesHandler) RegisterBarber(w http.ResponseWriter, r *http.Request
 		http.Error(w, "Ошибка регистрации: "+err.Error(), http.StatusBadRequest)
 		return
 	}
+	sendVerificationEmail(h.emailVerification, user)
 
-	// Генерируем токены
-	accessToken, err := h.authService.GenerateAccessToken(user)
+	// Открываем сессию устройства и выдаем токены
+	response, err := h.authService.CreateSession(user, deviceFromRequest(r))
 	if err != nil {
-		http.Error(w, "Ошибка генерации access token: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	refreshToken, err := h.authService.GenerateRefreshToken(user)
-	if err != nil {
-		http.Error(w, "Ошибка генерации refresh token: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	// Сохраняем refresh token
-	if err := h.authService.StoreRefreshToken(user.ID, refreshToken); err != nil {
-		http.Error(w, "Ошибка сохранения refresh token: "+err.Error(), http.StatusInternalServerError)
+		http.Error(w, "Ошибка создания сессии: "+err.Error(), http.StatusInternalServerError)
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(models.AuthResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresIn:    900, // 15 минут
-		User:         *user,
-	})
+	json.NewEncoder(w).Encode(response)
 }
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"garage-barbershop/internal/services"
+)
+
+// AvailabilityHandler обрабатывает HTTP запросы свободного времени барберов
+type AvailabilityHandler struct {
+	availabilityService services.AvailabilityService
+}
+
+// NewAvailabilityHandler создает новый экземпляр AvailabilityHandler
+func NewAvailabilityHandler(availabilityService services.AvailabilityService) *AvailabilityHandler {
+	return &AvailabilityHandler{availabilityService: availabilityService}
+}
+
+// GetAvailability возвращает свободные слоты барбера для услуги
+// GET /api/barbers/{id}/availability?service_id=&from=2006-01-02&to=2006-01-02
+// Даты понимаются в часовом поясе барбера, по умолчанию показывается ближайшая неделя
+func (h *AvailabilityHandler) GetAvailability(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	barberID, action, err := extractIDAndAction(r.URL.Path, "/api/barbers/")
+	if err != nil {
+		http.Error(w, "Неверный ID барбера: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if action != "availability" {
+		http.NotFound(w, r)
+		return
+	}
+
+	serviceID, err := parseOptionalUint(r, "service_id")
+	if err != nil || serviceID == 0 {
+		http.Error(w, "Параметр service_id обязателен", http.StatusBadRequest)
+		return
+	}
+
+	availability, err := h.availabilityService.GetAvailableSlots(barberID, serviceID, r.URL.Query().Get("from"), r.URL.Query().Get("to"))
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrServiceNotFound) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, "Ошибка получения свободного времени: "+err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(availability)
+}
@@ -2,10 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
-	"strconv"
-	"strings"
 
 	"garage-barbershop/internal/models"
 	"garage-barbershop/internal/services"
@@ -85,7 +82,7 @@ func (h *BarberHandler) AdminUpdateBarber(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	barber, err := h.barberService.UpdateBarber(barberID, req)
+	barber, err := h.barberService.UpdateBarber(auditActorFromRequest(r), barberID, req)
 	if err != nil {
 		http.Error(w, "Ошибка обновления барбера: "+err.Error(), http.StatusBadRequest)
 		return
@@ -109,7 +106,7 @@ func (h *BarberHandler) AdminDeleteBarber(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	if err := h.barberService.DeleteBarber(barberID); err != nil {
+	if err := h.barberService.DeleteBarber(auditActorFromRequest(r), barberID); err != nil {
 		http.Error(w, "Ошибка удаления барбера: "+err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -176,15 +173,5 @@ func (h *BarberHandler) BarberUpdateSelf(w http.ResponseWriter, r *http.Request)
 
 // extractIDFromURL извлекает ID из URL
 func (h *BarberHandler) extractIDFromURL(path, prefix string) (uint, error) {
-	idStr := strings.TrimPrefix(path, prefix)
-	if idStr == "" {
-		return 0, fmt.Errorf("ID не указан")
-	}
-
-	id, err := strconv.ParseUint(idStr, 10, 32)
-	if err != nil {
-		return 0, fmt.Errorf("неверный формат ID")
-	}
-
-	return uint(id), nil
+	return extractIDFromURL(path, prefix)
 }
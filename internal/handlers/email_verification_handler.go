@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/services"
+)
+
+// EmailVerificationHandler обрабатывает HTTP запросы подтверждения email
+type EmailVerificationHandler struct {
+	verificationService services.EmailVerificationService
+	auditService        services.AuditService
+}
+
+// NewEmailVerificationHandler создает новый экземпляр EmailVerificationHandler
+func NewEmailVerificationHandler(verificationService services.EmailVerificationService, auditService services.AuditService) *EmailVerificationHandler {
+	return &EmailVerificationHandler{verificationService: verificationService, auditService: auditService}
+}
+
+// VerifyEmail подтверждает email по токену из письма.
+// GET принимает токен параметром token (переход по ссылке), POST - в теле запроса
+// GET, POST /api/auth/email/verify
+func (h *EmailVerificationHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var token string
+	switch r.Method {
+	case http.MethodGet:
+		token = r.URL.Query().Get("token")
+	case http.MethodPost:
+		var req models.EmailVerifyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Неверные данные: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		token = req.Token
+	default:
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, err := h.verificationService.Verify(token)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalidVerificationToken) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	actor := auditActorFromRequest(r)
+	actor.UserID = user.ID
+	h.auditService.Record(actor, models.AuditEntry{
+		Action:     models.AuditActionEmailVerified,
+		TargetType: models.AuditTargetUser,
+		TargetID:   user.ID,
+		Details:    user.Email,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message":           "Email подтвержден",
+		"email_verified_at": user.EmailVerifiedAt,
+	})
+}
+
+// ResendVerification повторно отправляет письмо подтверждения текущему пользователю
+// POST /api/auth/email/resend
+func (h *EmailVerificationHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
+	}
+
+	if err := h.verificationService.Resend(userID); err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, services.ErrEmailAlreadyVerified):
+			status = http.StatusConflict
+		case errors.Is(err, services.ErrEmailNotSet):
+			status = http.StatusBadRequest
+		case errors.Is(err, services.ErrVerificationTooFrequent):
+			status = http.StatusTooManyRequests
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"message": "Письмо подтверждения отправлено"})
+}
+
+// sendVerificationEmail отправляет письмо подтверждения после регистрации.
+// Регистрация уже выполнена, поэтому ошибка только логируется: письмо можно запросить повторно
+func sendVerificationEmail(verificationService services.EmailVerificationService, user *models.User) {
+	if err := verificationService.SendVerification(user); err != nil {
+		log.Printf("⚠️  Ошибка отправки письма подтверждения пользователю %d: %v", user.ID, err)
+	}
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"garage-barbershop/internal/models"
+)
+
+// extractIDFromURL извлекает ID из URL вида <prefix><id>
+func extractIDFromURL(path, prefix string) (uint, error) {
+	idStr := strings.Trim(strings.TrimPrefix(path, prefix), "/")
+	if idStr == "" {
+		return 0, fmt.Errorf("ID не указан")
+	}
+
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("неверный формат ID")
+	}
+
+	return uint(id), nil
+}
+
+// extractIDAndAction извлекает ID и действие из URL вида <prefix><id>/<action>
+func extractIDAndAction(path, prefix string) (uint, string, error) {
+	parts := strings.SplitN(strings.Trim(strings.TrimPrefix(path, prefix), "/"), "/", 2)
+
+	id, err := extractIDFromURL(parts[0], "")
+	if err != nil {
+		return 0, "", err
+	}
+
+	action := ""
+	if len(parts) == 2 {
+		action = parts[1]
+	}
+	return id, action, nil
+}
+
+// adminBarbersPrefix префикс маршрутов администратора для управления барберами
+const adminBarbersPrefix = "/api/admin/barbers/"
+
+// scheduleTarget определяет барбера и вложенный элемент расписания из URL запроса:
+// /api/barber/<resource>[/<item>] - текущий барбер,
+// /api/admin/barbers/{id}/<resource>[/<item>] - любой барбер (для администратора).
+// При ошибке сам отвечает клиенту и возвращает ok = false
+func scheduleTarget(w http.ResponseWriter, r *http.Request, resource string) (barberID uint, item string, ok bool) {
+	if strings.HasPrefix(r.URL.Path, adminBarbersPrefix) {
+		id, action, err := extractIDAndAction(r.URL.Path, adminBarbersPrefix)
+		if err != nil {
+			http.Error(w, "Неверный ID барбера: "+err.Error(), http.StatusBadRequest)
+			return 0, "", false
+		}
+		return id, strings.Trim(strings.TrimPrefix(action, resource), "/"), true
+	}
+
+	barberID, ok = getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return 0, "", false
+	}
+	return barberID, strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/barber/"+resource), "/"), true
+}
+
+// getUserIDFromContext получает ID аутентифицированного пользователя из контекста запроса
+func getUserIDFromContext(r *http.Request) (uint, bool) {
+	userID, ok := r.Context().Value("userID").(uint)
+	return userID, ok
+}
+
+// clientIP возвращает IP адрес клиента, определенный HTTPClientIPMiddleware с учетом доверенных прокси,
+// или адрес соединения, если middleware не подключен. Заголовки прокси здесь не читаются: их задает клиент
+func clientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value("clientIP").(string); ok && ip != "" {
+		return ip
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// auditActorFromRequest собирает инициатора действия для журнала аудита из запроса
+func auditActorFromRequest(r *http.Request) models.AuditActor {
+	userID, _ := getUserIDFromContext(r)
+	return models.AuditActor{
+		UserID:    userID,
+		IP:        clientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+}
+
+// deviceFromRequest описывает устройство клиента для сессии.
+// Название устройства берется из заголовка X-Device-Name, иначе из User-Agent
+func deviceFromRequest(r *http.Request) models.DeviceInfo {
+	name := strings.TrimSpace(r.Header.Get("X-Device-Name"))
+	if name == "" {
+		name = r.UserAgent()
+	}
+	return models.DeviceInfo{
+		Name:      name,
+		IP:        clientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+}
+
+// getSessionIDFromContext получает ID сессии текущего access token из контекста запроса
+func getSessionIDFromContext(r *http.Request) uint {
+	sessionID, _ := r.Context().Value("sessionID").(uint)
+	return sessionID
+}
+
+// parseOptionalUint разбирает необязательный числовой query-параметр
+func parseOptionalUint(r *http.Request, name string) (uint, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return 0, nil
+	}
+
+	parsed, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("неверное значение параметра %s", name)
+	}
+	return uint(parsed), nil
+}
+
+// parseOptionalInt разбирает необязательный целочисленный query-параметр
+func parseOptionalInt(r *http.Request, name string) (*int, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, fmt.Errorf("неверное значение параметра %s", name)
+	}
+	return &parsed, nil
+}
+
+// parseOptionalFloat разбирает необязательный дробный query-параметр
+func parseOptionalFloat(r *http.Request, name string) (*float64, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("неверное значение параметра %s", name)
+	}
+	return &parsed, nil
+}
+
+// parseOptionalTime разбирает необязательный query-параметр времени в формате RFC3339
+func parseOptionalTime(r *http.Request, name string) (*time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return nil, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("неверное значение параметра %s", name)
+	}
+	return &parsed, nil
+}
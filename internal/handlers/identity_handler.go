@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/services"
+)
+
+// IdentityHandler обрабатывает HTTP запросы управления способами входа и объединения аккаунтов
+type IdentityHandler struct {
+	identityService   services.IdentityService
+	authService       services.AuthService
+	emailVerification services.EmailVerificationService
+	auditService      services.AuditService
+}
+
+// NewIdentityHandler создает новый экземпляр IdentityHandler
+func NewIdentityHandler(identityService services.IdentityService, authService services.AuthService, emailVerification services.EmailVerificationService, auditService services.AuditService) *IdentityHandler {
+	return &IdentityHandler{
+		identityService:   identityService,
+		authService:       authService,
+		emailVerification: emailVerification,
+		auditService:      auditService,
+	}
+}
+
+// ListIdentities возвращает способы входа текущего пользователя
+// GET /api/auth/identities
+func (h *IdentityHandler) ListIdentities(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
+	}
+
+	identities, err := h.identityService.ListIdentities(userID)
+	if err != nil {
+		writeIdentityError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"identities": identities})
+}
+
+// Telegram привязывает (POST) или отвязывает (DELETE) Telegram текущего пользователя.
+// Для привязки передаются данные Telegram Login Widget или initData Telegram WebApp
+// POST, DELETE /api/auth/identities/telegram
+func (h *IdentityHandler) Telegram(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.linkTelegram(w, r)
+	case http.MethodDelete:
+		h.unlinkTelegram(w, r)
+	default:
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+	}
+}
+
+// linkTelegram проверяет подпись данных Telegram и привязывает его к текущему пользователю
+func (h *IdentityHandler) linkTelegram(w http.ResponseWriter, r *http.Request) {
+	userID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.LinkTelegramRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверные данные: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var authData models.TelegramAuthData
+	switch {
+	case req.InitData != "":
+		initData, err := h.authService.ValidateWebAppInitData(req.InitData)
+		if err != nil {
+			http.Error(w, "Ошибка проверки Telegram: "+err.Error(), http.StatusUnauthorized)
+			return
+		}
+		authData = initData.AuthData()
+	case len(req.Telegram) > 0:
+		loginData, err := h.authService.ValidateTelegramAuth(req.Telegram)
+		if err != nil {
+			http.Error(w, "Ошибка проверки Telegram: "+err.Error(), http.StatusUnauthorized)
+			return
+		}
+		authData = *loginData
+	default:
+		http.Error(w, "Неверные данные: нужны telegram или init_data", http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.identityService.LinkTelegram(userID, authData)
+	if err != nil {
+		writeIdentityError(w, err)
+		return
+	}
+	h.auditService.Record(auditActorFromRequest(r), models.AuditEntry{
+		Action:     models.AuditActionIdentityLink,
+		TargetType: models.AuditTargetUser,
+		TargetID:   user.ID,
+		Details:    models.IdentityTelegram + ": " + strconv.FormatInt(user.TelegramID, 10),
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"identities": user.Identities()})
+}
+
+// unlinkTelegram отвязывает Telegram, если у пользователя есть вход по паролю
+func (h *IdentityHandler) unlinkTelegram(w http.ResponseWriter, r *http.Request) {
+	userID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
+	}
+
+	user, err := h.identityService.UnlinkTelegram(userID)
+	if err != nil {
+		writeIdentityError(w, err)
+		return
+	}
+	h.auditService.Record(auditActorFromRequest(r), models.AuditEntry{
+		Action:     models.AuditActionIdentityUnlink,
+		TargetType: models.AuditTargetUser,
+		TargetID:   user.ID,
+		Details:    models.IdentityTelegram,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"identities": user.Identities()})
+}
+
+// SetPassword добавляет вход по email и паролю и отправляет письмо подтверждения email
+// POST /api/auth/identities/password
+func (h *IdentityHandler) SetPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.SetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверные данные: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.identityService.SetPassword(userID, req.Email, req.Password)
+	if err != nil {
+		writeIdentityError(w, err)
+		return
+	}
+	if user.NeedsEmailVerification() {
+		sendVerificationEmail(h.emailVerification, user)
+	}
+	h.auditService.Record(auditActorFromRequest(r), models.AuditEntry{
+		Action:     models.AuditActionIdentityLink,
+		TargetType: models.AuditTargetUser,
+		TargetID:   user.ID,
+		Details:    models.IdentityPassword + ": " + user.Email,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"identities": user.Identities()})
+}
+
+// MergeUsers объединяет дубликат с аккаунтом из пути запроса: переносит способы входа,
+// записи, отзывы и роли, после чего удаляет дубликат
+// POST /api/admin/users/{id}/merge
+func (h *IdentityHandler) MergeUsers(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	primaryID, _, err := extractIDAndAction(r.URL.Path, "/api/admin/users/")
+	if err != nil {
+		http.Error(w, "Неверный ID пользователя: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req models.UserMergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверные данные: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.DuplicateUserID == 0 {
+		http.Error(w, "Неверные данные: не указан duplicate_user_id", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.identityService.MergeUsers(primaryID, req.DuplicateUserID)
+	if err != nil {
+		writeIdentityError(w, err)
+		return
+	}
+	h.auditService.Record(auditActorFromRequest(r), models.AuditEntry{
+		Action:     models.AuditActionUserMerge,
+		TargetType: models.AuditTargetUser,
+		TargetID:   result.User.ID,
+		Details:    "duplicate_user_id: " + strconv.FormatUint(uint64(req.DuplicateUserID), 10),
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// writeIdentityError отправляет ошибку управления способами входа с подходящим статусом
+func writeIdentityError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrTelegramAlreadyLinked),
+		errors.Is(err, services.ErrTelegramLinkedElsewhere),
+		errors.Is(err, services.ErrTelegramNotLinked),
+		errors.Is(err, services.ErrLastIdentity),
+		errors.Is(err, services.ErrPasswordAlreadySet),
+		errors.Is(err, services.ErrEmailTaken),
+		errors.Is(err, services.ErrMergeConflict),
+		errors.Is(err, services.ErrMergeBarberData),
+		errors.Is(err, services.ErrMergeTwoFactor):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, services.ErrEmailRequired),
+		errors.Is(err, services.ErrMergeSameUser),
+		errors.Is(err, services.ErrWeakPassword):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
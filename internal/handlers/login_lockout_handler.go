@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/services"
+)
+
+// LoginLockoutHandler обрабатывает HTTP запросы администратора по блокировкам входа
+type LoginLockoutHandler struct {
+	authService   services.AuthService
+	loginThrottle services.LoginThrottleService
+	auditService  services.AuditService
+}
+
+// NewLoginLockoutHandler создает новый экземпляр LoginLockoutHandler
+func NewLoginLockoutHandler(authService services.AuthService, loginThrottle services.LoginThrottleService, auditService services.AuditService) *LoginLockoutHandler {
+	return &LoginLockoutHandler{authService: authService, loginThrottle: loginThrottle, auditService: auditService}
+}
+
+// UnlockUser снимает блокировку входа по паролю после неудачных попыток.
+// Счетчики IP адресов не сбрасываются
+// POST /api/admin/users/{id}/unlock
+func (h *LoginLockoutHandler) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, _, err := extractIDAndAction(r.URL.Path, "/api/admin/users/")
+	if err != nil {
+		http.Error(w, "Неверный ID пользователя: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.authService.GetUserByID(userID)
+	if err != nil {
+		http.Error(w, "Пользователь не найден", http.StatusNotFound)
+		return
+	}
+
+	if user.Email != "" {
+		if err := h.loginThrottle.Unlock(user.Email); err != nil {
+			http.Error(w, "Ошибка снятия блокировки: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	h.auditService.Record(auditActorFromRequest(r), models.AuditEntry{
+		Action:     models.AuditActionLoginUnlock,
+		TargetType: models.AuditTargetUser,
+		TargetID:   user.ID,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Вход разблокирован",
+		"user_id": user.ID,
+	})
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/services"
+)
+
+// PasswordHandler обрабатывает HTTP запросы смены и восстановления пароля
+type PasswordHandler struct {
+	passwordService services.PasswordService
+	authService     services.AuthService
+	auditService    services.AuditService
+}
+
+// NewPasswordHandler создает новый экземпляр PasswordHandler
+func NewPasswordHandler(passwordService services.PasswordService, authService services.AuthService, auditService services.AuditService) *PasswordHandler {
+	return &PasswordHandler{passwordService: passwordService, authService: authService, auditService: auditService}
+}
+
+// ForgotPassword отправляет ссылку для сброса пароля.
+// Ответ не зависит от того, зарегистрирован ли email
+// POST /api/auth/password/forgot
+func (h *PasswordHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.PasswordForgotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверные данные: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	email := strings.TrimSpace(req.Email)
+	if email == "" {
+		http.Error(w, "Не указан email", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.passwordService.RequestReset(email); err != nil {
+		log.Printf("⚠️  Ошибка отправки ссылки сброса пароля: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Если адрес зарегистрирован, на него отправлена ссылка для сброса пароля",
+	})
+}
+
+// ResetPassword устанавливает новый пароль по токену из письма
+// POST /api/auth/password/reset
+func (h *PasswordHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.PasswordResetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверные данные: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.passwordService.ResetPassword(req.Token, req.NewPassword)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidResetToken):
+			h.auditService.Record(auditActorFromRequest(r), models.AuditEntry{Action: models.AuditActionPasswordReset, Failed: true})
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, services.ErrWeakPassword):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, "Ошибка сброса пароля: "+err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	actor := auditActorFromRequest(r)
+	actor.UserID = user.ID
+	h.auditService.Record(actor, models.AuditEntry{
+		Action:     models.AuditActionPasswordReset,
+		TargetType: models.AuditTargetUser,
+		TargetID:   user.ID,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Пароль изменен, войдите с новым паролем"})
+}
+
+// ChangePassword меняет пароль аутентифицированного пользователя по текущему паролю.
+// Все сессии завершаются, для текущего устройства открывается новая
+// POST /api/auth/password/change
+func (h *PasswordHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.PasswordChangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверные данные: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.passwordService.ChangePassword(userID, req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrWrongCurrentPassword):
+			h.auditService.Record(auditActorFromRequest(r), models.AuditEntry{
+				Action:     models.AuditActionPasswordChange,
+				TargetType: models.AuditTargetUser,
+				TargetID:   userID,
+				Failed:     true,
+			})
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case errors.Is(err, services.ErrWeakPassword):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			http.Error(w, "Ошибка смены пароля: "+err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	h.auditService.Record(auditActorFromRequest(r), models.AuditEntry{
+		Action:     models.AuditActionPasswordChange,
+		TargetType: models.AuditTargetUser,
+		TargetID:   user.ID,
+	})
+
+	response, err := h.authService.CreateSession(user, deviceFromRequest(r))
+	if err != nil {
+		http.Error(w, "Ошибка создания сессии: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/payments"
+	"garage-barbershop/internal/services"
+)
+
+// maxWebhookBodySize ограничение размера уведомления платежной системы
+const maxWebhookBodySize = 1 << 20
+
+// PaymentHandler обрабатывает HTTP запросы оплаты записей
+type PaymentHandler struct {
+	paymentService services.PaymentService
+}
+
+// NewPaymentHandler создает новый экземпляр PaymentHandler
+func NewPaymentHandler(paymentService services.PaymentService) *PaymentHandler {
+	return &PaymentHandler{paymentService: paymentService}
+}
+
+// ClientPay создает платеж по записи клиента
+// POST /api/appointments/{id}/pay
+func (h *PaymentHandler) ClientPay(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	clientID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
+	}
+
+	appointmentID, _, err := extractIDAndAction(r.URL.Path, "/api/appointments/")
+	if err != nil {
+		http.Error(w, "Неверный ID записи: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req models.PaymentCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверные данные: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	payment, err := h.paymentService.PayForAppointment(clientID, appointmentID, req)
+	if err != nil {
+		http.Error(w, "Ошибка оплаты: "+err.Error(), paymentErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(payment)
+}
+
+// ClientGetPayments возвращает платежи по записи клиента
+// GET /api/appointments/{id}/payments
+func (h *PaymentHandler) ClientGetPayments(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	clientID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
+	}
+
+	appointmentID, _, err := extractIDAndAction(r.URL.Path, "/api/appointments/")
+	if err != nil {
+		http.Error(w, "Неверный ID записи: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	paymentList, err := h.paymentService.GetClientPayments(clientID, appointmentID)
+	if err != nil {
+		http.Error(w, "Ошибка получения платежей: "+err.Error(), paymentErrorStatus(err))
+		return
+	}
+
+	writePayments(w, paymentList)
+}
+
+// BarberGetPayments возвращает платежи по записи к барберу
+// GET /api/barber/appointments/{id}/payments
+func (h *PaymentHandler) BarberGetPayments(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	barberID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
+	}
+
+	appointmentID, _, err := extractIDAndAction(r.URL.Path, "/api/barber/appointments/")
+	if err != nil {
+		http.Error(w, "Неверный ID записи: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	paymentList, err := h.paymentService.GetBarberPayments(barberID, appointmentID)
+	if err != nil {
+		http.Error(w, "Ошибка получения платежей: "+err.Error(), paymentErrorStatus(err))
+		return
+	}
+
+	writePayments(w, paymentList)
+}
+
+// BarberPaymentAction подтверждает получение денег или оформляет возврат
+// POST /api/barber/payments/{id}/capture, POST /api/barber/payments/{id}/refund
+func (h *PaymentHandler) BarberPaymentAction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	barberID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
+	}
+
+	paymentID, action, err := extractIDAndAction(r.URL.Path, "/api/barber/payments/")
+	if err != nil {
+		http.Error(w, "Неверный ID платежа: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var payment *models.Payment
+	switch action {
+	case "capture":
+		payment, err = h.paymentService.CapturePayment(barberID, paymentID)
+	case "refund":
+		payment, err = h.paymentService.RefundPayment(barberID, paymentID)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Ошибка обработки платежа: "+err.Error(), paymentErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(payment)
+}
+
+// AdminRefund оформляет возврат по любому платежу
+// POST /api/admin/payments/{id}/refund
+func (h *PaymentHandler) AdminRefund(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	paymentID, action, err := extractIDAndAction(r.URL.Path, "/api/admin/payments/")
+	if err != nil {
+		http.Error(w, "Неверный ID платежа: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if action != "refund" {
+		http.NotFound(w, r)
+		return
+	}
+
+	payment, err := h.paymentService.AdminRefundPayment(paymentID)
+	if err != nil {
+		http.Error(w, "Ошибка возврата: "+err.Error(), paymentErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(payment)
+}
+
+// Webhook принимает уведомления платежных систем
+// POST /api/payments/webhook/{method}
+func (h *PaymentHandler) Webhook(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	method := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/payments/webhook/"), "/")
+
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBodySize))
+	if err != nil {
+		http.Error(w, "Ошибка чтения уведомления", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.paymentService.HandleWebhook(method, payload, r.Header); err != nil {
+		http.Error(w, "Ошибка обработки уведомления: "+err.Error(), paymentErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
+
+// writePayments отправляет список платежей
+func writePayments(w http.ResponseWriter, paymentList []models.Payment) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"payments": paymentList,
+		"count":    len(paymentList),
+	})
+}
+
+// paymentErrorStatus подбирает HTTP статус по ошибке сервиса платежей
+func paymentErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrPaymentNotFound), errors.Is(err, services.ErrAppointmentNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrInvalidPaymentTransition), errors.Is(err, services.ErrAppointmentAlreadyPaid):
+		return http.StatusConflict
+	case errors.Is(err, payments.ErrInvalidSignature):
+		return http.StatusUnauthorized
+	default:
+		return http.StatusBadRequest
+	}
+}
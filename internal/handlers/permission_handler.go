@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/services"
+)
+
+// PermissionHandler обрабатывает HTTP запросы разрешений
+type PermissionHandler struct {
+	permissionService services.PermissionService
+}
+
+// NewPermissionHandler создает новый экземпляр PermissionHandler
+func NewPermissionHandler(permissionService services.PermissionService) *PermissionHandler {
+	return &PermissionHandler{permissionService: permissionService}
+}
+
+// GetMyPermissions возвращает разрешения текущего пользователя
+// GET /api/auth/permissions
+func (h *PermissionHandler) GetMyPermissions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
+	}
+
+	permissions, err := h.permissionService.GetUserPermissions(userID)
+	if err != nil {
+		http.Error(w, "Ошибка получения разрешений: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"permissions": permissions})
+}
+
+// SetRolePermissions заменяет разрешения роли
+// PUT /api/admin/roles/{id}/permissions
+func (h *PermissionHandler) SetRolePermissions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	roleID, _, err := extractIDAndAction(r.URL.Path, "/api/admin/roles/")
+	if err != nil {
+		http.Error(w, "Неверный ID роли: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var permissions models.PermissionSet
+	if err := json.NewDecoder(r.Body).Decode(&permissions); err != nil {
+		http.Error(w, "Неверные данные: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	role, err := h.permissionService.SetRolePermissions(roleID, permissions)
+	if err != nil {
+		http.Error(w, "Ошибка изменения разрешений: "+err.Error(), roleErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(role)
+}
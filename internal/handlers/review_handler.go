@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/services"
+)
+
+// ReviewHandler обрабатывает HTTP запросы отзывов
+type ReviewHandler struct {
+	reviewService services.ReviewService
+}
+
+// NewReviewHandler создает новый экземпляр ReviewHandler
+func NewReviewHandler(reviewService services.ReviewService) *ReviewHandler {
+	return &ReviewHandler{reviewService: reviewService}
+}
+
+// GetBarberReviews возвращает публичный список отзывов барбера
+// GET /api/barbers/{id}/reviews?page=1&page_size=20
+func (h *ReviewHandler) GetBarberReviews(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	barberID, _, err := extractIDAndAction(r.URL.Path, "/api/barbers/")
+	if err != nil {
+		http.Error(w, "Неверный ID барбера: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	filter, ok := parseReviewFilter(w, r)
+	if !ok {
+		return
+	}
+
+	reviews, total, err := h.reviewService.GetBarberReviews(barberID, filter)
+	if err != nil {
+		http.Error(w, "Ошибка получения отзывов: "+err.Error(), reviewErrorStatus(err))
+		return
+	}
+
+	writeReviewPage(w, reviews, len(reviews), total, filter)
+}
+
+// ClientReview управляет отзывом клиента на свою запись
+// GET, POST, PUT, DELETE /api/appointments/{id}/review
+func (h *ReviewHandler) ClientReview(w http.ResponseWriter, r *http.Request) {
+	clientID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
+	}
+
+	appointmentID, _, err := extractIDAndAction(r.URL.Path, "/api/appointments/")
+	if err != nil {
+		http.Error(w, "Неверный ID записи: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var review *models.Review
+	status := http.StatusOK
+	switch r.Method {
+	case http.MethodGet:
+		review, err = h.reviewService.GetClientReview(clientID, appointmentID)
+	case http.MethodPost, http.MethodPut:
+		var req models.ReviewRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Неверные данные: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if r.Method == http.MethodPost {
+			review, err = h.reviewService.CreateReview(clientID, appointmentID, req)
+			status = http.StatusCreated
+		} else {
+			review, err = h.reviewService.UpdateReview(clientID, appointmentID, req)
+		}
+	case http.MethodDelete:
+		if err := h.reviewService.DeleteReview(clientID, appointmentID); err != nil {
+			http.Error(w, "Ошибка удаления отзыва: "+err.Error(), reviewErrorStatus(err))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	default:
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Ошибка обработки отзыва: "+err.Error(), reviewErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(review)
+}
+
+// AdminListReviews возвращает отзывы для модерации, включая скрытые
+// GET /api/admin/reviews?barber_id=1&page=1&page_size=20
+func (h *ReviewHandler) AdminListReviews(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	filter, ok := parseReviewFilter(w, r)
+	if !ok {
+		return
+	}
+	barberID, err := parseOptionalUint(r, "barber_id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter.BarberID = barberID
+	filter.IncludeHidden = true
+
+	reviews, total, err := h.reviewService.ListReviews(filter)
+	if err != nil {
+		http.Error(w, "Ошибка получения отзывов: "+err.Error(), reviewErrorStatus(err))
+		return
+	}
+
+	writeReviewPage(w, reviews, len(reviews), total, filter)
+}
+
+// AdminReviewAction скрывает отзыв или возвращает его в публичный список
+// POST /api/admin/reviews/{id}/hide, POST /api/admin/reviews/{id}/unhide
+func (h *ReviewHandler) AdminReviewAction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	reviewID, action, err := extractIDAndAction(r.URL.Path, "/api/admin/reviews/")
+	if err != nil {
+		http.Error(w, "Неверный ID отзыва: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var review *models.Review
+	switch action {
+	case "hide":
+		var req models.ReviewHideRequest
+		// Причина необязательна, поэтому пустое тело допустимо
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "Неверные данные: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		review, err = h.reviewService.HideReview(reviewID, req)
+	case "unhide":
+		review, err = h.reviewService.UnhideReview(reviewID)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, "Ошибка модерации отзыва: "+err.Error(), reviewErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(review)
+}
+
+// parseReviewFilter разбирает параметры страницы списка отзывов.
+// При ошибке сам отвечает клиенту и возвращает ok = false
+func parseReviewFilter(w http.ResponseWriter, r *http.Request) (models.ReviewFilter, bool) {
+	var filter models.ReviewFilter
+
+	page, err := parseOptionalInt(r, "page")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return filter, false
+	}
+	pageSize, err := parseOptionalInt(r, "page_size")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return filter, false
+	}
+
+	if page != nil {
+		filter.Page = *page
+	}
+	if pageSize != nil {
+		filter.PageSize = *pageSize
+	}
+	filter.Normalize()
+	return filter, true
+}
+
+// writeReviewPage отправляет страницу отзывов
+func writeReviewPage(w http.ResponseWriter, reviews interface{}, count int, total int64, filter models.ReviewFilter) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"reviews":   reviews,
+		"count":     count,
+		"total":     total,
+		"page":      filter.Page,
+		"page_size": filter.PageSize,
+	})
+}
+
+// reviewErrorStatus подбирает HTTP статус по ошибке сервиса отзывов
+func reviewErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrReviewNotFound), errors.Is(err, services.ErrAppointmentNotFound), errors.Is(err, services.ErrNotBarber):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrReviewAlreadyExists), errors.Is(err, services.ErrAppointmentNotCompleted):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
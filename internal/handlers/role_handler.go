@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/services"
+)
+
+// RoleHandler обрабатывает HTTP запросы администрирования ролей
+type RoleHandler struct {
+	roleService       services.RoleService
+	permissionService services.PermissionService
+	auditService      services.AuditService
+	revocation        services.TokenRevocationService
+}
+
+// NewRoleHandler создает новый экземпляр RoleHandler.
+// permissionService нужен, чтобы сбрасывать кеш разрешений после изменений,
+// auditService - чтобы записывать назначение и снятие ролей в журнал,
+// revocation - чтобы отзывать access token с устаревшим набором ролей
+func NewRoleHandler(roleService services.RoleService, permissionService services.PermissionService, auditService services.AuditService, revocation services.TokenRevocationService) *RoleHandler {
+	return &RoleHandler{roleService: roleService, permissionService: permissionService, auditService: auditService, revocation: revocation}
+}
+
+// ListRoles возвращает все роли
+// GET /api/admin/roles
+func (h *RoleHandler) ListRoles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	roles, err := h.roleService.GetAllRoles()
+	if err != nil {
+		http.Error(w, "Ошибка получения ролей: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"roles": roles,
+		"count": len(roles),
+	})
+}
+
+// CreateRole создает новую роль
+// POST /api/admin/roles
+func (h *RoleHandler) CreateRole(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req models.RoleCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверные данные: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	role, err := h.roleService.CreateRoleFromRequest(req)
+	if err != nil {
+		http.Error(w, "Ошибка создания роли: "+err.Error(), roleErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(role)
+}
+
+// GetRole возвращает роль по ID
+// GET /api/admin/roles/{id}
+func (h *RoleHandler) GetRole(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	roleID, err := extractIDFromURL(r.URL.Path, "/api/admin/roles/")
+	if err != nil {
+		http.Error(w, "Неверный ID роли: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	role, err := h.roleService.GetRoleByID(roleID)
+	if err != nil {
+		http.Error(w, "Роль не найдена", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(role)
+}
+
+// UpdateRole изменяет описание, активность и разрешения роли
+// PUT /api/admin/roles/{id}
+func (h *RoleHandler) UpdateRole(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	roleID, err := extractIDFromURL(r.URL.Path, "/api/admin/roles/")
+	if err != nil {
+		http.Error(w, "Неверный ID роли: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req models.RoleUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверные данные: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	role, err := h.roleService.UpdateRoleFromRequest(roleID, req)
+	if err != nil {
+		http.Error(w, "Ошибка обновления роли: "+err.Error(), roleErrorStatus(err))
+		return
+	}
+	h.permissionService.InvalidateAll()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(role)
+}
+
+// DeactivateRole отключает роль (системные роли отключить нельзя)
+// DELETE /api/admin/roles/{id}
+func (h *RoleHandler) DeactivateRole(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	roleID, err := extractIDFromURL(r.URL.Path, "/api/admin/roles/")
+	if err != nil {
+		http.Error(w, "Неверный ID роли: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	role, err := h.roleService.DeactivateRole(roleID)
+	if err != nil {
+		http.Error(w, "Ошибка деактивации роли: "+err.Error(), roleErrorStatus(err))
+		return
+	}
+	h.permissionService.InvalidateAll()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(role)
+}
+
+// AssignRole назначает роль пользователю от имени текущего администратора
+// POST /api/admin/roles/assign
+func (h *RoleHandler) AssignRole(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	adminID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.RoleAssignmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверные данные: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	before := h.userRoleNames(req.UserID)
+	userRole, err := h.roleService.AssignRoleWithReason(req, adminID)
+	if err != nil {
+		http.Error(w, "Ошибка назначения роли: "+err.Error(), roleErrorStatus(err))
+		return
+	}
+	h.permissionService.InvalidateUser(req.UserID)
+	h.revokeUserTokens(req.UserID)
+	h.auditService.Record(auditActorFromRequest(r), models.AuditEntry{
+		Action:     models.AuditActionRoleAssign,
+		TargetType: models.AuditTargetUser,
+		TargetID:   req.UserID,
+		Before:     before,
+		After:      h.userRoleNames(req.UserID),
+		Details:    req.Reason,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(userRole)
+}
+
+// RevokeRole снимает роль с пользователя от имени текущего администратора
+// POST /api/admin/roles/revoke
+func (h *RoleHandler) RevokeRole(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	adminID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.RoleRemovalRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверные данные: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	before := h.userRoleNames(req.UserID)
+	if err := h.roleService.RevokeRoleWithReason(req, adminID); err != nil {
+		http.Error(w, "Ошибка снятия роли: "+err.Error(), roleErrorStatus(err))
+		return
+	}
+	h.permissionService.InvalidateUser(req.UserID)
+	h.revokeUserTokens(req.UserID)
+	h.auditService.Record(auditActorFromRequest(r), models.AuditEntry{
+		Action:     models.AuditActionRoleRevoke,
+		TargetType: models.AuditTargetUser,
+		TargetID:   req.UserID,
+		Before:     before,
+		After:      h.userRoleNames(req.UserID),
+		Details:    req.Reason,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Роль снята"})
+}
+
+// revokeUserTokens отзывает access token пользователя: роли в них больше не актуальны.
+// Роль уже изменена, поэтому ошибка отзыва только логируется
+func (h *RoleHandler) revokeUserTokens(userID uint) {
+	if err := h.revocation.RevokeUserTokens(userID); err != nil {
+		log.Printf("⚠️  Ошибка отзыва токенов пользователя %d: %v", userID, err)
+	}
+}
+
+// GetUserRoles возвращает активные роли пользователя
+// GET /api/admin/users/{id}/roles
+func (h *RoleHandler) GetUserRoles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, _, err := extractIDAndAction(r.URL.Path, "/api/admin/users/")
+	if err != nil {
+		http.Error(w, "Неверный ID пользователя: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := h.roleService.GetUserWithRoles(userID); err != nil {
+		http.Error(w, "Пользователь не найден", http.StatusNotFound)
+		return
+	}
+
+	// Роли берутся из активных назначений, снятые роли не показываются
+	roles, err := h.roleService.GetUserRoles(userID)
+	if err != nil {
+		http.Error(w, "Ошибка получения ролей: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"user_id": userID,
+		"roles":   roles,
+		"count":   len(roles),
+	})
+}
+
+// userRoleNames возвращает состояние ролей пользователя для журнала аудита
+func (h *RoleHandler) userRoleNames(userID uint) map[string]interface{} {
+	roles, _ := h.roleService.GetUserRoles(userID)
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+	return map[string]interface{}{"roles": names}
+}
+
+// roleErrorStatus подбирает HTTP статус по ошибке сервиса ролей
+func roleErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrRoleNotFound), errors.Is(err, services.ErrUserNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrProtectedRole), errors.Is(err, services.ErrLastAdmin):
+		return http.StatusForbidden
+	case errors.Is(err, services.ErrRoleExists), errors.Is(err, services.ErrRoleAlreadyAssigned), errors.Is(err, services.ErrRoleNotAssigned):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/services"
+)
+
+// ScheduleExceptionHandler обрабатывает HTTP запросы отпусков, выходных и дополнительных рабочих дней
+// Барбер работает со своими исключениями через /api/barber/schedule-exceptions,
+// администратор - с исключениями любого барбера через /api/admin/barbers/{id}/schedule-exceptions
+type ScheduleExceptionHandler struct {
+	exceptionService services.ScheduleExceptionService
+}
+
+// NewScheduleExceptionHandler создает новый экземпляр ScheduleExceptionHandler
+func NewScheduleExceptionHandler(exceptionService services.ScheduleExceptionService) *ScheduleExceptionHandler {
+	return &ScheduleExceptionHandler{exceptionService: exceptionService}
+}
+
+// GetExceptions возвращает исключения из расписания барбера
+// GET /api/barber/schedule-exceptions?from=2006-01-02&to=2006-01-02
+func (h *ScheduleExceptionHandler) GetExceptions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	barberID, _, ok := scheduleTarget(w, r, "schedule-exceptions")
+	if !ok {
+		return
+	}
+
+	exceptions, err := h.exceptionService.GetExceptions(barberID, r.URL.Query().Get("from"), r.URL.Query().Get("to"))
+	if err != nil {
+		http.Error(w, "Ошибка получения исключений: "+err.Error(), scheduleExceptionErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"exceptions": exceptions,
+		"count":      len(exceptions),
+	})
+}
+
+// CreateException создает исключение из расписания
+// POST /api/barber/schedule-exceptions
+// Если исключение задевает существующие записи, возвращает 409 со списком affected_appointments;
+// повторный запрос с "force": true создаст исключение несмотря на записи
+func (h *ScheduleExceptionHandler) CreateException(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	barberID, _, ok := scheduleTarget(w, r, "schedule-exceptions")
+	if !ok {
+		return
+	}
+
+	var req models.ScheduleExceptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверные данные: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.exceptionService.CreateException(barberID, req)
+	if errors.Is(err, services.ErrScheduleConflict) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":                 err.Error(),
+			"affected_appointments": result.AffectedAppointments,
+		})
+		return
+	}
+	if err != nil {
+		http.Error(w, "Ошибка создания исключения: "+err.Error(), scheduleExceptionErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(result)
+}
+
+// DeleteException удаляет исключение из расписания
+// DELETE /api/barber/schedule-exceptions/{id}
+func (h *ScheduleExceptionHandler) DeleteException(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	barberID, item, ok := scheduleTarget(w, r, "schedule-exceptions")
+	if !ok {
+		return
+	}
+
+	exceptionID, err := strconv.ParseUint(item, 10, 32)
+	if err != nil {
+		http.Error(w, "Неверный ID исключения", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.exceptionService.DeleteException(barberID, uint(exceptionID)); err != nil {
+		http.Error(w, "Ошибка удаления исключения: "+err.Error(), scheduleExceptionErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Исключение удалено"})
+}
+
+// scheduleExceptionErrorStatus подбирает HTTP статус по ошибке сервиса исключений
+func scheduleExceptionErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrScheduleExceptionNotFound), errors.Is(err, services.ErrNotBarber):
+		return http.StatusNotFound
+	case errors.Is(err, services.ErrScheduleExceptionOverlap):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/services"
+)
+
+// ServiceHandler обрабатывает HTTP запросы каталога услуг
+type ServiceHandler struct {
+	catalogService services.ServiceCatalogService
+}
+
+// NewServiceHandler создает новый экземпляр ServiceHandler
+func NewServiceHandler(catalogService services.ServiceCatalogService) *ServiceHandler {
+	return &ServiceHandler{catalogService: catalogService}
+}
+
+// ListServices возвращает публичный каталог услуг с фильтрами
+// GET /api/services?barber_id=&min_price=&max_price=&min_duration=&max_duration=
+func (h *ServiceHandler) ListServices(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var filter models.ServiceFilter
+	var err error
+
+	if filter.BarberID, err = parseOptionalUint(r, "barber_id"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.MinPrice, err = parseOptionalFloat(r, "min_price"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.MaxPrice, err = parseOptionalFloat(r, "max_price"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.MinDuration, err = parseOptionalInt(r, "min_duration"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if filter.MaxDuration, err = parseOptionalInt(r, "max_duration"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	serviceList, err := h.catalogService.ListServices(filter)
+	if err != nil {
+		http.Error(w, "Ошибка получения услуг: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"services": serviceList,
+		"count":    len(serviceList),
+	})
+}
+
+// GetService возвращает услугу по ID
+// GET /api/services/{id}
+//
+// Деактивированные и удаленные услуги тоже возвращаются, чтобы история записей
+// могла отобразить, на что был записан клиент.
+func (h *ServiceHandler) GetService(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	serviceID, err := extractIDFromURL(r.URL.Path, "/api/services/")
+	if err != nil {
+		http.Error(w, "Неверный ID услуги: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	service, err := h.catalogService.ResolveService(serviceID)
+	if err != nil {
+		http.Error(w, "Ошибка получения услуги: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(service)
+}
+
+// BarberGetServices возвращает все услуги текущего барбера, включая неактивные
+// GET /api/barber/services
+func (h *ServiceHandler) BarberGetServices(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	barberID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
+	}
+
+	serviceList, err := h.catalogService.GetBarberServices(barberID)
+	if err != nil {
+		http.Error(w, "Ошибка получения услуг: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"services": serviceList,
+		"count":    len(serviceList),
+	})
+}
+
+// BarberCreateService создает услугу текущего барбера
+// POST /api/barber/services
+func (h *ServiceHandler) BarberCreateService(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	barberID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.ServiceCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверные данные: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	service, err := h.catalogService.CreateService(barberID, req)
+	if err != nil {
+		http.Error(w, "Ошибка создания услуги: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(service)
+}
+
+// BarberUpdateService обновляет услугу текущего барбера
+// PUT /api/barber/services/{id}
+func (h *ServiceHandler) BarberUpdateService(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	barberID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
+	}
+
+	serviceID, err := extractIDFromURL(r.URL.Path, "/api/barber/services/")
+	if err != nil {
+		http.Error(w, "Неверный ID услуги: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req models.ServiceUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверные данные: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	service, err := h.catalogService.UpdateService(barberID, serviceID, req)
+	if err != nil {
+		http.Error(w, "Ошибка обновления услуги: "+err.Error(), serviceErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(service)
+}
+
+// BarberDeleteService удаляет услугу текущего барбера
+// DELETE /api/barber/services/{id}
+func (h *ServiceHandler) BarberDeleteService(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	barberID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
+	}
+
+	serviceID, err := extractIDFromURL(r.URL.Path, "/api/barber/services/")
+	if err != nil {
+		http.Error(w, "Неверный ID услуги: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.catalogService.DeleteService(barberID, serviceID); err != nil {
+		http.Error(w, "Ошибка удаления услуги: "+err.Error(), serviceErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Услуга успешно удалена",
+	})
+}
+
+// serviceErrorStatus возвращает HTTP статус для ошибки каталога услуг
+func serviceErrorStatus(err error) int {
+	if errors.Is(err, services.ErrServiceNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusBadRequest
+}
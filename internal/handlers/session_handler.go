@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"garage-barbershop/internal/services"
+)
+
+// SessionHandler обрабатывает HTTP запросы управления сессиями на устройствах
+type SessionHandler struct {
+	authService services.AuthService
+}
+
+// NewSessionHandler создает новый экземпляр SessionHandler
+func NewSessionHandler(authService services.AuthService) *SessionHandler {
+	return &SessionHandler{authService: authService}
+}
+
+// ListSessions возвращает активные сессии текущего пользователя
+// GET /api/auth/sessions
+func (h *SessionHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
+	}
+
+	sessions, err := h.authService.ListSessions(userID, getSessionIDFromContext(r))
+	if err != nil {
+		http.Error(w, "Ошибка получения сессий: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"sessions": sessions,
+		"count":    len(sessions),
+	})
+}
+
+// RevokeSession отзывает одну сессию текущего пользователя
+// DELETE /api/auth/sessions/{id}
+func (h *SessionHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID, err := extractIDFromURL(r.URL.Path, "/api/auth/sessions/")
+	if err != nil {
+		http.Error(w, "Неверный ID сессии: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.authService.RevokeSession(userID, sessionID); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, services.ErrSessionNotFound) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, "Ошибка завершения сессии: "+err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Сессия завершена"})
+}
+
+// RevokeOtherSessions завершает все сессии текущего пользователя, кроме текущей
+// POST /api/auth/sessions/revoke-others
+func (h *SessionHandler) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID := getSessionIDFromContext(r)
+	if sessionID == 0 {
+		http.Error(w, "Токен не привязан к сессии", http.StatusBadRequest)
+		return
+	}
+
+	revoked, err := h.authService.RevokeOtherSessions(userID, sessionID)
+	if err != nil {
+		http.Error(w, "Ошибка завершения сессий: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": "Остальные сессии завершены",
+		"revoked": revoked,
+	})
+}
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/services"
+)
+
+// TwoFactorHandler обрабатывает HTTP запросы управления двухфакторной аутентификацией
+type TwoFactorHandler struct {
+	twoFactor    services.TwoFactorService
+	auditService services.AuditService
+}
+
+// NewTwoFactorHandler создает новый экземпляр TwoFactorHandler
+func NewTwoFactorHandler(twoFactor services.TwoFactorService, auditService services.AuditService) *TwoFactorHandler {
+	return &TwoFactorHandler{twoFactor: twoFactor, auditService: auditService}
+}
+
+// GetStatus возвращает состояние 2FA текущего пользователя
+// GET /api/auth/2fa
+func (h *TwoFactorHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
+	}
+
+	status, err := h.twoFactor.Status(userID)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// Setup генерирует секрет для приложения-аутентификатора; 2FA включается после Confirm
+// POST /api/auth/2fa/setup
+func (h *TwoFactorHandler) Setup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
+	}
+
+	setup, err := h.twoFactor.BeginEnrollment(userID)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(setup)
+}
+
+// Confirm включает 2FA по первому коду из приложения и возвращает коды восстановления
+// POST /api/auth/2fa/confirm
+func (h *TwoFactorHandler) Confirm(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверные данные: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	codes, err := h.twoFactor.ConfirmEnrollment(userID, req.Code)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+	h.auditService.Record(auditActorFromRequest(r), models.AuditEntry{
+		Action:     models.AuditActionTwoFactorEnable,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// Disable отключает 2FA по коду из приложения или коду восстановления
+// POST /api/auth/2fa/disable
+func (h *TwoFactorHandler) Disable(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверные данные: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := h.twoFactor.Disable(userID, req.Code); err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+	h.auditService.Record(auditActorFromRequest(r), models.AuditEntry{
+		Action:     models.AuditActionTwoFactorDisable,
+		TargetType: models.AuditTargetUser,
+		TargetID:   userID,
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Двухфакторная аутентификация отключена"})
+}
+
+// RegenerateRecoveryCodes выдает новые коды восстановления, прежние перестают действовать
+// POST /api/auth/2fa/recovery-codes
+func (h *TwoFactorHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	userID, ok := getUserIDFromContext(r)
+	if !ok {
+		http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+		return
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверные данные: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	codes, err := h.twoFactor.RegenerateRecoveryCodes(userID, req.Code)
+	if err != nil {
+		writeTwoFactorError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// writeTwoFactorError отвечает клиенту кодом, соответствующим ошибке сервиса 2FA
+func writeTwoFactorError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrTwoFactorAlreadyEnabled),
+		errors.Is(err, services.ErrTwoFactorNotEnabled),
+		errors.Is(err, services.ErrTwoFactorNotStarted):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, services.ErrInvalidTwoFactorCode):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrTwoFactorRequired):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	default:
+		http.Error(w, "Ошибка двухфакторной аутентификации: "+err.Error(), http.StatusInternalServerError)
+	}
+}
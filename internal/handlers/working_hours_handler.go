@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/services"
+)
+
+// WorkingHoursHandler обрабатывает HTTP запросы управления рабочими часами
+// Барбер работает со своим расписанием через /api/barber/working-hours,
+// администратор - с расписанием любого барбера через /api/admin/barbers/{id}/working-hours
+type WorkingHoursHandler struct {
+	workingHoursService services.WorkingHoursService
+}
+
+// NewWorkingHoursHandler создает новый экземпляр WorkingHoursHandler
+func NewWorkingHoursHandler(workingHoursService services.WorkingHoursService) *WorkingHoursHandler {
+	return &WorkingHoursHandler{workingHoursService: workingHoursService}
+}
+
+// GetSchedule возвращает недельное расписание барбера
+// GET /api/barber/working-hours, GET /api/admin/barbers/{id}/working-hours
+func (h *WorkingHoursHandler) GetSchedule(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	barberID, _, ok := scheduleTarget(w, r, "working-hours")
+	if !ok {
+		return
+	}
+
+	schedule, err := h.workingHoursService.GetSchedule(barberID)
+	if err != nil {
+		http.Error(w, "Ошибка получения расписания: "+err.Error(), workingHoursErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"working_hours": schedule,
+		"count":         len(schedule),
+	})
+}
+
+// ReplaceWeek атомарно заменяет недельное расписание барбера
+// PUT /api/barber/working-hours, PUT /api/admin/barbers/{id}/working-hours
+func (h *WorkingHoursHandler) ReplaceWeek(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	barberID, _, ok := scheduleTarget(w, r, "working-hours")
+	if !ok {
+		return
+	}
+
+	var req models.WeeklyScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверные данные: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	schedule, err := h.workingHoursService.ReplaceWeek(barberID, req)
+	if err != nil {
+		http.Error(w, "Ошибка сохранения расписания: "+err.Error(), workingHoursErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"working_hours": schedule,
+		"count":         len(schedule),
+	})
+}
+
+// SetDay создает или заменяет расписание на один день недели
+// POST /api/barber/working-hours, POST /api/admin/barbers/{id}/working-hours
+func (h *WorkingHoursHandler) SetDay(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	barberID, _, ok := scheduleTarget(w, r, "working-hours")
+	if !ok {
+		return
+	}
+
+	var req models.WorkingHoursRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Неверные данные: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	workingHours, err := h.workingHoursService.SetDay(barberID, req)
+	if err != nil {
+		http.Error(w, "Ошибка сохранения расписания: "+err.Error(), workingHoursErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(workingHours)
+}
+
+// DeleteDay делает день недели выходным
+// DELETE /api/barber/working-hours/{day}, DELETE /api/admin/barbers/{id}/working-hours/{day}
+func (h *WorkingHoursHandler) DeleteDay(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		return
+	}
+
+	barberID, dayValue, ok := scheduleTarget(w, r, "working-hours")
+	if !ok {
+		return
+	}
+
+	dayOfWeek, err := strconv.Atoi(dayValue)
+	if err != nil {
+		http.Error(w, "Неверный день недели", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.workingHoursService.DeleteDay(barberID, dayOfWeek); err != nil {
+		http.Error(w, "Ошибка удаления расписания: "+err.Error(), workingHoursErrorStatus(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": "Расписание на день удалено"})
+}
+
+// workingHoursErrorStatus подбирает HTTP статус по ошибке сервиса расписания
+func workingHoursErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, services.ErrWorkingHoursNotFound), errors.Is(err, services.ErrNotBarber):
+		return http.StatusNotFound
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,63 @@
+package mailer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileMailer сохраняет каждое письмо в отдельный файл каталога.
+// Имена файлов упорядочены по времени отправки
+type FileMailer struct {
+	dir string
+
+	mu   sync.Mutex
+	seq  int
+	last string
+}
+
+// NewFileMailer создает Mailer, который пишет письма в каталог dir
+func NewFileMailer(dir string) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("ошибка создания каталога писем: %v", err)
+	}
+	return &FileMailer{dir: dir}, nil
+}
+
+// Send сохраняет письмо в файл
+func (m *FileMailer) Send(msg Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.seq++
+	name := fmt.Sprintf("%s-%04d-%s.txt", time.Now().UTC().Format("20060102T150405.000000000"), m.seq, fileSafe(msg.To))
+	content := fmt.Sprintf("To: %s\nSubject: %s\n\n%s\n", msg.To, msg.Subject, msg.Body)
+
+	path := filepath.Join(m.dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		return fmt.Errorf("ошибка сохранения письма: %v", err)
+	}
+	m.last = path
+	return nil
+}
+
+// LastMessagePath возвращает путь к последнему сохраненному письму
+func (m *FileMailer) LastMessagePath() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.last
+}
+
+// fileSafe заменяет в адресе символы, недопустимые в имени файла
+func fileSafe(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_', r == '@':
+			return r
+		}
+		return '_'
+	}, value)
+}
@@ -0,0 +1,17 @@
+package mailer
+
+import "log"
+
+// LogMailer выводит письма в лог приложения вместо отправки
+type LogMailer struct{}
+
+// NewLogMailer создает Mailer, который пишет письма в лог
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+// Send выводит письмо в лог
+func (m *LogMailer) Send(msg Message) error {
+	log.Printf("📧 Письмо для %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
@@ -0,0 +1,14 @@
+package mailer
+
+// Message письмо пользователю
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer интерфейс отправки писем. Реализации подключаются в main:
+// для разработки и тестов письма пишутся в лог или в файлы
+type Mailer interface {
+	Send(msg Message) error
+}
@@ -52,10 +52,18 @@ func JWTMiddleware(authService services.AuthService) gin.HandlerFunc {
 			return
 		}
 
+		// Проверяем, что токен не отозван
+		if authService.IsTokenRevoked(claims) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Токен отозван"})
+			c.Abort()
+			return
+		}
+
 		// Сохраняем данные пользователя в контекст
 		c.Set("user_id", claims.UserID)
 		c.Set("telegram_id", claims.TelegramID)
 		c.Set("user_roles", claims.Roles)
+		c.Set("session_id", claims.SessionID)
 		c.Set("jwt_claims", claims)
 
 		c.Next()
@@ -127,10 +135,11 @@ func OptionalAuth(authService services.AuthService) gin.HandlerFunc {
 			return
 		}
 
-		if claims.IsAccessToken() && !claims.IsExpired() {
+		if claims.IsAccessToken() && !claims.IsExpired() && !authService.IsTokenRevoked(claims) {
 			c.Set("user_id", claims.UserID)
 			c.Set("telegram_id", claims.TelegramID)
 			c.Set("user_roles", claims.Roles)
+			c.Set("session_id", claims.SessionID)
 			c.Set("jwt_claims", claims)
 		}
 
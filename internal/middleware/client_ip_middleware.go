@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// HTTPClientIPMiddleware определяет IP адрес клиента и добавляет его в контекст запроса.
+// Заголовки X-Forwarded-For и X-Real-IP учитываются, только если запрос пришел от доверенного
+// прокси (IP или подсеть CIDR из trustedProxies), иначе их может подделать сам клиент
+func HTTPClientIPMiddleware(trustedProxies []string) func(next http.HandlerFunc) http.HandlerFunc {
+	proxies := parseTrustedProxies(trustedProxies)
+
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), "clientIP", resolveClientIP(r, proxies))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		}
+	}
+}
+
+// parseTrustedProxies разбирает адреса и подсети доверенных прокси, неверные пропускаются
+func parseTrustedProxies(values []string) []*net.IPNet {
+	var proxies []*net.IPNet
+	for _, value := range values {
+		if !strings.Contains(value, "/") {
+			if ip := net.ParseIP(value); ip != nil && ip.To4() != nil {
+				value += "/32"
+			} else {
+				value += "/128"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			log.Printf("⚠️  Неверный адрес доверенного прокси %q пропущен", value)
+			continue
+		}
+		proxies = append(proxies, network)
+	}
+	return proxies
+}
+
+// resolveClientIP возвращает адрес соединения или, если соединение от доверенного прокси,
+// последний недоверенный адрес из цепочки X-Forwarded-For (X-Real-IP, если цепочки нет)
+func resolveClientIP(r *http.Request, proxies []*net.IPNet) string {
+	remote, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remote = r.RemoteAddr
+	}
+	if !isTrustedProxy(remote, proxies) {
+		return remote
+	}
+
+	// Прокси дописывают адреса в конец цепочки, поэтому идем справа налево
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		hops := strings.Split(forwarded, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				break
+			}
+			if !isTrustedProxy(hop, proxies) || i == 0 {
+				return hop
+			}
+		}
+	}
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(realIP) != nil {
+		return realIP
+	}
+	return remote
+}
+
+// isTrustedProxy проверяет, что адрес входит в одну из подсетей доверенных прокси
+func isTrustedProxy(address string, proxies []*net.IPNet) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, network := range proxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+
+	"garage-barbershop/internal/services"
+)
+
+// HTTPRequireVerifiedEmailMiddleware применяет политику для неподтвержденных email:
+// действие над ресурсом (выводится из HTTP метода) может требовать подтвержденного адреса.
+// Должен стоять после HTTPAuthMiddleware
+func HTTPRequireVerifiedEmailMiddleware(verificationService services.EmailVerificationService, resource string) func(next http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := r.Context().Value("userID").(uint)
+			if !ok {
+				http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+				return
+			}
+
+			if !verificationService.IsAllowed(userID, resource, ActionForMethod(r.Method)) {
+				http.Error(w, "Подтвердите email, чтобы выполнить это действие", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+	}
+}
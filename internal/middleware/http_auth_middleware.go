@@ -37,10 +37,18 @@ func HTTPAuthMiddleware(authService services.AuthService) func(next http.Handler
 				return
 			}
 
+			// Токен мог быть отозван до истечения срока (выход, смена ролей, деактивация)
+			if authService.IsTokenRevoked(claims) {
+				http.Error(w, "Токен отозван", http.StatusUnauthorized)
+				return
+			}
+
 			// Добавляем данные пользователя в контекст запроса
 			ctx := context.WithValue(r.Context(), "userID", claims.UserID)
 			ctx = context.WithValue(ctx, "telegramID", claims.TelegramID)
 			ctx = context.WithValue(ctx, "userRoles", claims.Roles)
+			ctx = context.WithValue(ctx, "sessionID", claims.SessionID)
+			ctx = context.WithValue(ctx, "tokenClaims", claims)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		}
 	}
@@ -50,8 +58,8 @@ func HTTPAuthMiddleware(authService services.AuthService) func(next http.Handler
 func HTTPRequireRoleMiddleware(requiredRole string) func(next http.HandlerFunc) http.HandlerFunc {
 	return func(next http.HandlerFunc) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
-			userRoles, ok := r.Context().Value("userRoles").(string)
-			if !ok || userRoles != requiredRole {
+			userRoles, ok := r.Context().Value("userRoles").([]string)
+			if !ok || !containsRole(userRoles, requiredRole) {
 				http.Error(w, "Недостаточно прав", http.StatusForbidden)
 				return
 			}
@@ -64,7 +72,7 @@ func HTTPRequireRoleMiddleware(requiredRole string) func(next http.HandlerFunc)
 func HTTPRequireAnyRoleMiddleware(roles ...string) func(next http.HandlerFunc) http.HandlerFunc {
 	return func(next http.HandlerFunc) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
-			userRoles, ok := r.Context().Value("userRoles").(string)
+			userRoles, ok := r.Context().Value("userRoles").([]string)
 			if !ok {
 				http.Error(w, "Роль пользователя не найдена", http.StatusUnauthorized)
 				return
@@ -72,7 +80,7 @@ func HTTPRequireAnyRoleMiddleware(roles ...string) func(next http.HandlerFunc) h
 
 			hasRole := false
 			for _, role := range roles {
-				if userRoles == role {
+				if containsRole(userRoles, role) {
 					hasRole = true
 					break
 				}
@@ -87,3 +95,13 @@ func HTTPRequireAnyRoleMiddleware(roles ...string) func(next http.HandlerFunc) h
 		}
 	}
 }
+
+// containsRole проверяет, есть ли роль в списке ролей из токена
+func containsRole(userRoles []string, role string) bool {
+	for _, userRole := range userRoles {
+		if userRole == role {
+			return true
+		}
+	}
+	return false
+}
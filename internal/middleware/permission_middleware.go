@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net/http"
+
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ActionForMethod сопоставляет HTTP метод действию над ресурсом
+func ActionForMethod(method string) string {
+	switch method {
+	case http.MethodPost:
+		return models.ActionCreate
+	case http.MethodPut, http.MethodPatch:
+		return models.ActionUpdate
+	case http.MethodDelete:
+		return models.ActionDelete
+	default:
+		return models.ActionRead
+	}
+}
+
+// HTTPRequirePermissionMiddleware проверяет разрешение пользователя на действие над ресурсом.
+// Должен стоять после HTTPAuthMiddleware
+func HTTPRequirePermissionMiddleware(permissionService services.PermissionService, resource, action string) func(next http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := r.Context().Value("userID").(uint)
+			if !ok {
+				http.Error(w, "Пользователь не аутентифицирован", http.StatusUnauthorized)
+				return
+			}
+
+			if !permissionService.Can(userID, resource, action) {
+				http.Error(w, "Недостаточно прав", http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+		}
+	}
+}
+
+// HTTPRequireResourcePermissionMiddleware проверяет разрешение на ресурс, выводя действие из HTTP метода
+func HTTPRequireResourcePermissionMiddleware(permissionService services.PermissionService, resource string) func(next http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			HTTPRequirePermissionMiddleware(permissionService, resource, ActionForMethod(r.Method))(next)(w, r)
+		}
+	}
+}
+
+// RequirePermission middleware для проверки разрешения пользователя (gin).
+// Должен стоять после JWTMiddleware
+func RequirePermission(permissionService services.PermissionService, resource, action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := c.Get("user_id")
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Пользователь не аутентифицирован"})
+			c.Abort()
+			return
+		}
+
+		id, ok := userID.(uint)
+		if !ok || !permissionService.Can(id, resource, action) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Недостаточно прав доступа"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -15,7 +15,7 @@ func CreateInitialRoles(db *gorm.DB) error {
 			DisplayName: "Администратор",
 			Description: "Полный доступ к системе",
 			IsActive:    true,
-			Permissions: `{"users": ["create", "read", "update", "delete"], "barbers": ["create", "read", "update", "delete"], "appointments": ["create", "read", "update", "delete"]}`,
+			Permissions: `{"*": ["*"]}`,
 		},
 		{
 			Name:        "barber",
@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"log"
+
+	"garage-barbershop/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// legacyAdminPermissions разрешения администратора, с которыми роль создавалась раньше.
+// В них нет ресурсов, появившихся позже (роли, отзывы, платежи), поэтому администратор получает "*"
+const legacyAdminPermissions = `{"users": ["create", "read", "update", "delete"], "barbers": ["create", "read", "update", "delete"], "appointments": ["create", "read", "update", "delete"]}`
+
+// UpgradeDefaultRolePermissions заменяет старые разрешения администратора по умолчанию.
+// Разрешения, измененные вручную, не трогаются
+func UpgradeDefaultRolePermissions(db *gorm.DB) error {
+	result := db.Model(&models.Role{}).
+		Where("name = ? AND permissions = ?", "admin", legacyAdminPermissions).
+		Update("permissions", `{"*": ["*"]}`)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected > 0 {
+		log.Println("✅ Разрешения роли admin обновлены")
+	}
+	return nil
+}
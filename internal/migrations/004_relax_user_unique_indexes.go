@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"log"
+
+	"garage-barbershop/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// legacyUserUniqueIndexes прежние уникальные индексы users: они не пропускали второго пользователя
+// без Telegram (telegram_id = 0) или без email. Их заменяют индексы только по заданным значениям
+var legacyUserUniqueIndexes = []string{"idx_users_telegram_id", "idx_users_email"}
+
+// RelaxUserUniqueIndexes удаляет прежние уникальные индексы telegram_id и email,
+// новые частичные индексы создает AutoMigrate по тегам модели User
+func RelaxUserUniqueIndexes(db *gorm.DB) error {
+	migrator := db.Migrator()
+	for _, name := range legacyUserUniqueIndexes {
+		if !migrator.HasIndex(&models.User{}, name) {
+			continue
+		}
+		if err := migrator.DropIndex(&models.User{}, name); err != nil {
+			return err
+		}
+		log.Printf("✅ Индекс %s заменен частичным уникальным индексом", name)
+	}
+	return nil
+}
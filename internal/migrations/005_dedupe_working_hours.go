@@ -0,0 +1,33 @@
+package migrations
+
+import (
+	"log"
+
+	"garage-barbershop/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// DedupeWorkingHours оставляет одно расписание барбера на день недели перед созданием
+// уникального индекса (barber_id, day_of_week). Дубли могли появиться при параллельных сохранениях,
+// остается последняя сохраненная строка
+func DedupeWorkingHours(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&models.WorkingHours{}) {
+		return nil
+	}
+
+	// Удаленные строки тоже попадают в уникальный индекс, а расписание удаляется только физически
+	if err := db.Unscoped().Where("deleted_at IS NOT NULL").Delete(&models.WorkingHours{}).Error; err != nil {
+		return err
+	}
+
+	latest := db.Model(&models.WorkingHours{}).Select("MAX(id)").Group("barber_id, day_of_week")
+	result := db.Unscoped().Where("id NOT IN (?)", latest).Delete(&models.WorkingHours{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("✅ Удалено повторяющихся рабочих часов: %d", result.RowsAffected)
+	}
+	return nil
+}
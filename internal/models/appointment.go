@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+// Статусы записи
+const (
+	AppointmentStatusPending   = "pending"
+	AppointmentStatusConfirmed = "confirmed"
+	AppointmentStatusCompleted = "completed"
+	AppointmentStatusCancelled = "cancelled"
+	AppointmentStatusNoShow    = "no_show"
+)
+
+// Статусы оплаты записи
+const (
+	PaymentStatusPending  = "pending"
+	PaymentStatusPaid     = "paid"
+	PaymentStatusRefunded = "refunded"
+)
+
+// BlockingAppointmentStatuses статусы записей, которые занимают время барбера
+var BlockingAppointmentStatuses = []string{
+	AppointmentStatusPending,
+	AppointmentStatusConfirmed,
+	AppointmentStatusCompleted,
+}
+
+// MaxAppointmentDuration верхняя граница длительности одной записи.
+// Используется, чтобы ограничить выборку при поиске пересечений, поэтому длительность услуги не может быть больше.
+const MaxAppointmentDuration = 24 * time.Hour
+
+// appointmentTransitions допустимые переходы между статусами записи
+var appointmentTransitions = map[string][]string{
+	AppointmentStatusPending:   {AppointmentStatusConfirmed, AppointmentStatusCancelled},
+	AppointmentStatusConfirmed: {AppointmentStatusCompleted, AppointmentStatusCancelled, AppointmentStatusNoShow},
+}
+
+// CanTransitionTo проверяет, можно ли перевести запись в указанный статус
+func (a *Appointment) CanTransitionTo(status string) bool {
+	for _, allowed := range appointmentTransitions[a.Status] {
+		if allowed == status {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFinal проверяет, находится ли запись в конечном статусе
+func (a *Appointment) IsFinal() bool {
+	return len(appointmentTransitions[a.Status]) == 0
+}
+
+// EndTime возвращает время окончания записи
+func (a *Appointment) EndTime() time.Time {
+	return a.DateTime.Add(time.Duration(a.Duration) * time.Minute)
+}
+
+// Overlaps проверяет, пересекается ли запись с интервалом [start, end)
+func (a *Appointment) Overlaps(start, end time.Time) bool {
+	return a.DateTime.Before(end) && a.EndTime().After(start)
+}
+
+// AppointmentCreateRequest представляет запрос клиента на запись
+type AppointmentCreateRequest struct {
+	BarberID  uint      `json:"barber_id"` // необязателен, берется из услуги
+	ServiceID uint      `json:"service_id" binding:"required"`
+	DateTime  time.Time `json:"datetime"`       // момент времени с указанием смещения (RFC 3339)
+	LocalTime string    `json:"local_datetime"` // либо время барбера без смещения, "2006-01-02T15:04"
+	Notes     string    `json:"notes"`
+}
+
+// Localize приводит время записи к UTC и заполняет время в часовом поясе барбера
+func (a *Appointment) Localize(loc *time.Location) {
+	a.DateTime = a.DateTime.UTC()
+	local := a.DateTime.In(loc)
+	a.LocalDateTime = &local
+	a.Timezone = loc.String()
+}
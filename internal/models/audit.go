@@ -0,0 +1,149 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+)
+
+// Действия журнала аудита
+const (
+	AuditActionBarberUpdate     = "barber.update"
+	AuditActionBarberDelete     = "barber.delete"
+	AuditActionRoleAssign       = "role.assign"
+	AuditActionRoleRevoke       = "role.revoke"
+	AuditActionLoginSuccess     = "auth.login"
+	AuditActionLoginFailure     = "auth.login_failed"
+	AuditActionTokenRefresh     = "auth.refresh"
+	AuditActionTokenRefreshFail = "auth.refresh_failed"
+	AuditActionTokenReuse       = "security.refresh_token_reuse"
+	AuditActionPasswordReset    = "auth.password_reset"
+	AuditActionPasswordChange   = "auth.password_change"
+	AuditActionLoginUnlock      = "auth.login_unlock"
+	AuditActionTwoFactorEnable  = "auth.2fa_enable"
+	AuditActionTwoFactorDisable = "auth.2fa_disable"
+	AuditActionEmailVerified    = "auth.email_verified"
+	AuditActionIdentityLink     = "auth.identity_link"
+	AuditActionIdentityUnlink   = "auth.identity_unlink"
+	AuditActionUserMerge        = "user.merge"
+)
+
+// AuditTargetUser тип объекта журнала аудита - пользователь
+const AuditTargetUser = "user"
+
+// AuditEvent - запись журнала аудита
+type AuditEvent struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+
+	ActorID    *uint  `json:"actor_id" gorm:"index"`        // кто выполнил действие, nil - анонимно
+	Action     string `json:"action" gorm:"not null;index"` // barber.update, role.assign, auth.login...
+	TargetType string `json:"target_type" gorm:"index"`     // тип объекта, например user
+	TargetID   *uint  `json:"target_id" gorm:"index"`       // ID объекта
+	Success    bool   `json:"success"`                      // успешно ли действие
+	Changes    string `json:"changes" gorm:"type:text"`     // JSON {"поле": {"before": ..., "after": ...}}
+	Details    string `json:"details"`                      // дополнительные сведения (причина, email при неудачном входе)
+	IP         string `json:"ip"`                           // IP адрес клиента
+	UserAgent  string `json:"user_agent"`                   // User-Agent клиента
+}
+
+// AuditActor описывает инициатора действия: пользователя и параметры запроса
+type AuditActor struct {
+	UserID    uint // 0 - пользователь не аутентифицирован
+	IP        string
+	UserAgent string
+}
+
+// AuditFilter представляет фильтры журнала аудита
+type AuditFilter struct {
+	ActorID    uint
+	TargetType string
+	TargetID   uint
+	Action     string
+	From       *time.Time
+	To         *time.Time
+	Page       int
+	PageSize   int
+}
+
+// Normalize подставляет значения страницы по умолчанию и ограничивает ее размер
+func (f *AuditFilter) Normalize() {
+	if f.Page < 1 {
+		f.Page = 1
+	}
+	if f.PageSize < 1 {
+		f.PageSize = DefaultPageSize
+	}
+	if f.PageSize > MaxPageSize {
+		f.PageSize = MaxPageSize
+	}
+}
+
+// Offset возвращает смещение первой записи страницы
+func (f AuditFilter) Offset() int {
+	return (f.Page - 1) * f.PageSize
+}
+
+// AuditChange изменение одного поля
+type AuditChange struct {
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// AuditDiff сравнивает JSON представления объектов до и после изменения
+// и возвращает только изменившиеся поля. nil означает отсутствие объекта
+func AuditDiff(before, after interface{}) (map[string]AuditChange, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return nil, err
+	}
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	// Время обновления меняется при каждом сохранении и не несет смысла в журнале
+	delete(beforeFields, "updated_at")
+	delete(afterFields, "updated_at")
+
+	changes := make(map[string]AuditChange)
+	for name, value := range beforeFields {
+		if newValue, ok := afterFields[name]; !ok || !reflect.DeepEqual(value, newValue) {
+			changes[name] = AuditChange{Before: value, After: afterFields[name]}
+		}
+	}
+	for name, value := range afterFields {
+		if _, ok := beforeFields[name]; !ok {
+			changes[name] = AuditChange{After: value}
+		}
+	}
+	return changes, nil
+}
+
+// auditFields разбирает объект в набор полей его JSON представления
+func auditFields(value interface{}) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	if value == nil || reflect.ValueOf(value).Kind() == reflect.Ptr && reflect.ValueOf(value).IsNil() {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// AuditEntry описывает событие для записи в журнал аудита
+type AuditEntry struct {
+	Action     string
+	TargetType string
+	TargetID   uint
+	Before     interface{} // состояние объекта до действия, nil - объекта не было
+	After      interface{} // состояние объекта после действия, nil - объект удален
+	Failed     bool        // действие не выполнено (например, неверный пароль)
+	Details    string
+}
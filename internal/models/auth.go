@@ -1,6 +1,9 @@
 package models
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -16,6 +19,71 @@ type TelegramAuthData struct {
 	Hash      string `json:"hash"`
 }
 
+// TelegramLoginData поля, переданные Telegram Login Widget, без изменений:
+// подпись проверяется по всем полям, поэтому неизвестные поля не отбрасываются
+type TelegramLoginData map[string]string
+
+// UnmarshalJSON принимает объект со строковыми и числовыми значениями
+// (id и auth_date виджет передает числами), числа сохраняются в исходной записи
+func (d *TelegramLoginData) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var raw map[string]interface{}
+	if err := decoder.Decode(&raw); err != nil {
+		return err
+	}
+
+	fields := make(TelegramLoginData, len(raw))
+	for key, value := range raw {
+		switch value := value.(type) {
+		case string:
+			fields[key] = value
+		case json.Number:
+			fields[key] = value.String()
+		default:
+			return fmt.Errorf("поле %s: ожидается строка или число", key)
+		}
+	}
+	*d = fields
+	return nil
+}
+
+// TelegramWebAppAuthRequest представляет запрос входа из Telegram WebApp:
+// строка initData (window.Telegram.WebApp.initData) без изменений
+type TelegramWebAppAuthRequest struct {
+	InitData string `json:"init_data" binding:"required"`
+}
+
+// TelegramWebAppUser пользователь из поля user данных initData
+type TelegramWebAppUser struct {
+	ID           int64  `json:"id"`
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name"`
+	Username     string `json:"username"`
+	LanguageCode string `json:"language_code"`
+	PhotoURL     string `json:"photo_url"`
+}
+
+// TelegramWebAppInitData проверенные данные запуска Telegram WebApp
+type TelegramWebAppInitData struct {
+	QueryID    string
+	User       TelegramWebAppUser
+	AuthDate   int64
+	StartParam string
+}
+
+// AuthData возвращает данные пользователя в формате входа через Telegram
+func (d *TelegramWebAppInitData) AuthData() TelegramAuthData {
+	return TelegramAuthData{
+		ID:        d.User.ID,
+		Username:  d.User.Username,
+		FirstName: d.User.FirstName,
+		LastName:  d.User.LastName,
+		AuthDate:  d.AuthDate,
+	}
+}
+
 // AuthResponse представляет ответ при аутентификации
 type AuthResponse struct {
 	AccessToken  string `json:"access_token"`
@@ -38,7 +106,7 @@ type DirectLoginRequest struct {
 // DirectRegisterRequest представляет запрос на прямую регистрацию
 type DirectRegisterRequest struct {
 	Email     string `json:"email" binding:"required,email"`
-	Password  string `json:"password" binding:"required,min=6"`
+	Password  string `json:"password" binding:"required"` // проверяется политикой паролей
 	FirstName string `json:"first_name" binding:"required"`
 	LastName  string `json:"last_name" binding:"required"`
 	Role      string `json:"role" binding:"required,oneof=client barber"`
@@ -47,7 +115,7 @@ type DirectRegisterRequest struct {
 // ClientRegisterRequest представляет запрос на регистрацию клиента
 type ClientRegisterRequest struct {
 	Email     string `json:"email" binding:"required,email"`
-	Password  string `json:"password" binding:"required,min=6"`
+	Password  string `json:"password" binding:"required"` // проверяется политикой паролей
 	FirstName string `json:"first_name" binding:"required"`
 	LastName  string `json:"last_name" binding:"required"`
 }
@@ -55,7 +123,7 @@ type ClientRegisterRequest struct {
 // BarberRegisterRequest представляет запрос на регистрацию барбера (только админ)
 type BarberRegisterRequest struct {
 	Email       string `json:"email" binding:"required,email"`
-	Password    string `json:"password" binding:"required,min=6"`
+	Password    string `json:"password" binding:"required"` // проверяется политикой паролей
 	FirstName   string `json:"first_name" binding:"required"`
 	LastName    string `json:"last_name" binding:"required"`
 	Specialties string `json:"specialties"` // специализации
@@ -64,21 +132,36 @@ type BarberRegisterRequest struct {
 
 // BarberUpdateRequest представляет запрос на обновление барбера (админ)
 type BarberUpdateRequest struct {
-	Email       string   `json:"email" binding:"omitempty,email"`
-	FirstName   string   `json:"first_name"`
-	LastName    string   `json:"last_name"`
-	Specialties string   `json:"specialties"`
-	Experience  int      `json:"experience"`
-	IsActive    *bool    `json:"is_active"` // указатель для различения false и отсутствия поля
-	Rating      *float64 `json:"rating"`    // указатель для различения 0 и отсутствия поля
+	Email       string  `json:"email" binding:"omitempty,email"`
+	FirstName   string  `json:"first_name"`
+	LastName    string  `json:"last_name"`
+	Specialties string  `json:"specialties"`
+	Experience  int     `json:"experience"`
+	IsActive    *bool   `json:"is_active"` // указатель для различения false и отсутствия поля
+	Timezone    *string `json:"timezone"`  // часовой пояс IANA, пустая строка - часовой пояс барбершопа
 }
 
 // BarberSelfUpdateRequest представляет запрос на обновление собственного профиля барбера
 type BarberSelfUpdateRequest struct {
-	FirstName   string `json:"first_name"`
-	LastName    string `json:"last_name"`
-	Specialties string `json:"specialties"`
-	Experience  int    `json:"experience"`
+	FirstName   string  `json:"first_name"`
+	LastName    string  `json:"last_name"`
+	Specialties string  `json:"specialties"`
+	Experience  int     `json:"experience"`
+	Timezone    *string `json:"timezone"` // часовой пояс IANA, пустая строка - часовой пояс барбершопа
+}
+
+// ProfileUpdateRequest представляет частичное обновление собственного профиля.
+// Поля-указатели: nil - поле не меняется
+type ProfileUpdateRequest struct {
+	FirstName   *string `json:"first_name"`
+	LastName    *string `json:"last_name"`
+	Phone       *string `json:"phone"`       // пустая строка удаляет телефон
+	Preferences *string `json:"preferences"` // только для клиентов
+}
+
+// EmailVerifyRequest представляет подтверждение email по токену из письма
+type EmailVerifyRequest struct {
+	Token string `json:"token" binding:"required"`
 }
 
 // TokenClaims представляет claims JWT токена
@@ -89,7 +172,9 @@ type TokenClaims struct {
 	Type       string   `json:"type"`
 	Exp        int64    `json:"exp"`
 	Iat        int64    `json:"iat"`
+	IatMicros  int64    `json:"-"` // время выпуска с точностью до микросекунд (для отзыва токенов)
 	Jti        string   `json:"jti"`
+	SessionID  uint     `json:"sid"` // сессия устройства, 0 - токен выдан без сессии
 	jwt.RegisteredClaims
 }
 
@@ -98,6 +183,14 @@ func (tc *TokenClaims) IsExpired() bool {
 	return time.Now().Unix() > tc.Exp
 }
 
+// IssuedAtTime возвращает время выпуска токена с максимальной доступной точностью
+func (tc *TokenClaims) IssuedAtTime() time.Time {
+	if tc.IatMicros != 0 {
+		return time.UnixMicro(tc.IatMicros)
+	}
+	return time.Unix(tc.Iat, 0)
+}
+
 // IsAccessToken проверяет, является ли токен access token
 func (tc *TokenClaims) IsAccessToken() bool {
 	return tc.Type == "access"
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeSlot представляет свободный интервал для записи
+// Start и End передаются в UTC, StartLocal и EndLocal - в часовом поясе барбера
+type TimeSlot struct {
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	StartLocal time.Time `json:"start_local"`
+	EndLocal   time.Time `json:"end_local"`
+}
+
+// Availability представляет свободное время барбера для услуги
+type Availability struct {
+	BarberID  uint       `json:"barber_id"`
+	ServiceID uint       `json:"service_id"`
+	Duration  int        `json:"duration"` // длительность услуги в минутах
+	Timezone  string     `json:"timezone"` // часовой пояс барбера, в котором заданы даты периода
+	From      string     `json:"from"`     // первый день периода, "2006-01-02"
+	To        string     `json:"to"`       // последний день периода, "2006-01-02"
+	Slots     []TimeSlot `json:"slots"`
+}
+
+// ParseClockTime разбирает время в формате "HH:MM" и возвращает минуты от начала суток
+func ParseClockTime(value string) (int, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("неверный формат времени %q, ожидается HH:MM", value)
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+// ISODayOfWeek возвращает день недели в формате WorkingHours (1 = понедельник, 7 = воскресенье)
+func ISODayOfWeek(t time.Time) int {
+	if t.Weekday() == time.Sunday {
+		return 7
+	}
+	return int(t.Weekday())
+}
+
+// HasBreak проверяет, задан ли перерыв
+func (wh *WorkingHours) HasBreak() bool {
+	return wh.BreakStart != "" && wh.BreakEnd != ""
+}
+
+// FormatClockTime форматирует минуты от начала суток в "HH:MM"
+func FormatClockTime(minutes int) string {
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// WorkingHoursRequest представляет расписание барбера на один день недели
+type WorkingHoursRequest struct {
+	DayOfWeek  int    `json:"day_of_week" binding:"required,min=1,max=7"` // 1 = понедельник
+	StartTime  string `json:"start_time" binding:"required"`              // "09:00"
+	EndTime    string `json:"end_time" binding:"required"`                // "18:00"
+	BreakStart string `json:"break_start"`                                // "13:00", необязательно
+	BreakEnd   string `json:"break_end"`                                  // "14:00", необязательно
+	IsActive   *bool  `json:"is_active"`                                  // по умолчанию день рабочий
+}
+
+// WeeklyScheduleRequest представляет полную замену недельного расписания
+type WeeklyScheduleRequest struct {
+	Days []WorkingHoursRequest `json:"days"`
+}
@@ -0,0 +1,80 @@
+package models
+
+import "strconv"
+
+// Способы входа, которые можно привязать к пользователю
+const (
+	IdentityTelegram = "telegram" // Telegram Login Widget или Telegram WebApp
+	IdentityPassword = "password" // email и пароль
+)
+
+// UserIdentity способ входа, привязанный к пользователю.
+// Отдельной таблицы способов входа нет: учетные данные хранятся в самом пользователе
+// (TelegramID, Email и PasswordHash), а их уникальность обеспечивают индексы users.
+// Поэтому у пользователя может быть одновременно вход через Telegram и по паролю,
+// но не больше одного Telegram и одного email: другой Telegram привязывается только
+// после отвязки текущего, а аккаунты с разными Telegram не объединяются
+type UserIdentity struct {
+	Provider string `json:"provider"`           // IdentityTelegram или IdentityPassword
+	Subject  string `json:"subject"`            // Telegram ID или email
+	Username string `json:"username,omitempty"` // имя пользователя Telegram
+	Verified bool   `json:"verified"`           // email подтвержден; Telegram подтверждается подписью
+}
+
+// HasPassword проверяет, что пользователь может входить по email и паролю
+func (u *User) HasPassword() bool {
+	return u.PasswordHash != ""
+}
+
+// HasTelegram проверяет, что к пользователю привязан Telegram
+func (u *User) HasTelegram() bool {
+	return u.TelegramID != 0
+}
+
+// Identities возвращает способы входа пользователя
+func (u *User) Identities() []UserIdentity {
+	identities := []UserIdentity{}
+	if u.HasTelegram() {
+		identities = append(identities, UserIdentity{
+			Provider: IdentityTelegram,
+			Subject:  strconv.FormatInt(u.TelegramID, 10),
+			Username: u.Username,
+			Verified: true,
+		})
+	}
+	if u.HasPassword() {
+		identities = append(identities, UserIdentity{
+			Provider: IdentityPassword,
+			Subject:  u.Email,
+			Verified: u.EmailVerifiedAt != nil,
+		})
+	}
+	return identities
+}
+
+// LinkTelegramRequest представляет привязку Telegram к текущему пользователю:
+// данные Telegram Login Widget или initData Telegram WebApp
+type LinkTelegramRequest struct {
+	Telegram TelegramLoginData `json:"telegram,omitempty"`
+	InitData string            `json:"init_data,omitempty"`
+}
+
+// SetPasswordRequest представляет добавление входа по паролю.
+// Email обязателен, если у пользователя его еще нет
+type SetPasswordRequest struct {
+	Email    string `json:"email"`
+	Password string `json:"password" binding:"required"` // проверяется политикой паролей
+}
+
+// UserMergeRequest представляет объединение дубликата с аккаунтом из пути запроса
+type UserMergeRequest struct {
+	DuplicateUserID uint `json:"duplicate_user_id" binding:"required"`
+}
+
+// UserMergeResult итог объединения аккаунтов: сохраненный пользователь и число перенесенных записей
+type UserMergeResult struct {
+	User         User  `json:"user"`
+	Appointments int64 `json:"appointments"`
+	Reviews      int64 `json:"reviews"`
+	Roles        int64 `json:"roles"`
+}
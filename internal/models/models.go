@@ -13,17 +13,27 @@ type User struct {
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 
-	// Основная информация
-	TelegramID int64  `json:"telegram_id" gorm:"uniqueIndex"`
+	// Основная информация; TelegramID 0 и пустой email - не привязаны, уникальны только заданные значения
+	TelegramID int64  `json:"telegram_id" gorm:"uniqueIndex:idx_users_telegram_id_linked,where:telegram_id <> 0"`
 	Username   string `json:"username"`
 	FirstName  string `json:"first_name"`
 	LastName   string `json:"last_name"`
 	Phone      string `json:"phone"`
-	Email      string `json:"email" gorm:"uniqueIndex"`
+	Email      string `json:"email" gorm:"uniqueIndex:idx_users_email_linked,where:email <> ''"`
 
-	// Прямая авторизация (без Telegram)
+	// Вход по email и паролю; доступен и пользователям Telegram, задавшим пароль
 	PasswordHash string `json:"-" gorm:"column:password_hash"` // хеш пароля (не возвращаем в JSON)
-	AuthMethod   string `json:"auth_method"`                   // "telegram" или "direct"
+	AuthMethod   string `json:"auth_method"`                   // способ регистрации: "telegram" или "direct"
+
+	// Подтверждение email (для прямой авторизации)
+	EmailVerifiedAt         *time.Time `json:"email_verified_at"`
+	EmailVerificationSentAt *time.Time `json:"-"` // когда отправлено последнее письмо подтверждения
+
+	// Двухфакторная аутентификация (TOTP)
+	TOTPSecret         string     `json:"-" gorm:"column:totp_secret"`                   // зашифрованный секрет, задается при подключении
+	TOTPEnabledAt      *time.Time `json:"totp_enabled_at" gorm:"column:totp_enabled_at"` // nil - не подключена или не подтверждена
+	TOTPLastStep       int64      `json:"-" gorm:"column:totp_last_step"`                // шаг последнего принятого кода, повтор не принимается
+	MFAChallengeUsedAt int64      `json:"-" gorm:"column:mfa_challenge_used_at"`         // время выдачи последнего использованного токена входа, более ранние не принимаются
 
 	// Роли пользователя (many-to-many через UserRole)
 	Roles []Role `json:"roles" gorm:"many2many:user_roles;"`
@@ -33,12 +43,24 @@ type User struct {
 	Specialties string  `json:"specialties"` // специализации (стрижки, бороды, etc)
 	Experience  int     `json:"experience"`  // опыт в годах
 	Rating      float64 `json:"rating"`      // рейтинг барбера
+	Timezone    string  `json:"timezone"`    // часовой пояс IANA, пустой - часовой пояс барбершопа
 
 	// Для клиента
 	Preferences string `json:"preferences"` // предпочтения клиента
 	Notes       string `json:"notes"`       // заметки о клиенте
 }
 
+// HasTwoFactor проверяет, что двухфакторная аутентификация подключена и подтверждена
+func (u *User) HasTwoFactor() bool {
+	return u.TOTPEnabledAt != nil
+}
+
+// NeedsEmailVerification проверяет, что пользователь со входом по паролю еще не подтвердил email.
+// Пользователей Telegram подтверждает сам Telegram
+func (u *User) NeedsEmailVerification() bool {
+	return u.HasPassword() && u.EmailVerifiedAt == nil
+}
+
 // Service - услуги барбера
 type Service struct {
 	ID        uint           `json:"id" gorm:"primaryKey"`
@@ -65,10 +87,14 @@ type Appointment struct {
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 
-	// Время записи
+	// Время записи (хранится в UTC)
 	DateTime time.Time `json:"datetime" gorm:"not null"`
 	Duration int       `json:"duration"` // длительность в минутах
 
+	// Время записи в часовом поясе барбера, заполняется при выдаче
+	LocalDateTime *time.Time `json:"local_datetime,omitempty" gorm:"-"`
+	Timezone      string     `json:"timezone,omitempty" gorm:"-"`
+
 	// Статус записи
 	Status string `json:"status"` // "pending", "confirmed", "completed", "cancelled"
 
@@ -95,8 +121,8 @@ type WorkingHours struct {
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
 
-	// День недели (1-7, где 1 = понедельник)
-	DayOfWeek int `json:"day_of_week" gorm:"not null"`
+	// День недели (1-7, где 1 = понедельник); у барбера одно расписание на день
+	DayOfWeek int `json:"day_of_week" gorm:"not null;uniqueIndex:idx_working_hours_barber_day,priority:2"`
 
 	// Время работы
 	StartTime string `json:"start_time"` // "09:00"
@@ -110,7 +136,33 @@ type WorkingHours struct {
 	IsActive bool `json:"is_active"`
 
 	// Связь с барбером
-	BarberID uint `json:"barber_id" gorm:"not null"`
+	BarberID uint `json:"barber_id" gorm:"not null;uniqueIndex:idx_working_hours_barber_day,priority:1"`
+	Barber   User `json:"barber" gorm:"foreignKey:BarberID"`
+}
+
+// ScheduleException - исключения из недельного расписания (отпуск, больничный, дополнительный рабочий день)
+type ScheduleException struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+
+	// Тип исключения: time_off или extra_working_day
+	Type string `json:"type" gorm:"not null"`
+
+	// Период действия (включительно)
+	StartDate string `json:"start_date" gorm:"not null;index"` // "2024-01-15"
+	EndDate   string `json:"end_date" gorm:"not null;index"`   // "2024-01-20"
+
+	// Время в каждый из дней периода; для выходного на весь день не заполняется
+	IsFullDay bool   `json:"is_full_day"`
+	StartTime string `json:"start_time"` // "12:00"
+	EndTime   string `json:"end_time"`   // "15:00"
+
+	Reason string `json:"reason"`
+
+	// Связь с барбером
+	BarberID uint `json:"barber_id" gorm:"not null;index"`
 	Barber   User `json:"barber" gorm:"foreignKey:BarberID"`
 }
 
@@ -136,8 +188,14 @@ type Payment struct {
 	Appointment   Appointment `json:"appointment" gorm:"foreignKey:AppointmentID"`
 
 	// Внешние ID
-	ExternalID string `json:"external_id"` // ID в платежной системе
-	ReceiptURL string `json:"receipt_url"` // ссылка на чек
+	ExternalID string `json:"external_id" gorm:"index"` // ID в платежной системе
+	ReceiptURL string `json:"receipt_url"`              // ссылка на чек
+
+	// Деньги списаны, хотя запись уже оплачена другим платежом: платеж нужно вернуть
+	RefundRequired bool `json:"refund_required"`
+
+	// Ссылка на оплату у провайдера, заполняется при создании платежа
+	PaymentURL string `json:"payment_url,omitempty" gorm:"-"`
 }
 
 // Review - отзывы клиентов
@@ -151,13 +209,19 @@ type Review struct {
 	Rating  int    `json:"rating"`  // оценка от 1 до 5
 	Comment string `json:"comment"` // комментарий клиента
 
+	// Модерация: скрытый отзыв не показывается публично и не влияет на рейтинг
+	IsHidden     bool       `json:"is_hidden" gorm:"default:false"`
+	HiddenReason string     `json:"hidden_reason,omitempty"`
+	HiddenAt     *time.Time `json:"hidden_at,omitempty"`
+
 	// Связи
 	ClientID uint `json:"client_id" gorm:"not null"`
 	Client   User `json:"client" gorm:"foreignKey:ClientID"`
 
-	BarberID uint `json:"barber_id" gorm:"not null"`
+	BarberID uint `json:"barber_id" gorm:"not null;index"`
 	Barber   User `json:"barber" gorm:"foreignKey:BarberID"`
 
-	AppointmentID uint        `json:"appointment_id" gorm:"not null"`
+	// Один отзыв на запись
+	AppointmentID uint        `json:"appointment_id" gorm:"not null;uniqueIndex"`
 	Appointment   Appointment `json:"appointment" gorm:"foreignKey:AppointmentID"`
 }
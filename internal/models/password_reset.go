@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// PasswordResetToken - одноразовый токен сброса пароля.
+// Хранится только SHA-256 хеш токена: сам токен знает лишь получатель письма
+type PasswordResetToken struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	TokenHash string     `json:"-" gorm:"not null;uniqueIndex"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"` // токен использован или заменен более новым
+}
+
+// IsUsable проверяет, что токен не использован и не истек
+func (t *PasswordResetToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
+
+// PasswordForgotRequest представляет запрос на сброс забытого пароля
+type PasswordForgotRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// PasswordResetRequest представляет установку нового пароля по токену из письма
+type PasswordResetRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"` // проверяется политикой паролей
+}
+
+// PasswordChangeRequest представляет смену пароля аутентифицированным пользователем
+type PasswordChangeRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"` // проверяется политикой паролей
+}
@@ -0,0 +1,49 @@
+package models
+
+// Статусы платежа
+const (
+	PaymentPending   = "pending"
+	PaymentCompleted = "completed"
+	PaymentFailed    = "failed"
+	PaymentRefunded  = "refunded"
+)
+
+// Способы оплаты
+const (
+	PaymentMethodCash     = "cash"
+	PaymentMethodCard     = "card"
+	PaymentMethodTelegram = "telegram"
+)
+
+// paymentTransitions допустимые переходы между статусами платежа
+var paymentTransitions = map[string][]string{
+	PaymentPending:   {PaymentCompleted, PaymentFailed},
+	PaymentCompleted: {PaymentRefunded},
+}
+
+// PaymentCreateRequest представляет запрос клиента на оплату записи
+type PaymentCreateRequest struct {
+	Method string `json:"method" binding:"required"` // "cash", "card", "telegram"
+}
+
+// CanTransitionTo проверяет, можно ли перевести платеж в указанный статус
+func (p *Payment) CanTransitionTo(status string) bool {
+	for _, allowed := range paymentTransitions[p.Status] {
+		if allowed == status {
+			return true
+		}
+	}
+	return false
+}
+
+// AppointmentPaymentStatus возвращает статус оплаты записи, соответствующий статусу платежа
+func (p *Payment) AppointmentPaymentStatus() string {
+	switch p.Status {
+	case PaymentCompleted:
+		return PaymentStatusPaid
+	case PaymentRefunded:
+		return PaymentStatusRefunded
+	default:
+		return PaymentStatusPending
+	}
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PermissionWildcard обозначает любой ресурс или любое действие
+const PermissionWildcard = "*"
+
+// Действия над ресурсами
+const (
+	ActionCreate = "create"
+	ActionRead   = "read"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// PermissionSet набор разрешений: ресурс → список действий.
+// В Role.Permissions хранится как JSON, например {"appointments": ["create", "read"]}
+type PermissionSet map[string][]string
+
+// ParsePermissions разбирает JSON разрешений роли. Пустая строка означает отсутствие разрешений
+func ParsePermissions(raw string) (PermissionSet, error) {
+	permissions := PermissionSet{}
+	if strings.TrimSpace(raw) == "" {
+		return permissions, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &permissions); err != nil {
+		return nil, fmt.Errorf("неверный формат разрешений: %v", err)
+	}
+	return permissions.Normalize()
+}
+
+// Normalize проверяет набор, приводит имена к нижнему регистру и убирает повторы
+func (p PermissionSet) Normalize() (PermissionSet, error) {
+	normalized := make(PermissionSet, len(p))
+	for resource, actions := range p {
+		resource = strings.ToLower(strings.TrimSpace(resource))
+		if resource == "" {
+			return nil, fmt.Errorf("пустое имя ресурса в разрешениях")
+		}
+
+		for _, action := range actions {
+			action = strings.ToLower(strings.TrimSpace(action))
+			if action == "" {
+				return nil, fmt.Errorf("пустое действие для ресурса %s", resource)
+			}
+			if !containsString(normalized[resource], action) {
+				normalized[resource] = append(normalized[resource], action)
+			}
+		}
+		sort.Strings(normalized[resource])
+	}
+	return normalized, nil
+}
+
+// Allows проверяет, разрешено ли действие над ресурсом (с учетом "*")
+func (p PermissionSet) Allows(resource, action string) bool {
+	for _, key := range []string{resource, PermissionWildcard} {
+		actions := p[key]
+		if containsString(actions, action) || containsString(actions, PermissionWildcard) {
+			return true
+		}
+	}
+	return false
+}
+
+// Merge добавляет разрешения другого набора
+func (p PermissionSet) Merge(other PermissionSet) {
+	for resource, actions := range other {
+		for _, action := range actions {
+			if !containsString(p[resource], action) {
+				p[resource] = append(p[resource], action)
+			}
+		}
+	}
+}
+
+// JSON сериализует набор для сохранения в Role.Permissions
+func (p PermissionSet) JSON() string {
+	data, _ := json.Marshal(p)
+	return string(data)
+}
+
+// ParsedPermissions разбирает разрешения роли
+func (r Role) ParsedPermissions() (PermissionSet, error) {
+	return ParsePermissions(r.Permissions)
+}
+
+// containsString проверяет наличие строки в списке
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
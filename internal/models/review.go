@@ -0,0 +1,88 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// MinReviewRating и MaxReviewRating границы оценки в отзыве
+	MinReviewRating = 1
+	MaxReviewRating = 5
+
+	// RatingPriorMean и RatingPriorWeight параметры байесовского среднего:
+	// рейтинг барбера ведет себя так, будто к его отзывам добавлено
+	// RatingPriorWeight оценок со значением RatingPriorMean.
+	// Новый барбер начинает с RatingPriorMean, и пара отзывов не сдвигает рейтинг к крайним значениям
+	RatingPriorMean   = 5.0
+	RatingPriorWeight = 5
+
+	// DefaultPageSize и MaxPageSize размер страницы списков по умолчанию и максимальный
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// ReviewRequest представляет запрос клиента на создание или изменение отзыва
+type ReviewRequest struct {
+	Rating  int    `json:"rating" binding:"required,min=1,max=5"`
+	Comment string `json:"comment"`
+}
+
+// ReviewHideRequest представляет запрос администратора на скрытие отзыва
+type ReviewHideRequest struct {
+	Reason string `json:"reason"`
+}
+
+// PublicReview отзыв в публичном списке: без контактов клиента
+type PublicReview struct {
+	ID         uint      `json:"id"`
+	Rating     int       `json:"rating"`
+	Comment    string    `json:"comment"`
+	ClientName string    `json:"client_name"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// NewPublicReview подготавливает отзыв для публичного списка (клиент должен быть загружен)
+func NewPublicReview(review Review) PublicReview {
+	return PublicReview{
+		ID:         review.ID,
+		Rating:     review.Rating,
+		Comment:    review.Comment,
+		ClientName: review.Client.FirstName,
+		CreatedAt:  review.CreatedAt,
+		UpdatedAt:  review.UpdatedAt,
+	}
+}
+
+// ReviewFilter представляет фильтры списка отзывов
+type ReviewFilter struct {
+	BarberID      uint
+	IncludeHidden bool // показывать скрытые отзывы (для администратора)
+	Page          int
+	PageSize      int
+}
+
+// Normalize подставляет значения страницы по умолчанию и ограничивает ее размер
+func (f *ReviewFilter) Normalize() {
+	if f.Page < 1 {
+		f.Page = 1
+	}
+	if f.PageSize < 1 {
+		f.PageSize = DefaultPageSize
+	}
+	if f.PageSize > MaxPageSize {
+		f.PageSize = MaxPageSize
+	}
+}
+
+// Offset возвращает смещение первой записи страницы
+func (f ReviewFilter) Offset() int {
+	return (f.Page - 1) * f.PageSize
+}
+
+// BayesianRating считает рейтинг барбера по числу и сумме оценок видимых отзывов
+func BayesianRating(count, sum int64) float64 {
+	rating := (RatingPriorMean*RatingPriorWeight + float64(sum)) / float64(RatingPriorWeight+count)
+	return math.Round(rating*100) / 100
+}
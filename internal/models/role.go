@@ -32,6 +32,12 @@ type UserRole struct {
 	AssignedBy uint      `json:"assigned_by"` // Кто назначил роль
 	AssignedAt time.Time `json:"assigned_at"` // Когда назначена
 	IsActive   int       `json:"is_active" gorm:"default:1"` // Активна ли связь (1 = true, 0 = false)
+	Reason     string    `json:"reason"`                     // Причина назначения
+
+	// Снятие роли
+	RevokedBy     uint       `json:"revoked_by,omitempty"`
+	RevokedAt     *time.Time `json:"revoked_at,omitempty"`
+	RevokedReason string     `json:"revoked_reason,omitempty"`
 
 	// Связи
 	User User `json:"user" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
@@ -57,3 +63,32 @@ type UserWithRoles struct {
 	User  User   `json:"user"`
 	Roles []Role `json:"roles"`
 }
+
+// ProtectedRoles системные роли, на которые опирается код: их нельзя удалить, деактивировать или переименовать
+var ProtectedRoles = []string{"admin", "barber", "client"}
+
+// IsProtected проверяет, является ли роль системной
+func (r Role) IsProtected() bool {
+	for _, name := range ProtectedRoles {
+		if r.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleCreateRequest представляет запрос администратора на создание роли
+type RoleCreateRequest struct {
+	Name        string        `json:"name" binding:"required"`
+	DisplayName string        `json:"display_name"`
+	Description string        `json:"description"`
+	Permissions PermissionSet `json:"permissions"`
+}
+
+// RoleUpdateRequest представляет запрос администратора на изменение роли
+type RoleUpdateRequest struct {
+	DisplayName *string       `json:"display_name"`
+	Description *string       `json:"description"`
+	IsActive    *bool         `json:"is_active"`   // указатель для различения false и отсутствия поля
+	Permissions PermissionSet `json:"permissions"` // nil - разрешения не меняются
+}
@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// Типы исключений из расписания
+const (
+	ScheduleExceptionTimeOff         = "time_off"          // барбер не работает
+	ScheduleExceptionExtraWorkingDay = "extra_working_day" // барбер работает вне недельного расписания
+)
+
+// DateLayout формат дат исключений из расписания
+const DateLayout = "2006-01-02"
+
+// ScheduleExceptionRequest представляет запрос на создание исключения из расписания
+type ScheduleExceptionRequest struct {
+	Type      string `json:"type"`                          // по умолчанию time_off
+	StartDate string `json:"start_date" binding:"required"` // "2024-01-15"
+	EndDate   string `json:"end_date"`                      // по умолчанию равен start_date
+	IsFullDay bool   `json:"is_full_day"`
+	StartTime string `json:"start_time"`
+	EndTime   string `json:"end_time"`
+	Reason    string `json:"reason"`
+	// Force создает исключение, даже если на это время уже есть записи
+	Force bool `json:"force"`
+}
+
+// ScheduleExceptionResult результат создания исключения
+type ScheduleExceptionResult struct {
+	Exception            *ScheduleException `json:"exception,omitempty"`
+	AffectedAppointments []Appointment      `json:"affected_appointments"`
+}
+
+// IsTimeOff проверяет, закрывает ли исключение время для записи
+func (e *ScheduleException) IsTimeOff() bool {
+	return e.Type == ScheduleExceptionTimeOff
+}
+
+// CoversDate проверяет, входит ли день в период исключения
+func (e *ScheduleException) CoversDate(day time.Time) bool {
+	date := day.Format(DateLayout)
+	return e.StartDate <= date && date <= e.EndDate
+}
+
+// Overlaps проверяет, пересекается ли исключение с другим по датам и времени
+func (e *ScheduleException) Overlaps(other *ScheduleException) bool {
+	if e.StartDate > other.EndDate || other.StartDate > e.EndDate {
+		return false
+	}
+	if e.IsFullDay || other.IsFullDay {
+		return true
+	}
+	// Время хранится в формате HH:MM, поэтому его можно сравнивать как строки
+	return e.StartTime < other.EndTime && other.StartTime < e.EndTime
+}
+
+// IntervalOn возвращает интервал исключения в указанный день
+func (e *ScheduleException) IntervalOn(day time.Time) (time.Time, time.Time, error) {
+	dayStart := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	if e.IsFullDay {
+		return dayStart, dayStart.AddDate(0, 0, 1), nil
+	}
+
+	start, err := ParseClockTime(e.StartTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end, err := ParseClockTime(e.EndTime)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), start/60, start%60, 0, 0, day.Location()),
+		time.Date(day.Year(), day.Month(), day.Day(), end/60, end%60, 0, 0, day.Location()), nil
+}
@@ -0,0 +1,29 @@
+package models
+
+// ServiceCreateRequest представляет запрос барбера на создание услуги
+type ServiceCreateRequest struct {
+	Name        string  `json:"name" binding:"required"`
+	Description string  `json:"description"`
+	Price       float64 `json:"price" binding:"min=0"`
+	Duration    int     `json:"duration" binding:"required,min=1"` // длительность в минутах
+	IsActive    *bool   `json:"is_active"`                         // по умолчанию услуга активна
+}
+
+// ServiceUpdateRequest представляет запрос барбера на обновление услуги
+type ServiceUpdateRequest struct {
+	Name        string   `json:"name"`
+	Description *string  `json:"description"` // указатель, чтобы можно было очистить описание
+	Price       *float64 `json:"price"`       // указатель для различения 0 и отсутствия поля
+	Duration    *int     `json:"duration"`
+	IsActive    *bool    `json:"is_active"`
+}
+
+// ServiceFilter представляет фильтры публичного каталога услуг
+type ServiceFilter struct {
+	BarberID        uint
+	MinPrice        *float64
+	MaxPrice        *float64
+	MinDuration     *int
+	MaxDuration     *int
+	IncludeInactive bool // показывать деактивированные услуги (для самого барбера)
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Причины отзыва сессии
+const (
+	SessionRevokedByUser      = "user"        // пользователь завершил сессию
+	SessionRevokedTokenReused = "token_reuse" // предъявлен уже замененный refresh token
+	SessionRevokedPassword    = "password"    // пароль пользователя сброшен или изменен
+	SessionRevokedMerged      = "merged"      // аккаунт объединен с другим
+)
+
+// Session - сессия пользователя на одном устройстве и одновременно семейство его refresh token.
+// Сессия хранит JTI действующего refresh token; при обновлении токенов JTI меняется,
+// а сама сессия (и ее ID) остается прежней. ExpiresAt - абсолютный срок жизни сессии,
+// после него нужен новый вход независимо от обновлений токенов
+type Session struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"-"`
+
+	UserID        uint       `json:"-" gorm:"not null;index"`
+	JTI           string     `json:"-" gorm:"not null;uniqueIndex"` // JTI действующего refresh token
+	DeviceName    string     `json:"device_name"`
+	IP            string     `json:"ip"`
+	UserAgent     string     `json:"user_agent"`
+	LastUsedAt    time.Time  `json:"last_used_at"`
+	ExpiresAt     time.Time  `json:"expires_at"`
+	RevokedAt     *time.Time `json:"-"`
+	RevokedReason string     `json:"-"`
+
+	Current bool `json:"current" gorm:"-"` // сессия текущего запроса
+}
+
+// IsActive проверяет, что сессия не отозвана и не истекла
+func (s *Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// DeviceInfo описывает устройство, с которого открывается или обновляется сессия
+type DeviceInfo struct {
+	Name      string
+	IP        string
+	UserAgent string
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LocalDateTimeLayout формат времени барбера без смещения
+const LocalDateTimeLayout = "2006-01-02T15:04"
+
+// LoadTimezone загружает часовой пояс IANA, например "Europe/Moscow"
+func LoadTimezone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	// Пустое имя и "Local" зависят от настроек сервера, поэтому не принимаются
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("часовой пояс не указан")
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("неизвестный часовой пояс %q", name)
+	}
+	return loc, nil
+}
+
+// ParseLocalDateTime разбирает время "2006-01-02T15:04" в указанном часовом поясе
+// Время, пропущенное при переходе на летнее время, считается ошибкой
+func ParseLocalDateTime(value string, loc *time.Location) (time.Time, error) {
+	parsed, err := time.ParseInLocation(LocalDateTimeLayout, value, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("неверный формат времени %q, ожидается YYYY-MM-DDTHH:MM", value)
+	}
+	if parsed.Format(LocalDateTimeLayout) != value {
+		return time.Time{}, fmt.Errorf("время %s не существует в часовом поясе %s", value, loc)
+	}
+	return parsed, nil
+}
@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// RecoveryCode - одноразовый код восстановления доступа при потере приложения-аутентификатора.
+// Хранится только SHA-256 хеш кода: сами коды показываются пользователю один раз
+type RecoveryCode struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+
+	UserID   uint       `json:"user_id" gorm:"not null;index"`
+	CodeHash string     `json:"-" gorm:"not null;uniqueIndex"`
+	UsedAt   *time.Time `json:"used_at"`
+}
+
+// TwoFactorSetup данные для подключения приложения-аутентификатора
+type TwoFactorSetup struct {
+	Secret string `json:"secret"` // секрет base32 для ручного ввода
+	URI    string `json:"uri"`    // otpauth:// URI для QR кода
+}
+
+// TwoFactorStatus состояние двухфакторной аутентификации пользователя
+type TwoFactorStatus struct {
+	Enabled            bool       `json:"enabled"`
+	EnabledAt          *time.Time `json:"enabled_at,omitempty"`
+	Required           bool       `json:"required"` // обязательна по политике для роли пользователя
+	RecoveryCodesCount int64      `json:"recovery_codes_count"`
+}
+
+// TwoFactorCodeRequest представляет код из приложения-аутентификатора или код восстановления
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// RecoveryCodesResponse новые коды восстановления, показываются один раз
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFAChallengeResponse ответ на вход, когда для выдачи токенов нужен второй фактор.
+// EnrollmentRequired - двухфакторная аутентификация обязательна, но еще не настроена
+type MFAChallengeResponse struct {
+	MFARequired        bool   `json:"mfa_required"`
+	EnrollmentRequired bool   `json:"enrollment_required,omitempty"`
+	ChallengeToken     string `json:"challenge_token"`
+	ExpiresIn          int    `json:"expires_in"` // секунды
+}
+
+// MFAChallengeRequest представляет токен второго шага входа
+type MFAChallengeRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+}
+
+// MFAVerifyRequest представляет второй шаг входа: токен и код
+type MFAVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+// MFAEnrollmentResponse токены и коды восстановления после настройки
+// обязательной двухфакторной аутентификации при входе
+type MFAEnrollmentResponse struct {
+	AuthResponse
+	RecoveryCodes []string `json:"recovery_codes"`
+}
@@ -0,0 +1,45 @@
+package payments
+
+import (
+	"net/http"
+
+	"garage-barbershop/internal/models"
+)
+
+// CashProvider оплата наличными в барбершопе: деньги принимает и возвращает барбер
+type CashProvider struct{}
+
+// NewCashProvider создает провайдер оплаты наличными
+func NewCashProvider() *CashProvider {
+	return &CashProvider{}
+}
+
+// Method возвращает способ оплаты
+func (p *CashProvider) Method() string {
+	return models.PaymentMethodCash
+}
+
+// CreateIntent ничего не регистрирует: платеж ждет, пока барбер примет деньги
+func (p *CashProvider) CreateIntent(payment *models.Payment) (*Intent, error) {
+	return &Intent{}, nil
+}
+
+// Capture фиксирует, что барбер получил наличные
+func (p *CashProvider) Capture(payment *models.Payment) (*Result, error) {
+	return &Result{ExternalID: payment.ExternalID}, nil
+}
+
+// Refund фиксирует, что барбер вернул наличные
+func (p *CashProvider) Refund(payment *models.Payment) (*Result, error) {
+	return &Result{ExternalID: payment.ExternalID}, nil
+}
+
+// Cancel ничего не делает: пока барбер не принял наличные, списывать нечего
+func (p *CashProvider) Cancel(payment *models.Payment) error {
+	return nil
+}
+
+// VerifyWebhook не поддерживается для наличных
+func (p *CashProvider) VerifyWebhook(payload []byte, header http.Header) (*WebhookEvent, error) {
+	return nil, ErrWebhookNotSupported
+}
@@ -0,0 +1,109 @@
+package payments
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"garage-barbershop/internal/models"
+)
+
+// FakeSignatureHeader заголовок с подписью уведомлений тестового провайдера
+const FakeSignatureHeader = "X-Fake-Signature"
+
+// FakeCardProvider имитация карточного эквайринга для разработки и тестов.
+// Уведомления подписываются HMAC-SHA256 от тела запроса с секретом провайдера
+type FakeCardProvider struct {
+	webhookSecret string
+
+	// FailCapture и FailRefund заставляют провайдера отклонять операции
+	FailCapture bool
+	FailRefund  bool
+
+	mu  sync.Mutex
+	seq int
+}
+
+// NewFakeCardProvider создает тестовый карточный провайдер
+func NewFakeCardProvider(webhookSecret string) *FakeCardProvider {
+	return &FakeCardProvider{webhookSecret: webhookSecret}
+}
+
+// Method возвращает способ оплаты
+func (p *FakeCardProvider) Method() string {
+	return models.PaymentMethodCard
+}
+
+// CreateIntent выдает ID платежа и ссылку на оплату
+func (p *FakeCardProvider) CreateIntent(payment *models.Payment) (*Intent, error) {
+	p.mu.Lock()
+	p.seq++
+	externalID := fmt.Sprintf("fake_pi_%d_%d", payment.ID, p.seq)
+	p.mu.Unlock()
+
+	return &Intent{
+		ExternalID: externalID,
+		PaymentURL: "https://pay.example.com/" + externalID,
+	}, nil
+}
+
+// Capture списывает деньги с карты
+func (p *FakeCardProvider) Capture(payment *models.Payment) (*Result, error) {
+	if p.FailCapture {
+		return nil, fmt.Errorf("карта отклонена")
+	}
+	return &Result{
+		ExternalID: payment.ExternalID,
+		ReceiptURL: "https://pay.example.com/receipts/" + payment.ExternalID,
+	}, nil
+}
+
+// Refund возвращает деньги на карту
+func (p *FakeCardProvider) Refund(payment *models.Payment) (*Result, error) {
+	if p.FailRefund {
+		return nil, fmt.Errorf("возврат отклонен")
+	}
+	return &Result{ExternalID: payment.ExternalID, ReceiptURL: payment.ReceiptURL}, nil
+}
+
+// Cancel отменяет платеж до списания
+func (p *FakeCardProvider) Cancel(payment *models.Payment) error {
+	return nil
+}
+
+// fakeWebhookPayload тело уведомления тестового провайдера
+type fakeWebhookPayload struct {
+	ExternalID string `json:"external_id"`
+	Status     string `json:"status"`
+}
+
+// VerifyWebhook проверяет подпись и разбирает уведомление
+func (p *FakeCardProvider) VerifyWebhook(payload []byte, header http.Header) (*WebhookEvent, error) {
+	signature, err := hex.DecodeString(header.Get(FakeSignatureHeader))
+	if err != nil || p.webhookSecret == "" || !hmac.Equal(signature, p.sign(payload)) {
+		return nil, ErrInvalidSignature
+	}
+
+	var event fakeWebhookPayload
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("неверный формат уведомления: %v", err)
+	}
+
+	return &WebhookEvent{ExternalID: event.ExternalID, Status: event.Status}, nil
+}
+
+// SignWebhook подписывает тело уведомления, как это делает платежная система
+func (p *FakeCardProvider) SignWebhook(payload []byte) string {
+	return hex.EncodeToString(p.sign(payload))
+}
+
+// sign вычисляет HMAC-SHA256 тела уведомления
+func (p *FakeCardProvider) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(p.webhookSecret))
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
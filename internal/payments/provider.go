@@ -0,0 +1,69 @@
+package payments
+
+import (
+	"errors"
+	"net/http"
+
+	"garage-barbershop/internal/models"
+)
+
+// ErrWebhookNotSupported возвращается провайдерами без уведомлений от платежной системы
+var ErrWebhookNotSupported = errors.New("провайдер не поддерживает уведомления")
+
+// ErrInvalidSignature возвращается, если подпись уведомления не прошла проверку
+var ErrInvalidSignature = errors.New("неверная подпись уведомления")
+
+// ErrCaptureNotSupported возвращается провайдерами, которые проводят оплату сами, без подтверждения барбером
+var ErrCaptureNotSupported = errors.New("платеж подтверждается платежной системой")
+
+// Intent результат создания платежа у провайдера
+type Intent struct {
+	ExternalID string // ID платежа у провайдера
+	PaymentURL string // ссылка, по которой клиент завершает оплату (если нужна)
+}
+
+// Result результат списания или возврата
+type Result struct {
+	ExternalID string
+	ReceiptURL string
+}
+
+// WebhookEvent уведомление платежной системы об изменении статуса платежа
+type WebhookEvent struct {
+	// PaymentID заполняется провайдерами, которые передают наш ID платежа;
+	// иначе платеж ищется по ExternalID
+	PaymentID  uint
+	ExternalID string
+	Status     string // models.PaymentCompleted, models.PaymentFailed или models.PaymentRefunded
+	ReceiptURL string
+
+	// PreCheckoutID заполняется для запроса подтверждения перед списанием (статус при этом пустой)
+	PreCheckoutID string
+	Amount        float64
+	Currency      string
+}
+
+// PreCheckoutAnswerer провайдер, который спрашивает подтверждение платежа перед списанием
+type PreCheckoutAnswerer interface {
+	AnswerPreCheckout(event *WebhookEvent, ok bool, reason string) error
+}
+
+// Canceler провайдер, у которого можно отменить незавершенный платеж, чтобы по нему уже не списали деньги
+type Canceler interface {
+	Cancel(payment *models.Payment) error
+}
+
+// Provider интерфейс платежного провайдера
+type Provider interface {
+	// Method возвращает способ оплаты, который обслуживает провайдер
+	Method() string
+	// CreateIntent регистрирует платеж у провайдера
+	CreateIntent(payment *models.Payment) (*Intent, error)
+	// Capture подтверждает получение денег
+	Capture(payment *models.Payment) (*Result, error)
+	// Refund возвращает деньги клиенту
+	Refund(payment *models.Payment) (*Result, error)
+	// VerifyWebhook проверяет подпись уведомления и разбирает его.
+	// Уведомления, не относящиеся к платежам, возвращаются как nil без ошибки
+	VerifyWebhook(payload []byte, header http.Header) (*WebhookEvent, error)
+}
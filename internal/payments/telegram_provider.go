@@ -0,0 +1,191 @@
+package payments
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/telegram"
+)
+
+// TelegramSecretHeader заголовок с секретом вебхука, заданным в setWebhook
+const TelegramSecretHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+// TelegramStarsCurrency валюта Telegram Stars; возврат по ней делает сам бот
+const TelegramStarsCurrency = "XTR"
+
+// telegramPayloadPrefix префикс payload счета, за которым следует ID платежа
+const telegramPayloadPrefix = "payment:"
+
+// UserLookup источник пользователей для определения чата клиента
+type UserLookup interface {
+	GetByID(id uint) (*models.User, error)
+}
+
+// TelegramProvider оплата через Telegram Payments.
+// Счет отправляется в чат клиента методом sendInvoice, оплата проводится уведомлением successful_payment
+type TelegramProvider struct {
+	bot           telegram.BotAPI
+	providerToken string
+	webhookSecret string
+	users         UserLookup
+}
+
+// NewTelegramProvider создает провайдер Telegram Payments.
+// providerToken выдается платежным провайдером в BotFather; для Telegram Stars он пустой
+func NewTelegramProvider(bot telegram.BotAPI, providerToken, webhookSecret string, users UserLookup) *TelegramProvider {
+	return &TelegramProvider{
+		bot:           bot,
+		providerToken: providerToken,
+		webhookSecret: webhookSecret,
+		users:         users,
+	}
+}
+
+// Method возвращает способ оплаты
+func (p *TelegramProvider) Method() string {
+	return models.PaymentMethodTelegram
+}
+
+// CreateIntent отправляет клиенту счет в Telegram.
+// ID в Telegram появляется только после оплаты, поэтому ExternalID пока пустой
+func (p *TelegramProvider) CreateIntent(payment *models.Payment) (*Intent, error) {
+	client, err := p.users.GetByID(payment.Appointment.ClientID)
+	if err != nil {
+		return nil, fmt.Errorf("клиент не найден")
+	}
+	if client.TelegramID == 0 {
+		return nil, fmt.Errorf("у клиента не привязан Telegram")
+	}
+
+	description := payment.Appointment.Service.Name
+	if description == "" {
+		description = "Оплата услуги барбершопа"
+	}
+
+	_, err = p.bot.SendInvoice(telegram.InvoiceParams{
+		ChatID:        client.TelegramID,
+		Title:         fmt.Sprintf("Запись #%d", payment.AppointmentID),
+		Description:   description,
+		Payload:       telegramPayloadPrefix + strconv.FormatUint(uint64(payment.ID), 10),
+		ProviderToken: p.providerToken,
+		Currency:      payment.Currency,
+		Prices: []telegram.LabeledPrice{
+			{Label: description, Amount: toMinorUnits(payment.Amount, payment.Currency)},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Intent{}, nil
+}
+
+// Capture не поддерживается: деньги списывает Telegram, платеж проводится уведомлением
+func (p *TelegramProvider) Capture(payment *models.Payment) (*Result, error) {
+	return nil, ErrCaptureNotSupported
+}
+
+// Refund возвращает оплату в Telegram Stars.
+// Возврат денег по карте делается в кабинете платежного провайдера
+func (p *TelegramProvider) Refund(payment *models.Payment) (*Result, error) {
+	if payment.Currency != TelegramStarsCurrency {
+		return nil, fmt.Errorf("возврат оплаты в %s выполняется через платежного провайдера", payment.Currency)
+	}
+
+	client, err := p.users.GetByID(payment.Appointment.ClientID)
+	if err != nil {
+		return nil, fmt.Errorf("клиент не найден")
+	}
+	if err := p.bot.RefundStarPayment(client.TelegramID, payment.ExternalID); err != nil {
+		return nil, err
+	}
+	return &Result{ExternalID: payment.ExternalID}, nil
+}
+
+// VerifyWebhook проверяет секрет вебхука и разбирает обновление бота
+func (p *TelegramProvider) VerifyWebhook(payload []byte, header http.Header) (*WebhookEvent, error) {
+	secret := header.Get(TelegramSecretHeader)
+	if p.webhookSecret == "" || subtle.ConstantTimeCompare([]byte(secret), []byte(p.webhookSecret)) != 1 {
+		return nil, ErrInvalidSignature
+	}
+
+	var update telegram.Update
+	if err := json.Unmarshal(payload, &update); err != nil {
+		return nil, fmt.Errorf("неверный формат обновления: %v", err)
+	}
+
+	switch {
+	case update.PreCheckoutQuery != nil:
+		query := update.PreCheckoutQuery
+		paymentID, err := parseTelegramPayload(query.InvoicePayload)
+		if err != nil {
+			return nil, err
+		}
+		return &WebhookEvent{
+			PaymentID:     paymentID,
+			PreCheckoutID: query.ID,
+			Amount:        fromMinorUnits(query.TotalAmount, query.Currency),
+			Currency:      query.Currency,
+		}, nil
+
+	case update.Message != nil && update.Message.SuccessfulPayment != nil:
+		paid := update.Message.SuccessfulPayment
+		paymentID, err := parseTelegramPayload(paid.InvoicePayload)
+		if err != nil {
+			return nil, err
+		}
+		return &WebhookEvent{
+			PaymentID:  paymentID,
+			ExternalID: paid.TelegramPaymentChargeID,
+			Status:     models.PaymentCompleted,
+			Amount:     fromMinorUnits(paid.TotalAmount, paid.Currency),
+			Currency:   paid.Currency,
+		}, nil
+	}
+
+	// Остальные обновления бота к платежам не относятся
+	return nil, nil
+}
+
+// AnswerPreCheckout отвечает на pre_checkout_query
+func (p *TelegramProvider) AnswerPreCheckout(event *WebhookEvent, ok bool, reason string) error {
+	return p.bot.AnswerPreCheckoutQuery(event.PreCheckoutID, ok, reason)
+}
+
+// parseTelegramPayload извлекает ID платежа из payload счета
+func parseTelegramPayload(payload string) (uint, error) {
+	if !strings.HasPrefix(payload, telegramPayloadPrefix) {
+		return 0, errors.New("неизвестный payload счета")
+	}
+	id, err := strconv.ParseUint(strings.TrimPrefix(payload, telegramPayloadPrefix), 10, 32)
+	if err != nil || id == 0 {
+		return 0, errors.New("неверный ID платежа в payload счета")
+	}
+	return uint(id), nil
+}
+
+// currencyExponent число знаков после запятой в валюте (в Bot API суммы передаются в минимальных единицах)
+func currencyExponent(currency string) int {
+	switch currency {
+	case TelegramStarsCurrency, "JPY", "KRW", "VND", "CLP", "ISK", "UGX":
+		return 0
+	default:
+		return 2
+	}
+}
+
+// toMinorUnits переводит сумму в минимальные единицы валюты
+func toMinorUnits(amount float64, currency string) int64 {
+	return int64(math.Round(amount * math.Pow10(currencyExponent(currency))))
+}
+
+// fromMinorUnits переводит сумму из минимальных единиц валюты
+func fromMinorUnits(amount int64, currency string) float64 {
+	return float64(amount) / math.Pow10(currencyExponent(currency))
+}
@@ -0,0 +1,169 @@
+package repositories
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"garage-barbershop/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrAppointmentOverlap возвращается, если время барбера уже занято другой записью
+var ErrAppointmentOverlap = errors.New("время пересекается с другой записью")
+
+// sqliteBarberLocks блокировки бронирования по барберам для SQLite.
+// SQLite не поддерживает блокировку строк (FOR UPDATE игнорируется),
+// поэтому бронирования одного барбера сериализуются внутри процесса.
+var sqliteBarberLocks sync.Map
+
+// barberTransaction выполняет fn в транзакции под блокировкой барбера:
+// строка барбера блокируется SELECT ... FOR UPDATE (в SQLite — мьютексом процесса)
+func barberTransaction(db *gorm.DB, barberID uint, fn func(tx *gorm.DB) error) error {
+	if db.Dialector.Name() == "sqlite" {
+		lock, _ := sqliteBarberLocks.LoadOrStore(barberID, &sync.Mutex{})
+		lock.(*sync.Mutex).Lock()
+		defer lock.(*sync.Mutex).Unlock()
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := lockBarber(tx, barberID); err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+}
+
+// lockBarber блокирует строку барбера до конца транзакции
+func lockBarber(tx *gorm.DB, barberID uint) error {
+	var barber models.User
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		First(&barber, barberID).Error
+}
+
+// AppointmentRepository интерфейс для работы с записями
+type AppointmentRepository interface {
+	Create(appointment *models.Appointment) error
+	// CreateIfAvailable создает запись, если время не занято. check получает исключения
+	// из расписания барбера на дни записи (с запасом в сутки) и может отменить бронирование ошибкой
+	CreateIfAvailable(appointment *models.Appointment, check func(exceptions []models.ScheduleException) error) error
+	GetByID(id uint) (*models.Appointment, error)
+	Update(appointment *models.Appointment) error
+	GetByClientID(clientID uint) ([]models.Appointment, error)
+	GetByBarberID(barberID uint) ([]models.Appointment, error)
+	GetOverlapping(barberID uint, start, end time.Time) ([]models.Appointment, error)
+}
+
+// appointmentRepository реализация репозитория записей
+type appointmentRepository struct {
+	db *gorm.DB
+}
+
+// NewAppointmentRepository создает новый репозиторий записей
+func NewAppointmentRepository(db *gorm.DB) AppointmentRepository {
+	return &appointmentRepository{db: db}
+}
+
+// withService подгружает услугу записи, включая удаленные из каталога
+func withService(db *gorm.DB) *gorm.DB {
+	return db.Preload("Service", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	})
+}
+
+// Create создает новую запись
+func (r *appointmentRepository) Create(appointment *models.Appointment) error {
+	return r.db.Create(appointment).Error
+}
+
+// CreateIfAvailable создает запись, если время барбера не занято.
+// Проверка и вставка выполняются в одной транзакции под блокировкой барбера,
+// поэтому параллельные бронирования одного слота не создают пересечений,
+// а исключение из расписания не появится между его проверкой и вставкой записи.
+func (r *appointmentRepository) CreateIfAvailable(appointment *models.Appointment, check func(exceptions []models.ScheduleException) error) error {
+	return barberTransaction(r.db, appointment.BarberID, func(tx *gorm.DB) error {
+		// Даты исключений заданы в часовом поясе барбера, поэтому берем период с запасом
+		from := appointment.DateTime.AddDate(0, 0, -1).Format(models.DateLayout)
+		to := appointment.EndTime().AddDate(0, 0, 1).Format(models.DateLayout)
+		exceptions, err := findExceptions(tx, appointment.BarberID, from, to)
+		if err != nil {
+			return err
+		}
+		if err := check(exceptions); err != nil {
+			return err
+		}
+
+		overlapping, err := findOverlapping(tx, appointment.BarberID, appointment.DateTime, appointment.EndTime())
+		if err != nil {
+			return err
+		}
+		if len(overlapping) > 0 {
+			return ErrAppointmentOverlap
+		}
+
+		return tx.Create(appointment).Error
+	})
+}
+
+// findOverlapping ищет активные записи барбера, пересекающиеся с интервалом [start, end)
+func findOverlapping(db *gorm.DB, barberID uint, start, end time.Time) ([]models.Appointment, error) {
+	var candidates []models.Appointment
+	err := db.Where("barber_id = ? AND status IN ?", barberID, models.BlockingAppointmentStatuses).
+		Where("date_time < ? AND date_time > ?", end, start.Add(-models.MaxAppointmentDuration)).
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	// Длительность хранится в минутах, поэтому окончание проверяем в Go
+	var overlapping []models.Appointment
+	for _, candidate := range candidates {
+		if candidate.Overlaps(start, end) {
+			overlapping = append(overlapping, candidate)
+		}
+	}
+	return overlapping, nil
+}
+
+// GetByID получает запись по ID
+func (r *appointmentRepository) GetByID(id uint) (*models.Appointment, error) {
+	var appointment models.Appointment
+	err := withService(r.db).First(&appointment, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &appointment, nil
+}
+
+// Update обновляет запись
+func (r *appointmentRepository) Update(appointment *models.Appointment) error {
+	return r.db.Omit("Client", "Barber", "Service").Save(appointment).Error
+}
+
+// GetByClientID получает записи клиента
+func (r *appointmentRepository) GetByClientID(clientID uint) ([]models.Appointment, error) {
+	var appointments []models.Appointment
+	err := withService(r.db).Preload("Barber").
+		Where("client_id = ?", clientID).
+		Order("date_time DESC").
+		Find(&appointments).Error
+	return appointments, err
+}
+
+// GetByBarberID получает записи к барберу
+func (r *appointmentRepository) GetByBarberID(barberID uint) ([]models.Appointment, error) {
+	var appointments []models.Appointment
+	err := withService(r.db).Preload("Client").
+		Where("barber_id = ?", barberID).
+		Order("date_time").
+		Find(&appointments).Error
+	return appointments, err
+}
+
+// GetOverlapping получает активные записи барбера, пересекающиеся с интервалом [start, end)
+func (r *appointmentRepository) GetOverlapping(barberID uint, start, end time.Time) ([]models.Appointment, error) {
+	return findOverlapping(r.db, barberID, start, end)
+}
@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"garage-barbershop/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// AuditRepository интерфейс для работы с журналом аудита.
+// Записи журнала только добавляются и не изменяются
+type AuditRepository interface {
+	Create(event *models.AuditEvent) error
+	List(filter models.AuditFilter) ([]models.AuditEvent, int64, error)
+}
+
+// auditRepository реализация репозитория журнала аудита
+type auditRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditRepository создает новый репозиторий журнала аудита
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+// Create добавляет запись в журнал
+func (r *auditRepository) Create(event *models.AuditEvent) error {
+	return r.db.Create(event).Error
+}
+
+// List получает страницу журнала по фильтру, новые записи первыми
+func (r *auditRepository) List(filter models.AuditFilter) ([]models.AuditEvent, int64, error) {
+	query := r.db.Model(&models.AuditEvent{})
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != 0 {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var events []models.AuditEvent
+	err := query.Order("created_at DESC, id DESC").
+		Offset(filter.Offset()).
+		Limit(filter.PageSize).
+		Find(&events).Error
+	return events, total, err
+}
@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"time"
+
+	"garage-barbershop/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// PasswordResetRepository интерфейс для работы с токенами сброса пароля
+type PasswordResetRepository interface {
+	Create(token *models.PasswordResetToken) error
+	GetByHash(tokenHash string) (*models.PasswordResetToken, error)
+	// MarkUsed помечает токен использованным, только если он еще не использован.
+	// Возвращает gorm.ErrRecordNotFound, если токен уже использовали
+	MarkUsed(id uint, now time.Time) error
+	// InvalidateUserTokens помечает использованными все неиспользованные токены пользователя
+	InvalidateUserTokens(userID uint, now time.Time) error
+}
+
+// passwordResetRepository реализация репозитория токенов сброса пароля
+type passwordResetRepository struct {
+	db *gorm.DB
+}
+
+// NewPasswordResetRepository создает новый репозиторий токенов сброса пароля
+func NewPasswordResetRepository(db *gorm.DB) PasswordResetRepository {
+	return &passwordResetRepository{db: db}
+}
+
+// Create сохраняет токен
+func (r *passwordResetRepository) Create(token *models.PasswordResetToken) error {
+	return r.db.Create(token).Error
+}
+
+// GetByHash получает токен по хешу
+func (r *passwordResetRepository) GetByHash(tokenHash string) (*models.PasswordResetToken, error) {
+	var token models.PasswordResetToken
+	if err := r.db.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// MarkUsed помечает токен использованным
+func (r *passwordResetRepository) MarkUsed(id uint, now time.Time) error {
+	result := r.db.Model(&models.PasswordResetToken{}).
+		Where("id = ? AND used_at IS NULL", id).
+		Update("used_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// InvalidateUserTokens помечает неиспользованные токены пользователя использованными
+func (r *passwordResetRepository) InvalidateUserTokens(userID uint, now time.Time) error {
+	return r.db.Model(&models.PasswordResetToken{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Update("used_at", now).Error
+}
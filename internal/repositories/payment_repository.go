@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"errors"
+	"sync"
+
+	"garage-barbershop/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrPaymentStatusChanged возвращается, если статус платежа изменился после того, как его прочитали
+var ErrPaymentStatusChanged = errors.New("статус платежа изменился")
+
+// sqlitePaymentLocks блокировки сохранения платежей по записям для SQLite, где FOR UPDATE игнорируется
+var sqlitePaymentLocks sync.Map
+
+// PaymentRepository интерфейс для работы с платежами
+type PaymentRepository interface {
+	Create(payment *models.Payment) error
+	GetByID(id uint) (*models.Payment, error)
+	GetByExternalID(method, externalID string) (*models.Payment, error)
+	GetByAppointmentID(appointmentID uint) ([]models.Payment, error)
+	// Save сохраняет платеж, только если его статус в БД все еще fromStatus, иначе возвращает
+	// ErrPaymentStatusChanged. В той же транзакции под блокировкой записи синхронизирует статус оплаты записи;
+	// проведенный платеж по уже оплаченной записи сохраняется с RefundRequired
+	Save(payment *models.Payment, fromStatus string) error
+}
+
+// paymentRepository реализация репозитория платежей
+type paymentRepository struct {
+	db *gorm.DB
+}
+
+// NewPaymentRepository создает новый репозиторий платежей
+func NewPaymentRepository(db *gorm.DB) PaymentRepository {
+	return &paymentRepository{db: db}
+}
+
+// Create создает платеж
+func (r *paymentRepository) Create(payment *models.Payment) error {
+	return r.db.Omit("Appointment").Create(payment).Error
+}
+
+// GetByID получает платеж по ID
+func (r *paymentRepository) GetByID(id uint) (*models.Payment, error) {
+	var payment models.Payment
+	if err := r.db.First(&payment, id).Error; err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// GetByExternalID получает платеж по ID в платежной системе
+func (r *paymentRepository) GetByExternalID(method, externalID string) (*models.Payment, error) {
+	var payment models.Payment
+	err := r.db.Where("payment_method = ? AND external_id = ?", method, externalID).First(&payment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+// GetByAppointmentID получает платежи по записи
+func (r *paymentRepository) GetByAppointmentID(appointmentID uint) ([]models.Payment, error) {
+	var payments []models.Payment
+	err := r.db.Where("appointment_id = ?", appointmentID).Order("created_at, id").Find(&payments).Error
+	return payments, err
+}
+
+// Save сохраняет платеж вместе со статусом оплаты записи
+func (r *paymentRepository) Save(payment *models.Payment, fromStatus string) error {
+	if r.db.Dialector.Name() == "sqlite" {
+		lock, _ := sqlitePaymentLocks.LoadOrStore(payment.AppointmentID, &sync.Mutex{})
+		lock.(*sync.Mutex).Lock()
+		defer lock.(*sync.Mutex).Unlock()
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Блокируем строку записи (PostgreSQL: SELECT ... FOR UPDATE), чтобы два платежа
+		// по одной записи не были проведены параллельно
+		var appointment models.Appointment
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			First(&appointment, payment.AppointmentID).Error
+		if err != nil {
+			return err
+		}
+
+		// Другой проведенный платеж по записи
+		var otherCompleted int64
+		err = tx.Model(&models.Payment{}).
+			Where("appointment_id = ? AND id <> ? AND status = ?", payment.AppointmentID, payment.ID, models.PaymentCompleted).
+			Count(&otherCompleted).Error
+		if err != nil {
+			return err
+		}
+		// Деньги по проведенному платежу уже списаны, поэтому он сохраняется, но помечается к возврату
+		payment.RefundRequired = payment.Status == models.PaymentCompleted && otherCompleted > 0
+
+		updated := tx.Model(&models.Payment{}).
+			Where("id = ? AND status = ?", payment.ID, fromStatus).
+			Updates(map[string]interface{}{
+				"status":          payment.Status,
+				"external_id":     payment.ExternalID,
+				"receipt_url":     payment.ReceiptURL,
+				"refund_required": payment.RefundRequired,
+			})
+		if updated.Error != nil {
+			return updated.Error
+		}
+		if updated.RowsAffected == 0 {
+			return ErrPaymentStatusChanged
+		}
+
+		// Статус оплаты записи меняют только проведенные и возвращенные платежи;
+		// пока по записи остается другой проведенный платеж, она считается оплаченной
+		if payment.Status != models.PaymentCompleted && payment.Status != models.PaymentRefunded {
+			return nil
+		}
+		if otherCompleted > 0 {
+			return nil
+		}
+		return tx.Model(&models.Appointment{}).
+			Where("id = ?", payment.AppointmentID).
+			Update("payment_status", payment.AppointmentPaymentStatus()).Error
+	})
+}
@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"garage-barbershop/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ReviewRepository интерфейс для работы с отзывами.
+// Create, Save и Delete в той же транзакции под блокировкой барбера пересчитывают его рейтинг
+type ReviewRepository interface {
+	Create(review *models.Review) error
+	GetByID(id uint) (*models.Review, error)
+	GetByAppointmentID(appointmentID uint) (*models.Review, error)
+	Save(review *models.Review) error
+	Delete(review *models.Review) error
+	List(filter models.ReviewFilter) ([]models.Review, int64, error)
+}
+
+// reviewRepository реализация репозитория отзывов
+type reviewRepository struct {
+	db *gorm.DB
+}
+
+// NewReviewRepository создает новый репозиторий отзывов
+func NewReviewRepository(db *gorm.DB) ReviewRepository {
+	return &reviewRepository{db: db}
+}
+
+// Create создает отзыв
+func (r *reviewRepository) Create(review *models.Review) error {
+	return barberTransaction(r.db, review.BarberID, func(tx *gorm.DB) error {
+		if err := tx.Omit("Client", "Barber", "Appointment").Create(review).Error; err != nil {
+			return err
+		}
+		return recalculateRating(tx, review.BarberID)
+	})
+}
+
+// GetByID получает отзыв по ID
+func (r *reviewRepository) GetByID(id uint) (*models.Review, error) {
+	var review models.Review
+	if err := r.db.First(&review, id).Error; err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// GetByAppointmentID получает отзыв по записи
+func (r *reviewRepository) GetByAppointmentID(appointmentID uint) (*models.Review, error) {
+	var review models.Review
+	if err := r.db.Where("appointment_id = ?", appointmentID).First(&review).Error; err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// Save сохраняет изменения отзыва
+func (r *reviewRepository) Save(review *models.Review) error {
+	return barberTransaction(r.db, review.BarberID, func(tx *gorm.DB) error {
+		if err := tx.Omit("Client", "Barber", "Appointment").Save(review).Error; err != nil {
+			return err
+		}
+		return recalculateRating(tx, review.BarberID)
+	})
+}
+
+// Delete удаляет отзыв окончательно, чтобы клиент мог оставить новый
+func (r *reviewRepository) Delete(review *models.Review) error {
+	return barberTransaction(r.db, review.BarberID, func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(&models.Review{}, review.ID).Error; err != nil {
+			return err
+		}
+		return recalculateRating(tx, review.BarberID)
+	})
+}
+
+// List возвращает страницу отзывов (новые первыми) и общее количество
+func (r *reviewRepository) List(filter models.ReviewFilter) ([]models.Review, int64, error) {
+	query := r.db.Model(&models.Review{})
+	if filter.BarberID != 0 {
+		query = query.Where("barber_id = ?", filter.BarberID)
+	}
+	if !filter.IncludeHidden {
+		query = query.Where("is_hidden = ?", false)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var reviews []models.Review
+	err := query.Preload("Client").
+		Order("created_at DESC, id DESC").
+		Offset(filter.Offset()).
+		Limit(filter.PageSize).
+		Find(&reviews).Error
+	return reviews, total, err
+}
+
+// recalculateRating пересчитывает рейтинг барбера по видимым отзывам.
+// Сначала блокирует строку барбера, чтобы параллельные изменения отзывов
+// не записали рейтинг по устаревшей выборке
+func recalculateRating(tx *gorm.DB, barberID uint) error {
+	if err := lockBarber(tx, barberID); err != nil {
+		return err
+	}
+
+	var stats struct {
+		Count int64
+		Sum   int64
+	}
+	err := tx.Model(&models.Review{}).
+		Select("COUNT(*) AS count, COALESCE(SUM(rating), 0) AS sum").
+		Where("barber_id = ? AND is_hidden = ?", barberID, false).
+		Scan(&stats).Error
+	if err != nil {
+		return err
+	}
+
+	return tx.Model(&models.User{}).
+		Where("id = ?", barberID).
+		Update("rating", models.BayesianRating(stats.Count, stats.Sum)).Error
+}
@@ -1,13 +1,22 @@
 package repositories
 
 import (
+	"errors"
+	"sync"
 	"time"
 
 	"garage-barbershop/internal/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// ErrLastRoleHolder возвращается при попытке снять роль с ее последнего активного пользователя
+var ErrLastRoleHolder = errors.New("роль есть только у этого пользователя")
+
+// sqliteRoleLocks блокировки снятия ролей для SQLite, где FOR UPDATE игнорируется
+var sqliteRoleLocks sync.Map
+
 // RoleRepository интерфейс для работы с ролями
 type RoleRepository interface {
 	// Управление ролями
@@ -20,11 +29,17 @@ type RoleRepository interface {
 
 	// Управление связями пользователь-роль
 	AssignRoleToUser(userID, roleID uint, assignedBy uint) error
-	RemoveRoleFromUser(userID, roleID uint) error
+	// RemoveRoleFromUser удаляет связь; при keepLast роль не снимается с последнего активного пользователя
+	RemoveRoleFromUser(userID, roleID uint, keepLast bool) error
 	GetUserRoles(userID uint) ([]models.Role, error)
 	GetUsersWithRole(roleID uint) ([]models.User, error)
 	GetUserRole(userID, roleID uint) (*models.UserRole, error)
 	HasUserRole(userID uint, roleName string) bool
+	// AssignRole сохраняет подготовленную связь пользователь-роль (с причиной назначения)
+	AssignRole(userRole *models.UserRole) error
+	// RevokeRole снимает роль, сохраняя кто, когда и почему ее снял;
+	// при keepLast роль не снимается с последнего активного пользователя
+	RevokeRole(userID, roleID, revokedBy uint, reason string, keepLast bool) error
 
 	// Получение пользователей с ролями
 	GetUserWithRoles(userID uint) (*models.UserWithRoles, error)
@@ -96,8 +111,72 @@ func (r *roleRepository) AssignRoleToUser(userID, roleID uint, assignedBy uint)
 }
 
 // RemoveRoleFromUser снимает роль с пользователя
-func (r *roleRepository) RemoveRoleFromUser(userID, roleID uint) error {
-	return r.db.Where("user_id = ? AND role_id = ?", userID, roleID).Delete(&models.UserRole{}).Error
+func (r *roleRepository) RemoveRoleFromUser(userID, roleID uint, keepLast bool) error {
+	return r.withRoleHolders(userID, roleID, keepLast, func(tx *gorm.DB) error {
+		return tx.Where("user_id = ? AND role_id = ?", userID, roleID).Delete(&models.UserRole{}).Error
+	})
+}
+
+// AssignRole сохраняет связь пользователь-роль
+func (r *roleRepository) AssignRole(userRole *models.UserRole) error {
+	if userRole.AssignedAt.IsZero() {
+		userRole.AssignedAt = time.Now()
+	}
+	userRole.IsActive = 1
+	return r.db.Omit("User", "Role").Create(userRole).Error
+}
+
+// RevokeRole помечает активную связь снятой и удаляет ее (мягко, история остается в БД)
+func (r *roleRepository) RevokeRole(userID, roleID, revokedBy uint, reason string, keepLast bool) error {
+	return r.withRoleHolders(userID, roleID, keepLast, func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.UserRole{}).
+			Where("user_id = ? AND role_id = ? AND is_active = ?", userID, roleID, 1).
+			Updates(map[string]interface{}{
+				"is_active":      0,
+				"revoked_by":     revokedBy,
+				"revoked_at":     now,
+				"revoked_reason": reason,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.Where("user_id = ? AND role_id = ? AND is_active = ?", userID, roleID, 0).
+			Delete(&models.UserRole{}).Error
+	})
+}
+
+// withRoleHolders выполняет снятие роли в транзакции. При keepLast активные связи роли
+// блокируются (PostgreSQL: SELECT ... FOR UPDATE) до конца транзакции, поэтому параллельные
+// снятия не оставят роль без пользователей
+func (r *roleRepository) withRoleHolders(userID, roleID uint, keepLast bool, remove func(tx *gorm.DB) error) error {
+	if keepLast && r.db.Dialector.Name() == "sqlite" {
+		lock, _ := sqliteRoleLocks.LoadOrStore(roleID, &sync.Mutex{})
+		lock.(*sync.Mutex).Lock()
+		defer lock.(*sync.Mutex).Unlock()
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if keepLast {
+			var holders []uint
+			err := tx.Model(&models.UserRole{}).
+				Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "user_roles"}}).
+				Joins("JOIN users ON users.id = user_roles.user_id AND users.deleted_at IS NULL").
+				Where("user_roles.role_id = ? AND user_roles.is_active = ? AND users.is_active = ?", roleID, 1, true).
+				Pluck("user_roles.user_id", &holders).Error
+			if err != nil {
+				return err
+			}
+			if len(holders) == 1 && holders[0] == userID {
+				return ErrLastRoleHolder
+			}
+		}
+		return remove(tx)
+	})
 }
 
 // GetUserRoles получает роли пользователя
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"time"
+
+	"garage-barbershop/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ScheduleExceptionRepository интерфейс для работы с исключениями из расписания
+type ScheduleExceptionRepository interface {
+	// Create создает исключение под блокировкой барбера. check получает активные записи
+	// и исключения барбера за период исключения (записи — с запасом в сутки по краям,
+	// так как даты исключения заданы в часовом поясе барбера) и может отменить создание ошибкой
+	Create(exception *models.ScheduleException, check func(appointments []models.Appointment, exceptions []models.ScheduleException) error) error
+	GetByID(id uint) (*models.ScheduleException, error)
+	Delete(id uint) error
+	// GetByBarberInRange возвращает исключения, пересекающиеся с периодом дат from..to (включительно)
+	GetByBarberInRange(barberID uint, from, to string) ([]models.ScheduleException, error)
+}
+
+// scheduleExceptionRepository реализация репозитория исключений из расписания
+type scheduleExceptionRepository struct {
+	db *gorm.DB
+}
+
+// NewScheduleExceptionRepository создает новый репозиторий исключений из расписания
+func NewScheduleExceptionRepository(db *gorm.DB) ScheduleExceptionRepository {
+	return &scheduleExceptionRepository{db: db}
+}
+
+// Create создает исключение, если check не вернул ошибку.
+// Бронирования барбера берут ту же блокировку, поэтому новая запись не может
+// появиться между проверкой и сохранением исключения
+func (r *scheduleExceptionRepository) Create(exception *models.ScheduleException, check func(appointments []models.Appointment, exceptions []models.ScheduleException) error) error {
+	return barberTransaction(r.db, exception.BarberID, func(tx *gorm.DB) error {
+		firstDay, err := time.Parse(models.DateLayout, exception.StartDate)
+		if err != nil {
+			return err
+		}
+		lastDay, err := time.Parse(models.DateLayout, exception.EndDate)
+		if err != nil {
+			return err
+		}
+
+		appointments, err := findOverlapping(tx, exception.BarberID, firstDay.AddDate(0, 0, -1), lastDay.AddDate(0, 0, 2))
+		if err != nil {
+			return err
+		}
+		exceptions, err := findExceptions(tx, exception.BarberID, exception.StartDate, exception.EndDate)
+		if err != nil {
+			return err
+		}
+		if err := check(appointments, exceptions); err != nil {
+			return err
+		}
+
+		return tx.Omit("Barber").Create(exception).Error
+	})
+}
+
+// GetByID получает исключение по ID
+func (r *scheduleExceptionRepository) GetByID(id uint) (*models.ScheduleException, error) {
+	var exception models.ScheduleException
+	if err := r.db.First(&exception, id).Error; err != nil {
+		return nil, err
+	}
+	return &exception, nil
+}
+
+// Delete удаляет исключение (soft delete)
+func (r *scheduleExceptionRepository) Delete(id uint) error {
+	return r.db.Delete(&models.ScheduleException{}, id).Error
+}
+
+// GetByBarberInRange получает исключения барбера, пересекающиеся с периодом
+func (r *scheduleExceptionRepository) GetByBarberInRange(barberID uint, from, to string) ([]models.ScheduleException, error) {
+	return findExceptions(r.db, barberID, from, to)
+}
+
+// findExceptions ищет исключения барбера, пересекающиеся с периодом дат from..to
+func findExceptions(db *gorm.DB, barberID uint, from, to string) ([]models.ScheduleException, error) {
+	var exceptions []models.ScheduleException
+	err := db.Where("barber_id = ? AND start_date <= ? AND end_date >= ?", barberID, to, from).
+		Order("start_date, id").
+		Find(&exceptions).Error
+	return exceptions, err
+}
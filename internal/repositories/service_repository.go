@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"garage-barbershop/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ServiceRepository интерфейс для работы с услугами барберов
+type ServiceRepository interface {
+	Create(service *models.Service) error
+	GetByID(id uint) (*models.Service, error)
+	GetByIDUnscoped(id uint) (*models.Service, error)
+	Update(service *models.Service) error
+	Delete(id uint) error
+	GetByBarberID(barberID uint) ([]models.Service, error)
+	List(filter models.ServiceFilter) ([]models.Service, error)
+}
+
+// serviceRepository реализация репозитория услуг
+type serviceRepository struct {
+	db *gorm.DB
+}
+
+// NewServiceRepository создает новый репозиторий услуг
+func NewServiceRepository(db *gorm.DB) ServiceRepository {
+	return &serviceRepository{db: db}
+}
+
+// Create создает новую услугу
+func (r *serviceRepository) Create(service *models.Service) error {
+	return r.db.Create(service).Error
+}
+
+// GetByID получает услугу по ID (без удаленных)
+func (r *serviceRepository) GetByID(id uint) (*models.Service, error) {
+	var service models.Service
+	err := r.db.First(&service, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &service, nil
+}
+
+// GetByIDUnscoped получает услугу по ID, включая удаленные.
+// Нужен для истории записей: услуга могла быть удалена после бронирования.
+func (r *serviceRepository) GetByIDUnscoped(id uint) (*models.Service, error) {
+	var service models.Service
+	err := r.db.Unscoped().First(&service, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &service, nil
+}
+
+// Update обновляет услугу
+func (r *serviceRepository) Update(service *models.Service) error {
+	return r.db.Save(service).Error
+}
+
+// Delete удаляет услугу (soft delete)
+func (r *serviceRepository) Delete(id uint) error {
+	return r.db.Delete(&models.Service{}, id).Error
+}
+
+// GetByBarberID получает все услуги барбера, включая неактивные
+func (r *serviceRepository) GetByBarberID(barberID uint) ([]models.Service, error) {
+	var services []models.Service
+	err := r.db.Where("barber_id = ?", barberID).Order("id").Find(&services).Error
+	return services, err
+}
+
+// List получает услуги по фильтру
+func (r *serviceRepository) List(filter models.ServiceFilter) ([]models.Service, error) {
+	query := r.db.Model(&models.Service{}).Preload("Barber")
+
+	if !filter.IncludeInactive {
+		query = query.Where("is_active = ?", true)
+	}
+	if filter.BarberID != 0 {
+		query = query.Where("barber_id = ?", filter.BarberID)
+	}
+	if filter.MinPrice != nil {
+		query = query.Where("price >= ?", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		query = query.Where("price <= ?", *filter.MaxPrice)
+	}
+	if filter.MinDuration != nil {
+		query = query.Where("duration >= ?", *filter.MinDuration)
+	}
+	if filter.MaxDuration != nil {
+		query = query.Where("duration <= ?", *filter.MaxDuration)
+	}
+
+	var services []models.Service
+	err := query.Order("price, id").Find(&services).Error
+	return services, err
+}
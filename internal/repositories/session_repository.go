@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"time"
+
+	"garage-barbershop/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// SessionRepository интерфейс для работы с сессиями пользователей
+type SessionRepository interface {
+	Create(session *models.Session) error
+	GetByID(id uint) (*models.Session, error)
+	GetByJTI(jti string) (*models.Session, error)
+	ListActive(userID uint, now time.Time) ([]models.Session, error)
+	// Rotate заменяет JTI сессии, только если он не изменился с момента чтения.
+	// Возвращает gorm.ErrRecordNotFound, если сессию уже обновили или отозвали
+	Rotate(session *models.Session, oldJTI string) error
+	Revoke(id uint, reason string, now time.Time) error
+	// RevokeAll отзывает все активные сессии пользователя, кроме exceptID (0 - без исключений)
+	RevokeAll(userID, exceptID uint, reason string, now time.Time) (int64, error)
+}
+
+// sessionRepository реализация репозитория сессий
+type sessionRepository struct {
+	db *gorm.DB
+}
+
+// NewSessionRepository создает новый репозиторий сессий
+func NewSessionRepository(db *gorm.DB) SessionRepository {
+	return &sessionRepository{db: db}
+}
+
+// Create создает сессию
+func (r *sessionRepository) Create(session *models.Session) error {
+	return r.db.Create(session).Error
+}
+
+// GetByID получает сессию по ID
+func (r *sessionRepository) GetByID(id uint) (*models.Session, error) {
+	var session models.Session
+	if err := r.db.First(&session, id).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GetByJTI получает сессию по JTI refresh token
+func (r *sessionRepository) GetByJTI(jti string) (*models.Session, error) {
+	var session models.Session
+	if err := r.db.Where("jti = ?", jti).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ListActive получает действующие сессии пользователя, недавно использованные первыми
+func (r *sessionRepository) ListActive(userID uint, now time.Time) ([]models.Session, error) {
+	var sessions []models.Session
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, now).
+		Order("last_used_at DESC, id DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// Rotate сохраняет новый JTI и данные устройства сессии
+func (r *sessionRepository) Rotate(session *models.Session, oldJTI string) error {
+	result := r.db.Model(&models.Session{}).
+		Where("id = ? AND jti = ? AND revoked_at IS NULL", session.ID, oldJTI).
+		Updates(map[string]interface{}{
+			"jti":          session.JTI,
+			"ip":           session.IP,
+			"user_agent":   session.UserAgent,
+			"last_used_at": session.LastUsedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Revoke отзывает сессию
+func (r *sessionRepository) Revoke(id uint, reason string, now time.Time) error {
+	return r.db.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{"revoked_at": now, "revoked_reason": reason}).Error
+}
+
+// RevokeAll отзывает активные сессии пользователя
+func (r *sessionRepository) RevokeAll(userID, exceptID uint, reason string, now time.Time) (int64, error) {
+	result := r.db.Model(&models.Session{}).
+		Where("user_id = ? AND id <> ? AND revoked_at IS NULL", userID, exceptID).
+		Updates(map[string]interface{}{"revoked_at": now, "revoked_reason": reason})
+	return result.RowsAffected, result.Error
+}
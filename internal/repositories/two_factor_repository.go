@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"time"
+
+	"garage-barbershop/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// TwoFactorRepository интерфейс для работы с кодами восстановления и шагами TOTP
+type TwoFactorRepository interface {
+	// ReplaceRecoveryCodes удаляет прежние коды восстановления пользователя и сохраняет новые
+	ReplaceRecoveryCodes(userID uint, codeHashes []string) error
+	// UseRecoveryCode помечает код использованным, только если он еще не использован.
+	// Возвращает gorm.ErrRecordNotFound, если кода нет или его уже использовали
+	UseRecoveryCode(userID uint, codeHash string, now time.Time) error
+	CountRecoveryCodes(userID uint) (int64, error)
+	DeleteRecoveryCodes(userID uint) error
+	// AdvanceTOTPStep запоминает шаг принятого кода, только если он больше предыдущего.
+	// Возвращает gorm.ErrRecordNotFound, если код этого шага уже принимали
+	AdvanceTOTPStep(userID uint, step int64) error
+	// UseMFAChallenge запоминает время выдачи использованного токена второго шага, только если оно больше предыдущего.
+	// Возвращает gorm.ErrRecordNotFound, если этот или более поздний токен уже использовали
+	UseMFAChallenge(userID uint, issuedAt int64) error
+}
+
+// twoFactorRepository реализация репозитория двухфакторной аутентификации
+type twoFactorRepository struct {
+	db *gorm.DB
+}
+
+// NewTwoFactorRepository создает новый репозиторий двухфакторной аутентификации
+func NewTwoFactorRepository(db *gorm.DB) TwoFactorRepository {
+	return &twoFactorRepository{db: db}
+}
+
+// ReplaceRecoveryCodes заменяет коды восстановления в одной транзакции
+func (r *twoFactorRepository) ReplaceRecoveryCodes(userID uint, codeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		codes := make([]models.RecoveryCode, len(codeHashes))
+		for i, hash := range codeHashes {
+			codes[i] = models.RecoveryCode{UserID: userID, CodeHash: hash}
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// UseRecoveryCode помечает код использованным
+func (r *twoFactorRepository) UseRecoveryCode(userID uint, codeHash string, now time.Time) error {
+	result := r.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// CountRecoveryCodes возвращает число неиспользованных кодов восстановления
+func (r *twoFactorRepository) CountRecoveryCodes(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// DeleteRecoveryCodes удаляет все коды восстановления пользователя
+func (r *twoFactorRepository) DeleteRecoveryCodes(userID uint) error {
+	return r.db.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+}
+
+// AdvanceTOTPStep запоминает шаг принятого кода
+func (r *twoFactorRepository) AdvanceTOTPStep(userID uint, step int64) error {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND totp_last_step < ?", userID, step).
+		Update("totp_last_step", step)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// UseMFAChallenge запоминает время выдачи использованного токена второго шага
+func (r *twoFactorRepository) UseMFAChallenge(userID uint, issuedAt int64) error {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND mfa_challenge_used_at < ?", userID, issuedAt).
+		Update("mfa_challenge_used_at", issuedAt)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
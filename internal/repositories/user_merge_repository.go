@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"garage-barbershop/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// Ошибки объединения аккаунтов: эти данные дубликата нельзя перенести без конфликта с данными primary
+var (
+	ErrMergeBarberData = errors.New("у дубликата есть услуги или расписание барбера")
+	ErrMergeTwoFactor  = errors.New("у дубликата включена двухфакторная аутентификация")
+)
+
+// UserMergeRepository интерфейс объединения аккаунтов одного человека
+type UserMergeRepository interface {
+	// Merge сохраняет primary с перенесенными учетными данными, переносит на него записи,
+	// отзывы и роли дубликата и удаляет дубликат. Все изменения выполняются в одной транзакции.
+	// Возвращает ErrMergeBarberData или ErrMergeTwoFactor, если у дубликата есть действующие услуги,
+	// рабочие часы, исключения расписания или 2FA
+	Merge(primary *models.User, duplicateID uint, now time.Time) (*models.UserMergeResult, error)
+}
+
+// userMergeRepository реализация репозитория объединения аккаунтов
+type userMergeRepository struct {
+	db *gorm.DB
+}
+
+// NewUserMergeRepository создает новый репозиторий объединения аккаунтов
+func NewUserMergeRepository(db *gorm.DB) UserMergeRepository {
+	return &userMergeRepository{db: db}
+}
+
+// Merge объединяет аккаунты в одной транзакции
+func (r *userMergeRepository) Merge(primary *models.User, duplicateID uint, now time.Time) (*models.UserMergeResult, error) {
+	result := &models.UserMergeResult{}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := ensureMergeable(tx, duplicateID); err != nil {
+			return err
+		}
+
+		// Сначала освобождаем Telegram ID и email дубликата, иначе primary нельзя сохранить
+		// из-за уникальных индексов
+		if err := tx.Model(&models.User{}).Where("id = ?", duplicateID).Updates(map[string]interface{}{
+			"telegram_id":   0,
+			"email":         "",
+			"password_hash": "",
+			"is_active":     false,
+		}).Error; err != nil {
+			return err
+		}
+		if err := tx.Omit("Roles").Save(primary).Error; err != nil {
+			return err
+		}
+
+		for _, column := range []string{"client_id", "barber_id"} {
+			moved := tx.Model(&models.Appointment{}).Where(column+" = ?", duplicateID).Update(column, primary.ID)
+			if moved.Error != nil {
+				return moved.Error
+			}
+			result.Appointments += moved.RowsAffected
+
+			moved = tx.Model(&models.Review{}).Where(column+" = ?", duplicateID).Update(column, primary.ID)
+			if moved.Error != nil {
+				return moved.Error
+			}
+			result.Reviews += moved.RowsAffected
+
+			// Отзывы о дубликате как о барбере теперь учитываются в рейтинге primary
+			if column == "barber_id" && moved.RowsAffected > 0 {
+				if err := recalculateRating(tx, primary.ID); err != nil {
+					return err
+				}
+				if err := tx.Model(&models.User{}).Where("id = ?", primary.ID).Pluck("rating", &primary.Rating).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		// Удаленные услуги дубликата нужны для истории перенесенных записей
+		if err := tx.Unscoped().Model(&models.Service{}).
+			Where("barber_id = ? AND deleted_at IS NOT NULL", duplicateID).
+			Update("barber_id", primary.ID).Error; err != nil {
+			return err
+		}
+
+		// Переносим только действующие роли, которых у primary нет; история ролей остается у дубликата
+		moved := tx.Model(&models.UserRole{}).
+			Where("user_id = ? AND is_active = 1", duplicateID).
+			Where("role_id NOT IN (?)", tx.Model(&models.UserRole{}).Select("role_id").Where("user_id = ? AND is_active = 1", primary.ID)).
+			Update("user_id", primary.ID)
+		if moved.Error != nil {
+			return moved.Error
+		}
+		result.Roles = moved.RowsAffected
+
+		if err := tx.Model(&models.Session{}).
+			Where("user_id = ? AND revoked_at IS NULL", duplicateID).
+			Updates(map[string]interface{}{"revoked_at": now, "revoked_reason": models.SessionRevokedMerged}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.User{}, duplicateID).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.User = *primary
+	return result, nil
+}
+
+// ensureMergeable проверяет, что у дубликата нет данных, которые нельзя перенести без конфликта:
+// расписание и услуги барбера пересекались бы с данными primary, а секрет 2FA привязан к приложению дубликата
+func ensureMergeable(tx *gorm.DB, duplicateID uint) error {
+	var count int64
+	if err := tx.Model(&models.User{}).Where("id = ? AND totp_enabled_at IS NOT NULL", duplicateID).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrMergeTwoFactor
+	}
+
+	for _, model := range []interface{}{&models.Service{}, &models.WorkingHours{}, &models.ScheduleException{}} {
+		if err := tx.Model(model).Where("barber_id = ?", duplicateID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrMergeBarberData
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"garage-barbershop/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WorkingHoursRepository интерфейс для работы с рабочими часами барберов
+type WorkingHoursRepository interface {
+	GetByBarberID(barberID uint) ([]models.WorkingHours, error)
+	GetActiveByBarberID(barberID uint) ([]models.WorkingHours, error)
+	GetByBarberAndDay(barberID uint, dayOfWeek int) (*models.WorkingHours, error)
+	SaveDay(workingHours *models.WorkingHours) error
+	DeleteDay(barberID uint, dayOfWeek int) error
+	ReplaceWeek(barberID uint, week []models.WorkingHours) error
+}
+
+// workingHoursRepository реализация репозитория рабочих часов
+type workingHoursRepository struct {
+	db *gorm.DB
+}
+
+// NewWorkingHoursRepository создает новый репозиторий рабочих часов
+func NewWorkingHoursRepository(db *gorm.DB) WorkingHoursRepository {
+	return &workingHoursRepository{db: db}
+}
+
+// GetByBarberID получает недельное расписание барбера
+func (r *workingHoursRepository) GetByBarberID(barberID uint) ([]models.WorkingHours, error) {
+	var workingHours []models.WorkingHours
+	err := r.db.Where("barber_id = ?", barberID).Order("day_of_week").Find(&workingHours).Error
+	return workingHours, err
+}
+
+// GetActiveByBarberID получает только рабочие дни барбера
+func (r *workingHoursRepository) GetActiveByBarberID(barberID uint) ([]models.WorkingHours, error) {
+	var workingHours []models.WorkingHours
+	err := r.db.Where("barber_id = ? AND is_active = ?", barberID, true).Order("day_of_week").Find(&workingHours).Error
+	return workingHours, err
+}
+
+// GetByBarberAndDay получает расписание барбера на конкретный день недели
+func (r *workingHoursRepository) GetByBarberAndDay(barberID uint, dayOfWeek int) (*models.WorkingHours, error) {
+	var workingHours models.WorkingHours
+	err := r.db.Where("barber_id = ? AND day_of_week = ?", barberID, dayOfWeek).First(&workingHours).Error
+	if err != nil {
+		return nil, err
+	}
+	return &workingHours, nil
+}
+
+// SaveDay создает или обновляет расписание барбера на день недели одним upsert
+// по уникальному индексу (barber_id, day_of_week), поэтому параллельные сохранения не создают дублей
+func (r *workingHoursRepository) SaveDay(workingHours *models.WorkingHours) error {
+	err := r.db.Omit("Barber").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "barber_id"}, {Name: "day_of_week"}},
+		DoUpdates: clause.AssignmentColumns([]string{"updated_at", "start_time", "end_time", "break_start", "break_end", "is_active"}),
+	}).Create(workingHours).Error
+	if err != nil {
+		return err
+	}
+
+	// При обновлении ID и дата создания остаются от существующей строки
+	return r.db.Where("barber_id = ? AND day_of_week = ?", workingHours.BarberID, workingHours.DayOfWeek).First(workingHours).Error
+}
+
+// DeleteDay удаляет расписание барбера на день недели
+func (r *workingHoursRepository) DeleteDay(barberID uint, dayOfWeek int) error {
+	result := r.db.Unscoped().Where("barber_id = ? AND day_of_week = ?", barberID, dayOfWeek).Delete(&models.WorkingHours{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ReplaceWeek атомарно заменяет все недельное расписание барбера
+func (r *workingHoursRepository) ReplaceWeek(barberID uint, week []models.WorkingHours) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("barber_id = ?", barberID).Delete(&models.WorkingHours{}).Error; err != nil {
+			return err
+		}
+		if len(week) == 0 {
+			return nil
+		}
+		for i := range week {
+			week[i].BarberID = barberID
+		}
+		return tx.Omit("Barber").Create(&week).Error
+	})
+}
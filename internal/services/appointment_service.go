@@ -0,0 +1,261 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/repositories"
+)
+
+var (
+	// ErrAppointmentNotFound возвращается, если запись не найдена или недоступна пользователю
+	ErrAppointmentNotFound = errors.New("запись не найдена")
+	// ErrInvalidStatusTransition возвращается при недопустимой смене статуса записи
+	ErrInvalidStatusTransition = errors.New("недопустимая смена статуса записи")
+	// ErrSlotUnavailable возвращается, если выбранное время уже занято
+	ErrSlotUnavailable = errors.New("выбранное время уже занято")
+	// ErrOutsideWorkingHours возвращается, если запись не помещается в рабочее время барбера
+	ErrOutsideWorkingHours = errors.New("барбер не работает в выбранное время")
+)
+
+// AppointmentService интерфейс для управления записями
+type AppointmentService interface {
+	// Действия клиента
+	BookAppointment(clientID uint, req models.AppointmentCreateRequest) (*models.Appointment, error)
+	GetClientAppointments(clientID uint) ([]models.Appointment, error)
+	CancelByClient(clientID, appointmentID uint) (*models.Appointment, error)
+
+	// Действия барбера
+	GetBarberAppointments(barberID uint) ([]models.Appointment, error)
+	ConfirmAppointment(barberID, appointmentID uint) (*models.Appointment, error)
+	CompleteAppointment(barberID, appointmentID uint) (*models.Appointment, error)
+	MarkNoShow(barberID, appointmentID uint) (*models.Appointment, error)
+}
+
+// appointmentService реализация AppointmentService
+type appointmentService struct {
+	appointmentRepo  repositories.AppointmentRepository
+	serviceRepo      repositories.ServiceRepository
+	userRepo         repositories.UserRepository
+	workingHoursRepo repositories.WorkingHoursRepository
+	exceptionRepo    repositories.ScheduleExceptionRepository
+	timezoneService  TimezoneService
+}
+
+// NewAppointmentService создает новый экземпляр AppointmentService
+func NewAppointmentService(appointmentRepo repositories.AppointmentRepository, serviceRepo repositories.ServiceRepository, userRepo repositories.UserRepository, workingHoursRepo repositories.WorkingHoursRepository, exceptionRepo repositories.ScheduleExceptionRepository, timezoneService TimezoneService) AppointmentService {
+	return &appointmentService{
+		appointmentRepo:  appointmentRepo,
+		serviceRepo:      serviceRepo,
+		userRepo:         userRepo,
+		workingHoursRepo: workingHoursRepo,
+		exceptionRepo:    exceptionRepo,
+		timezoneService:  timezoneService,
+	}
+}
+
+// BookAppointment создает запись клиента на услугу
+func (s *appointmentService) BookAppointment(clientID uint, req models.AppointmentCreateRequest) (*models.Appointment, error) {
+	if req.DateTime.IsZero() == (req.LocalTime == "") {
+		return nil, fmt.Errorf("укажите время записи: datetime или local_datetime")
+	}
+	if !req.DateTime.IsZero() && !req.DateTime.After(time.Now()) {
+		return nil, fmt.Errorf("нельзя записаться на прошедшее время")
+	}
+
+	// Получаем услугу из каталога
+	service, err := s.serviceRepo.GetByID(req.ServiceID)
+	if err != nil || !service.IsActive {
+		return nil, ErrServiceNotFound
+	}
+	if req.BarberID != 0 && req.BarberID != service.BarberID {
+		return nil, fmt.Errorf("услуга не принадлежит выбранному барберу")
+	}
+
+	// Проверяем, что барбер активен
+	barber, err := s.userRepo.GetByID(service.BarberID)
+	if err != nil {
+		return nil, fmt.Errorf("барбер не найден: %v", err)
+	}
+	if !barber.IsActive {
+		return nil, fmt.Errorf("барбер не принимает записи")
+	}
+
+	if service.BarberID == clientID {
+		return nil, fmt.Errorf("нельзя записаться к самому себе")
+	}
+
+	// Время без смещения понимается в часовом поясе барбера
+	loc := s.timezoneService.UserLocation(barber)
+	dateTime := req.DateTime
+	if req.LocalTime != "" {
+		if dateTime, err = models.ParseLocalDateTime(req.LocalTime, loc); err != nil {
+			return nil, err
+		}
+		if !dateTime.After(time.Now()) {
+			return nil, fmt.Errorf("нельзя записаться на прошедшее время")
+		}
+	}
+
+	// Цена и длительность фиксируются на момент бронирования
+	appointment := &models.Appointment{
+		DateTime:      dateTime.UTC(),
+		Duration:      service.Duration,
+		Status:        models.AppointmentStatusPending,
+		ClientID:      clientID,
+		BarberID:      service.BarberID,
+		ServiceID:     service.ID,
+		Notes:         req.Notes,
+		Price:         service.Price,
+		PaymentStatus: models.PaymentStatusPending,
+	}
+
+	// Время должно быть рабочим, а барбер - не в отпуске и не на больничном
+	check, err := s.checkSchedule(appointment, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Исключения из расписания проверяются повторно под блокировкой барбера
+	if err := s.appointmentRepo.CreateIfAvailable(appointment, check); err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrAppointmentOverlap):
+			return nil, ErrSlotUnavailable
+		case errors.Is(err, ErrSlotUnavailable), errors.Is(err, ErrOutsideWorkingHours):
+			return nil, err
+		}
+		return nil, fmt.Errorf("ошибка создания записи: %v", err)
+	}
+
+	appointment.Service = *service
+	appointment.Localize(loc)
+	return appointment, nil
+}
+
+// GetClientAppointments получает записи клиента
+func (s *appointmentService) GetClientAppointments(clientID uint) ([]models.Appointment, error) {
+	appointments, err := s.appointmentRepo.GetByClientID(clientID)
+	if err != nil {
+		return nil, err
+	}
+	s.localize(appointments)
+	return appointments, nil
+}
+
+// CancelByClient отменяет запись клиентом
+func (s *appointmentService) CancelByClient(clientID, appointmentID uint) (*models.Appointment, error) {
+	appointment, err := s.appointmentRepo.GetByID(appointmentID)
+	if err != nil || appointment.ClientID != clientID {
+		return nil, ErrAppointmentNotFound
+	}
+
+	return s.transition(appointment, models.AppointmentStatusCancelled)
+}
+
+// GetBarberAppointments получает записи к барберу
+func (s *appointmentService) GetBarberAppointments(barberID uint) ([]models.Appointment, error) {
+	appointments, err := s.appointmentRepo.GetByBarberID(barberID)
+	if err != nil {
+		return nil, err
+	}
+	s.localize(appointments)
+	return appointments, nil
+}
+
+// ConfirmAppointment подтверждает запись барбером
+func (s *appointmentService) ConfirmAppointment(barberID, appointmentID uint) (*models.Appointment, error) {
+	return s.barberTransition(barberID, appointmentID, models.AppointmentStatusConfirmed)
+}
+
+// CompleteAppointment отмечает запись выполненной
+func (s *appointmentService) CompleteAppointment(barberID, appointmentID uint) (*models.Appointment, error) {
+	return s.barberTransition(barberID, appointmentID, models.AppointmentStatusCompleted)
+}
+
+// MarkNoShow отмечает, что клиент не пришел
+func (s *appointmentService) MarkNoShow(barberID, appointmentID uint) (*models.Appointment, error) {
+	return s.barberTransition(barberID, appointmentID, models.AppointmentStatusNoShow)
+}
+
+// barberTransition меняет статус записи барбером с проверкой принадлежности
+func (s *appointmentService) barberTransition(barberID, appointmentID uint, status string) (*models.Appointment, error) {
+	appointment, err := s.appointmentRepo.GetByID(appointmentID)
+	if err != nil || appointment.BarberID != barberID {
+		return nil, ErrAppointmentNotFound
+	}
+
+	// Завершить или отметить неявку можно только после начала записи
+	if status != models.AppointmentStatusConfirmed && time.Now().Before(appointment.DateTime) {
+		return nil, fmt.Errorf("%w: запись еще не началась", ErrInvalidStatusTransition)
+	}
+
+	return s.transition(appointment, status)
+}
+
+// transition проверяет и применяет смену статуса
+func (s *appointmentService) transition(appointment *models.Appointment, status string) (*models.Appointment, error) {
+	if !appointment.CanTransitionTo(status) {
+		return nil, fmt.Errorf("%w: %s → %s", ErrInvalidStatusTransition, appointment.Status, status)
+	}
+
+	appointment.Status = status
+	if err := s.appointmentRepo.Update(appointment); err != nil {
+		return nil, fmt.Errorf("ошибка обновления записи: %v", err)
+	}
+
+	appointment.Localize(s.timezoneService.BarberLocation(appointment.BarberID))
+	return appointment, nil
+}
+
+// localize заполняет время записей в часовых поясах их барберов
+func (s *appointmentService) localize(appointments []models.Appointment) {
+	locations := make(map[uint]*time.Location)
+	for i := range appointments {
+		loc, ok := locations[appointments[i].BarberID]
+		if !ok {
+			loc = s.timezoneService.BarberLocation(appointments[i].BarberID)
+			locations[appointments[i].BarberID] = loc
+		}
+		appointments[i].Localize(loc)
+	}
+}
+
+// checkSchedule проверяет запись по тем же правилам, по которым рассчитываются свободные слоты:
+// запись помещается в рабочие часы дня без перерыва и не попадает на нерабочее время из исключений.
+// Возвращает и саму проверку, чтобы повторить ее с исключениями, прочитанными под блокировкой барбера
+func (s *appointmentService) checkSchedule(appointment *models.Appointment, loc *time.Location) (func(exceptions []models.ScheduleException) error, error) {
+	start := appointment.DateTime.In(loc)
+	end := appointment.EndTime().In(loc)
+
+	exceptions, err := s.exceptionRepo.GetByBarberInRange(appointment.BarberID, start.Format(models.DateLayout), end.Format(models.DateLayout))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка проверки расписания: %v", err)
+	}
+	workingHours, err := s.workingHoursRepo.GetActiveByBarberID(appointment.BarberID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка проверки рабочих часов: %v", err)
+	}
+
+	check := func(exceptions []models.ScheduleException) error {
+		day := startOfDay(start)
+		wh, ok := dayWorkingHours(workingHours, exceptions, day)
+		if !ok {
+			return ErrOutsideWorkingHours
+		}
+		work, err := newWorkingDay(day, wh)
+		if err != nil {
+			return fmt.Errorf("ошибка проверки рабочих часов: %v", err)
+		}
+		if !work.fits(start, end) {
+			return ErrOutsideWorkingHours
+		}
+
+		if timeOffOverlaps(exceptions, start, end) {
+			return ErrSlotUnavailable
+		}
+		return nil
+	}
+	return check, check(exceptions)
+}
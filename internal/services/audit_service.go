@@ -0,0 +1,69 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/repositories"
+)
+
+// AuditService интерфейс журнала аудита
+type AuditService interface {
+	// Record записывает событие. Ошибка записи журнала не должна прерывать
+	// основное действие, поэтому она только логируется
+	Record(actor models.AuditActor, entry models.AuditEntry)
+	List(filter models.AuditFilter) ([]models.AuditEvent, int64, error)
+}
+
+// auditService реализация AuditService
+type auditService struct {
+	auditRepo repositories.AuditRepository
+}
+
+// NewAuditService создает новый экземпляр AuditService
+func NewAuditService(auditRepo repositories.AuditRepository) AuditService {
+	return &auditService{auditRepo: auditRepo}
+}
+
+// Record записывает событие с разницей состояний объекта до и после действия
+func (s *auditService) Record(actor models.AuditActor, entry models.AuditEntry) {
+	event := &models.AuditEvent{
+		Action:     entry.Action,
+		TargetType: entry.TargetType,
+		Success:    !entry.Failed,
+		Details:    entry.Details,
+		IP:         actor.IP,
+		UserAgent:  actor.UserAgent,
+	}
+	if actor.UserID != 0 {
+		actorID := actor.UserID
+		event.ActorID = &actorID
+	}
+	if entry.TargetID != 0 {
+		targetID := entry.TargetID
+		event.TargetID = &targetID
+	}
+
+	if entry.Before != nil || entry.After != nil {
+		changes, err := models.AuditDiff(entry.Before, entry.After)
+		if err == nil {
+			var data []byte
+			data, err = json.Marshal(changes)
+			event.Changes = string(data)
+		}
+		if err != nil {
+			log.Printf("⚠️  Ошибка сравнения состояний для аудита %s: %v", entry.Action, err)
+		}
+	}
+
+	if err := s.auditRepo.Create(event); err != nil {
+		log.Printf("⚠️  Ошибка записи события аудита %s: %v", entry.Action, err)
+	}
+}
+
+// List возвращает страницу журнала аудита по фильтру
+func (s *auditService) List(filter models.AuditFilter) ([]models.AuditEvent, int64, error) {
+	filter.Normalize()
+	return s.auditRepo.List(filter)
+}
@@ -1,25 +1,30 @@
 package services
 
 import (
-	"context"
-	"crypto/hmac"
-	"crypto/sha256"
+	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
 	"time"
 
 	"garage-barbershop/internal/models"
 	"garage-barbershop/internal/repositories"
 
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/redis/go-redis/v9"
 	"golang.org/x/crypto/bcrypt"
 )
 
 // AuthService интерфейс для аутентификации
 type AuthService interface {
-	ValidateTelegramAuth(authData models.TelegramAuthData, botToken string) bool
+	// ValidateTelegramAuth проверяет данные Telegram Login Widget, подписанные токеном бота из конфигурации
+	ValidateTelegramAuth(data models.TelegramLoginData) (*models.TelegramAuthData, error)
 	AuthenticateUser(authData models.TelegramAuthData) (*models.User, error)
+	// ValidateWebAppInitData проверяет initData Telegram WebApp, подписанные токеном бота из конфигурации
+	ValidateWebAppInitData(initData string) (*models.TelegramWebAppInitData, error)
 	GenerateAccessToken(user *models.User) (string, error)
 	GenerateRefreshToken(user *models.User) (string, error)
 	ParseJWT(tokenString string) (*models.TokenClaims, error)
@@ -28,61 +33,100 @@ type AuthService interface {
 	UpdateRefreshToken(userID uint, oldToken, newToken string) error
 	RevokeRefreshToken(userID uint) error
 
+	// Сессии на устройствах
+	CreateSession(user *models.User, device models.DeviceInfo) (*models.AuthResponse, error)
+	RefreshSession(refreshToken string, device models.DeviceInfo) (*models.AuthResponse, error)
+	ListSessions(userID, currentSessionID uint) ([]models.Session, error)
+	RevokeSession(userID, sessionID uint) error
+	RevokeOtherSessions(userID, currentSessionID uint) (int64, error)
+
+	// Отзыв access token
+	IsTokenRevoked(claims *models.TokenClaims) bool
+	RevokeAccessToken(claims *models.TokenClaims) error
+	RevokeUserTokens(userID uint) error
+
 	// Прямая авторизация (без Telegram)
 	RegisterUserDirect(req models.DirectRegisterRequest) (*models.User, error)
 	RegisterClient(req models.ClientRegisterRequest) (*models.User, error)
 	RegisterBarber(req models.BarberRegisterRequest) (*models.User, error)
 	LoginDirect(req models.DirectLoginRequest) (*models.User, error)
 
-	// Получение пользователя
+	// Получение пользователя и профиль
 	GetUserByID(userID uint) (*models.User, error)
+	GetProfile(userID uint) (*models.User, error)
+	UpdateProfile(userID uint, req models.ProfileUpdateRequest) (*models.User, error)
 	HashPassword(password string) (string, error)
 	CheckPassword(password, hash string) bool
+	// ValidatePassword проверяет пароль по политике паролей
+	ValidatePassword(password, email string) error
 }
 
+// Время жизни токенов и сессий
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 7 * 24 * time.Hour
+	// DefaultSessionMaxLifetime абсолютный срок жизни сессии по умолчанию
+	DefaultSessionMaxLifetime = 30 * 24 * time.Hour
+)
+
+// Ошибки сессий
+var (
+	ErrInvalidRefreshToken = errors.New("невалидный refresh token")
+	ErrRefreshTokenReused  = errors.New("повторное использование refresh token")
+	ErrSessionNotFound     = errors.New("сессия не найдена")
+	ErrUserInactive        = errors.New("пользователь деактивирован")
+	ErrInvalidCredentials  = errors.New("неверный email или пароль")
+)
+
+// dummyPasswordHash хеш для проверки пароля неизвестного пользователя
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("garage-barbershop"), bcrypt.DefaultCost)
+	return hash
+})
+
+// Ошибки профиля
+var (
+	ErrInvalidProfile        = errors.New("неверные данные профиля")
+	ErrPreferencesClientOnly = errors.New("предпочтения доступны только клиентам")
+)
+
+// phonePattern допустимый формат телефона: цифры с необязательным "+" и разделителями
+var phonePattern = regexp.MustCompile(`^\+?[0-9][0-9 ()-]{5,18}[0-9]$`)
+
 // authService реализация AuthService
 type authService struct {
-	userRepo  repositories.UserRepository
-	roleRepo  repositories.RoleRepository
-	rdb       *redis.Client
-	jwtSecret string
-	botToken  string
+	userRepo    repositories.UserRepository
+	roleRepo    repositories.RoleRepository
+	sessionRepo repositories.SessionRepository
+	revocation  TokenRevocationService
+	jwtSecret   string
+	botToken    string
+
+	sessionMaxLifetime time.Duration
+	passwordPolicy     PasswordPolicy
 }
 
-// NewAuthService создает новый сервис аутентификации
-func NewAuthService(userRepo repositories.UserRepository, roleRepo repositories.RoleRepository, rdb *redis.Client, jwtSecret, botToken string) AuthService {
-	return &authService{
-		userRepo:  userRepo,
-		roleRepo:  roleRepo,
-		rdb:       rdb,
-		jwtSecret: jwtSecret,
-		botToken:  botToken,
+// NewAuthService создает новый сервис аутентификации.
+// sessionMaxLifetime - абсолютный срок жизни сессии, 0 - DefaultSessionMaxLifetime;
+// passwordPolicy - политика паролей, nil - политика по умолчанию
+func NewAuthService(userRepo repositories.UserRepository, roleRepo repositories.RoleRepository, sessionRepo repositories.SessionRepository, revocation TokenRevocationService, jwtSecret, botToken string, sessionMaxLifetime time.Duration, passwordPolicy PasswordPolicy) AuthService {
+	if sessionMaxLifetime <= 0 {
+		sessionMaxLifetime = DefaultSessionMaxLifetime
 	}
-}
-
-// ValidateTelegramAuth проверяет подпись Telegram
-func (s *authService) ValidateTelegramAuth(authData models.TelegramAuthData, botToken string) bool {
-	// Проверяем время (auth_date не старше 5 минут)
-	if time.Now().Unix()-authData.AuthDate > 300 {
-		return false
+	if passwordPolicy == nil {
+		passwordPolicy = NewPasswordPolicy(DefaultPasswordMinLength, nil)
 	}
+	return &authService{
+		userRepo:    userRepo,
+		roleRepo:    roleRepo,
+		sessionRepo: sessionRepo,
+		revocation:  revocation,
+		jwtSecret:   jwtSecret,
+		botToken:    botToken,
 
-	// Создаем строку для проверки подписи
-	dataCheckString := fmt.Sprintf("auth_date=%d\nfirst_name=%s\nid=%d\nlast_name=%s\nusername=%s",
-		authData.AuthDate,
-		authData.FirstName,
-		authData.ID,
-		authData.LastName,
-		authData.Username,
-	)
-
-	// Создаем HMAC подпись
-	h := hmac.New(sha256.New, []byte(botToken))
-	h.Write([]byte(dataCheckString))
-	calculatedHash := hex.EncodeToString(h.Sum(nil))
-
-	// Сравниваем с переданной подписью
-	return calculatedHash == authData.Hash
+		sessionMaxLifetime: sessionMaxLifetime,
+		passwordPolicy:     passwordPolicy,
+	}
 }
 
 // AuthenticateUser находит или создает пользователя
@@ -90,11 +134,18 @@ func (s *authService) AuthenticateUser(authData models.TelegramAuthData) (*model
 	// Ищем пользователя по TelegramID
 	user, err := s.userRepo.GetByTelegramID(authData.ID)
 	if err == nil {
-		// Пользователь найден, обновляем данные
+		// Деактивированный пользователь не может снова войти через Telegram
+		if !user.IsActive {
+			return nil, ErrUserInactive
+		}
+
+		// Пользователь найден, обновляем данные. Имя аккаунта со входом по паролю,
+		// к которому привязан Telegram, задает сам пользователь
 		user.Username = authData.Username
-		user.FirstName = authData.FirstName
-		user.LastName = authData.LastName
-		user.IsActive = true
+		if !user.HasPassword() {
+			user.FirstName = authData.FirstName
+			user.LastName = authData.LastName
+		}
 
 		if err := s.userRepo.Update(user); err != nil {
 			return nil, fmt.Errorf("ошибка обновления пользователя: %v", err)
@@ -109,6 +160,7 @@ func (s *authService) AuthenticateUser(authData models.TelegramAuthData) (*model
 		Username:   authData.Username,
 		FirstName:  authData.FirstName,
 		LastName:   authData.LastName,
+		AuthMethod: "telegram",
 		IsActive:   true,
 	}
 
@@ -127,6 +179,16 @@ func (s *authService) AuthenticateUser(authData models.TelegramAuthData) (*model
 
 // GenerateAccessToken создает access token
 func (s *authService) GenerateAccessToken(user *models.User) (string, error) {
+	return s.accessToken(user, 0)
+}
+
+// GenerateRefreshToken создает refresh token
+func (s *authService) GenerateRefreshToken(user *models.User) (string, error) {
+	return s.refreshToken(user, 0, generateJTI(), time.Now().Add(RefreshTokenTTL))
+}
+
+// accessToken создает access token, привязанный к сессии (sessionID = 0 - без сессии)
+func (s *authService) accessToken(user *models.User, sessionID uint) (string, error) {
 	// Получаем роли пользователя
 	roles, err := s.roleRepo.GetUserRoles(user.ID)
 	if err != nil {
@@ -143,24 +205,30 @@ func (s *authService) GenerateAccessToken(user *models.User) (string, error) {
 		"telegram_id": user.TelegramID,
 		"roles":       roleNames,
 		"type":        "access",
-		"exp":         time.Now().Add(15 * time.Minute).Unix(),
-		"iat":         time.Now().Unix(),
+		"exp":         time.Now().Add(AccessTokenTTL).Unix(),
+		"iat":         issuedAt(time.Now()),
 		"jti":         generateJTI(),
 	}
+	if sessionID != 0 {
+		claims["sid"] = sessionID
+	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(s.jwtSecret))
 }
 
-// GenerateRefreshToken создает refresh token
-func (s *authService) GenerateRefreshToken(user *models.User) (string, error) {
+// refreshToken создает refresh token сессии с заданным JTI и сроком действия
+func (s *authService) refreshToken(user *models.User, sessionID uint, jti string, expiresAt time.Time) (string, error) {
 	claims := jwt.MapClaims{
 		"user_id":     user.ID,
 		"telegram_id": user.TelegramID,
 		"type":        "refresh",
-		"exp":         time.Now().Add(7 * 24 * time.Hour).Unix(),
-		"iat":         time.Now().Unix(),
-		"jti":         generateJTI(),
+		"exp":         expiresAt.Unix(),
+		"iat":         issuedAt(time.Now()),
+		"jti":         jti,
+	}
+	if sessionID != 0 {
+		claims["sid"] = sessionID
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -189,6 +257,7 @@ func (s *authService) ParseJWT(tokenString string) (*models.TokenClaims, error)
 		exp, _ := claims["exp"].(float64)
 		iat, _ := claims["iat"].(float64)
 		jti, _ := claims["jti"].(string)
+		sid, _ := claims["sid"].(float64)
 
 		// Преобразуем роли из interface{} в []string
 		var roles []string
@@ -208,61 +277,228 @@ func (s *authService) ParseJWT(tokenString string) (*models.TokenClaims, error)
 			Type:       tokenType,
 			Exp:        int64(exp),
 			Iat:        int64(iat),
+			IatMicros:  int64(math.Round(iat * 1e6)),
 			Jti:        jti,
+			SessionID:  uint(sid),
 		}, nil
 	}
 
 	return nil, fmt.Errorf("невалидный токен")
 }
 
-// StoreRefreshToken сохраняет refresh token в Redis
+// StoreRefreshToken открывает сессию для refresh token без сведений об устройстве
 func (s *authService) StoreRefreshToken(userID uint, refreshToken string) error {
-	if s.rdb == nil {
-		return nil // В тестах Redis может быть nil
+	claims, err := s.ParseJWT(refreshToken)
+	if err != nil || claims.UserID != userID || claims.Jti == "" {
+		return ErrInvalidRefreshToken
 	}
-	key := fmt.Sprintf("refresh_token:%d", userID)
-	return s.rdb.Set(context.Background(), key, refreshToken, 7*24*time.Hour).Err()
+
+	now := time.Now()
+	return s.sessionRepo.Create(&models.Session{
+		UserID:     userID,
+		JTI:        claims.Jti,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(s.sessionMaxLifetime),
+	})
 }
 
-// IsRefreshTokenValid проверяет валидность refresh token
+// IsRefreshTokenValid проверяет, что refresh token является действующим токеном активной сессии
 func (s *authService) IsRefreshTokenValid(userID uint, refreshToken string) bool {
-	if s.rdb == nil {
-		return true // В тестах Redis может быть nil, считаем токен валидным
-	}
-	key := fmt.Sprintf("refresh_token:%d", userID)
-	storedToken, err := s.rdb.Get(context.Background(), key).Result()
-	return err == nil && storedToken == refreshToken
+	session, _, err := s.refreshSession(refreshToken)
+	return err == nil && session.UserID == userID
 }
 
-// UpdateRefreshToken обновляет refresh token
+// UpdateRefreshToken заменяет refresh token сессии новым
 func (s *authService) UpdateRefreshToken(userID uint, oldToken, newToken string) error {
-	if s.rdb == nil {
-		return nil // В тестах Redis может быть nil
+	session, _, err := s.refreshSession(oldToken)
+	if err != nil || session.UserID != userID {
+		return ErrInvalidRefreshToken
 	}
-	key := fmt.Sprintf("refresh_token:%d", userID)
-
-	// Проверяем, что старый токен совпадает
-	storedToken, err := s.rdb.Get(context.Background(), key).Result()
-	if err != nil || storedToken != oldToken {
-		return fmt.Errorf("невалидный refresh token")
+	newClaims, err := s.ParseJWT(newToken)
+	if err != nil || newClaims.Jti == "" {
+		return ErrInvalidRefreshToken
 	}
 
-	// Обновляем на новый токен
-	return s.rdb.Set(context.Background(), key, newToken, 7*24*time.Hour).Err()
+	oldJTI := session.JTI
+	session.JTI = newClaims.Jti
+	session.LastUsedAt = time.Now()
+	if err := s.sessionRepo.Rotate(session, oldJTI); err != nil {
+		return ErrInvalidRefreshToken
+	}
+	return nil
 }
 
-// RevokeRefreshToken отзывает refresh token
+// RevokeRefreshToken отзывает все сессии пользователя
 func (s *authService) RevokeRefreshToken(userID uint) error {
-	if s.rdb == nil {
-		return nil // В тестах Redis может быть nil
+	_, err := s.sessionRepo.RevokeAll(userID, 0, models.SessionRevokedByUser, time.Now())
+	return err
+}
+
+// CreateSession открывает сессию на устройстве и выдает пару токенов
+func (s *authService) CreateSession(user *models.User, device models.DeviceInfo) (*models.AuthResponse, error) {
+	now := time.Now()
+	session := &models.Session{
+		UserID:     user.ID,
+		JTI:        generateJTI(),
+		DeviceName: device.Name,
+		IP:         device.IP,
+		UserAgent:  device.UserAgent,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(s.sessionMaxLifetime),
+	}
+	if err := s.sessionRepo.Create(session); err != nil {
+		return nil, fmt.Errorf("ошибка создания сессии: %v", err)
+	}
+
+	return s.sessionTokens(user, session)
+}
+
+// RefreshSession обновляет токены сессии: старый refresh token становится недействительным
+func (s *authService) RefreshSession(refreshToken string, device models.DeviceInfo) (*models.AuthResponse, error) {
+	session, claims, err := s.refreshSession(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(claims.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: пользователь не найден", ErrInvalidRefreshToken)
+	}
+	if !user.IsActive {
+		return nil, ErrUserInactive
+	}
+
+	oldJTI := session.JTI
+	session.JTI = generateJTI()
+	session.LastUsedAt = time.Now()
+	if device.IP != "" {
+		session.IP = device.IP
+	}
+	if device.UserAgent != "" {
+		session.UserAgent = device.UserAgent
+	}
+	if err := s.sessionRepo.Rotate(session, oldJTI); err != nil {
+		// Токен успели обновить параллельным запросом или сессию отозвали
+		return nil, ErrInvalidRefreshToken
+	}
+
+	return s.sessionTokens(user, session)
+}
+
+// ListSessions возвращает активные сессии пользователя; currentSessionID отмечается как текущая
+func (s *authService) ListSessions(userID, currentSessionID uint) ([]models.Session, error) {
+	sessions, err := s.sessionRepo.ListActive(userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+	return sessions, nil
+}
+
+// RevokeSession отзывает сессию пользователя
+func (s *authService) RevokeSession(userID, sessionID uint) error {
+	session, err := s.sessionRepo.GetByID(sessionID)
+	if err != nil || session.UserID != userID || !session.IsActive(time.Now()) {
+		return ErrSessionNotFound
+	}
+	return s.sessionRepo.Revoke(sessionID, models.SessionRevokedByUser, time.Now())
+}
+
+// RevokeOtherSessions отзывает все сессии пользователя, кроме текущей, и возвращает их число
+func (s *authService) RevokeOtherSessions(userID, currentSessionID uint) (int64, error) {
+	return s.sessionRepo.RevokeAll(userID, currentSessionID, models.SessionRevokedByUser, time.Now())
+}
+
+// IsTokenRevoked проверяет, отозван ли access token
+func (s *authService) IsTokenRevoked(claims *models.TokenClaims) bool {
+	return s.revocation.IsRevoked(claims)
+}
+
+// RevokeAccessToken отзывает access token до истечения его срока
+func (s *authService) RevokeAccessToken(claims *models.TokenClaims) error {
+	return s.revocation.RevokeToken(claims)
+}
+
+// RevokeUserTokens отзывает все выпущенные access token пользователя
+func (s *authService) RevokeUserTokens(userID uint) error {
+	return s.revocation.RevokeUserTokens(userID)
+}
+
+// refreshSession проверяет refresh token и находит его активную сессию.
+// Токен действителен, только пока его JTI совпадает с текущим JTI сессии.
+// Подписанный нами токен сессии с другим JTI - это уже замененный токен:
+// его предъявление означает утечку, и сессия (все семейство токенов) отзывается
+func (s *authService) refreshSession(refreshToken string) (*models.Session, *models.TokenClaims, error) {
+	claims, err := s.ParseJWT(refreshToken)
+	if err != nil || !claims.IsRefreshToken() || claims.IsExpired() {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+
+	session, err := s.sessionRepo.GetByJTI(claims.Jti)
+	if err != nil {
+		if claims.SessionID != 0 {
+			return nil, nil, s.detectTokenReuse(claims)
+		}
+		return nil, nil, ErrInvalidRefreshToken
+	}
+	if session.UserID != claims.UserID || !session.IsActive(time.Now()) {
+		return nil, nil, ErrInvalidRefreshToken
+	}
+	return session, claims, nil
+}
+
+// detectTokenReuse отзывает сессию, если предъявлен замененный refresh token ее семейства
+func (s *authService) detectTokenReuse(claims *models.TokenClaims) error {
+	session, err := s.sessionRepo.GetByID(claims.SessionID)
+	if err != nil || session.UserID != claims.UserID || !session.IsActive(time.Now()) {
+		return ErrInvalidRefreshToken
+	}
+
+	if err := s.sessionRepo.Revoke(session.ID, models.SessionRevokedTokenReused, time.Now()); err != nil {
+		return fmt.Errorf("ошибка отзыва сессии %d: %v", session.ID, err)
 	}
-	key := fmt.Sprintf("refresh_token:%d", userID)
-	return s.rdb.Del(context.Background(), key).Err()
+	return fmt.Errorf("%w: сессия %d отозвана", ErrRefreshTokenReused, session.ID)
+}
+
+// sessionTokens выдает пару токенов для текущего JTI сессии
+func (s *authService) sessionTokens(user *models.User, session *models.Session) (*models.AuthResponse, error) {
+	accessToken, err := s.accessToken(user, session.ID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка генерации access token: %v", err)
+	}
+	// Refresh token не переживает абсолютный срок жизни сессии
+	expiresAt := time.Now().Add(RefreshTokenTTL)
+	if session.ExpiresAt.Before(expiresAt) {
+		expiresAt = session.ExpiresAt
+	}
+	refreshToken, err := s.refreshToken(user, session.ID, session.JTI, expiresAt)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка генерации refresh token: %v", err)
+	}
+
+	return &models.AuthResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(AccessTokenTTL.Seconds()),
+		User:         *user,
+	}, nil
+}
+
+// issuedAt возвращает iat с точностью до микросекунд: отзыв токенов пользователя
+// должен отличать токены, выпущенные незадолго до и сразу после отзыва
+func issuedAt(now time.Time) float64 {
+	return float64(now.UnixMicro()) / 1e6
 }
 
 // generateJTI генерирует уникальный JWT ID
 func generateJTI() string {
-	return fmt.Sprintf("%d_%d", time.Now().UnixNano(), time.Now().Unix())
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
 }
 
 // HashPassword хеширует пароль с помощью bcrypt
@@ -277,6 +513,11 @@ func (s *authService) CheckPassword(password, hash string) bool {
 	return err == nil
 }
 
+// ValidatePassword проверяет пароль по политике паролей
+func (s *authService) ValidatePassword(password, email string) error {
+	return s.passwordPolicy.Validate(password, email)
+}
+
 // RegisterUserDirect регистрирует пользователя напрямую (без Telegram)
 func (s *authService) RegisterUserDirect(req models.DirectRegisterRequest) (*models.User, error) {
 	// Проверяем, что email не занят
@@ -285,6 +526,11 @@ func (s *authService) RegisterUserDirect(req models.DirectRegisterRequest) (*mod
 		return nil, fmt.Errorf("пользователь с email %s уже существует", req.Email)
 	}
 
+	// Проверяем пароль по политике
+	if err := s.ValidatePassword(req.Password, req.Email); err != nil {
+		return nil, err
+	}
+
 	// Хешируем пароль
 	passwordHash, err := s.HashPassword(req.Password)
 	if err != nil {
@@ -318,27 +564,21 @@ func (s *authService) RegisterUserDirect(req models.DirectRegisterRequest) (*mod
 	return user, nil
 }
 
-// LoginDirect авторизует пользователя напрямую (без Telegram)
+// LoginDirect авторизует пользователя напрямую (без Telegram).
+// При любой ошибке возвращается ErrInvalidCredentials, чтобы по ответу нельзя было узнать,
+// зарегистрирован ли email
 func (s *authService) LoginDirect(req models.DirectLoginRequest) (*models.User, error) {
-	// Находим пользователя по email
+	// Находим пользователя по email; пароль может быть задан и у пользователя Telegram
 	user, err := s.userRepo.GetByEmail(req.Email)
-	if err != nil {
-		return nil, fmt.Errorf("пользователь не найден")
+	if err != nil || !user.HasPassword() {
+		// Проверяем пароль и для неизвестного email, чтобы время ответа не выдавало его
+		bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(req.Password))
+		return nil, ErrInvalidCredentials
 	}
 
-	// Проверяем, что это прямая авторизация
-	if user.AuthMethod != "direct" {
-		return nil, fmt.Errorf("этот пользователь авторизуется через Telegram")
-	}
-
-	// Проверяем пароль
-	if !s.CheckPassword(req.Password, user.PasswordHash) {
-		return nil, fmt.Errorf("неверный пароль")
-	}
-
-	// Проверяем, что пользователь активен
-	if !user.IsActive {
-		return nil, fmt.Errorf("пользователь деактивирован")
+	// Проверяем пароль и что пользователь активен
+	if !s.CheckPassword(req.Password, user.PasswordHash) || !user.IsActive {
+		return nil, ErrInvalidCredentials
 	}
 
 	return user, nil
@@ -352,6 +592,11 @@ func (s *authService) RegisterClient(req models.ClientRegisterRequest) (*models.
 		return nil, fmt.Errorf("пользователь с email %s уже существует", req.Email)
 	}
 
+	// Проверяем пароль по политике
+	if err := s.ValidatePassword(req.Password, req.Email); err != nil {
+		return nil, err
+	}
+
 	// Хешируем пароль
 	passwordHash, err := s.HashPassword(req.Password)
 	if err != nil {
@@ -393,6 +638,11 @@ func (s *authService) RegisterBarber(req models.BarberRegisterRequest) (*models.
 		return nil, fmt.Errorf("пользователь с email %s уже существует", req.Email)
 	}
 
+	// Проверяем пароль по политике
+	if err := s.ValidatePassword(req.Password, req.Email); err != nil {
+		return nil, err
+	}
+
 	// Хешируем пароль
 	passwordHash, err := s.HashPassword(req.Password)
 	if err != nil {
@@ -433,3 +683,55 @@ func (s *authService) RegisterBarber(req models.BarberRegisterRequest) (*models.
 func (s *authService) GetUserByID(userID uint) (*models.User, error) {
 	return s.userRepo.GetByID(userID)
 }
+
+// GetProfile возвращает пользователя с его активными ролями
+func (s *authService) GetProfile(userID uint) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	roles, err := s.roleRepo.GetUserRoles(userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения ролей: %v", err)
+	}
+	user.Roles = roles
+	return user, nil
+}
+
+// UpdateProfile обновляет имя, телефон и предпочтения (для клиентов) пользователя
+func (s *authService) UpdateProfile(userID uint, req models.ProfileUpdateRequest) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.FirstName != nil {
+		firstName := strings.TrimSpace(*req.FirstName)
+		if firstName == "" {
+			return nil, fmt.Errorf("%w: имя не может быть пустым", ErrInvalidProfile)
+		}
+		user.FirstName = firstName
+	}
+	if req.LastName != nil {
+		user.LastName = strings.TrimSpace(*req.LastName)
+	}
+	if req.Phone != nil {
+		phone := strings.TrimSpace(*req.Phone)
+		if phone != "" && !phonePattern.MatchString(phone) {
+			return nil, fmt.Errorf("%w: неверный формат телефона", ErrInvalidProfile)
+		}
+		user.Phone = phone
+	}
+	if req.Preferences != nil {
+		if !s.roleRepo.HasUserRole(userID, "client") {
+			return nil, ErrPreferencesClientOnly
+		}
+		user.Preferences = strings.TrimSpace(*req.Preferences)
+	}
+
+	// Роли подгружаются отдельно, чтобы Update не трогал связи пользователя
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, fmt.Errorf("ошибка обновления профиля: %v", err)
+	}
+	return s.GetProfile(userID)
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/repositories"
+)
+
+const (
+	// AvailabilitySlotStep шаг, с которым предлагаются времена начала записи
+	AvailabilitySlotStep = 15 * time.Minute
+	// MaxAvailabilityDays максимальный период, за который можно запросить свободное время
+	MaxAvailabilityDays = 31
+)
+
+// AvailabilityService интерфейс для расчета свободного времени барбера
+type AvailabilityService interface {
+	// GetAvailableSlots возвращает свободные слоты для услуги с from по to включительно.
+	// Даты "2006-01-02" понимаются в часовом поясе барбера; пустой from - сегодня, пустой to - неделя от from
+	GetAvailableSlots(barberID, serviceID uint, from, to string) (*models.Availability, error)
+}
+
+// availabilityService реализация AvailabilityService
+type availabilityService struct {
+	workingHoursRepo repositories.WorkingHoursRepository
+	exceptionRepo    repositories.ScheduleExceptionRepository
+	appointmentRepo  repositories.AppointmentRepository
+	serviceRepo      repositories.ServiceRepository
+	timezoneService  TimezoneService
+}
+
+// NewAvailabilityService создает новый экземпляр AvailabilityService
+func NewAvailabilityService(workingHoursRepo repositories.WorkingHoursRepository, exceptionRepo repositories.ScheduleExceptionRepository, appointmentRepo repositories.AppointmentRepository, serviceRepo repositories.ServiceRepository, timezoneService TimezoneService) AvailabilityService {
+	return &availabilityService{
+		workingHoursRepo: workingHoursRepo,
+		exceptionRepo:    exceptionRepo,
+		appointmentRepo:  appointmentRepo,
+		serviceRepo:      serviceRepo,
+		timezoneService:  timezoneService,
+	}
+}
+
+// GetAvailableSlots рассчитывает свободные слоты по рабочим часам, перерывам,
+// исключениям из расписания и существующим записям
+func (s *availabilityService) GetAvailableSlots(barberID, serviceID uint, from, to string) (*models.Availability, error) {
+	// Рабочие часы заданы по часам барбера, поэтому и дни считаем в его часовом поясе
+	loc := s.timezoneService.BarberLocation(barberID)
+
+	firstDay := startOfDay(time.Now().In(loc))
+	if from != "" {
+		parsed, err := time.ParseInLocation(models.DateLayout, from, loc)
+		if err != nil {
+			return nil, fmt.Errorf("неверный формат from, ожидается YYYY-MM-DD")
+		}
+		firstDay = parsed
+	}
+	lastDay := firstDay.AddDate(0, 0, 6)
+	if to != "" {
+		parsed, err := time.ParseInLocation(models.DateLayout, to, loc)
+		if err != nil {
+			return nil, fmt.Errorf("неверный формат to, ожидается YYYY-MM-DD")
+		}
+		lastDay = parsed
+	}
+
+	if lastDay.Before(firstDay) {
+		return nil, fmt.Errorf("дата окончания раньше даты начала")
+	}
+	if firstDay.AddDate(0, 0, MaxAvailabilityDays).Before(lastDay.AddDate(0, 0, 1)) {
+		return nil, fmt.Errorf("период не может превышать %d дней", MaxAvailabilityDays)
+	}
+
+	// Услуга должна быть активной и принадлежать барберу
+	service, err := s.serviceRepo.GetByID(serviceID)
+	if err != nil || !service.IsActive || service.BarberID != barberID {
+		return nil, ErrServiceNotFound
+	}
+	duration := time.Duration(service.Duration) * time.Minute
+
+	workingHours, err := s.workingHoursRepo.GetActiveByBarberID(barberID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения рабочих часов: %v", err)
+	}
+
+	exceptions, err := s.exceptionRepo.GetByBarberInRange(barberID, firstDay.Format(models.DateLayout), lastDay.Format(models.DateLayout))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения исключений из расписания: %v", err)
+	}
+
+	rangeEnd := lastDay.AddDate(0, 0, 1)
+	appointments, err := s.appointmentRepo.GetOverlapping(barberID, firstDay, rangeEnd)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения записей: %v", err)
+	}
+
+	availability := &models.Availability{
+		BarberID:  barberID,
+		ServiceID: serviceID,
+		Duration:  service.Duration,
+		Timezone:  loc.String(),
+		From:      firstDay.Format("2006-01-02"),
+		To:        lastDay.Format("2006-01-02"),
+		Slots:     []models.TimeSlot{},
+	}
+
+	now := time.Now()
+	for day := firstDay; day.Before(rangeEnd); day = day.AddDate(0, 0, 1) {
+		wh, ok := dayWorkingHours(workingHours, exceptions, day)
+		if !ok {
+			continue
+		}
+
+		daySlots, err := daySlots(day, wh, duration, appointments, exceptions, now)
+		if err != nil {
+			return nil, err
+		}
+		availability.Slots = append(availability.Slots, daySlots...)
+	}
+
+	return availability, nil
+}
+
+// daySlots рассчитывает свободные слоты одного рабочего дня
+// Слоты идут с шагом по абсолютному времени, поэтому в дни перехода на летнее/зимнее время
+// длительность слота не искажается
+func daySlots(day time.Time, wh models.WorkingHours, duration time.Duration, appointments []models.Appointment, exceptions []models.ScheduleException, now time.Time) ([]models.TimeSlot, error) {
+	work, err := newWorkingDay(day, wh)
+	if err != nil {
+		return nil, err
+	}
+
+	var slots []models.TimeSlot
+	for start := work.start; !start.Add(duration).After(work.end); start = start.Add(AvailabilitySlotStep) {
+		end := start.Add(duration)
+
+		// Прошедшее время не предлагаем
+		if !start.After(now) {
+			continue
+		}
+
+		// Слот не должен захватывать перерыв
+		if !work.fits(start, end) {
+			continue
+		}
+
+		if overlapsAny(appointments, start, end) || timeOffOverlaps(exceptions, start, end) {
+			continue
+		}
+
+		slots = append(slots, models.TimeSlot{
+			Start:      start.UTC(),
+			End:        end.UTC(),
+			StartLocal: start,
+			EndLocal:   end,
+		})
+	}
+
+	return slots, nil
+}
+
+// workingDay рабочее время одного дня в часовом поясе барбера
+type workingDay struct {
+	start, end           time.Time
+	breakStart, breakEnd time.Time // нулевые, если перерыва нет
+}
+
+// newWorkingDay переводит рабочие часы в моменты времени указанного дня
+func newWorkingDay(day time.Time, wh models.WorkingHours) (workingDay, error) {
+	var work workingDay
+	var err error
+	if work.start, err = clockOnDay(day, wh.StartTime); err != nil {
+		return work, err
+	}
+	if work.end, err = clockOnDay(day, wh.EndTime); err != nil {
+		return work, err
+	}
+	if wh.HasBreak() {
+		if work.breakStart, err = clockOnDay(day, wh.BreakStart); err != nil {
+			return work, err
+		}
+		if work.breakEnd, err = clockOnDay(day, wh.BreakEnd); err != nil {
+			return work, err
+		}
+	}
+	return work, nil
+}
+
+// fits проверяет, что интервал помещается в рабочее время и не захватывает перерыв
+func (w workingDay) fits(start, end time.Time) bool {
+	if start.Before(w.start) || end.After(w.end) {
+		return false
+	}
+	return w.breakStart.IsZero() || !start.Before(w.breakEnd) || !end.After(w.breakStart)
+}
+
+// dayWorkingHours возвращает рабочие часы дня; дополнительный рабочий день заменяет недельное расписание
+func dayWorkingHours(weekly []models.WorkingHours, exceptions []models.ScheduleException, day time.Time) (models.WorkingHours, bool) {
+	if extra, found := extraWorkingHours(exceptions, day); found {
+		return extra, true
+	}
+	for _, wh := range weekly {
+		if wh.DayOfWeek == models.ISODayOfWeek(day) {
+			return wh, true
+		}
+	}
+	return models.WorkingHours{}, false
+}
+
+// extraWorkingHours возвращает часы дополнительного рабочего дня, которые заменяют недельное расписание
+func extraWorkingHours(exceptions []models.ScheduleException, day time.Time) (models.WorkingHours, bool) {
+	for i := range exceptions {
+		if exceptions[i].Type == models.ScheduleExceptionExtraWorkingDay && exceptions[i].CoversDate(day) {
+			return models.WorkingHours{
+				DayOfWeek: models.ISODayOfWeek(day),
+				StartTime: exceptions[i].StartTime,
+				EndTime:   exceptions[i].EndTime,
+				IsActive:  true,
+				BarberID:  exceptions[i].BarberID,
+			}, true
+		}
+	}
+	return models.WorkingHours{}, false
+}
+
+// overlapsAny проверяет, пересекается ли интервал с какой-либо записью
+func overlapsAny(appointments []models.Appointment, start, end time.Time) bool {
+	for i := range appointments {
+		if appointments[i].Overlaps(start, end) {
+			return true
+		}
+	}
+	return false
+}
+
+// clockOnDay переводит время "HH:MM" в момент времени указанного дня
+func clockOnDay(day time.Time, clock string) (time.Time, error) {
+	minutes, err := models.ParseClockTime(clock)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), minutes/60, minutes%60, 0, 0, day.Location()), nil
+}
+
+// startOfDay возвращает начало суток для указанного момента
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
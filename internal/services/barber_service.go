@@ -2,6 +2,7 @@ package services
 
 import (
 	"fmt"
+	"log"
 
 	"garage-barbershop/internal/models"
 	"garage-barbershop/internal/repositories"
@@ -10,8 +11,8 @@ import (
 // BarberService интерфейс для управления барберами
 type BarberService interface {
 	// Управление барберами (только админ)
-	UpdateBarber(barberID uint, req models.BarberUpdateRequest) (*models.User, error)
-	DeleteBarber(barberID uint) error
+	UpdateBarber(actor models.AuditActor, barberID uint, req models.BarberUpdateRequest) (*models.User, error)
+	DeleteBarber(actor models.AuditActor, barberID uint) error
 	GetBarberByID(barberID uint) (*models.User, error)
 	GetAllBarbers() ([]models.User, error)
 
@@ -22,17 +23,21 @@ type BarberService interface {
 
 // barberService реализация BarberService
 type barberService struct {
-	userRepo  repositories.UserRepository
-	roleRepo  repositories.RoleRepository
+	userRepo     repositories.UserRepository
+	roleRepo     repositories.RoleRepository
+	auditService AuditService
+	revocation   TokenRevocationService
 }
 
-// NewBarberService создает новый экземпляр BarberService
-func NewBarberService(userRepo repositories.UserRepository, roleRepo repositories.RoleRepository) BarberService {
-	return &barberService{userRepo: userRepo, roleRepo: roleRepo}
+// NewBarberService создает новый экземпляр BarberService.
+// Действия администратора записываются в журнал аудита, а деактивация и удаление
+// барбера сразу отзывают его access token
+func NewBarberService(userRepo repositories.UserRepository, roleRepo repositories.RoleRepository, auditService AuditService, revocation TokenRevocationService) BarberService {
+	return &barberService{userRepo: userRepo, roleRepo: roleRepo, auditService: auditService, revocation: revocation}
 }
 
 // UpdateBarber обновляет барбера (только админ)
-func (s *barberService) UpdateBarber(barberID uint, req models.BarberUpdateRequest) (*models.User, error) {
+func (s *barberService) UpdateBarber(actor models.AuditActor, barberID uint, req models.BarberUpdateRequest) (*models.User, error) {
 	// Получаем барбера
 	barber, err := s.userRepo.GetByID(barberID)
 	if err != nil {
@@ -43,6 +48,7 @@ func (s *barberService) UpdateBarber(barberID uint, req models.BarberUpdateReque
 	if !s.roleRepo.HasUserRole(barberID, "barber") {
 		return nil, fmt.Errorf("пользователь не является барбером")
 	}
+	before := *barber
 
 	// Обновляем поля, если они переданы
 	if req.Email != "" {
@@ -75,33 +81,65 @@ func (s *barberService) UpdateBarber(barberID uint, req models.BarberUpdateReque
 		barber.IsActive = *req.IsActive
 	}
 
-	if req.Rating != nil {
-		barber.Rating = *req.Rating
+	if req.Timezone != nil {
+		if err := setBarberTimezone(barber, *req.Timezone); err != nil {
+			return nil, err
+		}
 	}
 
 	// Сохраняем изменения
 	if err := s.userRepo.Update(barber); err != nil {
 		return nil, fmt.Errorf("ошибка обновления барбера: %v", err)
 	}
+	if before.IsActive && !barber.IsActive {
+		s.revokeTokens(barberID)
+	}
 
+	s.auditService.Record(actor, models.AuditEntry{
+		Action:     models.AuditActionBarberUpdate,
+		TargetType: models.AuditTargetUser,
+		TargetID:   barberID,
+		Before:     before,
+		After:      barber,
+	})
 	return barber, nil
 }
 
 // DeleteBarber удаляет барбера (только админ)
-func (s *barberService) DeleteBarber(barberID uint) error {
+func (s *barberService) DeleteBarber(actor models.AuditActor, barberID uint) error {
 	// Проверяем, что это барбер
 	if !s.roleRepo.HasUserRole(barberID, "barber") {
 		return fmt.Errorf("пользователь не является барбером")
 	}
 
+	barber, err := s.userRepo.GetByID(barberID)
+	if err != nil {
+		return fmt.Errorf("барбер не найден: %v", err)
+	}
+
 	// Удаляем барбера
 	if err := s.userRepo.Delete(barberID); err != nil {
 		return fmt.Errorf("ошибка удаления барбера: %v", err)
 	}
+	s.revokeTokens(barberID)
 
+	s.auditService.Record(actor, models.AuditEntry{
+		Action:     models.AuditActionBarberDelete,
+		TargetType: models.AuditTargetUser,
+		TargetID:   barberID,
+		Before:     barber,
+	})
 	return nil
 }
 
+// revokeTokens отзывает access token барбера. Изменение уже сохранено,
+// поэтому ошибка отзыва только логируется
+func (s *barberService) revokeTokens(barberID uint) {
+	if err := s.revocation.RevokeUserTokens(barberID); err != nil {
+		log.Printf("⚠️  Ошибка отзыва токенов барбера %d: %v", barberID, err)
+	}
+}
+
 // GetBarberByID получает барбера по ID (только админ)
 func (s *barberService) GetBarberByID(barberID uint) (*models.User, error) {
 	barber, err := s.userRepo.GetByID(barberID)
@@ -152,6 +190,12 @@ func (s *barberService) UpdateBarberSelf(barberID uint, req models.BarberSelfUpd
 		barber.Experience = req.Experience
 	}
 
+	if req.Timezone != nil {
+		if err := setBarberTimezone(barber, *req.Timezone); err != nil {
+			return nil, err
+		}
+	}
+
 	// Сохраняем изменения
 	if err := s.userRepo.Update(barber); err != nil {
 		return nil, fmt.Errorf("ошибка обновления профиля: %v", err)
@@ -174,3 +218,18 @@ func (s *barberService) GetBarberSelf(barberID uint) (*models.User, error) {
 
 	return barber, nil
 }
+
+// setBarberTimezone проверяет и устанавливает часовой пояс барбера; пустая строка сбрасывает его
+func setBarberTimezone(barber *models.User, timezone string) error {
+	if timezone == "" {
+		barber.Timezone = ""
+		return nil
+	}
+
+	loc, err := models.LoadTimezone(timezone)
+	if err != nil {
+		return err
+	}
+	barber.Timezone = loc.String()
+	return nil
+}
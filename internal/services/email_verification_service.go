@@ -0,0 +1,195 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"garage-barbershop/internal/mailer"
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/repositories"
+)
+
+// Время действия ссылки подтверждения и минимальный интервал между письмами
+const (
+	EmailVerificationTTL            = 72 * time.Hour
+	EmailVerificationResendInterval = time.Minute
+)
+
+// Ошибки подтверждения email
+var (
+	ErrInvalidVerificationToken = errors.New("ссылка подтверждения email недействительна или устарела")
+	ErrEmailAlreadyVerified     = errors.New("email уже подтвержден")
+	ErrEmailNotSet              = errors.New("у пользователя не указан email")
+	ErrVerificationTooFrequent  = errors.New("письмо подтверждения уже отправлено, повторите попытку позже")
+)
+
+// EmailVerificationService интерфейс подтверждения email пользователей прямой авторизации.
+// Ссылка содержит подписанные ID пользователя, email и срок действия, поэтому не хранится в БД;
+// после смены email прежние ссылки перестают действовать
+type EmailVerificationService interface {
+	SendVerification(user *models.User) error
+	Resend(userID uint) error
+	Verify(token string) (*models.User, error)
+	// IsAllowed проверяет, может ли пользователь выполнить действие над ресурсом
+	// с учетом политики для неподтвержденных email
+	IsAllowed(userID uint, resource, action string) bool
+}
+
+// emailVerificationService реализация EmailVerificationService
+type emailVerificationService struct {
+	userRepo   repositories.UserRepository
+	mailer     mailer.Mailer
+	key        []byte
+	verifyURL  string
+	restricted map[string]bool // "ресурс:действие" и "ресурс:*", запрещенные до подтверждения
+}
+
+// NewEmailVerificationService создает сервис подтверждения email.
+// secret - секрет подписи ссылок, verifyURL - адрес страницы подтверждения,
+// restricted - действия "ресурс:действие", недоступные до подтверждения email
+func NewEmailVerificationService(userRepo repositories.UserRepository, sender mailer.Mailer, secret, verifyURL string, restricted []string) EmailVerificationService {
+	// Отдельный ключ, чтобы подпись ссылки нельзя было использовать в другом контексте
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("email-verification"))
+
+	restrictedSet := make(map[string]bool, len(restricted))
+	for _, item := range restricted {
+		restrictedSet[strings.TrimSpace(item)] = true
+	}
+
+	return &emailVerificationService{
+		userRepo:   userRepo,
+		mailer:     sender,
+		key:        mac.Sum(nil),
+		verifyURL:  verifyURL,
+		restricted: restrictedSet,
+	}
+}
+
+// SendVerification отправляет пользователю ссылку подтверждения email
+func (s *emailVerificationService) SendVerification(user *models.User) error {
+	if !user.NeedsEmailVerification() {
+		return ErrEmailAlreadyVerified
+	}
+	if user.Email == "" {
+		return ErrEmailNotSet
+	}
+
+	now := time.Now()
+	token := s.sign(user.ID, user.Email, now.Add(EmailVerificationTTL))
+	if err := s.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Подтверждение email",
+		Body: fmt.Sprintf(
+			"Здравствуйте, %s!\n\nПодтвердите адрес, чтобы записываться к барберам:\n%s\n\nСсылка действует %d ч.",
+			user.FirstName, tokenLink(s.verifyURL, token), int(EmailVerificationTTL.Hours()),
+		),
+	}); err != nil {
+		return fmt.Errorf("ошибка отправки письма: %v", err)
+	}
+
+	user.EmailVerificationSentAt = &now
+	return s.userRepo.Update(user)
+}
+
+// Resend повторно отправляет ссылку подтверждения не чаще EmailVerificationResendInterval
+func (s *emailVerificationService) Resend(userID uint) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return fmt.Errorf("пользователь не найден: %v", err)
+	}
+	if !user.NeedsEmailVerification() {
+		return ErrEmailAlreadyVerified
+	}
+	if user.EmailVerificationSentAt != nil && time.Since(*user.EmailVerificationSentAt) < EmailVerificationResendInterval {
+		return ErrVerificationTooFrequent
+	}
+	return s.SendVerification(user)
+}
+
+// Verify проверяет подпись и срок ссылки и отмечает email подтвержденным.
+// Повторное подтверждение того же адреса не считается ошибкой
+func (s *emailVerificationService) Verify(token string) (*models.User, error) {
+	userID, email, err := s.parse(token)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil || !strings.EqualFold(user.Email, email) {
+		return nil, ErrInvalidVerificationToken
+	}
+	if user.EmailVerifiedAt != nil {
+		return user, nil
+	}
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, fmt.Errorf("ошибка сохранения подтверждения: %v", err)
+	}
+	return user, nil
+}
+
+// IsAllowed проверяет политику для неподтвержденных email
+func (s *emailVerificationService) IsAllowed(userID uint, resource, action string) bool {
+	if !s.restricted[resource+":"+action] && !s.restricted[resource+":*"] {
+		return true
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return false
+	}
+	return !user.NeedsEmailVerification()
+}
+
+// sign формирует токен "данные.подпись", данные - "ID:срок:email"
+func (s *emailVerificationService) sign(userID uint, email string, expiresAt time.Time) string {
+	payload := fmt.Sprintf("%d:%d:%s", userID, expiresAt.Unix(), strings.ToLower(email))
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.mac(encoded))
+}
+
+// parse проверяет подпись и срок токена и возвращает ID пользователя и email
+func (s *emailVerificationService) parse(token string) (uint, string, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return 0, "", ErrInvalidVerificationToken
+	}
+	expected, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, s.mac(encoded)) {
+		return 0, "", ErrInvalidVerificationToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return 0, "", ErrInvalidVerificationToken
+	}
+	parts := strings.SplitN(string(payload), ":", 3)
+	if len(parts) != 3 {
+		return 0, "", ErrInvalidVerificationToken
+	}
+	userID, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil {
+		return 0, "", ErrInvalidVerificationToken
+	}
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || time.Now().Unix() >= expiresAt {
+		return 0, "", ErrInvalidVerificationToken
+	}
+	return uint(userID), parts[2], nil
+}
+
+// mac вычисляет подпись данных токена
+func (s *emailVerificationService) mac(data string) []byte {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
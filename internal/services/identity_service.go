@@ -0,0 +1,208 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/repositories"
+)
+
+// Ошибки управления способами входа
+var (
+	ErrTelegramAlreadyLinked   = errors.New("к аккаунту уже привязан другой Telegram, сначала отвяжите его")
+	ErrTelegramLinkedElsewhere = errors.New("этот Telegram привязан к другому аккаунту, обратитесь к администратору для объединения")
+	ErrTelegramNotLinked       = errors.New("Telegram не привязан к аккаунту")
+	ErrLastIdentity            = errors.New("нельзя отвязать единственный способ входа")
+	ErrPasswordAlreadySet      = errors.New("пароль уже задан, используйте смену пароля")
+	ErrEmailRequired           = errors.New("для входа по паролю нужен email")
+	ErrEmailTaken              = errors.New("email уже используется другим аккаунтом")
+	ErrMergeSameUser           = errors.New("нельзя объединить аккаунт с самим собой")
+	ErrMergeConflict           = errors.New("к аккаунтам привязаны разные Telegram")
+	ErrMergeBarberData         = errors.New("у дубликата есть услуги или расписание барбера, перенесите или удалите их перед объединением")
+	ErrMergeTwoFactor          = errors.New("у дубликата включена двухфакторная аутентификация, отключите ее перед объединением")
+)
+
+// IdentityService интерфейс управления способами входа пользователя.
+// Один пользователь может входить и через Telegram, и по email и паролю;
+// каждого способа не больше одного (см. models.UserIdentity)
+type IdentityService interface {
+	ListIdentities(userID uint) ([]models.UserIdentity, error)
+	// LinkTelegram привязывает проверенные данные Telegram к пользователю
+	LinkTelegram(userID uint, authData models.TelegramAuthData) (*models.User, error)
+	// UnlinkTelegram отвязывает Telegram, если у пользователя остается вход по паролю
+	UnlinkTelegram(userID uint) (*models.User, error)
+	// SetPassword добавляет вход по email и паролю пользователю без пароля.
+	// Новый или измененный email нужно подтвердить
+	SetPassword(userID uint, email, password string) (*models.User, error)
+	// MergeUsers переносит способы входа, записи, отзывы и роли дубликата на основной аккаунт
+	// и удаляет дубликат. Дубликат с услугами, расписанием барбера или 2FA не объединяется
+	MergeUsers(primaryID, duplicateID uint) (*models.UserMergeResult, error)
+}
+
+// identityService реализация IdentityService
+type identityService struct {
+	userRepo    repositories.UserRepository
+	mergeRepo   repositories.UserMergeRepository
+	authService AuthService
+	revocation  TokenRevocationService
+}
+
+// NewIdentityService создает сервис управления способами входа
+func NewIdentityService(userRepo repositories.UserRepository, mergeRepo repositories.UserMergeRepository, authService AuthService, revocation TokenRevocationService) IdentityService {
+	return &identityService{
+		userRepo:    userRepo,
+		mergeRepo:   mergeRepo,
+		authService: authService,
+		revocation:  revocation,
+	}
+}
+
+// ListIdentities возвращает способы входа пользователя
+func (s *identityService) ListIdentities(userID uint) ([]models.UserIdentity, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	return user.Identities(), nil
+}
+
+// LinkTelegram привязывает Telegram; повторная привязка того же Telegram ничего не меняет
+func (s *identityService) LinkTelegram(userID uint, authData models.TelegramAuthData) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if user.TelegramID == authData.ID {
+		return user, nil
+	}
+	if user.HasTelegram() {
+		return nil, ErrTelegramAlreadyLinked
+	}
+	if owner, err := s.userRepo.GetByTelegramID(authData.ID); err == nil && owner.ID != user.ID {
+		return nil, ErrTelegramLinkedElsewhere
+	}
+
+	user.TelegramID = authData.ID
+	user.Username = authData.Username
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, fmt.Errorf("ошибка привязки Telegram: %v", err)
+	}
+	return user, nil
+}
+
+// UnlinkTelegram отвязывает Telegram от пользователя
+func (s *identityService) UnlinkTelegram(userID uint) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if !user.HasTelegram() {
+		return nil, ErrTelegramNotLinked
+	}
+	if !user.HasPassword() {
+		return nil, ErrLastIdentity
+	}
+
+	user.TelegramID = 0
+	user.Username = ""
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, fmt.Errorf("ошибка отвязки Telegram: %v", err)
+	}
+	return user, nil
+}
+
+// SetPassword задает email и пароль пользователю, который входил только через Telegram
+func (s *identityService) SetPassword(userID uint, email, password string) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if user.HasPassword() {
+		return nil, ErrPasswordAlreadySet
+	}
+
+	email = strings.TrimSpace(email)
+	if email == "" {
+		email = user.Email
+	}
+	if email == "" {
+		return nil, ErrEmailRequired
+	}
+	if email != user.Email {
+		if owner, err := s.userRepo.GetByEmail(email); err == nil && owner.ID != user.ID {
+			return nil, ErrEmailTaken
+		}
+		user.Email = email
+		user.EmailVerifiedAt = nil
+	}
+
+	if err := s.authService.ValidatePassword(password, email); err != nil {
+		return nil, err
+	}
+	passwordHash, err := s.authService.HashPassword(password)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка хеширования пароля: %v", err)
+	}
+	user.PasswordHash = passwordHash
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, fmt.Errorf("ошибка сохранения пароля: %v", err)
+	}
+	return user, nil
+}
+
+// MergeUsers объединяет дубликат с основным аккаунтом. Учетные данные дубликата переносятся,
+// только если у основного аккаунта такого способа входа нет; пароль основного аккаунта сохраняется
+func (s *identityService) MergeUsers(primaryID, duplicateID uint) (*models.UserMergeResult, error) {
+	if primaryID == duplicateID {
+		return nil, ErrMergeSameUser
+	}
+	primary, err := s.userRepo.GetByID(primaryID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	duplicate, err := s.userRepo.GetByID(duplicateID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	if duplicate.HasTelegram() {
+		if primary.HasTelegram() && primary.TelegramID != duplicate.TelegramID {
+			return nil, ErrMergeConflict
+		}
+		primary.TelegramID = duplicate.TelegramID
+		if primary.Username == "" {
+			primary.Username = duplicate.Username
+		}
+	}
+	if duplicate.HasPassword() && !primary.HasPassword() {
+		primary.Email = duplicate.Email
+		primary.PasswordHash = duplicate.PasswordHash
+		primary.EmailVerifiedAt = duplicate.EmailVerifiedAt
+	}
+	if primary.Email == "" {
+		primary.Email = duplicate.Email
+	}
+	if primary.Phone == "" {
+		primary.Phone = duplicate.Phone
+	}
+
+	result, err := s.mergeRepo.Merge(primary, duplicate.ID, time.Now())
+	switch {
+	case errors.Is(err, repositories.ErrMergeBarberData):
+		return nil, ErrMergeBarberData
+	case errors.Is(err, repositories.ErrMergeTwoFactor):
+		return nil, ErrMergeTwoFactor
+	case err != nil:
+		return nil, fmt.Errorf("ошибка объединения аккаунтов: %v", err)
+	}
+
+	// Дубликат удален, его access token больше не должны приниматься
+	if err := s.revocation.RevokeUserTokens(duplicate.ID); err != nil {
+		log.Printf("⚠️  Ошибка отзыва токенов пользователя %d: %v", duplicate.ID, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// LoginThrottlePolicy политика ограничения попыток входа по паролю для одного ключа (email или IP).
+// После FreeAttempts неудачных попыток каждая следующая доступна через удваивающуюся задержку,
+// после MaxAttempts вход блокируется на LockoutDuration. Счетчик сбрасывается через
+// LockoutDuration после последней неудачной попытки
+type LoginThrottlePolicy struct {
+	FreeAttempts    int
+	BaseDelay       time.Duration
+	MaxAttempts     int
+	LockoutDuration time.Duration
+}
+
+// Политики по умолчанию: с одного IP могут входить несколько пользователей, поэтому лимит выше
+var (
+	DefaultEmailLoginPolicy = LoginThrottlePolicy{FreeAttempts: 3, BaseDelay: time.Second, MaxAttempts: 10, LockoutDuration: 15 * time.Minute}
+	DefaultIPLoginPolicy    = LoginThrottlePolicy{FreeAttempts: 20, BaseDelay: time.Second, MaxAttempts: 100, LockoutDuration: 15 * time.Minute}
+)
+
+// retryAfter возвращает, сколько ждать до следующей попытки после failures неудач, последняя - в lastFailure
+func (p LoginThrottlePolicy) retryAfter(failures int, lastFailure, now time.Time) time.Duration {
+	delay := p.LockoutDuration
+	if failures < p.MaxAttempts {
+		if failures <= p.FreeAttempts {
+			return 0
+		}
+		// Сдвиг ограничен, чтобы задержка не переполнилась
+		if shift := failures - p.FreeAttempts - 1; shift < 30 {
+			delay = min(p.BaseDelay<<shift, p.LockoutDuration)
+		}
+	}
+
+	if wait := lastFailure.Add(delay).Sub(now); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// LoginThrottleService интерфейс защиты входа по паролю от подбора.
+// Неудачные попытки считаются отдельно по email и по IP адресу клиента
+type LoginThrottleService interface {
+	// Reserve проверяет счетчики и, если попытка разрешена, сразу учитывает ее как неудачную,
+	// чтобы параллельные запросы не проверили больше паролей, чем разрешает политика.
+	// Возвращает, сколько ждать до следующей попытки; 0 - попытка разрешена и учтена
+	Reserve(email, ip string) time.Duration
+	// Release возвращает учтенную попытку, если пароль так и не был проверен
+	Release(email, ip string)
+	// RecordSuccess сбрасывает счетчик email и возвращает попытку, учтенную для IP
+	RecordSuccess(email, ip string)
+	// Unlock снимает блокировку входа для email
+	Unlock(email string) error
+}
+
+// throttlePruneInterval как часто из памяти удаляются истекшие счетчики
+const throttlePruneInterval = time.Minute
+
+// throttleReserveRetries сколько раз повторяется резервирование, если счетчик в Redis изменился параллельно
+const throttleReserveRetries = 5
+
+// loginAttempts неудачные попытки входа по одному ключу
+type loginAttempts struct {
+	failures    int
+	lastFailure time.Time
+	expiresAt   time.Time // момент сброса счетчика
+}
+
+// attemptsCounter счетчик попыток входа и политика, по которой он проверяется
+type attemptsCounter struct {
+	key    string
+	policy LoginThrottlePolicy
+}
+
+// loginThrottleService реализация LoginThrottleService на Redis.
+// Без Redis (локальный запуск, тесты) счетчики хранятся в памяти процесса по той же политике
+type loginThrottleService struct {
+	rdb         *redis.Client
+	emailPolicy LoginThrottlePolicy
+	ipPolicy    LoginThrottlePolicy
+
+	mu        sync.Mutex
+	attempts  map[string]*loginAttempts // ключ счетчика -> неудачные попытки
+	nextPrune time.Time                 // когда удалять истекшие счетчики
+}
+
+// NewLoginThrottleService создает сервис защиты входа от подбора пароля; rdb может быть nil
+func NewLoginThrottleService(rdb *redis.Client, emailPolicy, ipPolicy LoginThrottlePolicy) LoginThrottleService {
+	return &loginThrottleService{
+		rdb:         rdb,
+		emailPolicy: emailPolicy,
+		ipPolicy:    ipPolicy,
+		attempts:    make(map[string]*loginAttempts),
+	}
+}
+
+// emailAttemptsKey ключ счетчика попыток входа по email
+func emailAttemptsKey(email string) string {
+	return "login_attempts:email:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// ipAttemptsKey ключ счетчика попыток входа с IP адреса
+func ipAttemptsKey(ip string) string {
+	return "login_attempts:ip:" + ip
+}
+
+// counters возвращает счетчики email и IP (без IP, если он неизвестен)
+func (s *loginThrottleService) counters(email, ip string) []attemptsCounter {
+	counters := []attemptsCounter{{key: emailAttemptsKey(email), policy: s.emailPolicy}}
+	if ip != "" {
+		counters = append(counters, attemptsCounter{key: ipAttemptsKey(ip), policy: s.ipPolicy})
+	}
+	return counters
+}
+
+// Reserve проверяет счетчики email и IP и учитывает попытку, только если оба ее разрешают
+func (s *loginThrottleService) Reserve(email, ip string) time.Duration {
+	counters := s.counters(email, ip)
+	now := time.Now()
+	if s.rdb != nil {
+		return s.reserveRedis(counters, now)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.prune(now)
+
+	var wait time.Duration
+	for _, counter := range counters {
+		failures, last := 0, time.Time{}
+		if attempts, ok := s.attempts[counter.key]; ok && now.Before(attempts.expiresAt) {
+			failures, last = attempts.failures, attempts.lastFailure
+		}
+		wait = max(wait, counter.policy.retryAfter(failures, last, now))
+	}
+	if wait > 0 {
+		return wait
+	}
+
+	for _, counter := range counters {
+		attempts, ok := s.attempts[counter.key]
+		if !ok || !now.Before(attempts.expiresAt) {
+			attempts = &loginAttempts{}
+			s.attempts[counter.key] = attempts
+		}
+		attempts.failures++
+		attempts.lastFailure = now
+		attempts.expiresAt = now.Add(counter.policy.LockoutDuration)
+	}
+	return 0
+}
+
+// reserveRedis проверяет и увеличивает счетчики в транзакции WATCH/MULTI: если другой запрос
+// изменил счетчик между чтением и записью, транзакция не применяется и повторяется.
+// При недоступности Redis вход не ограничивается, чтобы не блокировать всех пользователей
+func (s *loginThrottleService) reserveRedis(counters []attemptsCounter, now time.Time) time.Duration {
+	ctx := context.Background()
+	keys := make([]string, len(counters))
+	for i, counter := range counters {
+		keys[i] = counter.key
+	}
+
+	for i := 0; i < throttleReserveRetries; i++ {
+		var wait time.Duration
+		err := s.rdb.Watch(ctx, func(tx *redis.Tx) error {
+			for _, counter := range counters {
+				values, err := tx.HGetAll(ctx, counter.key).Result()
+				if err != nil {
+					return err
+				}
+				failures, _ := strconv.Atoi(values["failures"])
+				last, _ := strconv.ParseInt(values["last"], 10, 64)
+				wait = max(wait, counter.policy.retryAfter(failures, time.UnixMilli(last), now))
+			}
+			if wait > 0 {
+				return nil
+			}
+
+			_, err := tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				for _, counter := range counters {
+					pipe.HIncrBy(ctx, counter.key, "failures", 1)
+					pipe.HSet(ctx, counter.key, "last", now.UnixMilli())
+					pipe.Expire(ctx, counter.key, counter.policy.LockoutDuration)
+				}
+				return nil
+			})
+			return err
+		}, keys...)
+		if err == nil {
+			return wait
+		}
+		if !errors.Is(err, redis.TxFailedErr) {
+			log.Printf("⚠️  Ошибка записи счетчика попыток входа: %v", err)
+			return 0
+		}
+	}
+
+	// Счетчики непрерывно меняются параллельными попытками - так выглядит подбор
+	return s.emailPolicy.BaseDelay
+}
+
+// Release уменьшает счетчики email и IP на зарезервированную попытку
+func (s *loginThrottleService) Release(email, ip string) {
+	for _, counter := range s.counters(email, ip) {
+		s.decrement(counter.key)
+	}
+}
+
+// RecordSuccess сбрасывает счетчик email. Счетчик IP не сбрасывается, иначе вход
+// в собственный аккаунт позволял бы продолжать подбор чужих паролей, а только
+// уменьшается на попытку, учтенную при резервировании
+func (s *loginThrottleService) RecordSuccess(email, ip string) {
+	if err := s.Unlock(email); err != nil {
+		log.Printf("⚠️  Ошибка сброса счетчика попыток входа: %v", err)
+	}
+	if ip != "" {
+		s.decrement(ipAttemptsKey(ip))
+	}
+}
+
+// Unlock удаляет счетчик неудачных попыток email
+func (s *loginThrottleService) Unlock(email string) error {
+	key := emailAttemptsKey(email)
+	if s.rdb != nil {
+		return s.rdb.Del(context.Background(), key).Err()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.attempts, key)
+	return nil
+}
+
+// decrement возвращает зарезервированную попытку; истекший или удаленный счетчик не создается заново
+func (s *loginThrottleService) decrement(key string) {
+	if s.rdb != nil {
+		ctx := context.Background()
+		err := s.rdb.Watch(ctx, func(tx *redis.Tx) error {
+			failures, err := tx.HGet(ctx, key, "failures").Int()
+			if errors.Is(err, redis.Nil) || (err == nil && failures <= 0) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.HIncrBy(ctx, key, "failures", -1)
+				return nil
+			})
+			return err
+		}, key)
+		if err != nil {
+			log.Printf("⚠️  Ошибка записи счетчика попыток входа: %v", err)
+		}
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if attempts, ok := s.attempts[key]; ok && attempts.failures > 0 {
+		attempts.failures--
+	}
+}
+
+// prune удаляет из памяти истекшие счетчики не чаще раза в throttlePruneInterval
+func (s *loginThrottleService) prune(now time.Time) {
+	if now.Before(s.nextPrune) {
+		return
+	}
+	s.nextPrune = now.Add(throttlePruneInterval)
+	for key, attempts := range s.attempts {
+		if !now.Before(attempts.expiresAt) {
+			delete(s.attempts, key)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package services
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultPasswordMinLength минимальная длина пароля по умолчанию
+const DefaultPasswordMinLength = 8
+
+// ErrWeakPassword пароль не соответствует политике паролей
+var ErrWeakPassword = errors.New("пароль слишком простой")
+
+// commonPasswords встроенный список распространенных и утекших паролей
+//
+//go:embed common_passwords.txt
+var commonPasswords string
+
+// PasswordPolicy интерфейс политики паролей пользователей прямой авторизации
+type PasswordPolicy interface {
+	// Validate проверяет пароль пользователя с указанным email, ошибка оборачивает ErrWeakPassword
+	Validate(password, email string) error
+}
+
+// passwordPolicy реализация PasswordPolicy
+type passwordPolicy struct {
+	minLength int
+	blocklist map[string]bool
+}
+
+// NewPasswordPolicy создает политику паролей: не короче minLength символов,
+// не из встроенного списка распространенных паролей и extraBlocklist, не совпадает с email.
+// minLength <= 0 - DefaultPasswordMinLength
+func NewPasswordPolicy(minLength int, extraBlocklist []string) PasswordPolicy {
+	if minLength <= 0 {
+		minLength = DefaultPasswordMinLength
+	}
+
+	// Встроенный список читается из памяти, ошибок чтения быть не может
+	common, _ := parsePasswordList(strings.NewReader(commonPasswords))
+	blocklist := make(map[string]bool, len(common)+len(extraBlocklist))
+	for _, password := range common {
+		blocklist[password] = true
+	}
+	for _, password := range extraBlocklist {
+		blocklist[strings.ToLower(strings.TrimSpace(password))] = true
+	}
+
+	return &passwordPolicy{minLength: minLength, blocklist: blocklist}
+}
+
+// Validate проверяет пароль по политике
+func (p *passwordPolicy) Validate(password, email string) error {
+	if utf8.RuneCountInString(password) < p.minLength {
+		return fmt.Errorf("%w: минимум %d символов", ErrWeakPassword, p.minLength)
+	}
+
+	normalized := strings.ToLower(strings.TrimSpace(password))
+	if email != "" && normalized == strings.ToLower(strings.TrimSpace(email)) {
+		return fmt.Errorf("%w: пароль не должен совпадать с email", ErrWeakPassword)
+	}
+	if p.blocklist[normalized] {
+		return fmt.Errorf("%w: пароль входит в список распространенных или утекших паролей", ErrWeakPassword)
+	}
+	return nil
+}
+
+// LoadPasswordList читает список запрещенных паролей из файла: по одному на строку,
+// пустые строки и строки, начинающиеся с "#", пропускаются
+func LoadPasswordList(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения списка паролей: %v", err)
+	}
+	defer file.Close()
+
+	passwords, err := parsePasswordList(file)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения списка паролей: %v", err)
+	}
+	return passwords, nil
+}
+
+// parsePasswordList разбирает список паролей и приводит их к нижнему регистру
+func parsePasswordList(r io.Reader) ([]string, error) {
+	var passwords []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		passwords = append(passwords, strings.ToLower(line))
+	}
+	return passwords, scanner.Err()
+}
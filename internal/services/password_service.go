@@ -0,0 +1,210 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"garage-barbershop/internal/mailer"
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/repositories"
+)
+
+// PasswordResetTTL время действия ссылки сброса пароля
+const PasswordResetTTL = time.Hour
+
+// Ошибки управления паролем
+var (
+	ErrInvalidResetToken    = errors.New("ссылка для сброса пароля недействительна или устарела")
+	ErrWrongCurrentPassword = errors.New("неверный текущий пароль")
+)
+
+// PasswordService интерфейс смены и восстановления пароля пользователей с прямой авторизацией.
+// Новый пароль проверяется политикой паролей AuthService
+type PasswordService interface {
+	// RequestReset отправляет ссылку сброса пароля. Для неизвестного email ошибка не возвращается,
+	// чтобы по ответу нельзя было узнать, зарегистрирован ли адрес
+	RequestReset(email string) error
+	// ResetPassword устанавливает новый пароль по токену и завершает все сессии пользователя
+	ResetPassword(token, newPassword string) (*models.User, error)
+	// ChangePassword меняет пароль после проверки текущего и завершает все сессии пользователя
+	ChangePassword(userID uint, currentPassword, newPassword string) (*models.User, error)
+}
+
+// passwordService реализация PasswordService
+type passwordService struct {
+	userRepo    repositories.UserRepository
+	resetRepo   repositories.PasswordResetRepository
+	sessionRepo repositories.SessionRepository
+	authService AuthService
+	revocation  TokenRevocationService
+	mailer      mailer.Mailer
+	resetURL    string
+}
+
+// NewPasswordService создает сервис восстановления пароля.
+// resetURL - адрес страницы сброса пароля, токен добавляется к нему параметром token
+func NewPasswordService(userRepo repositories.UserRepository, resetRepo repositories.PasswordResetRepository, sessionRepo repositories.SessionRepository, authService AuthService, revocation TokenRevocationService, sender mailer.Mailer, resetURL string) PasswordService {
+	return &passwordService{
+		userRepo:    userRepo,
+		resetRepo:   resetRepo,
+		sessionRepo: sessionRepo,
+		authService: authService,
+		revocation:  revocation,
+		mailer:      sender,
+		resetURL:    resetURL,
+	}
+}
+
+// RequestReset создает одноразовый токен и отправляет ссылку на email пользователя
+func (s *passwordService) RequestReset(email string) error {
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil || !user.HasPassword() || !user.IsActive {
+		return nil
+	}
+
+	token, err := generateResetToken()
+	if err != nil {
+		return fmt.Errorf("ошибка генерации токена: %v", err)
+	}
+
+	// Действует только последняя отправленная ссылка
+	now := time.Now()
+	if err := s.resetRepo.InvalidateUserTokens(user.ID, now); err != nil {
+		return fmt.Errorf("ошибка отзыва прежних токенов: %v", err)
+	}
+	if err := s.resetRepo.Create(&models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: hashResetToken(token),
+		ExpiresAt: now.Add(PasswordResetTTL),
+	}); err != nil {
+		return fmt.Errorf("ошибка сохранения токена: %v", err)
+	}
+
+	return s.mailer.Send(mailer.Message{
+		To:      user.Email,
+		Subject: "Восстановление пароля",
+		Body: fmt.Sprintf(
+			"Здравствуйте, %s!\n\nЧтобы задать новый пароль, перейдите по ссылке:\n%s\n\nСсылка действует %d мин. Если вы не запрашивали сброс пароля, просто проигнорируйте это письмо.",
+			user.FirstName, s.resetLink(token), int(PasswordResetTTL.Minutes()),
+		),
+	})
+}
+
+// ResetPassword проверяет токен, меняет пароль и отзывает сессии и access token пользователя
+func (s *passwordService) ResetPassword(token, newPassword string) (*models.User, error) {
+	now := time.Now()
+	resetToken, err := s.resetRepo.GetByHash(hashResetToken(token))
+	if err != nil || !resetToken.IsUsable(now) {
+		return nil, ErrInvalidResetToken
+	}
+	user, err := s.userRepo.GetByID(resetToken.UserID)
+	if err != nil || !user.IsActive {
+		return nil, ErrInvalidResetToken
+	}
+	// Слабый пароль не расходует токен
+	if err := s.authService.ValidatePassword(newPassword, user.Email); err != nil {
+		return nil, err
+	}
+
+	// Токен одноразовый: из параллельных запросов пройдет только один
+	if err := s.resetRepo.MarkUsed(resetToken.ID, now); err != nil {
+		return nil, ErrInvalidResetToken
+	}
+
+	if err := s.setPassword(user, newPassword); err != nil {
+		return nil, err
+	}
+
+	s.endSessions(user.ID, now)
+	return user, nil
+}
+
+// ChangePassword проверяет текущий пароль, меняет его и отзывает сессии и access token пользователя
+func (s *passwordService) ChangePassword(userID uint, currentPassword, newPassword string) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("пользователь не найден: %v", err)
+	}
+	if !user.HasPassword() || !s.authService.CheckPassword(currentPassword, user.PasswordHash) {
+		return nil, ErrWrongCurrentPassword
+	}
+	if err := s.authService.ValidatePassword(newPassword, user.Email); err != nil {
+		return nil, err
+	}
+	if s.authService.CheckPassword(newPassword, user.PasswordHash) {
+		return nil, fmt.Errorf("%w: новый пароль совпадает с текущим", ErrWeakPassword)
+	}
+
+	if err := s.setPassword(user, newPassword); err != nil {
+		return nil, err
+	}
+
+	s.endSessions(user.ID, time.Now())
+	return user, nil
+}
+
+// setPassword хеширует и сохраняет новый пароль пользователя
+func (s *passwordService) setPassword(user *models.User, password string) error {
+	passwordHash, err := s.authService.HashPassword(password)
+	if err != nil {
+		return fmt.Errorf("ошибка хеширования пароля: %v", err)
+	}
+	user.PasswordHash = passwordHash
+	if err := s.userRepo.Update(user); err != nil {
+		return fmt.Errorf("ошибка сохранения пароля: %v", err)
+	}
+	return nil
+}
+
+// endSessions завершает все сессии пользователя и отзывает выданные access token.
+// Пароль уже изменен, поэтому ошибки только логируются
+func (s *passwordService) endSessions(userID uint, now time.Time) {
+	if _, err := s.sessionRepo.RevokeAll(userID, 0, models.SessionRevokedPassword, now); err != nil {
+		log.Printf("⚠️  Ошибка отзыва сессий пользователя %d: %v", userID, err)
+	}
+	if err := s.revocation.RevokeUserTokens(userID); err != nil {
+		log.Printf("⚠️  Ошибка отзыва токенов пользователя %d: %v", userID, err)
+	}
+	if err := s.resetRepo.InvalidateUserTokens(userID, now); err != nil {
+		log.Printf("⚠️  Ошибка отзыва токенов сброса пароля пользователя %d: %v", userID, err)
+	}
+}
+
+// resetLink формирует ссылку на страницу сброса пароля
+func (s *passwordService) resetLink(token string) string {
+	return tokenLink(s.resetURL, token)
+}
+
+// tokenLink добавляет токен к адресу страницы параметром token
+func tokenLink(base, token string) string {
+	link, err := url.Parse(base)
+	if err != nil {
+		return base + "?token=" + url.QueryEscape(token)
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String()
+}
+
+// generateResetToken генерирует случайный токен для ссылки
+func generateResetToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashResetToken возвращает хеш токена для хранения в БД
+func hashResetToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,389 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/payments"
+	"garage-barbershop/internal/repositories"
+)
+
+var (
+	// ErrPaymentNotFound возвращается, если платеж не найден или недоступен пользователю
+	ErrPaymentNotFound = errors.New("платеж не найден")
+	// ErrUnsupportedPaymentMethod возвращается для способа оплаты без подключенного провайдера
+	ErrUnsupportedPaymentMethod = errors.New("способ оплаты не поддерживается")
+	// ErrInvalidPaymentTransition возвращается при недопустимой смене статуса платежа
+	ErrInvalidPaymentTransition = errors.New("недопустимая смена статуса платежа")
+	// ErrAppointmentAlreadyPaid возвращается при попытке повторно оплатить запись
+	ErrAppointmentAlreadyPaid = errors.New("запись уже оплачена")
+)
+
+// PaymentService интерфейс для оплаты записей
+// Статус платежа и статус оплаты записи (Appointment.PaymentStatus) меняются только вместе
+type PaymentService interface {
+	// Действия клиента
+	PayForAppointment(clientID, appointmentID uint, req models.PaymentCreateRequest) (*models.Payment, error)
+	GetClientPayments(clientID, appointmentID uint) ([]models.Payment, error)
+
+	// Действия барбера
+	GetBarberPayments(barberID, appointmentID uint) ([]models.Payment, error)
+	CapturePayment(barberID, paymentID uint) (*models.Payment, error)
+	RefundPayment(barberID, paymentID uint) (*models.Payment, error)
+
+	// Действия администратора
+	AdminRefundPayment(paymentID uint) (*models.Payment, error)
+
+	// HandleWebhook обрабатывает уведомление платежной системы
+	HandleWebhook(method string, payload []byte, header http.Header) (*models.Payment, error)
+}
+
+// paymentService реализация PaymentService
+type paymentService struct {
+	paymentRepo     repositories.PaymentRepository
+	appointmentRepo repositories.AppointmentRepository
+	currency        string
+	providers       map[string]payments.Provider
+}
+
+// NewPaymentService создает новый экземпляр PaymentService с подключенными провайдерами
+func NewPaymentService(paymentRepo repositories.PaymentRepository, appointmentRepo repositories.AppointmentRepository, currency string, providers ...payments.Provider) PaymentService {
+	byMethod := make(map[string]payments.Provider, len(providers))
+	for _, provider := range providers {
+		byMethod[provider.Method()] = provider
+	}
+
+	return &paymentService{
+		paymentRepo:     paymentRepo,
+		appointmentRepo: appointmentRepo,
+		currency:        currency,
+		providers:       byMethod,
+	}
+}
+
+// PayForAppointment создает платеж по записи клиента
+func (s *paymentService) PayForAppointment(clientID, appointmentID uint, req models.PaymentCreateRequest) (*models.Payment, error) {
+	appointment, err := s.appointmentRepo.GetByID(appointmentID)
+	if err != nil || appointment.ClientID != clientID {
+		return nil, ErrAppointmentNotFound
+	}
+	if appointment.Status == models.AppointmentStatusCancelled || appointment.Status == models.AppointmentStatusNoShow {
+		return nil, fmt.Errorf("нельзя оплатить запись в статусе %s", appointment.Status)
+	}
+	if appointment.PaymentStatus == models.PaymentStatusPaid {
+		return nil, ErrAppointmentAlreadyPaid
+	}
+
+	provider, ok := s.providers[req.Method]
+	if !ok {
+		return nil, ErrUnsupportedPaymentMethod
+	}
+
+	payment, err := s.replacePending(appointmentID, provider)
+	if err != nil {
+		return nil, err
+	}
+	reused := payment != nil
+	if !reused {
+		payment = &models.Payment{
+			Amount:        appointment.Price,
+			Currency:      s.currency,
+			Status:        models.PaymentPending,
+			PaymentMethod: provider.Method(),
+			AppointmentID: appointment.ID,
+		}
+		if err := s.paymentRepo.Create(payment); err != nil {
+			return nil, fmt.Errorf("ошибка создания платежа: %v", err)
+		}
+	}
+
+	// Провайдеру нужны данные записи: клиент, услуга
+	payment.Appointment = *appointment
+	intent, err := provider.CreateIntent(payment)
+	if err != nil {
+		// Прежний счет повторно используемого платежа еще могут оплатить, поэтому он остается ожидать
+		if !reused {
+			payment.Status = models.PaymentFailed
+			s.paymentRepo.Save(payment, models.PaymentPending)
+		}
+		return nil, fmt.Errorf("ошибка создания платежа у провайдера: %v", err)
+	}
+
+	payment.ExternalID = intent.ExternalID
+	if err := s.paymentRepo.Save(payment, models.PaymentPending); err != nil {
+		return nil, fmt.Errorf("ошибка сохранения платежа: %v", err)
+	}
+	payment.PaymentURL = intent.PaymentURL
+
+	return payment, nil
+}
+
+// replacePending разбирается с незавершенными платежами записи перед новой оплатой, чтобы по записи
+// не прошло две оплаты. Платежи, которые провайдер может отменить, отменяются. Остальные клиент
+// еще может оплатить, поэтому платеж тем же способом возвращается для повторного использования,
+// а платежи другими способами остаются ожидать: лишнее списание будет помечено к возврату
+func (s *paymentService) replacePending(appointmentID uint, provider payments.Provider) (*models.Payment, error) {
+	existing, err := s.paymentRepo.GetByAppointmentID(appointmentID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения платежей: %v", err)
+	}
+
+	var reused *models.Payment
+	for i := range existing {
+		pending := &existing[i]
+		if pending.Status != models.PaymentPending {
+			continue
+		}
+
+		canceler, ok := s.providers[pending.PaymentMethod].(payments.Canceler)
+		if !ok {
+			if reused == nil && pending.PaymentMethod == provider.Method() {
+				reused = pending
+			}
+			continue
+		}
+		if err := canceler.Cancel(pending); err != nil {
+			return nil, fmt.Errorf("ошибка отмены платежа: %v", err)
+		}
+		pending.Status = models.PaymentFailed
+		err := s.paymentRepo.Save(pending, models.PaymentPending)
+		if err != nil && !errors.Is(err, repositories.ErrPaymentStatusChanged) {
+			return nil, fmt.Errorf("ошибка обновления платежа: %v", err)
+		}
+	}
+	return reused, nil
+}
+
+// GetClientPayments возвращает платежи по записи клиента
+func (s *paymentService) GetClientPayments(clientID, appointmentID uint) ([]models.Payment, error) {
+	appointment, err := s.appointmentRepo.GetByID(appointmentID)
+	if err != nil || appointment.ClientID != clientID {
+		return nil, ErrAppointmentNotFound
+	}
+	return s.paymentRepo.GetByAppointmentID(appointmentID)
+}
+
+// GetBarberPayments возвращает платежи по записи к барберу
+func (s *paymentService) GetBarberPayments(barberID, appointmentID uint) ([]models.Payment, error) {
+	appointment, err := s.appointmentRepo.GetByID(appointmentID)
+	if err != nil || appointment.BarberID != barberID {
+		return nil, ErrAppointmentNotFound
+	}
+	return s.paymentRepo.GetByAppointmentID(appointmentID)
+}
+
+// CapturePayment подтверждает получение денег барбером
+func (s *paymentService) CapturePayment(barberID, paymentID uint) (*models.Payment, error) {
+	payment, provider, err := s.barberPayment(barberID, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if !payment.CanTransitionTo(models.PaymentCompleted) {
+		return nil, fmt.Errorf("%w: %s → %s", ErrInvalidPaymentTransition, payment.Status, models.PaymentCompleted)
+	}
+	if err := s.ensureNotPaid(payment); err != nil {
+		return nil, err
+	}
+
+	result, err := provider.Capture(payment)
+	if errors.Is(err, payments.ErrCaptureNotSupported) {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPaymentTransition, err)
+	}
+	if err != nil {
+		payment.Status = models.PaymentFailed
+		s.paymentRepo.Save(payment, models.PaymentPending)
+		return nil, fmt.Errorf("ошибка списания: %v", err)
+	}
+
+	return s.applyStatus(payment, models.PaymentCompleted, result.ReceiptURL)
+}
+
+// RefundPayment возвращает деньги по записи барбера
+func (s *paymentService) RefundPayment(barberID, paymentID uint) (*models.Payment, error) {
+	payment, provider, err := s.barberPayment(barberID, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	return s.refund(payment, provider)
+}
+
+// AdminRefundPayment возвращает деньги по любому платежу
+func (s *paymentService) AdminRefundPayment(paymentID uint) (*models.Payment, error) {
+	payment, err := s.paymentRepo.GetByID(paymentID)
+	if err != nil {
+		return nil, ErrPaymentNotFound
+	}
+	provider, ok := s.providers[payment.PaymentMethod]
+	if !ok {
+		return nil, ErrUnsupportedPaymentMethod
+	}
+	appointment, err := s.appointmentRepo.GetByID(payment.AppointmentID)
+	if err != nil {
+		return nil, ErrAppointmentNotFound
+	}
+	payment.Appointment = *appointment
+	return s.refund(payment, provider)
+}
+
+// HandleWebhook проверяет уведомление провайдера и применяет новый статус платежа
+func (s *paymentService) HandleWebhook(method string, payload []byte, header http.Header) (*models.Payment, error) {
+	provider, ok := s.providers[method]
+	if !ok {
+		return nil, ErrUnsupportedPaymentMethod
+	}
+
+	event, err := provider.VerifyWebhook(payload, header)
+	if err != nil || event == nil {
+		return nil, err
+	}
+
+	payment, err := s.webhookPayment(method, event)
+	if err != nil {
+		return nil, err
+	}
+
+	if event.PreCheckoutID != "" {
+		return s.answerPreCheckout(payment, provider, event)
+	}
+
+	// Платежные системы повторяют уведомления, повтор не считается ошибкой
+	if payment.Status == event.Status {
+		return payment, nil
+	}
+	// Провайдер мог списать деньги по платежу, который мы уже считали неудачным: списание нужно записать
+	lateCompletion := payment.Status == models.PaymentFailed && event.Status == models.PaymentCompleted
+	if !payment.CanTransitionTo(event.Status) && !lateCompletion {
+		return nil, fmt.Errorf("%w: %s → %s", ErrInvalidPaymentTransition, payment.Status, event.Status)
+	}
+
+	if event.ExternalID != "" {
+		payment.ExternalID = event.ExternalID
+	}
+	return s.applyStatus(payment, event.Status, event.ReceiptURL)
+}
+
+// webhookPayment находит платеж из уведомления по нашему ID или по ID в платежной системе
+func (s *paymentService) webhookPayment(method string, event *payments.WebhookEvent) (*models.Payment, error) {
+	if event.PaymentID == 0 {
+		payment, err := s.paymentRepo.GetByExternalID(method, event.ExternalID)
+		if err != nil {
+			return nil, ErrPaymentNotFound
+		}
+		return payment, nil
+	}
+
+	payment, err := s.paymentRepo.GetByID(event.PaymentID)
+	if err != nil || payment.PaymentMethod != method {
+		return nil, ErrPaymentNotFound
+	}
+	return payment, nil
+}
+
+// answerPreCheckout проверяет, что платеж еще можно провести, и отвечает провайдеру.
+// Отказ доставляется клиенту провайдером, поэтому ошибкой обработки не считается
+func (s *paymentService) answerPreCheckout(payment *models.Payment, provider payments.Provider, event *payments.WebhookEvent) (*models.Payment, error) {
+	answerer, ok := provider.(payments.PreCheckoutAnswerer)
+	if !ok {
+		return nil, ErrUnsupportedPaymentMethod
+	}
+
+	reason := s.preCheckoutRejection(payment, event)
+	if err := answerer.AnswerPreCheckout(event, reason == "", reason); err != nil {
+		return nil, fmt.Errorf("ошибка ответа на запрос оплаты: %v", err)
+	}
+	return payment, nil
+}
+
+// preCheckoutRejection возвращает причину отказа в оплате или пустую строку
+func (s *paymentService) preCheckoutRejection(payment *models.Payment, event *payments.WebhookEvent) string {
+	if payment.Status != models.PaymentPending {
+		return "Счет больше не действителен, запросите новый"
+	}
+	if event.Currency != payment.Currency || math.Abs(event.Amount-payment.Amount) >= 0.005 {
+		return "Сумма счета изменилась, запросите новый"
+	}
+
+	appointment, err := s.appointmentRepo.GetByID(payment.AppointmentID)
+	if err != nil || appointment.Status == models.AppointmentStatusCancelled || appointment.Status == models.AppointmentStatusNoShow {
+		return "Запись отменена"
+	}
+	if appointment.PaymentStatus == models.PaymentStatusPaid {
+		return "Запись уже оплачена"
+	}
+	return ""
+}
+
+// refund проверяет статус и возвращает деньги через провайдера
+func (s *paymentService) refund(payment *models.Payment, provider payments.Provider) (*models.Payment, error) {
+	if !payment.CanTransitionTo(models.PaymentRefunded) {
+		return nil, fmt.Errorf("%w: %s → %s", ErrInvalidPaymentTransition, payment.Status, models.PaymentRefunded)
+	}
+
+	result, err := provider.Refund(payment)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка возврата: %v", err)
+	}
+
+	return s.applyStatus(payment, models.PaymentRefunded, result.ReceiptURL)
+}
+
+// applyStatus сохраняет новый статус платежа вместе со статусом оплаты записи.
+// Если статус платежа успели изменить параллельно, смена статуса отклоняется
+func (s *paymentService) applyStatus(payment *models.Payment, status, receiptURL string) (*models.Payment, error) {
+	from := payment.Status
+	payment.Status = status
+	if receiptURL != "" {
+		payment.ReceiptURL = receiptURL
+	}
+
+	err := s.paymentRepo.Save(payment, from)
+	if errors.Is(err, repositories.ErrPaymentStatusChanged) {
+		return nil, fmt.Errorf("%w: %s → %s", ErrInvalidPaymentTransition, from, status)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сохранения платежа: %v", err)
+	}
+	return payment, nil
+}
+
+// ensureNotPaid проверяет до списания, что по записи платежа еще не прошел другой платеж.
+// Параллельное проведение двух платежей ловит Save: второй платеж помечается к возврату
+func (s *paymentService) ensureNotPaid(payment *models.Payment) error {
+	if payment.Appointment.PaymentStatus == models.PaymentStatusPaid {
+		return ErrAppointmentAlreadyPaid
+	}
+
+	existing, err := s.paymentRepo.GetByAppointmentID(payment.AppointmentID)
+	if err != nil {
+		return fmt.Errorf("ошибка получения платежей: %v", err)
+	}
+	for _, other := range existing {
+		if other.ID != payment.ID && other.Status == models.PaymentCompleted {
+			return ErrAppointmentAlreadyPaid
+		}
+	}
+	return nil
+}
+
+// barberPayment находит платеж по записи барбера и его провайдера
+func (s *paymentService) barberPayment(barberID, paymentID uint) (*models.Payment, payments.Provider, error) {
+	payment, err := s.paymentRepo.GetByID(paymentID)
+	if err != nil {
+		return nil, nil, ErrPaymentNotFound
+	}
+
+	appointment, err := s.appointmentRepo.GetByID(payment.AppointmentID)
+	if err != nil || appointment.BarberID != barberID {
+		return nil, nil, ErrPaymentNotFound
+	}
+
+	provider, ok := s.providers[payment.PaymentMethod]
+	if !ok {
+		return nil, nil, ErrUnsupportedPaymentMethod
+	}
+	payment.Appointment = *appointment
+	return payment, provider, nil
+}
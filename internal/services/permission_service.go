@@ -0,0 +1,137 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/repositories"
+)
+
+// PermissionCacheTTL время жизни закешированного набора разрешений пользователя.
+// Изменения ролей в этом экземпляре сбрасывают кеш сразу, в остальных — не позже чем через TTL
+const PermissionCacheTTL = time.Minute
+
+// PermissionService интерфейс проверки разрешений по Role.Permissions
+type PermissionService interface {
+	// Can проверяет, может ли пользователь выполнить действие над ресурсом
+	Can(userID uint, resource, action string) bool
+	// GetUserPermissions возвращает объединенные разрешения активных ролей пользователя
+	GetUserPermissions(userID uint) (models.PermissionSet, error)
+	// SetRolePermissions заменяет разрешения роли
+	SetRolePermissions(roleID uint, permissions models.PermissionSet) (*models.Role, error)
+
+	// InvalidateUser сбрасывает кеш пользователя (после смены его ролей)
+	InvalidateUser(userID uint)
+	// InvalidateAll сбрасывает весь кеш (после изменения разрешений роли)
+	InvalidateAll()
+}
+
+// cachedPermissions закешированный набор разрешений пользователя
+type cachedPermissions struct {
+	permissions models.PermissionSet
+	expiresAt   time.Time
+}
+
+// permissionService реализация PermissionService
+type permissionService struct {
+	roleRepo repositories.RoleRepository
+	ttl      time.Duration
+
+	mu    sync.RWMutex
+	cache map[uint]cachedPermissions
+}
+
+// NewPermissionService создает новый экземпляр PermissionService
+func NewPermissionService(roleRepo repositories.RoleRepository) PermissionService {
+	return &permissionService{
+		roleRepo: roleRepo,
+		ttl:      PermissionCacheTTL,
+		cache:    make(map[uint]cachedPermissions),
+	}
+}
+
+// Can проверяет разрешение; при ошибке загрузки ролей доступ запрещается
+func (s *permissionService) Can(userID uint, resource, action string) bool {
+	permissions, err := s.GetUserPermissions(userID)
+	if err != nil {
+		log.Printf("⚠️  Ошибка загрузки разрешений пользователя %d: %v", userID, err)
+		return false
+	}
+	return permissions.Allows(resource, action)
+}
+
+// GetUserPermissions возвращает разрешения пользователя из кеша или из ролей
+func (s *permissionService) GetUserPermissions(userID uint) (models.PermissionSet, error) {
+	s.mu.RLock()
+	cached, ok := s.cache[userID]
+	s.mu.RUnlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.permissions, nil
+	}
+
+	roles, err := s.roleRepo.GetUserRoles(userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения ролей: %v", err)
+	}
+
+	permissions := models.PermissionSet{}
+	for _, role := range roles {
+		if !role.IsActive {
+			continue
+		}
+		rolePermissions, err := role.ParsedPermissions()
+		if err != nil {
+			// Испорченный JSON одной роли не должен лишать пользователя остальных прав
+			log.Printf("⚠️  Роль %s: %v", role.Name, err)
+			continue
+		}
+		permissions.Merge(rolePermissions)
+	}
+
+	s.mu.Lock()
+	s.cache[userID] = cachedPermissions{permissions: permissions, expiresAt: time.Now().Add(s.ttl)}
+	s.mu.Unlock()
+
+	return permissions, nil
+}
+
+// SetRolePermissions проверяет и сохраняет новые разрешения роли
+func (s *permissionService) SetRolePermissions(roleID uint, permissions models.PermissionSet) (*models.Role, error) {
+	role, err := s.roleRepo.GetRoleByID(roleID)
+	if err != nil {
+		return nil, ErrRoleNotFound
+	}
+
+	normalized, err := permissions.Normalize()
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureAdminKeepsRoleAccess(role, normalized); err != nil {
+		return nil, err
+	}
+
+	role.Permissions = normalized.JSON()
+	if err := s.roleRepo.UpdateRole(role); err != nil {
+		return nil, fmt.Errorf("ошибка сохранения разрешений: %v", err)
+	}
+
+	s.InvalidateAll()
+	return role, nil
+}
+
+// InvalidateUser сбрасывает кеш пользователя
+func (s *permissionService) InvalidateUser(userID uint) {
+	s.mu.Lock()
+	delete(s.cache, userID)
+	s.mu.Unlock()
+}
+
+// InvalidateAll сбрасывает весь кеш
+func (s *permissionService) InvalidateAll() {
+	s.mu.Lock()
+	s.cache = make(map[uint]cachedPermissions)
+	s.mu.Unlock()
+}
@@ -0,0 +1,210 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/repositories"
+)
+
+var (
+	// ErrReviewNotFound возвращается, если отзыв не найден или недоступен пользователю
+	ErrReviewNotFound = errors.New("отзыв не найден")
+	// ErrReviewAlreadyExists возвращается при попытке оставить второй отзыв на запись
+	ErrReviewAlreadyExists = errors.New("отзыв на эту запись уже оставлен")
+	// ErrAppointmentNotCompleted возвращается при попытке оценить незавершенную запись
+	ErrAppointmentNotCompleted = errors.New("оставить отзыв можно только после завершения записи")
+)
+
+// MaxReviewCommentLength максимальная длина комментария в символах
+const MaxReviewCommentLength = 2000
+
+// ReviewService интерфейс для работы с отзывами.
+// Рейтинг барбера пересчитывается при каждом изменении его отзывов
+type ReviewService interface {
+	// Действия клиента
+	CreateReview(clientID, appointmentID uint, req models.ReviewRequest) (*models.Review, error)
+	GetClientReview(clientID, appointmentID uint) (*models.Review, error)
+	UpdateReview(clientID, appointmentID uint, req models.ReviewRequest) (*models.Review, error)
+	DeleteReview(clientID, appointmentID uint) error
+
+	// Публичный список отзывов барбера
+	GetBarberReviews(barberID uint, filter models.ReviewFilter) ([]models.PublicReview, int64, error)
+
+	// Действия администратора
+	ListReviews(filter models.ReviewFilter) ([]models.Review, int64, error)
+	HideReview(reviewID uint, req models.ReviewHideRequest) (*models.Review, error)
+	UnhideReview(reviewID uint) (*models.Review, error)
+}
+
+// reviewService реализация ReviewService
+type reviewService struct {
+	reviewRepo      repositories.ReviewRepository
+	appointmentRepo repositories.AppointmentRepository
+	roleRepo        repositories.RoleRepository
+}
+
+// NewReviewService создает новый экземпляр ReviewService
+func NewReviewService(reviewRepo repositories.ReviewRepository, appointmentRepo repositories.AppointmentRepository, roleRepo repositories.RoleRepository) ReviewService {
+	return &reviewService{
+		reviewRepo:      reviewRepo,
+		appointmentRepo: appointmentRepo,
+		roleRepo:        roleRepo,
+	}
+}
+
+// CreateReview оставляет отзыв на завершенную запись клиента
+func (s *reviewService) CreateReview(clientID, appointmentID uint, req models.ReviewRequest) (*models.Review, error) {
+	appointment, err := s.appointmentRepo.GetByID(appointmentID)
+	if err != nil || appointment.ClientID != clientID {
+		return nil, ErrAppointmentNotFound
+	}
+	if appointment.Status != models.AppointmentStatusCompleted {
+		return nil, ErrAppointmentNotCompleted
+	}
+	if _, err := s.reviewRepo.GetByAppointmentID(appointmentID); err == nil {
+		return nil, ErrReviewAlreadyExists
+	}
+
+	review := &models.Review{
+		ClientID:      clientID,
+		BarberID:      appointment.BarberID,
+		AppointmentID: appointment.ID,
+	}
+	if err := applyReviewRequest(review, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.reviewRepo.Create(review); err != nil {
+		// Уникальный индекс ловит одновременную отправку двух отзывов
+		if _, getErr := s.reviewRepo.GetByAppointmentID(appointmentID); getErr == nil {
+			return nil, ErrReviewAlreadyExists
+		}
+		return nil, fmt.Errorf("ошибка создания отзыва: %v", err)
+	}
+	return review, nil
+}
+
+// GetClientReview возвращает отзыв клиента на его запись
+func (s *reviewService) GetClientReview(clientID, appointmentID uint) (*models.Review, error) {
+	review, err := s.reviewRepo.GetByAppointmentID(appointmentID)
+	if err != nil || review.ClientID != clientID {
+		return nil, ErrReviewNotFound
+	}
+	return review, nil
+}
+
+// UpdateReview изменяет оценку и комментарий отзыва клиента
+func (s *reviewService) UpdateReview(clientID, appointmentID uint, req models.ReviewRequest) (*models.Review, error) {
+	review, err := s.GetClientReview(clientID, appointmentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyReviewRequest(review, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.reviewRepo.Save(review); err != nil {
+		return nil, fmt.Errorf("ошибка обновления отзыва: %v", err)
+	}
+	return review, nil
+}
+
+// DeleteReview удаляет отзыв клиента
+func (s *reviewService) DeleteReview(clientID, appointmentID uint) error {
+	review, err := s.GetClientReview(clientID, appointmentID)
+	if err != nil {
+		return err
+	}
+	if err := s.reviewRepo.Delete(review); err != nil {
+		return fmt.Errorf("ошибка удаления отзыва: %v", err)
+	}
+	return nil
+}
+
+// GetBarberReviews возвращает страницу видимых отзывов барбера
+func (s *reviewService) GetBarberReviews(barberID uint, filter models.ReviewFilter) ([]models.PublicReview, int64, error) {
+	if !s.roleRepo.HasUserRole(barberID, "barber") {
+		return nil, 0, ErrNotBarber
+	}
+
+	filter.BarberID = barberID
+	filter.IncludeHidden = false
+	filter.Normalize()
+
+	reviews, total, err := s.reviewRepo.List(filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ошибка получения отзывов: %v", err)
+	}
+
+	public := make([]models.PublicReview, 0, len(reviews))
+	for _, review := range reviews {
+		public = append(public, models.NewPublicReview(review))
+	}
+	return public, total, nil
+}
+
+// ListReviews возвращает страницу отзывов для модерации, включая скрытые
+func (s *reviewService) ListReviews(filter models.ReviewFilter) ([]models.Review, int64, error) {
+	filter.Normalize()
+	reviews, total, err := s.reviewRepo.List(filter)
+	if err != nil {
+		return nil, 0, fmt.Errorf("ошибка получения отзывов: %v", err)
+	}
+	return reviews, total, nil
+}
+
+// HideReview скрывает отзыв, не удаляя его
+func (s *reviewService) HideReview(reviewID uint, req models.ReviewHideRequest) (*models.Review, error) {
+	review, err := s.reviewRepo.GetByID(reviewID)
+	if err != nil {
+		return nil, ErrReviewNotFound
+	}
+
+	now := time.Now().UTC()
+	review.IsHidden = true
+	review.HiddenReason = strings.TrimSpace(req.Reason)
+	review.HiddenAt = &now
+
+	if err := s.reviewRepo.Save(review); err != nil {
+		return nil, fmt.Errorf("ошибка скрытия отзыва: %v", err)
+	}
+	return review, nil
+}
+
+// UnhideReview возвращает скрытый отзыв в публичный список
+func (s *reviewService) UnhideReview(reviewID uint) (*models.Review, error) {
+	review, err := s.reviewRepo.GetByID(reviewID)
+	if err != nil {
+		return nil, ErrReviewNotFound
+	}
+
+	review.IsHidden = false
+	review.HiddenReason = ""
+	review.HiddenAt = nil
+
+	if err := s.reviewRepo.Save(review); err != nil {
+		return nil, fmt.Errorf("ошибка обновления отзыва: %v", err)
+	}
+	return review, nil
+}
+
+// applyReviewRequest проверяет и переносит оценку и комментарий в отзыв
+func applyReviewRequest(review *models.Review, req models.ReviewRequest) error {
+	if req.Rating < models.MinReviewRating || req.Rating > models.MaxReviewRating {
+		return fmt.Errorf("оценка должна быть от %d до %d", models.MinReviewRating, models.MaxReviewRating)
+	}
+
+	comment := strings.TrimSpace(req.Comment)
+	if utf8.RuneCountInString(comment) > MaxReviewCommentLength {
+		return fmt.Errorf("комментарий длиннее %d символов", MaxReviewCommentLength)
+	}
+
+	review.Rating = req.Rating
+	review.Comment = comment
+	return nil
+}
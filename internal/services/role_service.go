@@ -1,12 +1,37 @@
 package services
 
 import (
+	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 
 	"garage-barbershop/internal/models"
 	"garage-barbershop/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrRoleNotFound возвращается, если роль не найдена
+	ErrRoleNotFound = errors.New("роль не найдена")
+	// ErrProtectedRole возвращается при попытке удалить, деактивировать или переименовать системную роль
+	ErrProtectedRole = errors.New("системную роль нельзя удалить или деактивировать")
+	// ErrLastAdmin возвращается при попытке снять роль с последнего администратора
+	ErrLastAdmin = errors.New("нельзя снять роль с последнего администратора")
+	// ErrRoleExists возвращается при создании роли с занятым именем
+	ErrRoleExists = errors.New("роль с таким именем уже существует")
+	// ErrRoleAlreadyAssigned возвращается при повторном назначении роли
+	ErrRoleAlreadyAssigned = errors.New("роль уже назначена пользователю")
+	// ErrRoleNotAssigned возвращается при снятии роли, которой у пользователя нет
+	ErrRoleNotAssigned = errors.New("роль не назначена пользователю")
+	// ErrUserNotFound возвращается, если пользователь не найден
+	ErrUserNotFound = errors.New("пользователь не найден")
 )
 
+// roleNamePattern допустимое имя роли: латиница в нижнем регистре, цифры и подчеркивание
+var roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+
 // RoleService интерфейс для управления ролями
 type RoleService interface {
 	// Управление ролями
@@ -26,6 +51,13 @@ type RoleService interface {
 	GetUserWithRoles(userID uint) (*models.UserWithRoles, error)
 	GetAllUsersWithRoles() ([]models.UserWithRoles, error)
 
+	// Администрирование ролей
+	CreateRoleFromRequest(req models.RoleCreateRequest) (*models.Role, error)
+	UpdateRoleFromRequest(roleID uint, req models.RoleUpdateRequest) (*models.Role, error)
+	DeactivateRole(roleID uint) (*models.Role, error)
+	AssignRoleWithReason(req models.RoleAssignmentRequest, assignedBy uint) (*models.UserRole, error)
+	RevokeRoleWithReason(req models.RoleRemovalRequest, revokedBy uint) error
+
 	// Проверка разрешений
 	HasAnyRole(userID uint, roleNames ...string) bool
 	HasAllRoles(userID uint, roleNames ...string) bool
@@ -69,8 +101,15 @@ func (s *roleService) UpdateRole(role *models.Role) error {
 	return s.roleRepo.UpdateRole(role)
 }
 
-// DeleteRole удаляет роль
+// DeleteRole удаляет роль; системные роли удалить нельзя
 func (s *roleService) DeleteRole(id uint) error {
+	role, err := s.roleRepo.GetRoleByID(id)
+	if err != nil {
+		return ErrRoleNotFound
+	}
+	if role.IsProtected() {
+		return ErrProtectedRole
+	}
 	return s.roleRepo.DeleteRole(id)
 }
 
@@ -88,9 +127,17 @@ func (s *roleService) AssignRoleToUser(userID, roleID uint, assignedBy uint) err
 	return s.roleRepo.AssignRoleToUser(userID, roleID, assignedBy)
 }
 
-// RemoveRoleFromUser снимает роль с пользователя
+// RemoveRoleFromUser снимает роль с пользователя; последнего администратора оставить без роли нельзя
 func (s *roleService) RemoveRoleFromUser(userID, roleID uint) error {
-	return s.roleRepo.RemoveRoleFromUser(userID, roleID)
+	keepLast, err := s.isAdminRole(roleID)
+	if err != nil {
+		return err
+	}
+	err = s.roleRepo.RemoveRoleFromUser(userID, roleID, keepLast)
+	if errors.Is(err, repositories.ErrLastRoleHolder) {
+		return ErrLastAdmin
+	}
+	return err
 }
 
 // GetUserRoles получает роли пользователя
@@ -152,3 +199,141 @@ func (s *roleService) IsBarber(userID uint) bool {
 func (s *roleService) IsClient(userID uint) bool {
 	return s.roleRepo.HasUserRole(userID, "client")
 }
+
+// CreateRoleFromRequest создает новую роль администратором
+func (s *roleService) CreateRoleFromRequest(req models.RoleCreateRequest) (*models.Role, error) {
+	name := strings.ToLower(strings.TrimSpace(req.Name))
+	if !roleNamePattern.MatchString(name) {
+		return nil, fmt.Errorf("имя роли должно состоять из латинских букв, цифр и _ (2-50 символов)")
+	}
+	if _, err := s.roleRepo.GetRoleByName(name); err == nil {
+		return nil, ErrRoleExists
+	}
+
+	permissions, err := req.Permissions.Normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	role := &models.Role{
+		Name:        name,
+		DisplayName: strings.TrimSpace(req.DisplayName),
+		Description: strings.TrimSpace(req.Description),
+		IsActive:    true,
+		Permissions: permissions.JSON(),
+	}
+	if role.DisplayName == "" {
+		role.DisplayName = name
+	}
+
+	if err := s.roleRepo.CreateRole(role); err != nil {
+		return nil, fmt.Errorf("ошибка создания роли: %v", err)
+	}
+	return role, nil
+}
+
+// UpdateRoleFromRequest изменяет описание, активность и разрешения роли
+func (s *roleService) UpdateRoleFromRequest(roleID uint, req models.RoleUpdateRequest) (*models.Role, error) {
+	role, err := s.roleRepo.GetRoleByID(roleID)
+	if err != nil {
+		return nil, ErrRoleNotFound
+	}
+
+	if req.DisplayName != nil {
+		role.DisplayName = strings.TrimSpace(*req.DisplayName)
+	}
+	if req.Description != nil {
+		role.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.IsActive != nil {
+		if !*req.IsActive && role.IsProtected() {
+			return nil, ErrProtectedRole
+		}
+		role.IsActive = *req.IsActive
+	}
+	if req.Permissions != nil {
+		permissions, err := req.Permissions.Normalize()
+		if err != nil {
+			return nil, err
+		}
+		if err := ensureAdminKeepsRoleAccess(role, permissions); err != nil {
+			return nil, err
+		}
+		role.Permissions = permissions.JSON()
+	}
+
+	if err := s.roleRepo.UpdateRole(role); err != nil {
+		return nil, fmt.Errorf("ошибка обновления роли: %v", err)
+	}
+	return role, nil
+}
+
+// DeactivateRole отключает роль, не удаляя ее и назначения
+func (s *roleService) DeactivateRole(roleID uint) (*models.Role, error) {
+	inactive := false
+	return s.UpdateRoleFromRequest(roleID, models.RoleUpdateRequest{IsActive: &inactive})
+}
+
+// AssignRoleWithReason назначает роль с указанием, кто и почему ее назначил
+func (s *roleService) AssignRoleWithReason(req models.RoleAssignmentRequest, assignedBy uint) (*models.UserRole, error) {
+	role, err := s.roleRepo.GetRoleByID(req.RoleID)
+	if err != nil {
+		return nil, ErrRoleNotFound
+	}
+	if !role.IsActive {
+		return nil, fmt.Errorf("роль %s деактивирована", role.Name)
+	}
+	if _, err := s.roleRepo.GetUserWithRoles(req.UserID); err != nil {
+		return nil, ErrUserNotFound
+	}
+	if s.roleRepo.HasUserRole(req.UserID, role.Name) {
+		return nil, ErrRoleAlreadyAssigned
+	}
+
+	userRole := &models.UserRole{
+		UserID:     req.UserID,
+		RoleID:     role.ID,
+		AssignedBy: assignedBy,
+		Reason:     strings.TrimSpace(req.Reason),
+	}
+	if err := s.roleRepo.AssignRole(userRole); err != nil {
+		return nil, fmt.Errorf("ошибка назначения роли: %v", err)
+	}
+	return userRole, nil
+}
+
+// RevokeRoleWithReason снимает роль с указанием, кто и почему ее снял
+func (s *roleService) RevokeRoleWithReason(req models.RoleRemovalRequest, revokedBy uint) error {
+	keepLast, err := s.isAdminRole(req.RoleID)
+	if err != nil {
+		return err
+	}
+
+	err = s.roleRepo.RevokeRole(req.UserID, req.RoleID, revokedBy, strings.TrimSpace(req.Reason), keepLast)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrRoleNotAssigned
+	case errors.Is(err, repositories.ErrLastRoleHolder):
+		return ErrLastAdmin
+	case err != nil:
+		return fmt.Errorf("ошибка снятия роли: %v", err)
+	}
+	return nil
+}
+
+// ensureAdminKeepsRoleAccess не дает отнять у роли admin управление ролями, иначе вернуть права будет некому
+func ensureAdminKeepsRoleAccess(role *models.Role, permissions models.PermissionSet) error {
+	if role.Name == "admin" && !permissions.Allows("roles", models.ActionUpdate) {
+		return fmt.Errorf("%w: роль admin должна сохранять право roles:update", ErrProtectedRole)
+	}
+	return nil
+}
+
+// isAdminRole проверяет, что роль - admin: ее нельзя снять с единственного активного администратора
+func (s *roleService) isAdminRole(roleID uint) (bool, error) {
+	role, err := s.roleRepo.GetRoleByID(roleID)
+	if err != nil {
+		return false, ErrRoleNotFound
+	}
+	return role.Name == "admin", nil
+}
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/repositories"
+)
+
+// MaxScheduleExceptionDays максимальная длина одного исключения из расписания
+const MaxScheduleExceptionDays = 366
+
+// ErrScheduleExceptionNotFound возвращается, если исключение не найдено или принадлежит другому барберу
+var ErrScheduleExceptionNotFound = errors.New("исключение из расписания не найдено")
+
+// ErrScheduleConflict возвращается, если на закрываемое время уже есть записи
+var ErrScheduleConflict = errors.New("на это время уже есть записи")
+
+// ErrScheduleExceptionOverlap возвращается, если исключение пересекается с другим исключением барбера
+var ErrScheduleExceptionOverlap = errors.New("исключение пересекается с другим исключением из расписания")
+
+// ScheduleExceptionService интерфейс для управления отпусками, выходными и дополнительными рабочими днями
+type ScheduleExceptionService interface {
+	// CreateException создает исключение; если оно задевает записи и не указан Force,
+	// возвращает ErrScheduleConflict вместе со списком затронутых записей.
+	// Пересекающееся с другим исключение отклоняется с ErrScheduleExceptionOverlap
+	CreateException(barberID uint, req models.ScheduleExceptionRequest) (*models.ScheduleExceptionResult, error)
+	// GetExceptions возвращает исключения в периоде дат; пустые from/to означают "с сегодняшнего дня" и "без ограничения"
+	GetExceptions(barberID uint, from, to string) ([]models.ScheduleException, error)
+	DeleteException(barberID, exceptionID uint) error
+}
+
+// scheduleExceptionService реализация ScheduleExceptionService
+type scheduleExceptionService struct {
+	exceptionRepo   repositories.ScheduleExceptionRepository
+	roleRepo        repositories.RoleRepository
+	timezoneService TimezoneService
+}
+
+// NewScheduleExceptionService создает новый экземпляр ScheduleExceptionService
+func NewScheduleExceptionService(exceptionRepo repositories.ScheduleExceptionRepository, roleRepo repositories.RoleRepository, timezoneService TimezoneService) ScheduleExceptionService {
+	return &scheduleExceptionService{
+		exceptionRepo:   exceptionRepo,
+		roleRepo:        roleRepo,
+		timezoneService: timezoneService,
+	}
+}
+
+// CreateException проверяет исключение, ищет затронутые записи и сохраняет его.
+// Проверки выполняются под блокировкой барбера вместе с сохранением, как и при бронировании
+func (s *scheduleExceptionService) CreateException(barberID uint, req models.ScheduleExceptionRequest) (*models.ScheduleExceptionResult, error) {
+	if !s.roleRepo.HasUserRole(barberID, "barber") {
+		return nil, ErrNotBarber
+	}
+
+	loc := s.timezoneService.BarberLocation(barberID)
+	exception, err := buildScheduleException(barberID, req, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ScheduleExceptionResult{AffectedAppointments: []models.Appointment{}}
+	err = s.exceptionRepo.Create(exception, func(appointments []models.Appointment, exceptions []models.ScheduleException) error {
+		for i := range exceptions {
+			if exceptions[i].Overlaps(exception) {
+				return ErrScheduleExceptionOverlap
+			}
+		}
+		if !exception.IsTimeOff() {
+			return nil
+		}
+
+		result.AffectedAppointments = affectedAppointments(exception, appointments, loc)
+		if len(result.AffectedAppointments) > 0 && !req.Force {
+			return ErrScheduleConflict
+		}
+		return nil
+	})
+	switch {
+	case errors.Is(err, ErrScheduleConflict):
+		return result, err
+	case errors.Is(err, ErrScheduleExceptionOverlap):
+		return nil, err
+	case err != nil:
+		return nil, fmt.Errorf("ошибка создания исключения: %v", err)
+	}
+	result.Exception = exception
+
+	return result, nil
+}
+
+// GetExceptions возвращает исключения барбера
+func (s *scheduleExceptionService) GetExceptions(barberID uint, from, to string) ([]models.ScheduleException, error) {
+	if !s.roleRepo.HasUserRole(barberID, "barber") {
+		return nil, ErrNotBarber
+	}
+
+	if from == "" {
+		from = time.Now().In(s.timezoneService.BarberLocation(barberID)).Format(models.DateLayout)
+	}
+	if to == "" {
+		to = "9999-12-31"
+	}
+	for _, value := range []string{from, to} {
+		if _, err := time.Parse(models.DateLayout, value); err != nil {
+			return nil, fmt.Errorf("неверный формат даты %q, ожидается YYYY-MM-DD", value)
+		}
+	}
+
+	exceptions, err := s.exceptionRepo.GetByBarberInRange(barberID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения исключений: %v", err)
+	}
+	return exceptions, nil
+}
+
+// DeleteException удаляет исключение барбера
+func (s *scheduleExceptionService) DeleteException(barberID, exceptionID uint) error {
+	exception, err := s.exceptionRepo.GetByID(exceptionID)
+	if err != nil || exception.BarberID != barberID {
+		return ErrScheduleExceptionNotFound
+	}
+
+	if err := s.exceptionRepo.Delete(exception.ID); err != nil {
+		return fmt.Errorf("ошибка удаления исключения: %v", err)
+	}
+	return nil
+}
+
+// affectedAppointments отбирает из активных записей те, что попадают в закрываемое время
+func affectedAppointments(exception *models.ScheduleException, appointments []models.Appointment, loc *time.Location) []models.Appointment {
+	exceptions := []models.ScheduleException{*exception}
+	affected := []models.Appointment{}
+	for _, appointment := range appointments {
+		if timeOffOverlaps(exceptions, appointment.DateTime.In(loc), appointment.EndTime().In(loc)) {
+			appointment.Localize(loc)
+			affected = append(affected, appointment)
+		}
+	}
+	return affected
+}
+
+// buildScheduleException проверяет запрос и собирает исключение; даты понимаются в часовом поясе барбера
+func buildScheduleException(barberID uint, req models.ScheduleExceptionRequest, loc *time.Location) (*models.ScheduleException, error) {
+	exception := &models.ScheduleException{
+		Type:      req.Type,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+		IsFullDay: req.IsFullDay,
+		Reason:    strings.TrimSpace(req.Reason),
+		BarberID:  barberID,
+	}
+	if exception.Type == "" {
+		exception.Type = models.ScheduleExceptionTimeOff
+	}
+	if exception.EndDate == "" {
+		exception.EndDate = exception.StartDate
+	}
+
+	switch exception.Type {
+	case models.ScheduleExceptionTimeOff:
+	case models.ScheduleExceptionExtraWorkingDay:
+		// Дополнительный рабочий день всегда задается временем работы
+		exception.IsFullDay = false
+	default:
+		return nil, fmt.Errorf("неизвестный тип исключения %q", exception.Type)
+	}
+
+	startDate, err := time.Parse(models.DateLayout, exception.StartDate)
+	if err != nil {
+		return nil, fmt.Errorf("неверный формат start_date, ожидается YYYY-MM-DD")
+	}
+	endDate, err := time.Parse(models.DateLayout, exception.EndDate)
+	if err != nil {
+		return nil, fmt.Errorf("неверный формат end_date, ожидается YYYY-MM-DD")
+	}
+	if endDate.Before(startDate) {
+		return nil, fmt.Errorf("дата окончания раньше даты начала")
+	}
+	if endDate.Sub(startDate) >= MaxScheduleExceptionDays*24*time.Hour {
+		return nil, fmt.Errorf("исключение не может быть длиннее %d дней", MaxScheduleExceptionDays)
+	}
+	if exception.EndDate < time.Now().In(loc).Format(models.DateLayout) {
+		return nil, fmt.Errorf("нельзя создать исключение в прошлом")
+	}
+
+	if exception.IsFullDay {
+		return exception, nil
+	}
+
+	start, err := models.ParseClockTime(req.StartTime)
+	if err != nil {
+		return nil, err
+	}
+	end, err := models.ParseClockTime(req.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	if start >= end {
+		return nil, fmt.Errorf("время начала должно быть раньше времени окончания")
+	}
+	exception.StartTime = models.FormatClockTime(start)
+	exception.EndTime = models.FormatClockTime(end)
+
+	return exception, nil
+}
+
+// timeOffOverlaps проверяет, пересекается ли интервал с нерабочим временем из исключений
+func timeOffOverlaps(exceptions []models.ScheduleException, start, end time.Time) bool {
+	for i := range exceptions {
+		if !exceptions[i].IsTimeOff() {
+			continue
+		}
+		for day := startOfDay(start); day.Before(end); day = day.AddDate(0, 0, 1) {
+			if !exceptions[i].CoversDate(day) {
+				continue
+			}
+			offStart, offEnd, err := exceptions[i].IntervalOn(day)
+			if err != nil {
+				continue
+			}
+			if start.Before(offEnd) && end.After(offStart) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/repositories"
+)
+
+// ErrServiceNotFound возвращается, если услуга не найдена или принадлежит другому барберу
+var ErrServiceNotFound = errors.New("услуга не найдена")
+
+// ServiceCatalogService интерфейс для управления каталогом услуг
+type ServiceCatalogService interface {
+	// Управление собственными услугами барбера
+	CreateService(barberID uint, req models.ServiceCreateRequest) (*models.Service, error)
+	UpdateService(barberID, serviceID uint, req models.ServiceUpdateRequest) (*models.Service, error)
+	DeleteService(barberID, serviceID uint) error
+	GetBarberServices(barberID uint) ([]models.Service, error)
+
+	// Публичный каталог
+	ListServices(filter models.ServiceFilter) ([]models.Service, error)
+	GetService(serviceID uint) (*models.Service, error)
+
+	// ResolveService возвращает услугу, даже если она деактивирована или удалена
+	ResolveService(serviceID uint) (*models.Service, error)
+}
+
+// serviceCatalogService реализация ServiceCatalogService
+type serviceCatalogService struct {
+	serviceRepo repositories.ServiceRepository
+	roleRepo    repositories.RoleRepository
+}
+
+// NewServiceCatalogService создает новый экземпляр ServiceCatalogService
+func NewServiceCatalogService(serviceRepo repositories.ServiceRepository, roleRepo repositories.RoleRepository) ServiceCatalogService {
+	return &serviceCatalogService{serviceRepo: serviceRepo, roleRepo: roleRepo}
+}
+
+// CreateService создает услугу барбера
+func (s *serviceCatalogService) CreateService(barberID uint, req models.ServiceCreateRequest) (*models.Service, error) {
+	// Проверяем, что это барбер
+	if !s.roleRepo.HasUserRole(barberID, "barber") {
+		return nil, fmt.Errorf("пользователь не является барбером")
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("название услуги обязательно")
+	}
+	if err := validateServicePriceAndDuration(req.Price, req.Duration); err != nil {
+		return nil, err
+	}
+
+	service := &models.Service{
+		Name:        name,
+		Description: req.Description,
+		Price:       req.Price,
+		Duration:    req.Duration,
+		IsActive:    true,
+		BarberID:    barberID,
+	}
+	if req.IsActive != nil {
+		service.IsActive = *req.IsActive
+	}
+
+	if err := s.serviceRepo.Create(service); err != nil {
+		return nil, fmt.Errorf("ошибка создания услуги: %v", err)
+	}
+
+	return service, nil
+}
+
+// UpdateService обновляет услугу барбера
+func (s *serviceCatalogService) UpdateService(barberID, serviceID uint, req models.ServiceUpdateRequest) (*models.Service, error) {
+	service, err := s.getOwnService(barberID, serviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Обновляем поля, если они переданы
+	if req.Name != "" {
+		name := strings.TrimSpace(req.Name)
+		if name == "" {
+			return nil, fmt.Errorf("название услуги обязательно")
+		}
+		service.Name = name
+	}
+
+	if req.Description != nil {
+		service.Description = *req.Description
+	}
+
+	if req.Price != nil {
+		service.Price = *req.Price
+	}
+
+	if req.Duration != nil {
+		service.Duration = *req.Duration
+	}
+
+	if req.IsActive != nil {
+		service.IsActive = *req.IsActive
+	}
+
+	if err := validateServicePriceAndDuration(service.Price, service.Duration); err != nil {
+		return nil, err
+	}
+
+	// Сохраняем изменения
+	if err := s.serviceRepo.Update(service); err != nil {
+		return nil, fmt.Errorf("ошибка обновления услуги: %v", err)
+	}
+
+	return service, nil
+}
+
+// DeleteService удаляет услугу барбера (soft delete, записи сохраняют ссылку)
+func (s *serviceCatalogService) DeleteService(barberID, serviceID uint) error {
+	if _, err := s.getOwnService(barberID, serviceID); err != nil {
+		return err
+	}
+
+	if err := s.serviceRepo.Delete(serviceID); err != nil {
+		return fmt.Errorf("ошибка удаления услуги: %v", err)
+	}
+
+	return nil
+}
+
+// GetBarberServices получает все услуги барбера, включая неактивные
+func (s *serviceCatalogService) GetBarberServices(barberID uint) ([]models.Service, error) {
+	return s.serviceRepo.GetByBarberID(barberID)
+}
+
+// ListServices получает активные услуги по фильтру
+func (s *serviceCatalogService) ListServices(filter models.ServiceFilter) ([]models.Service, error) {
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return nil, fmt.Errorf("минимальная цена больше максимальной")
+	}
+	if filter.MinDuration != nil && filter.MaxDuration != nil && *filter.MinDuration > *filter.MaxDuration {
+		return nil, fmt.Errorf("минимальная длительность больше максимальной")
+	}
+
+	// Публичный каталог показывает только активные услуги
+	filter.IncludeInactive = false
+	return s.serviceRepo.List(filter)
+}
+
+// GetService получает активную услугу из каталога
+func (s *serviceCatalogService) GetService(serviceID uint) (*models.Service, error) {
+	service, err := s.serviceRepo.GetByID(serviceID)
+	if err != nil || !service.IsActive {
+		return nil, ErrServiceNotFound
+	}
+	return service, nil
+}
+
+// ResolveService получает услугу по ID, включая деактивированные и удаленные
+func (s *serviceCatalogService) ResolveService(serviceID uint) (*models.Service, error) {
+	service, err := s.serviceRepo.GetByIDUnscoped(serviceID)
+	if err != nil {
+		return nil, ErrServiceNotFound
+	}
+	return service, nil
+}
+
+// getOwnService получает услугу и проверяет, что она принадлежит барберу
+func (s *serviceCatalogService) getOwnService(barberID, serviceID uint) (*models.Service, error) {
+	service, err := s.serviceRepo.GetByID(serviceID)
+	if err != nil {
+		return nil, ErrServiceNotFound
+	}
+
+	// Чужие услуги не раскрываем
+	if service.BarberID != barberID {
+		return nil, ErrServiceNotFound
+	}
+
+	return service, nil
+}
+
+// validateServicePriceAndDuration проверяет цену и длительность услуги
+func validateServicePriceAndDuration(price float64, duration int) error {
+	if price < 0 {
+		return fmt.Errorf("цена не может быть отрицательной")
+	}
+	if duration <= 0 {
+		return fmt.Errorf("длительность должна быть больше нуля")
+	}
+	// Поиск пересечений записей заглядывает назад только на MaxAppointmentDuration
+	if maxMinutes := int(models.MaxAppointmentDuration / time.Minute); duration > maxMinutes {
+		return fmt.Errorf("длительность не может превышать %d минут", maxMinutes)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"garage-barbershop/internal/models"
+)
+
+// TelegramLoginMaxAge срок, в течение которого данные Telegram Login Widget принимаются для входа
+const TelegramLoginMaxAge = 5 * time.Minute
+
+// telegramClockSkew допустимое расхождение часов для auth_date из будущего
+const telegramClockSkew = time.Minute
+
+// Ошибки входа через Telegram Login Widget
+var (
+	ErrInvalidTelegramAuth = errors.New("недействительные данные Telegram")
+	ErrTelegramAuthExpired = errors.New("данные Telegram устарели")
+)
+
+// ValidateTelegramAuth проверяет данные Telegram Login Widget и возвращает данные пользователя.
+// Ключ подписи - SHA256 токена бота, подписывается строка из всех переданных полей, кроме hash,
+// отсортированных по имени, в виде "имя=значение" через перевод строки
+func (s *authService) ValidateTelegramAuth(data models.TelegramLoginData) (*models.TelegramAuthData, error) {
+	if s.botToken == "" {
+		return nil, fmt.Errorf("%w: не задан токен бота", ErrInvalidTelegramAuth)
+	}
+
+	key := sha256.Sum256([]byte(s.botToken))
+	if !checkTelegramSignature(data, key[:]) {
+		return nil, fmt.Errorf("%w: неверная подпись", ErrInvalidTelegramAuth)
+	}
+
+	// Подпись верна, проверяем содержимое
+	authDate, err := checkTelegramAuthDate(data["auth_date"], TelegramLoginMaxAge)
+	if errors.Is(err, errTelegramAuthDateExpired) {
+		return nil, ErrTelegramAuthExpired
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTelegramAuth, err)
+	}
+
+	id, err := strconv.ParseInt(data["id"], 10, 64)
+	if err != nil || id <= 0 {
+		return nil, fmt.Errorf("%w: неверный id", ErrInvalidTelegramAuth)
+	}
+	if strings.TrimSpace(data["first_name"]) == "" {
+		return nil, fmt.Errorf("%w: нет имени пользователя", ErrInvalidTelegramAuth)
+	}
+
+	return &models.TelegramAuthData{
+		ID:        id,
+		Username:  data["username"],
+		FirstName: data["first_name"],
+		LastName:  data["last_name"],
+		AuthDate:  authDate,
+		Hash:      data["hash"],
+	}, nil
+}
+
+// checkTelegramSignature сверяет поле hash с HMAC-SHA256 остальных полей на ключе key
+// за постоянное время. Формат подписываемой строки общий для Login Widget и WebApp
+func checkTelegramSignature(fields map[string]string, key []byte) bool {
+	hash, err := hex.DecodeString(fields["hash"])
+	if err != nil || len(hash) != sha256.Size {
+		return false
+	}
+
+	lines := make([]string, 0, len(fields))
+	for name, value := range fields {
+		if name != "hash" {
+			lines = append(lines, name+"="+value)
+		}
+	}
+	sort.Strings(lines)
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(strings.Join(lines, "\n")))
+	return hmac.Equal(hash, mac.Sum(nil))
+}
+
+// errTelegramAuthDateExpired auth_date старше допустимого срока
+var errTelegramAuthDateExpired = errors.New("auth_date устарел")
+
+// checkTelegramAuthDate разбирает auth_date и проверяет, что он не старше maxAge и не из будущего
+func checkTelegramAuthDate(value string, maxAge time.Duration) (int64, error) {
+	authDate, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || authDate <= 0 {
+		return 0, errors.New("неверный auth_date")
+	}
+
+	issuedAt := time.Unix(authDate, 0)
+	if time.Since(issuedAt) > maxAge {
+		return 0, errTelegramAuthDateExpired
+	}
+	if time.Until(issuedAt) > telegramClockSkew {
+		return 0, errors.New("auth_date в будущем")
+	}
+	return authDate, nil
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"garage-barbershop/internal/models"
+)
+
+// TelegramInitDataMaxAge срок, в течение которого initData Telegram WebApp принимается для входа.
+// Дальше WebApp продлевает сессию refresh token, повторный вход нужен только при новом запуске
+const TelegramInitDataMaxAge = time.Hour
+
+// Ошибки входа через Telegram WebApp
+var (
+	ErrInvalidInitData = errors.New("недействительные данные Telegram WebApp")
+	ErrInitDataExpired = errors.New("данные Telegram WebApp устарели")
+)
+
+// ValidateWebAppInitData проверяет подпись initData Telegram WebApp и возвращает разобранные данные.
+// Ключ подписи - HMAC-SHA256 токена бота с ключом "WebAppData", подписываемая строка та же,
+// что у Login Widget. query_id передается только при запуске WebApp из меню или inline кнопки, поэтому необязателен
+func (s *authService) ValidateWebAppInitData(initData string) (*models.TelegramWebAppInitData, error) {
+	if s.botToken == "" {
+		return nil, fmt.Errorf("%w: не задан токен бота", ErrInvalidInitData)
+	}
+
+	values, err := url.ParseQuery(initData)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInitData, err)
+	}
+
+	fields := make(map[string]string, len(values))
+	for key, value := range values {
+		if len(value) != 1 {
+			return nil, fmt.Errorf("%w: поле %s передано %d раз", ErrInvalidInitData, key, len(value))
+		}
+		fields[key] = value[0]
+	}
+
+	secretKey := hmac.New(sha256.New, []byte("WebAppData"))
+	secretKey.Write([]byte(s.botToken))
+	if !checkTelegramSignature(fields, secretKey.Sum(nil)) {
+		return nil, fmt.Errorf("%w: неверная подпись", ErrInvalidInitData)
+	}
+
+	// Подпись верна, проверяем содержимое
+	authDate, err := checkTelegramAuthDate(fields["auth_date"], TelegramInitDataMaxAge)
+	if errors.Is(err, errTelegramAuthDateExpired) {
+		return nil, ErrInitDataExpired
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidInitData, err)
+	}
+
+	if values.Has("query_id") && values.Get("query_id") == "" {
+		return nil, fmt.Errorf("%w: пустой query_id", ErrInvalidInitData)
+	}
+
+	data := &models.TelegramWebAppInitData{
+		QueryID:    values.Get("query_id"),
+		AuthDate:   authDate,
+		StartParam: values.Get("start_param"),
+	}
+	if !values.Has("user") {
+		return nil, fmt.Errorf("%w: нет данных пользователя", ErrInvalidInitData)
+	}
+	if err := json.Unmarshal([]byte(values.Get("user")), &data.User); err != nil {
+		return nil, fmt.Errorf("%w: неверные данные пользователя: %v", ErrInvalidInitData, err)
+	}
+	if data.User.ID <= 0 || strings.TrimSpace(data.User.FirstName) == "" {
+		return nil, fmt.Errorf("%w: неверные данные пользователя", ErrInvalidInitData)
+	}
+
+	return data, nil
+}
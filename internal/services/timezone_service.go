@@ -0,0 +1,56 @@
+package services
+
+import (
+	"time"
+
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/repositories"
+)
+
+// TimezoneService определяет часовой пояс, в котором работает барбер
+type TimezoneService interface {
+	// DefaultLocation возвращает часовой пояс барбершопа
+	DefaultLocation() *time.Location
+	// BarberLocation возвращает часовой пояс барбера или часовой пояс барбершопа, если он не задан
+	BarberLocation(barberID uint) *time.Location
+	// UserLocation возвращает часовой пояс уже загруженного пользователя
+	UserLocation(user *models.User) *time.Location
+}
+
+// timezoneService реализация TimezoneService
+type timezoneService struct {
+	userRepo        repositories.UserRepository
+	defaultLocation *time.Location
+}
+
+// NewTimezoneService создает новый экземпляр TimezoneService
+func NewTimezoneService(userRepo repositories.UserRepository, defaultLocation *time.Location) TimezoneService {
+	return &timezoneService{userRepo: userRepo, defaultLocation: defaultLocation}
+}
+
+// DefaultLocation возвращает часовой пояс барбершопа
+func (s *timezoneService) DefaultLocation() *time.Location {
+	return s.defaultLocation
+}
+
+// BarberLocation возвращает часовой пояс барбера
+func (s *timezoneService) BarberLocation(barberID uint) *time.Location {
+	barber, err := s.userRepo.GetByID(barberID)
+	if err != nil {
+		return s.defaultLocation
+	}
+	return s.UserLocation(barber)
+}
+
+// UserLocation возвращает часовой пояс пользователя или часовой пояс барбершопа
+func (s *timezoneService) UserLocation(user *models.User) *time.Location {
+	if user.Timezone == "" {
+		return s.defaultLocation
+	}
+
+	loc, err := models.LoadTimezone(user.Timezone)
+	if err != nil {
+		return s.defaultLocation
+	}
+	return loc
+}
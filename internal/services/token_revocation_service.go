@@ -0,0 +1,143 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"garage-barbershop/internal/models"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// TokenRevocationService интерфейс отзыва access token до истечения их срока.
+// Отдельный токен попадает в denylist по JTI, а все токены пользователя
+// отзываются отметкой времени: токены, выпущенные раньше нее, недействительны
+type TokenRevocationService interface {
+	RevokeToken(claims *models.TokenClaims) error
+	RevokeUserTokens(userID uint) error
+	IsRevoked(claims *models.TokenClaims) bool
+}
+
+// tokenRevocationService реализация TokenRevocationService на Redis.
+// Без Redis (локальный запуск, тесты) отзывы хранятся в памяти процесса
+type tokenRevocationService struct {
+	rdb *redis.Client
+
+	mu         sync.Mutex
+	denylist   map[string]time.Time // JTI -> момент истечения токена
+	watermarks map[uint]time.Time   // ID пользователя -> токены, выпущенные раньше, отозваны
+}
+
+// NewTokenRevocationService создает сервис отзыва токенов; rdb может быть nil
+func NewTokenRevocationService(rdb *redis.Client) TokenRevocationService {
+	return &tokenRevocationService{
+		rdb:        rdb,
+		denylist:   make(map[string]time.Time),
+		watermarks: make(map[uint]time.Time),
+	}
+}
+
+// denylistKey ключ Redis отозванного токена
+func denylistKey(jti string) string {
+	return "token_denylist:" + jti
+}
+
+// watermarkKey ключ Redis отметки отзыва токенов пользователя
+func watermarkKey(userID uint) string {
+	return fmt.Sprintf("tokens_revoked_before:%d", userID)
+}
+
+// RevokeToken добавляет токен в denylist до истечения его срока
+func (s *tokenRevocationService) RevokeToken(claims *models.TokenClaims) error {
+	if claims.Jti == "" {
+		return fmt.Errorf("токен без JTI нельзя отозвать")
+	}
+
+	expiresAt := time.Unix(claims.Exp, 0)
+	ttl := expiresAt.Sub(time.Now())
+	if ttl <= 0 {
+		return nil // токен уже истек
+	}
+
+	if s.rdb != nil {
+		return s.rdb.Set(context.Background(), denylistKey(claims.Jti), 1, ttl).Err()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for jti, expires := range s.denylist {
+		if !time.Now().Before(expires) {
+			delete(s.denylist, jti)
+		}
+	}
+	s.denylist[claims.Jti] = expiresAt
+	return nil
+}
+
+// RevokeUserTokens отзывает все выпущенные к этому моменту access token пользователя.
+// Отметка хранится, пока могут жить выпущенные до нее токены
+func (s *tokenRevocationService) RevokeUserTokens(userID uint) error {
+	// iat токенов хранится с точностью до микросекунд: токен, выпущенный в ту же
+	// микросекунду после отзыва, не должен выглядеть выпущенным раньше отметки
+	now := time.Now().Truncate(time.Microsecond)
+
+	if s.rdb != nil {
+		value := strconv.FormatInt(now.UnixMicro(), 10)
+		return s.rdb.Set(context.Background(), watermarkKey(userID), value, AccessTokenTTL).Err()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.watermarks[userID] = now
+	return nil
+}
+
+// IsRevoked проверяет, отозван ли токен по JTI или отметкой пользователя.
+// При недоступности Redis токен считается действующим, чтобы не блокировать всех пользователей
+func (s *tokenRevocationService) IsRevoked(claims *models.TokenClaims) bool {
+	issuedAt := claims.IssuedAtTime()
+
+	if s.rdb != nil {
+		ctx := context.Background()
+		if claims.Jti != "" {
+			exists, err := s.rdb.Exists(ctx, denylistKey(claims.Jti)).Result()
+			if err != nil {
+				log.Printf("⚠️  Ошибка проверки denylist токенов: %v", err)
+			} else if exists > 0 {
+				return true
+			}
+		}
+
+		value, err := s.rdb.Get(ctx, watermarkKey(claims.UserID)).Result()
+		if err != nil {
+			if err != redis.Nil {
+				log.Printf("⚠️  Ошибка проверки отзыва токенов пользователя %d: %v", claims.UserID, err)
+			}
+			return false
+		}
+		watermark, err := strconv.ParseInt(value, 10, 64)
+		return err == nil && issuedAt.Before(time.UnixMicro(watermark))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if expiresAt, ok := s.denylist[claims.Jti]; ok {
+		if now.Before(expiresAt) {
+			return true
+		}
+		delete(s.denylist, claims.Jti)
+	}
+	if watermark, ok := s.watermarks[claims.UserID]; ok {
+		if now.Sub(watermark) > AccessTokenTTL {
+			delete(s.watermarks, claims.UserID)
+			return false
+		}
+		return issuedAt.Before(watermark)
+	}
+	return false
+}
@@ -0,0 +1,410 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/repositories"
+	"garage-barbershop/internal/totp"
+)
+
+// TwoFactorIssuer название сервиса в приложении-аутентификаторе
+const TwoFactorIssuer = "Garage Barbershop"
+
+// MFAChallengeTTL время действия токена второго шага входа
+const MFAChallengeTTL = 5 * time.Minute
+
+// recoveryCodeCount число кодов восстановления, выдаваемых пользователю
+const recoveryCodeCount = 10
+
+// Цели токена второго шага входа
+const (
+	MFAChallengeLogin  = "login"  // ввести код двухфакторной аутентификации
+	MFAChallengeEnroll = "enroll" // подключить обязательную двухфакторную аутентификацию
+)
+
+// Ошибки двухфакторной аутентификации
+var (
+	ErrTwoFactorAlreadyEnabled = errors.New("двухфакторная аутентификация уже включена")
+	ErrTwoFactorNotEnabled     = errors.New("двухфакторная аутентификация не включена")
+	ErrTwoFactorNotStarted     = errors.New("подключение двухфакторной аутентификации не начато")
+	ErrTwoFactorRequired       = errors.New("двухфакторная аутентификация обязательна для вашей роли")
+	ErrInvalidTwoFactorCode    = errors.New("неверный код подтверждения")
+	ErrInvalidMFAChallenge     = errors.New("токен входа недействителен или устарел")
+)
+
+// TwoFactorService интерфейс двухфакторной аутентификации по TOTP (RFC 6238).
+// Секрет хранится зашифрованным, коды восстановления - в виде хешей
+type TwoFactorService interface {
+	Status(userID uint) (*models.TwoFactorStatus, error)
+	// IsRequired проверяет, обязательна ли двухфакторная аутентификация для пользователя по политике
+	IsRequired(userID uint) bool
+	// BeginEnrollment создает новый секрет; включается 2FA только после ConfirmEnrollment
+	BeginEnrollment(userID uint) (*models.TwoFactorSetup, error)
+	// ConfirmEnrollment проверяет первый код, включает 2FA и возвращает коды восстановления
+	ConfirmEnrollment(userID uint, code string) ([]string, error)
+	// Disable отключает 2FA после проверки кода
+	Disable(userID uint, code string) error
+	// RegenerateRecoveryCodes заменяет коды восстановления после проверки кода
+	RegenerateRecoveryCodes(userID uint, code string) ([]string, error)
+	// VerifyCode проверяет код из приложения или одноразовый код восстановления
+	VerifyCode(user *models.User, code string) error
+
+	// IssueChallenge выдает подписанный токен второго шага входа
+	IssueChallenge(user *models.User, purpose string) string
+	// ParseChallenge проверяет токен второго шага входа и возвращает пользователя
+	ParseChallenge(token, purpose string) (*models.User, error)
+	// UseChallenge погашает токен второго шага после принятого кода: повторно его и выданные
+	// раньше токены использовать нельзя
+	UseChallenge(token, purpose string) error
+}
+
+// twoFactorService реализация TwoFactorService
+type twoFactorService struct {
+	userRepo        repositories.UserRepository
+	twoFactorRepo   repositories.TwoFactorRepository
+	roleRepo        repositories.RoleRepository
+	encryptionKey   []byte
+	challengeKey    []byte
+	requireForAdmin bool
+}
+
+// NewTwoFactorService создает сервис двухфакторной аутентификации.
+// Ключи шифрования секретов и подписи токенов входа выводятся из secret;
+// requireForAdmin делает 2FA обязательной для роли admin
+func NewTwoFactorService(userRepo repositories.UserRepository, twoFactorRepo repositories.TwoFactorRepository, roleRepo repositories.RoleRepository, secret string, requireForAdmin bool) TwoFactorService {
+	return &twoFactorService{
+		userRepo:        userRepo,
+		twoFactorRepo:   twoFactorRepo,
+		roleRepo:        roleRepo,
+		encryptionKey:   deriveKey(secret, "totp-secret-encryption"),
+		challengeKey:    deriveKey(secret, "mfa-challenge"),
+		requireForAdmin: requireForAdmin,
+	}
+}
+
+// deriveKey выводит отдельный ключ для назначения, чтобы ключи нельзя было использовать в другом контексте
+func deriveKey(secret, purpose string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+// Status возвращает состояние 2FA пользователя
+func (s *twoFactorService) Status(userID uint) (*models.TwoFactorStatus, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("пользователь не найден: %v", err)
+	}
+
+	status := &models.TwoFactorStatus{
+		Enabled:   user.HasTwoFactor(),
+		EnabledAt: user.TOTPEnabledAt,
+		Required:  s.IsRequired(userID),
+	}
+	if status.Enabled {
+		if status.RecoveryCodesCount, err = s.twoFactorRepo.CountRecoveryCodes(userID); err != nil {
+			return nil, fmt.Errorf("ошибка подсчета кодов восстановления: %v", err)
+		}
+	}
+	return status, nil
+}
+
+// IsRequired проверяет политику: 2FA обязательна для администраторов, если она включена
+func (s *twoFactorService) IsRequired(userID uint) bool {
+	return s.requireForAdmin && s.roleRepo.HasUserRole(userID, "admin")
+}
+
+// BeginEnrollment генерирует секрет и сохраняет его до подтверждения первым кодом
+func (s *twoFactorService) BeginEnrollment(userID uint) (*models.TwoFactorSetup, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("пользователь не найден: %v", err)
+	}
+	if user.HasTwoFactor() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка генерации секрета: %v", err)
+	}
+	encrypted, err := s.encrypt(secret)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка шифрования секрета: %v", err)
+	}
+
+	user.TOTPSecret = encrypted
+	user.TOTPLastStep = 0
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, fmt.Errorf("ошибка сохранения секрета: %v", err)
+	}
+
+	return &models.TwoFactorSetup{
+		Secret: secret,
+		URI:    totp.URI(TwoFactorIssuer, accountName(user), secret),
+	}, nil
+}
+
+// ConfirmEnrollment включает 2FA после проверки кода из приложения
+func (s *twoFactorService) ConfirmEnrollment(userID uint, code string) ([]string, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("пользователь не найден: %v", err)
+	}
+	if user.HasTwoFactor() {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTwoFactorNotStarted
+	}
+
+	secret, err := s.decrypt(user.TOTPSecret)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка расшифровки секрета: %v", err)
+	}
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	now := time.Now()
+	user.TOTPEnabledAt = &now
+	user.TOTPLastStep = step
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, fmt.Errorf("ошибка включения двухфакторной аутентификации: %v", err)
+	}
+	return s.issueRecoveryCodes(user.ID)
+}
+
+// Disable отключает 2FA, если она не обязательна для пользователя
+func (s *twoFactorService) Disable(userID uint, code string) error {
+	if s.IsRequired(userID) {
+		return ErrTwoFactorRequired
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return fmt.Errorf("пользователь не найден: %v", err)
+	}
+	if err := s.VerifyCode(user, code); err != nil {
+		return err
+	}
+
+	user.TOTPSecret = ""
+	user.TOTPEnabledAt = nil
+	user.TOTPLastStep = 0
+	if err := s.userRepo.Update(user); err != nil {
+		return fmt.Errorf("ошибка отключения двухфакторной аутентификации: %v", err)
+	}
+	return s.twoFactorRepo.DeleteRecoveryCodes(user.ID)
+}
+
+// RegenerateRecoveryCodes выдает новые коды восстановления, прежние перестают действовать
+func (s *twoFactorService) RegenerateRecoveryCodes(userID uint, code string) ([]string, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("пользователь не найден: %v", err)
+	}
+	if err := s.VerifyCode(user, code); err != nil {
+		return nil, err
+	}
+	return s.issueRecoveryCodes(user.ID)
+}
+
+// VerifyCode принимает код из приложения не более одного раза или неиспользованный код восстановления
+func (s *twoFactorService) VerifyCode(user *models.User, code string) error {
+	if !user.HasTwoFactor() {
+		return ErrTwoFactorNotEnabled
+	}
+
+	code = strings.TrimSpace(code)
+	if len(strings.ReplaceAll(code, " ", "")) == totp.Digits {
+		secret, err := s.decrypt(user.TOTPSecret)
+		if err != nil {
+			return fmt.Errorf("ошибка расшифровки секрета: %v", err)
+		}
+		step, ok := totp.Validate(secret, code, time.Now())
+		if !ok || s.twoFactorRepo.AdvanceTOTPStep(user.ID, step) != nil {
+			return ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+
+	if err := s.twoFactorRepo.UseRecoveryCode(user.ID, hashRecoveryCode(code), time.Now()); err != nil {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+// IssueChallenge формирует токен "данные.подпись", данные - "цель:ID:время выдачи:срок"
+func (s *twoFactorService) IssueChallenge(user *models.User, purpose string) string {
+	now := time.Now()
+	payload := fmt.Sprintf("%s:%d:%d:%d", purpose, user.ID, now.UnixNano(), now.Add(MFAChallengeTTL).Unix())
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.sign(encoded))
+}
+
+// ParseChallenge проверяет подпись, цель и срок токена
+func (s *twoFactorService) ParseChallenge(token, purpose string) (*models.User, error) {
+	user, _, err := s.parseChallenge(token, purpose)
+	return user, err
+}
+
+// UseChallenge проверяет токен и запоминает время его выдачи у пользователя
+func (s *twoFactorService) UseChallenge(token, purpose string) error {
+	user, issuedAt, err := s.parseChallenge(token, purpose)
+	if err != nil {
+		return err
+	}
+	if err := s.twoFactorRepo.UseMFAChallenge(user.ID, issuedAt); err != nil {
+		return ErrInvalidMFAChallenge
+	}
+	return nil
+}
+
+// parseChallenge проверяет токен и возвращает пользователя и время выдачи токена.
+// Токен, выданный не позже последнего использованного, недействителен
+func (s *twoFactorService) parseChallenge(token, purpose string) (*models.User, int64, error) {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, 0, ErrInvalidMFAChallenge
+	}
+	expected, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, s.sign(encoded)) {
+		return nil, 0, ErrInvalidMFAChallenge
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, 0, ErrInvalidMFAChallenge
+	}
+	parts := strings.Split(string(payload), ":")
+	if len(parts) != 4 || parts[0] != purpose {
+		return nil, 0, ErrInvalidMFAChallenge
+	}
+	userID, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil {
+		return nil, 0, ErrInvalidMFAChallenge
+	}
+	issuedAt, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return nil, 0, ErrInvalidMFAChallenge
+	}
+	expiresAt, err := strconv.ParseInt(parts[3], 10, 64)
+	if err != nil || time.Now().Unix() >= expiresAt {
+		return nil, 0, ErrInvalidMFAChallenge
+	}
+
+	user, err := s.userRepo.GetByID(uint(userID))
+	if err != nil || !user.IsActive || issuedAt <= user.MFAChallengeUsedAt {
+		return nil, 0, ErrInvalidMFAChallenge
+	}
+	return user, issuedAt, nil
+}
+
+// issueRecoveryCodes генерирует коды восстановления и сохраняет их хеши
+func (s *twoFactorService) issueRecoveryCodes(userID uint) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, fmt.Errorf("ошибка генерации кодов восстановления: %v", err)
+		}
+		codes[i] = code
+		hashes[i] = hashRecoveryCode(code)
+	}
+
+	if err := s.twoFactorRepo.ReplaceRecoveryCodes(userID, hashes); err != nil {
+		return nil, fmt.Errorf("ошибка сохранения кодов восстановления: %v", err)
+	}
+	return codes, nil
+}
+
+// sign вычисляет подпись данных токена входа
+func (s *twoFactorService) sign(data string) []byte {
+	mac := hmac.New(sha256.New, s.challengeKey)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// encrypt шифрует секрет TOTP (AES-GCM), результат - base64(nonce + шифротекст)
+func (s *twoFactorService) encrypt(secret string) (string, error) {
+	gcm, err := s.cipher()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(secret), nil)), nil
+}
+
+// decrypt расшифровывает секрет TOTP
+func (s *twoFactorService) decrypt(encrypted string) (string, error) {
+	gcm, err := s.cipher()
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil || len(data) < gcm.NonceSize() {
+		return "", fmt.Errorf("поврежденный секрет")
+	}
+	secret, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
+
+// cipher создает AES-GCM с ключом шифрования секретов
+func (s *twoFactorService) cipher() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(s.encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// accountName имя учетной записи в приложении-аутентификаторе
+func accountName(user *models.User) string {
+	switch {
+	case user.Email != "":
+		return user.Email
+	case user.Username != "":
+		return user.Username
+	default:
+		return fmt.Sprintf("user-%d", user.ID)
+	}
+}
+
+// generateRecoveryCode генерирует код восстановления вида "xxxxx-xxxxx"
+func generateRecoveryCode() (string, error) {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf))
+	return code[:5] + "-" + code[5:10], nil
+}
+
+// hashRecoveryCode возвращает хеш кода восстановления без учета регистра и разделителей
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
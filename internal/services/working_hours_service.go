@@ -0,0 +1,173 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+// ErrWorkingHoursNotFound возвращается, если у барбера нет расписания на указанный день
+var ErrWorkingHoursNotFound = errors.New("расписание на этот день не найдено")
+
+// ErrNotBarber возвращается, если пользователь не является барбером
+var ErrNotBarber = errors.New("пользователь не является барбером")
+
+// WorkingHoursService интерфейс для управления недельным расписанием барберов
+type WorkingHoursService interface {
+	GetSchedule(barberID uint) ([]models.WorkingHours, error)
+	SetDay(barberID uint, req models.WorkingHoursRequest) (*models.WorkingHours, error)
+	DeleteDay(barberID uint, dayOfWeek int) error
+	// ReplaceWeek атомарно заменяет все расписание: дни, не вошедшие в запрос, становятся выходными
+	ReplaceWeek(barberID uint, req models.WeeklyScheduleRequest) ([]models.WorkingHours, error)
+}
+
+// workingHoursService реализация WorkingHoursService
+type workingHoursService struct {
+	workingHoursRepo repositories.WorkingHoursRepository
+	roleRepo         repositories.RoleRepository
+}
+
+// NewWorkingHoursService создает новый экземпляр WorkingHoursService
+func NewWorkingHoursService(workingHoursRepo repositories.WorkingHoursRepository, roleRepo repositories.RoleRepository) WorkingHoursService {
+	return &workingHoursService{workingHoursRepo: workingHoursRepo, roleRepo: roleRepo}
+}
+
+// GetSchedule возвращает недельное расписание барбера
+func (s *workingHoursService) GetSchedule(barberID uint) ([]models.WorkingHours, error) {
+	if !s.roleRepo.HasUserRole(barberID, "barber") {
+		return nil, ErrNotBarber
+	}
+
+	schedule, err := s.workingHoursRepo.GetByBarberID(barberID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения расписания: %v", err)
+	}
+	return schedule, nil
+}
+
+// SetDay создает или заменяет расписание барбера на один день недели
+func (s *workingHoursService) SetDay(barberID uint, req models.WorkingHoursRequest) (*models.WorkingHours, error) {
+	if !s.roleRepo.HasUserRole(barberID, "barber") {
+		return nil, ErrNotBarber
+	}
+
+	workingHours, err := buildWorkingHours(barberID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.workingHoursRepo.SaveDay(workingHours); err != nil {
+		return nil, fmt.Errorf("ошибка сохранения расписания: %v", err)
+	}
+	return workingHours, nil
+}
+
+// DeleteDay удаляет расписание на день недели, делая его выходным
+func (s *workingHoursService) DeleteDay(barberID uint, dayOfWeek int) error {
+	if !s.roleRepo.HasUserRole(barberID, "barber") {
+		return ErrNotBarber
+	}
+	if dayOfWeek < 1 || dayOfWeek > 7 {
+		return fmt.Errorf("день недели должен быть от 1 до 7")
+	}
+
+	if err := s.workingHoursRepo.DeleteDay(barberID, dayOfWeek); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrWorkingHoursNotFound
+		}
+		return fmt.Errorf("ошибка удаления расписания: %v", err)
+	}
+	return nil
+}
+
+// ReplaceWeek проверяет все дни и заменяет расписание одной транзакцией
+func (s *workingHoursService) ReplaceWeek(barberID uint, req models.WeeklyScheduleRequest) ([]models.WorkingHours, error) {
+	if !s.roleRepo.HasUserRole(barberID, "barber") {
+		return nil, ErrNotBarber
+	}
+	if len(req.Days) > 7 {
+		return nil, fmt.Errorf("в неделе не больше 7 дней")
+	}
+
+	week := make([]models.WorkingHours, 0, len(req.Days))
+	seen := make(map[int]bool, len(req.Days))
+	for _, day := range req.Days {
+		if seen[day.DayOfWeek] {
+			return nil, fmt.Errorf("день недели %d указан несколько раз", day.DayOfWeek)
+		}
+		seen[day.DayOfWeek] = true
+
+		workingHours, err := buildWorkingHours(barberID, day)
+		if err != nil {
+			return nil, err
+		}
+		week = append(week, *workingHours)
+	}
+
+	if err := s.workingHoursRepo.ReplaceWeek(barberID, week); err != nil {
+		return nil, fmt.Errorf("ошибка сохранения расписания: %v", err)
+	}
+
+	return s.workingHoursRepo.GetByBarberID(barberID)
+}
+
+// buildWorkingHours проверяет запрос и приводит время к формату "HH:MM"
+func buildWorkingHours(barberID uint, req models.WorkingHoursRequest) (*models.WorkingHours, error) {
+	if req.DayOfWeek < 1 || req.DayOfWeek > 7 {
+		return nil, fmt.Errorf("день недели должен быть от 1 до 7")
+	}
+
+	start, err := models.ParseClockTime(req.StartTime)
+	if err != nil {
+		return nil, err
+	}
+	end, err := models.ParseClockTime(req.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	if start >= end {
+		return nil, fmt.Errorf("день %d: время начала должно быть раньше времени окончания", req.DayOfWeek)
+	}
+
+	workingHours := &models.WorkingHours{
+		DayOfWeek: req.DayOfWeek,
+		StartTime: models.FormatClockTime(start),
+		EndTime:   models.FormatClockTime(end),
+		IsActive:  true,
+		BarberID:  barberID,
+	}
+	if req.IsActive != nil {
+		workingHours.IsActive = *req.IsActive
+	}
+
+	// Перерыв задается либо полностью, либо не задается вовсе
+	if req.BreakStart == "" && req.BreakEnd == "" {
+		return workingHours, nil
+	}
+	if req.BreakStart == "" || req.BreakEnd == "" {
+		return nil, fmt.Errorf("день %d: необходимо указать начало и конец перерыва", req.DayOfWeek)
+	}
+
+	breakStart, err := models.ParseClockTime(req.BreakStart)
+	if err != nil {
+		return nil, err
+	}
+	breakEnd, err := models.ParseClockTime(req.BreakEnd)
+	if err != nil {
+		return nil, err
+	}
+	if breakStart >= breakEnd {
+		return nil, fmt.Errorf("день %d: начало перерыва должно быть раньше его окончания", req.DayOfWeek)
+	}
+	if breakStart <= start || breakEnd >= end {
+		return nil, fmt.Errorf("день %d: перерыв должен быть внутри рабочего времени", req.DayOfWeek)
+	}
+
+	workingHours.BreakStart = models.FormatClockTime(breakStart)
+	workingHours.BreakEnd = models.FormatClockTime(breakEnd)
+	return workingHours, nil
+}
@@ -0,0 +1,129 @@
+package telegram
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultAPIURL адрес Telegram Bot API
+const DefaultAPIURL = "https://api.telegram.org"
+
+// defaultTimeout таймаут запросов к Bot API
+const defaultTimeout = 10 * time.Second
+
+// BotAPI методы Bot API, которые использует приложение.
+// Интерфейс позволяет подменить клиента в тестах
+type BotAPI interface {
+	SendInvoice(params InvoiceParams) (*Message, error)
+	AnswerPreCheckoutQuery(queryID string, ok bool, errorMessage string) error
+	RefundStarPayment(userID int64, telegramPaymentChargeID string) error
+}
+
+// BotClient HTTP клиент Telegram Bot API
+type BotClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// NewBotClient создает клиента Bot API.
+// Пустой baseURL означает DefaultAPIURL, nil httpClient — клиент с таймаутом по умолчанию
+func NewBotClient(baseURL, token string, httpClient *http.Client) *BotClient {
+	if baseURL == "" {
+		baseURL = DefaultAPIURL
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultTimeout}
+	}
+	return &BotClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      token,
+		httpClient: httpClient,
+	}
+}
+
+// SendInvoice отправляет пользователю счет на оплату
+func (c *BotClient) SendInvoice(params InvoiceParams) (*Message, error) {
+	var message Message
+	if err := c.call("sendInvoice", params, &message); err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// AnswerPreCheckoutQuery подтверждает или отклоняет оплату перед списанием
+func (c *BotClient) AnswerPreCheckoutQuery(queryID string, ok bool, errorMessage string) error {
+	params := map[string]interface{}{
+		"pre_checkout_query_id": queryID,
+		"ok":                    ok,
+	}
+	if !ok {
+		params["error_message"] = errorMessage
+	}
+	return c.call("answerPreCheckoutQuery", params, nil)
+}
+
+// RefundStarPayment возвращает оплату в Telegram Stars
+func (c *BotClient) RefundStarPayment(userID int64, telegramPaymentChargeID string) error {
+	params := map[string]interface{}{
+		"user_id":                    userID,
+		"telegram_payment_charge_id": telegramPaymentChargeID,
+	}
+	return c.call("refundStarPayment", params, nil)
+}
+
+// apiResponse общий формат ответа Bot API
+type apiResponse struct {
+	OK          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result"`
+	ErrorCode   int             `json:"error_code"`
+	Description string          `json:"description"`
+}
+
+// call вызывает метод Bot API и разбирает результат в result (если он не nil)
+func (c *BotClient) call(method string, params interface{}, result interface{}) error {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("ошибка подготовки запроса %s: %v", method, err)
+	}
+
+	url := fmt.Sprintf("%s/bot%s/%s", c.baseURL, c.token, method)
+	resp, err := c.httpClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		// Текст ошибки содержит URL с токеном бота, поэтому наружу отдается только метод
+		return fmt.Errorf("ошибка запроса %s к Telegram Bot API", method)
+	}
+	defer resp.Body.Close()
+
+	var apiResp apiResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return fmt.Errorf("неверный ответ Telegram Bot API на %s (HTTP %d)", method, resp.StatusCode)
+	}
+	if !apiResp.OK {
+		return &APIError{Method: method, Code: apiResp.ErrorCode, Description: apiResp.Description}
+	}
+
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(apiResp.Result, result); err != nil {
+		return fmt.Errorf("неверный результат %s: %v", method, err)
+	}
+	return nil
+}
+
+// APIError ошибка, которую вернул Bot API
+type APIError struct {
+	Method      string
+	Code        int
+	Description string
+}
+
+// Error возвращает текст ошибки
+func (e *APIError) Error() string {
+	return fmt.Sprintf("Telegram Bot API %s: %d %s", e.Method, e.Code, e.Description)
+}
@@ -0,0 +1,63 @@
+package telegram
+
+// Update входящее обновление бота (только поля, нужные для платежей)
+type Update struct {
+	UpdateID         int64             `json:"update_id"`
+	Message          *Message          `json:"message,omitempty"`
+	PreCheckoutQuery *PreCheckoutQuery `json:"pre_checkout_query,omitempty"`
+}
+
+// User пользователь Telegram
+type User struct {
+	ID        int64  `json:"id"`
+	FirstName string `json:"first_name"`
+	Username  string `json:"username,omitempty"`
+}
+
+// Chat чат, в который пришло сообщение
+type Chat struct {
+	ID int64 `json:"id"`
+}
+
+// Message сообщение бота
+type Message struct {
+	MessageID         int64              `json:"message_id"`
+	From              *User              `json:"from,omitempty"`
+	Chat              Chat               `json:"chat"`
+	SuccessfulPayment *SuccessfulPayment `json:"successful_payment,omitempty"`
+}
+
+// LabeledPrice строка счета; сумма в минимальных единицах валюты
+type LabeledPrice struct {
+	Label  string `json:"label"`
+	Amount int64  `json:"amount"`
+}
+
+// InvoiceParams параметры метода sendInvoice
+type InvoiceParams struct {
+	ChatID        int64          `json:"chat_id"`
+	Title         string         `json:"title"`
+	Description   string         `json:"description"`
+	Payload       string         `json:"payload"`
+	ProviderToken string         `json:"provider_token,omitempty"`
+	Currency      string         `json:"currency"`
+	Prices        []LabeledPrice `json:"prices"`
+}
+
+// PreCheckoutQuery запрос подтверждения перед списанием
+type PreCheckoutQuery struct {
+	ID             string `json:"id"`
+	From           User   `json:"from"`
+	Currency       string `json:"currency"`
+	TotalAmount    int64  `json:"total_amount"`
+	InvoicePayload string `json:"invoice_payload"`
+}
+
+// SuccessfulPayment сведения о проведенной оплате
+type SuccessfulPayment struct {
+	Currency                string `json:"currency"`
+	TotalAmount             int64  `json:"total_amount"`
+	InvoicePayload          string `json:"invoice_payload"`
+	TelegramPaymentChargeID string `json:"telegram_payment_charge_id"`
+	ProviderPaymentChargeID string `json:"provider_payment_charge_id"`
+}
@@ -7,14 +7,18 @@ import (
 	"net/http"
 	"os"
 	"time"
+	_ "time/tzdata" // база часовых поясов нужна и в минимальных образах без /usr/share/zoneinfo
 
 	"garage-barbershop/internal/config"
 	"garage-barbershop/internal/database"
 	"garage-barbershop/internal/handlers"
+	"garage-barbershop/internal/mailer"
 	"garage-barbershop/internal/middleware"
 	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/payments"
 	"garage-barbershop/internal/repositories"
 	"garage-barbershop/internal/services"
+	"garage-barbershop/internal/telegram"
 
 	"github.com/redis/go-redis/v9"
 )
@@ -56,11 +60,18 @@ func migrateDB() error {
 	// Автоматическая миграция всех моделей
 	err := db.Migrate(
 		&models.User{},
+		&models.Role{},
+		&models.UserRole{},
 		&models.Service{},
 		&models.Appointment{},
 		&models.WorkingHours{},
+		&models.ScheduleException{},
 		&models.Payment{},
 		&models.Review{},
+		&models.AuditEvent{},
+		&models.Session{},
+		&models.PasswordResetToken{},
+		&models.RecoveryCode{},
 	)
 
 	if err != nil {
@@ -106,32 +117,154 @@ func setupDependencies() {
 	// Создаем репозитории
 	userRepo := repositories.NewUserRepository(db.DB)
 	roleRepo := repositories.NewRoleRepository(db.DB)
+	serviceRepo := repositories.NewServiceRepository(db.DB)
+	appointmentRepo := repositories.NewAppointmentRepository(db.DB)
+	workingHoursRepo := repositories.NewWorkingHoursRepository(db.DB)
+	exceptionRepo := repositories.NewScheduleExceptionRepository(db.DB)
+	paymentRepo := repositories.NewPaymentRepository(db.DB)
+	reviewRepo := repositories.NewReviewRepository(db.DB)
+	auditRepo := repositories.NewAuditRepository(db.DB)
+	sessionRepo := repositories.NewSessionRepository(db.DB)
+	passwordResetRepo := repositories.NewPasswordResetRepository(db.DB)
+	twoFactorRepo := repositories.NewTwoFactorRepository(db.DB)
+	userMergeRepo := repositories.NewUserMergeRepository(db.DB)
 
 	// Создаем сервисы
 	userService := services.NewUserService(userRepo, roleRepo)
+	permissionService := services.NewPermissionService(roleRepo)
+	roleService := services.NewRoleService(roleRepo)
+	auditService := services.NewAuditService(auditRepo)
+	tokenRevocationService := services.NewTokenRevocationService(rdb)
+
+	// Защита входа по паролю от подбора; без Redis счетчики хранятся в памяти процесса
+	emailLoginPolicy := services.DefaultEmailLoginPolicy
+	emailLoginPolicy.MaxAttempts = cfg.LoginMaxAttempts
+	emailLoginPolicy.LockoutDuration = cfg.LoginLockoutDuration
+	ipLoginPolicy := services.DefaultIPLoginPolicy
+	ipLoginPolicy.MaxAttempts = cfg.LoginIPMaxAttempts
+	ipLoginPolicy.LockoutDuration = cfg.LoginLockoutDuration
+	loginThrottleService := services.NewLoginThrottleService(rdb, emailLoginPolicy, ipLoginPolicy)
+
+	// Часовой пояс барбершопа, в нем задаются рабочие часы барберов без собственного пояса
+	shopLocation, err := models.LoadTimezone(cfg.Timezone)
+	if err != nil {
+		log.Printf("⚠️  %v, используем UTC", err)
+		shopLocation = time.UTC
+	}
+	timezoneService := services.NewTimezoneService(userRepo, shopLocation)
+
+	// Политика паролей: встроенный список распространенных паролей дополняется файлом PASSWORD_BLOCKLIST_FILE
+	var passwordBlocklist []string
+	if cfg.PasswordBlocklistFile != "" {
+		passwordBlocklist, err = services.LoadPasswordList(cfg.PasswordBlocklistFile)
+		if err != nil {
+			log.Printf("⚠️  %v, используем только встроенный список", err)
+		}
+	}
+	passwordPolicy := services.NewPasswordPolicy(cfg.PasswordMinLength, passwordBlocklist)
 
 	// Создаем сервис аутентификации
-	authService := services.NewAuthService(userRepo, roleRepo, rdb, cfg.JWTSecret, cfg.TelegramBotToken)
+	authService := services.NewAuthService(userRepo, roleRepo, sessionRepo, tokenRevocationService, cfg.JWTSecret, cfg.TelegramBotToken, cfg.SessionMaxLifetime, passwordPolicy)
+
+	// Создаем сервисы восстановления пароля и подтверждения email; письма пишутся в каталог MAIL_DIR или в лог
+	var mailSender mailer.Mailer = mailer.NewLogMailer()
+	if cfg.MailDir != "" {
+		fileMailer, err := mailer.NewFileMailer(cfg.MailDir)
+		if err != nil {
+			log.Printf("⚠️  %v, письма будут выводиться в лог", err)
+		} else {
+			mailSender = fileMailer
+		}
+	}
+	emailVerificationService := services.NewEmailVerificationService(userRepo, mailSender, cfg.JWTSecret, cfg.EmailVerifyURL, cfg.UnverifiedEmailRestrictions)
+	// Двухфакторная аутентификация; REQUIRE_ADMIN_2FA делает ее обязательной для администраторов
+	twoFactorService := services.NewTwoFactorService(userRepo, twoFactorRepo, roleRepo, cfg.JWTSecret, cfg.RequireAdminTwoFactor)
+	passwordService := services.NewPasswordService(userRepo, passwordResetRepo, sessionRepo, authService, tokenRevocationService, mailSender, cfg.PasswordResetURL)
+	identityService := services.NewIdentityService(userRepo, userMergeRepo, authService, tokenRevocationService)
+
+	// Создаем сервис каталога услуг
+	catalogService := services.NewServiceCatalogService(serviceRepo, roleRepo)
+
+	// Создаем сервис записей
+	appointmentService := services.NewAppointmentService(appointmentRepo, serviceRepo, userRepo, workingHoursRepo, exceptionRepo, timezoneService)
+
+	// Создаем сервис расчета свободного времени
+	availabilityService := services.NewAvailabilityService(workingHoursRepo, exceptionRepo, appointmentRepo, serviceRepo, timezoneService)
+
+	// Создаем сервис управления рабочими часами
+	workingHoursService := services.NewWorkingHoursService(workingHoursRepo, roleRepo)
+
+	// Создаем сервис исключений из расписания
+	exceptionService := services.NewScheduleExceptionService(exceptionRepo, roleRepo, timezoneService)
+
+	// Создаем сервис платежей; тестовый карточный провайдер доступен только вне production
+	paymentProviders := []payments.Provider{payments.NewCashProvider()}
+	if !cfg.IsProduction() {
+		paymentProviders = append(paymentProviders, payments.NewFakeCardProvider(cfg.PaymentWebhookSecret))
+	}
+	if cfg.TelegramBotToken != "" {
+		botClient := telegram.NewBotClient(telegram.DefaultAPIURL, cfg.TelegramBotToken, nil)
+		paymentProviders = append(paymentProviders, payments.NewTelegramProvider(
+			botClient, cfg.TelegramPaymentProviderToken, cfg.TelegramWebhookSecret, userRepo,
+		))
+	}
+	paymentService := services.NewPaymentService(paymentRepo, appointmentRepo, cfg.Currency, paymentProviders...)
+
+	// Создаем сервис отзывов
+	reviewService := services.NewReviewService(reviewRepo, appointmentRepo, roleRepo)
 
 	// Создаем хендлеры
 	userHandler := handlers.NewUserHandler(userService)
-	authHTTPHandler := handlers.NewAuthHTTPHandler(authService)
+	authHTTPHandler := handlers.NewAuthHTTPHandler(authService, auditService, emailVerificationService, loginThrottleService, twoFactorService)
+	serviceHandler := handlers.NewServiceHandler(catalogService)
+	appointmentHandler := handlers.NewAppointmentHandler(appointmentService)
+	availabilityHandler := handlers.NewAvailabilityHandler(availabilityService)
+	workingHoursHandler := handlers.NewWorkingHoursHandler(workingHoursService)
+	exceptionHandler := handlers.NewScheduleExceptionHandler(exceptionService)
+	paymentHandler := handlers.NewPaymentHandler(paymentService)
+	reviewHandler := handlers.NewReviewHandler(reviewService)
+	permissionHandler := handlers.NewPermissionHandler(permissionService)
+	roleHandler := handlers.NewRoleHandler(roleService, permissionService, auditService, tokenRevocationService)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	sessionHandler := handlers.NewSessionHandler(authService)
+	passwordHandler := handlers.NewPasswordHandler(passwordService, authService, auditService)
+	emailVerificationHandler := handlers.NewEmailVerificationHandler(emailVerificationService, auditService)
+	loginLockoutHandler := handlers.NewLoginLockoutHandler(authService, loginThrottleService, auditService)
+	twoFactorHandler := handlers.NewTwoFactorHandler(twoFactorService, auditService)
+	identityHandler := handlers.NewIdentityHandler(identityService, authService, emailVerificationService, auditService)
 
 	// Настраиваем API routes
-	setupAPIRoutes(userHandler, authHTTPHandler, authService, userRepo, roleRepo)
+	setupAPIRoutes(userHandler, authHTTPHandler, authService, permissionService, auditService, tokenRevocationService, emailVerificationService, userRepo, roleRepo)
+	setupSessionRoutes(sessionHandler, authService)
+	setupPasswordRoutes(passwordHandler, authService)
+	setupEmailVerificationRoutes(emailVerificationHandler, authService)
+	setupTwoFactorRoutes(twoFactorHandler, authHTTPHandler, authService)
+	setupIdentityRoutes(identityHandler, authService, permissionService)
+	setupPermissionRoutes(permissionHandler, authService, permissionService)
+	setupRoleRoutes(roleHandler, authService, permissionService)
+	setupAuditRoutes(auditHandler, authService, permissionService)
+	setupLoginLockoutRoutes(loginLockoutHandler, authService, permissionService)
+	setupServiceRoutes(serviceHandler, authService)
+	setupAppointmentRoutes(appointmentHandler, authService, emailVerificationService)
+	setupAvailabilityRoutes(availabilityHandler)
+	setupWorkingHoursRoutes(workingHoursHandler, authService)
+	setupScheduleExceptionRoutes(exceptionHandler, authService)
+	setupPaymentRoutes(paymentHandler, authService)
+	setupReviewRoutes(reviewHandler, authService)
 }
 
 // Настройка API маршрутов
-func setupAPIRoutes(userHandler *handlers.UserHandler, authHTTPHandler *handlers.AuthHTTPHandler, authService services.AuthService, userRepo repositories.UserRepository, roleRepo repositories.RoleRepository) {
+func setupAPIRoutes(userHandler *handlers.UserHandler, authHTTPHandler *handlers.AuthHTTPHandler, authService services.AuthService, permissionService services.PermissionService, auditService services.AuditService, tokenRevocationService services.TokenRevocationService, emailVerificationService services.EmailVerificationService, userRepo repositories.UserRepository, roleRepo repositories.RoleRepository) {
 	// Создаем handler для ролевой авторизации
-	authRolesHandler := handlers.NewAuthRolesHandler(authService)
+	authRolesHandler := handlers.NewAuthRolesHandler(authService, emailVerificationService)
 
 	// Создаем сервис для барберов
-	barberService := services.NewBarberService(userRepo, roleRepo)
+	barberService := services.NewBarberService(userRepo, roleRepo, auditService, tokenRevocationService)
 	barberHandler := handlers.NewBarberHandler(barberService)
 
 	// Публичные маршруты (не требуют аутентификации)
 	http.HandleFunc("/api/auth/telegram", authHTTPHandler.TelegramAuth)
+	http.HandleFunc("/api/auth/telegram/webapp", authHTTPHandler.TelegramWebAppAuth)
 	http.HandleFunc("/api/auth/refresh", authHTTPHandler.RefreshToken)
 	http.HandleFunc("/api/auth/register", authHTTPHandler.RegisterDirect) // Старый endpoint (deprecated)
 	http.HandleFunc("/api/auth/login", authHTTPHandler.LoginDirect)
@@ -139,30 +272,36 @@ func setupAPIRoutes(userHandler *handlers.UserHandler, authHTTPHandler *handlers
 	// Новые ролевые маршруты
 	http.HandleFunc("/api/auth/register/client", authRolesHandler.RegisterClient) // Публичный
 
-	// Защищенный endpoint для регистрации барберов (только админ)
+	// Защищенный endpoint для регистрации барберов (разрешение barbers:create)
 	barberRegisterHandler := middleware.HTTPAuthMiddleware(authService)(
-		middleware.HTTPRequireRoleMiddleware("admin")(authRolesHandler.RegisterBarber),
+		middleware.HTTPRequirePermissionMiddleware(permissionService, "barbers", models.ActionCreate)(authRolesHandler.RegisterBarber),
 	)
 	http.HandleFunc("/api/auth/register/barber", barberRegisterHandler)
 
 	// Защищенные маршруты (требуют JWT токен)
-	http.HandleFunc("/api/auth/logout", authHTTPHandler.Logout)
-	http.HandleFunc("/api/auth/profile", authHTTPHandler.GetProfile)
+	http.HandleFunc("/api/auth/logout", middleware.HTTPAuthMiddleware(authService)(authHTTPHandler.Logout))
+	http.HandleFunc("/api/auth/profile", middleware.HTTPAuthMiddleware(authService)(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch {
+			authHTTPHandler.UpdateProfile(w, r)
+			return
+		}
+		authHTTPHandler.GetProfile(w, r)
+	}))
 
 	// API для пользователей (защищенные)
 	http.HandleFunc("/api/users", userHandler.GetUsers)
 	http.HandleFunc("/api/users/", userHandler.GetUser)
 	http.HandleFunc("/api/users/create", userHandler.CreateUser)
 
-	// Админские маршруты для управления барберами (только админ)
+	// Админские маршруты для управления барберами (разрешения на ресурс barbers, действие по HTTP методу)
 	adminBarberHandler := middleware.HTTPAuthMiddleware(authService)(
-		middleware.HTTPRequireRoleMiddleware("admin")(barberHandler.AdminGetAllBarbers),
+		middleware.HTTPRequireResourcePermissionMiddleware(permissionService, "barbers")(barberHandler.AdminGetAllBarbers),
 	)
 	http.HandleFunc("/api/admin/barbers", adminBarberHandler)
 
 	// Создаем универсальный обработчик для всех операций с барберами по ID
 	adminBarberByIDHandler := middleware.HTTPAuthMiddleware(authService)(
-		middleware.HTTPRequireRoleMiddleware("admin")(func(w http.ResponseWriter, r *http.Request) {
+		middleware.HTTPRequireResourcePermissionMiddleware(permissionService, "barbers")(func(w http.ResponseWriter, r *http.Request) {
 			switch r.Method {
 			case http.MethodGet:
 				barberHandler.AdminGetBarber(w, r)
@@ -195,6 +334,339 @@ func setupAPIRoutes(userHandler *handlers.UserHandler, authHTTPHandler *handlers
 	log.Println("✅ API маршруты настроены")
 }
 
+// Настройка маршрутов каталога услуг
+func setupServiceRoutes(serviceHandler *handlers.ServiceHandler, authService services.AuthService) {
+	// Публичный каталог услуг
+	http.HandleFunc("/api/services", serviceHandler.ListServices)
+	http.HandleFunc("/api/services/", serviceHandler.GetService)
+
+	// Управление собственными услугами барбера
+	barberServicesHandler := middleware.HTTPAuthMiddleware(authService)(
+		middleware.HTTPRequireRoleMiddleware("barber")(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				serviceHandler.BarberGetServices(w, r)
+			case http.MethodPost:
+				serviceHandler.BarberCreateService(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		}),
+	)
+	http.HandleFunc("/api/barber/services", barberServicesHandler)
+
+	barberServiceByIDHandler := middleware.HTTPAuthMiddleware(authService)(
+		middleware.HTTPRequireRoleMiddleware("barber")(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPut:
+				serviceHandler.BarberUpdateService(w, r)
+			case http.MethodDelete:
+				serviceHandler.BarberDeleteService(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		}),
+	)
+	http.HandleFunc("/api/barber/services/", barberServiceByIDHandler)
+
+	log.Println("✅ Маршруты каталога услуг настроены")
+}
+
+// Настройка маршрутов записей
+func setupAppointmentRoutes(appointmentHandler *handlers.AppointmentHandler, authService services.AuthService, emailVerificationService services.EmailVerificationService) {
+	// Записи клиента; запись может требовать подтвержденного email (UNVERIFIED_EMAIL_RESTRICTIONS)
+	clientAppointmentsHandler := middleware.HTTPAuthMiddleware(authService)(
+		middleware.HTTPRequireRoleMiddleware("client")(middleware.HTTPRequireVerifiedEmailMiddleware(emailVerificationService, "appointments")(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				appointmentHandler.ClientGetAppointments(w, r)
+			case http.MethodPost:
+				appointmentHandler.ClientBook(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		})),
+	)
+	http.HandleFunc("/api/appointments", clientAppointmentsHandler)
+
+	clientAppointmentActionHandler := middleware.HTTPAuthMiddleware(authService)(
+		middleware.HTTPRequireRoleMiddleware("client")(appointmentHandler.ClientAction),
+	)
+	http.HandleFunc("/api/appointments/", clientAppointmentActionHandler)
+
+	// Записи к барберу
+	barberAppointmentsHandler := middleware.HTTPAuthMiddleware(authService)(
+		middleware.HTTPRequireRoleMiddleware("barber")(appointmentHandler.BarberGetAppointments),
+	)
+	http.HandleFunc("/api/barber/appointments", barberAppointmentsHandler)
+
+	barberAppointmentActionHandler := middleware.HTTPAuthMiddleware(authService)(
+		middleware.HTTPRequireRoleMiddleware("barber")(appointmentHandler.BarberAction),
+	)
+	http.HandleFunc("/api/barber/appointments/", barberAppointmentActionHandler)
+
+	log.Println("✅ Маршруты записей настроены")
+}
+
+// Настройка маршрутов свободного времени
+func setupAvailabilityRoutes(availabilityHandler *handlers.AvailabilityHandler) {
+	// Публичный endpoint: /api/barbers/{id}/availability
+	http.HandleFunc("/api/barbers/", availabilityHandler.GetAvailability)
+
+	log.Println("✅ Маршруты свободного времени настроены")
+}
+
+// Настройка маршрутов управления рабочими часами
+func setupWorkingHoursRoutes(workingHoursHandler *handlers.WorkingHoursHandler, authService services.AuthService) {
+	scheduleHandler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			workingHoursHandler.GetSchedule(w, r)
+		case http.MethodPut:
+			workingHoursHandler.ReplaceWeek(w, r)
+		case http.MethodPost:
+			workingHoursHandler.SetDay(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+
+	// Собственное расписание барбера
+	http.HandleFunc("/api/barber/working-hours", middleware.HTTPAuthMiddleware(authService)(
+		middleware.HTTPRequireRoleMiddleware("barber")(scheduleHandler),
+	))
+	http.HandleFunc("/api/barber/working-hours/", middleware.HTTPAuthMiddleware(authService)(
+		middleware.HTTPRequireRoleMiddleware("barber")(workingHoursHandler.DeleteDay),
+	))
+
+	// Расписание любого барбера для администратора
+	http.HandleFunc("/api/admin/barbers/{id}/working-hours", middleware.HTTPAuthMiddleware(authService)(
+		middleware.HTTPRequireRoleMiddleware("admin")(scheduleHandler),
+	))
+	http.HandleFunc("/api/admin/barbers/{id}/working-hours/{day}", middleware.HTTPAuthMiddleware(authService)(
+		middleware.HTTPRequireRoleMiddleware("admin")(workingHoursHandler.DeleteDay),
+	))
+
+	log.Println("✅ Маршруты рабочих часов настроены")
+}
+
+// Настройка маршрутов исключений из расписания
+func setupScheduleExceptionRoutes(exceptionHandler *handlers.ScheduleExceptionHandler, authService services.AuthService) {
+	exceptionsHandler := func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			exceptionHandler.GetExceptions(w, r)
+		case http.MethodPost:
+			exceptionHandler.CreateException(w, r)
+		default:
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+
+	// Собственные исключения барбера
+	http.HandleFunc("/api/barber/schedule-exceptions", middleware.HTTPAuthMiddleware(authService)(
+		middleware.HTTPRequireRoleMiddleware("barber")(exceptionsHandler),
+	))
+	http.HandleFunc("/api/barber/schedule-exceptions/", middleware.HTTPAuthMiddleware(authService)(
+		middleware.HTTPRequireRoleMiddleware("barber")(exceptionHandler.DeleteException),
+	))
+
+	// Исключения любого барбера для администратора
+	http.HandleFunc("/api/admin/barbers/{id}/schedule-exceptions", middleware.HTTPAuthMiddleware(authService)(
+		middleware.HTTPRequireRoleMiddleware("admin")(exceptionsHandler),
+	))
+	http.HandleFunc("/api/admin/barbers/{id}/schedule-exceptions/{exceptionID}", middleware.HTTPAuthMiddleware(authService)(
+		middleware.HTTPRequireRoleMiddleware("admin")(exceptionHandler.DeleteException),
+	))
+
+	log.Println("✅ Маршруты исключений из расписания настроены")
+}
+
+// Настройка маршрутов оплаты
+func setupPaymentRoutes(paymentHandler *handlers.PaymentHandler, authService services.AuthService) {
+	// Оплата записи клиентом
+	http.HandleFunc("/api/appointments/{id}/pay", middleware.HTTPAuthMiddleware(authService)(
+		middleware.HTTPRequireRoleMiddleware("client")(paymentHandler.ClientPay),
+	))
+	http.HandleFunc("/api/appointments/{id}/payments", middleware.HTTPAuthMiddleware(authService)(
+		middleware.HTTPRequireRoleMiddleware("client")(paymentHandler.ClientGetPayments),
+	))
+
+	// Платежи по записям барбера: просмотр, прием наличных и возврат
+	http.HandleFunc("/api/barber/appointments/{id}/payments", middleware.HTTPAuthMiddleware(authService)(
+		middleware.HTTPRequireRoleMiddleware("barber")(paymentHandler.BarberGetPayments),
+	))
+	http.HandleFunc("/api/barber/payments/", middleware.HTTPAuthMiddleware(authService)(
+		middleware.HTTPRequireRoleMiddleware("barber")(paymentHandler.BarberPaymentAction),
+	))
+
+	// Возврат администратором
+	http.HandleFunc("/api/admin/payments/", middleware.HTTPAuthMiddleware(authService)(
+		middleware.HTTPRequireRoleMiddleware("admin")(paymentHandler.AdminRefund),
+	))
+
+	// Уведомления платежных систем (проверяются подписью провайдера)
+	http.HandleFunc("/api/payments/webhook/", paymentHandler.Webhook)
+
+	log.Println("✅ Маршруты оплаты настроены")
+}
+
+// Настройка маршрутов разрешений
+func setupPermissionRoutes(permissionHandler *handlers.PermissionHandler, authService services.AuthService, permissionService services.PermissionService) {
+	// Разрешения текущего пользователя
+	http.HandleFunc("/api/auth/permissions", middleware.HTTPAuthMiddleware(authService)(permissionHandler.GetMyPermissions))
+
+	// Изменение разрешений роли без деплоя
+	http.HandleFunc("/api/admin/roles/{id}/permissions", middleware.HTTPAuthMiddleware(authService)(
+		middleware.HTTPRequirePermissionMiddleware(permissionService, "roles", models.ActionUpdate)(permissionHandler.SetRolePermissions),
+	))
+
+	log.Println("✅ Маршруты разрешений настроены")
+}
+
+// Настройка маршрутов администрирования ролей
+func setupRoleRoutes(roleHandler *handlers.RoleHandler, authService services.AuthService, permissionService services.PermissionService) {
+	// Список и создание ролей
+	http.HandleFunc("/api/admin/roles", middleware.HTTPAuthMiddleware(authService)(
+		middleware.HTTPRequireResourcePermissionMiddleware(permissionService, "roles")(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				roleHandler.ListRoles(w, r)
+			case http.MethodPost:
+				roleHandler.CreateRole(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		}),
+	))
+
+	// Просмотр, изменение и деактивация роли
+	http.HandleFunc("/api/admin/roles/{id}", middleware.HTTPAuthMiddleware(authService)(
+		middleware.HTTPRequireResourcePermissionMiddleware(permissionService, "roles")(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				roleHandler.GetRole(w, r)
+			case http.MethodPut:
+				roleHandler.UpdateRole(w, r)
+			case http.MethodDelete:
+				roleHandler.DeactivateRole(w, r)
+			default:
+				http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			}
+		}),
+	))
+
+	// Назначение и снятие ролей пользователям
+	http.HandleFunc("/api/admin/roles/assign", middleware.HTTPAuthMiddleware(authService)(
+		middleware.HTTPRequirePermissionMiddleware(permissionService, "roles", "assign")(roleHandler.AssignRole),
+	))
+	http.HandleFunc("/api/admin/roles/revoke", middleware.HTTPAuthMiddleware(authService)(
+		middleware.HTTPRequirePermissionMiddleware(permissionService, "roles", "revoke")(roleHandler.RevokeRole),
+	))
+	http.HandleFunc("/api/admin/users/{id}/roles", middleware.HTTPAuthMiddleware(authService)(
+		middleware.HTTPRequirePermissionMiddleware(permissionService, "roles", models.ActionRead)(roleHandler.GetUserRoles),
+	))
+
+	log.Println("✅ Маршруты администрирования ролей настроены")
+}
+
+// Настройка маршрутов сессий пользователя на устройствах
+func setupSessionRoutes(sessionHandler *handlers.SessionHandler, authService services.AuthService) {
+	http.HandleFunc("/api/auth/sessions", middleware.HTTPAuthMiddleware(authService)(sessionHandler.ListSessions))
+	http.HandleFunc("/api/auth/sessions/{id}", middleware.HTTPAuthMiddleware(authService)(sessionHandler.RevokeSession))
+	http.HandleFunc("/api/auth/sessions/revoke-others", middleware.HTTPAuthMiddleware(authService)(sessionHandler.RevokeOtherSessions))
+
+	log.Println("✅ Маршруты сессий настроены")
+}
+
+// Настройка маршрутов смены и восстановления пароля
+func setupPasswordRoutes(passwordHandler *handlers.PasswordHandler, authService services.AuthService) {
+	http.HandleFunc("/api/auth/password/forgot", passwordHandler.ForgotPassword) // Публичный
+	http.HandleFunc("/api/auth/password/reset", passwordHandler.ResetPassword)   // Публичный
+	http.HandleFunc("/api/auth/password/change", middleware.HTTPAuthMiddleware(authService)(passwordHandler.ChangePassword))
+
+	log.Println("✅ Маршруты смены и восстановления пароля настроены")
+}
+
+// Настройка маршрутов подтверждения email
+func setupEmailVerificationRoutes(emailVerificationHandler *handlers.EmailVerificationHandler, authService services.AuthService) {
+	http.HandleFunc("/api/auth/email/verify", emailVerificationHandler.VerifyEmail) // Публичный: переход по ссылке из письма
+	http.HandleFunc("/api/auth/email/resend", middleware.HTTPAuthMiddleware(authService)(emailVerificationHandler.ResendVerification))
+
+	log.Println("✅ Маршруты подтверждения email настроены")
+}
+
+// Настройка маршрутов двухфакторной аутентификации
+func setupTwoFactorRoutes(twoFactorHandler *handlers.TwoFactorHandler, authHTTPHandler *handlers.AuthHTTPHandler, authService services.AuthService) {
+	// Второй шаг входа по токену из ответа /api/auth/login, /api/auth/telegram или /api/auth/telegram/webapp
+	http.HandleFunc("/api/auth/login/2fa", authHTTPHandler.LoginTwoFactor)
+	http.HandleFunc("/api/auth/login/2fa/setup", authHTTPHandler.LoginTwoFactorSetup)
+	http.HandleFunc("/api/auth/login/2fa/confirm", authHTTPHandler.LoginTwoFactorConfirm)
+
+	// Управление 2FA текущего пользователя
+	http.HandleFunc("/api/auth/2fa", middleware.HTTPAuthMiddleware(authService)(twoFactorHandler.GetStatus))
+	http.HandleFunc("/api/auth/2fa/setup", middleware.HTTPAuthMiddleware(authService)(twoFactorHandler.Setup))
+	http.HandleFunc("/api/auth/2fa/confirm", middleware.HTTPAuthMiddleware(authService)(twoFactorHandler.Confirm))
+	http.HandleFunc("/api/auth/2fa/disable", middleware.HTTPAuthMiddleware(authService)(twoFactorHandler.Disable))
+	http.HandleFunc("/api/auth/2fa/recovery-codes", middleware.HTTPAuthMiddleware(authService)(twoFactorHandler.RegenerateRecoveryCodes))
+
+	log.Println("✅ Маршруты двухфакторной аутентификации настроены")
+}
+
+// Настройка маршрутов журнала аудита
+func setupAuditRoutes(auditHandler *handlers.AuditHandler, authService services.AuthService, permissionService services.PermissionService) {
+	http.HandleFunc("/api/admin/audit", middleware.HTTPAuthMiddleware(authService)(
+		middleware.HTTPRequirePermissionMiddleware(permissionService, "audit", models.ActionRead)(auditHandler.ListEvents),
+	))
+
+	log.Println("✅ Маршруты журнала аудита настроены")
+}
+
+// Настройка маршрутов способов входа и объединения аккаунтов
+func setupIdentityRoutes(identityHandler *handlers.IdentityHandler, authService services.AuthService, permissionService services.PermissionService) {
+	// Способы входа текущего пользователя: Telegram и email с паролем
+	http.HandleFunc("/api/auth/identities", middleware.HTTPAuthMiddleware(authService)(identityHandler.ListIdentities))
+	http.HandleFunc("/api/auth/identities/telegram", middleware.HTTPAuthMiddleware(authService)(identityHandler.Telegram))
+	http.HandleFunc("/api/auth/identities/password", middleware.HTTPAuthMiddleware(authService)(identityHandler.SetPassword))
+
+	// Объединение дубликата с аккаунтом; дубликат удаляется
+	http.HandleFunc("/api/admin/users/{id}/merge", middleware.HTTPAuthMiddleware(authService)(
+		middleware.HTTPRequirePermissionMiddleware(permissionService, "users", models.ActionDelete)(identityHandler.MergeUsers),
+	))
+
+	log.Println("✅ Маршруты способов входа настроены")
+}
+
+// Настройка маршрутов снятия блокировки входа (администратор)
+func setupLoginLockoutRoutes(loginLockoutHandler *handlers.LoginLockoutHandler, authService services.AuthService, permissionService services.PermissionService) {
+	http.HandleFunc("/api/admin/users/{id}/unlock", middleware.HTTPAuthMiddleware(authService)(
+		middleware.HTTPRequirePermissionMiddleware(permissionService, "users", models.ActionUpdate)(loginLockoutHandler.UnlockUser),
+	))
+
+	log.Println("✅ Маршруты блокировок входа настроены")
+}
+
+// Настройка маршрутов отзывов
+func setupReviewRoutes(reviewHandler *handlers.ReviewHandler, authService services.AuthService) {
+	// Публичный список отзывов барбера
+	http.HandleFunc("/api/barbers/{id}/reviews", reviewHandler.GetBarberReviews)
+
+	// Отзыв клиента на свою запись
+	http.HandleFunc("/api/appointments/{id}/review", middleware.HTTPAuthMiddleware(authService)(
+		middleware.HTTPRequireRoleMiddleware("client")(reviewHandler.ClientReview),
+	))
+
+	// Модерация отзывов администратором
+	http.HandleFunc("/api/admin/reviews", middleware.HTTPAuthMiddleware(authService)(
+		middleware.HTTPRequireRoleMiddleware("admin")(reviewHandler.AdminListReviews),
+	))
+	http.HandleFunc("/api/admin/reviews/", middleware.HTTPAuthMiddleware(authService)(
+		middleware.HTTPRequireRoleMiddleware("admin")(reviewHandler.AdminReviewAction),
+	))
+
+	log.Println("✅ Маршруты отзывов настроены")
+}
+
 // Middleware для логирования HTTP запросов
 func loggingMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -387,7 +859,7 @@ func main() {
 			},
 			"Review": map[string]interface{}{
 				"description": "Отзывы клиентов",
-				"fields":      []string{"ID", "Rating", "Comment", "ClientID", "BarberID", "AppointmentID"},
+				"fields":      []string{"ID", "Rating", "Comment", "IsHidden", "HiddenReason", "HiddenAt", "ClientID", "BarberID", "AppointmentID"},
 			},
 		}
 
@@ -405,5 +877,6 @@ func main() {
 		log.Printf("🚀 Garage Barbershop сервер запускается на порту %s", port)
 	}
 
-	log.Fatal(http.ListenAndServe(":"+port, nil))
+	// IP клиента для журнала аудита, сессий и защиты входа берется из заголовков только доверенных прокси
+	log.Fatal(http.ListenAndServe(":"+port, middleware.HTTPClientIPMiddleware(cfg.TrustedProxies)(http.DefaultServeMux.ServeHTTP)))
 }
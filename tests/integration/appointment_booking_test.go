@@ -0,0 +1,304 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"garage-barbershop/internal/database"
+	"garage-barbershop/internal/handlers"
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/repositories"
+	"garage-barbershop/internal/services"
+
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// AppointmentBookingTestSuite набор тестов для бронирования записей
+type AppointmentBookingTestSuite struct {
+	suite.Suite
+	db                 *database.Database
+	userRepo           repositories.UserRepository
+	roleRepo           repositories.RoleRepository
+	appointmentService services.AppointmentService
+	exceptionService   services.ScheduleExceptionService
+	appointmentHandler *handlers.AppointmentHandler
+
+	barber  *models.User
+	service *models.Service
+	clients []*models.User
+}
+
+// SetupSuite инициализирует тестовую среду
+func (suite *AppointmentBookingTestSuite) SetupSuite() {
+	// Файловая БД: параллельные горутины используют разные соединения
+	dsn := filepath.Join(suite.T().TempDir(), "booking.db") + "?_busy_timeout=5000"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	suite.Require().NoError(err)
+
+	suite.db = &database.Database{DB: db}
+	err = suite.db.Migrate(&models.User{}, &models.Role{}, &models.UserRole{}, &models.Service{}, &models.Appointment{}, &models.WorkingHours{}, &models.ScheduleException{})
+	suite.Require().NoError(err)
+
+	suite.userRepo = repositories.NewUserRepository(db)
+	suite.roleRepo = repositories.NewRoleRepository(db)
+	serviceRepo := repositories.NewServiceRepository(db)
+	appointmentRepo := repositories.NewAppointmentRepository(db)
+	exceptionRepo := repositories.NewScheduleExceptionRepository(db)
+	// Время записей в тестах задается в UTC, в нем же и рабочие часы барбера
+	timezoneService := services.NewTimezoneService(suite.userRepo, time.UTC)
+	suite.appointmentService = services.NewAppointmentService(appointmentRepo, serviceRepo, suite.userRepo, repositories.NewWorkingHoursRepository(db),
+		exceptionRepo, timezoneService)
+	suite.exceptionService = services.NewScheduleExceptionService(exceptionRepo, suite.roleRepo, timezoneService)
+	suite.appointmentHandler = handlers.NewAppointmentHandler(suite.appointmentService)
+}
+
+// TearDownSuite очищает тестовую среду
+func (suite *AppointmentBookingTestSuite) TearDownSuite() {
+	sqlDB, err := suite.db.DB.DB()
+	suite.Require().NoError(err)
+	sqlDB.Close()
+}
+
+// SetupTest создает барбера, услугу и клиентов перед каждым тестом
+func (suite *AppointmentBookingTestSuite) SetupTest() {
+	suite.db.DB.Exec("DELETE FROM schedule_exceptions")
+	suite.db.DB.Exec("DELETE FROM appointments")
+	suite.db.DB.Exec("DELETE FROM working_hours")
+	suite.db.DB.Exec("DELETE FROM services")
+	suite.db.DB.Exec("DELETE FROM user_roles")
+	suite.db.DB.Exec("DELETE FROM users")
+
+	suite.barber = &models.User{TelegramID: 1, Email: "barber@example.com", IsActive: true}
+	suite.Require().NoError(suite.userRepo.Create(suite.barber))
+	role, err := suite.roleRepo.GetRoleByName("barber")
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.roleRepo.AssignRoleToUser(suite.barber.ID, role.ID, suite.barber.ID))
+
+	suite.service = &models.Service{Name: "Стрижка", Price: 1000, Duration: 60, IsActive: true, BarberID: suite.barber.ID}
+	suite.Require().NoError(suite.db.DB.Create(suite.service).Error)
+
+	for day := 1; day <= 7; day++ {
+		suite.Require().NoError(suite.db.DB.Create(&models.WorkingHours{
+			DayOfWeek: day, StartTime: "09:00", EndTime: "21:00", BreakStart: "18:00", BreakEnd: "19:00", IsActive: true, BarberID: suite.barber.ID,
+		}).Error)
+	}
+
+	suite.clients = nil
+	for i := 0; i < 20; i++ {
+		client := &models.User{TelegramID: int64(100 + i), Email: fmt.Sprintf("client%d@example.com", i), IsActive: true}
+		suite.Require().NoError(suite.userRepo.Create(client))
+		suite.clients = append(suite.clients, client)
+	}
+}
+
+// slot возвращает время записи в будущем
+func (suite *AppointmentBookingTestSuite) slot(hour, minute int) time.Time {
+	tomorrow := time.Now().UTC().Add(24 * time.Hour)
+	return time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), hour, minute, 0, 0, time.UTC)
+}
+
+// TestConcurrentBooking_SameSlot тестирует, что из параллельных бронирований одного слота проходит только одно
+func (suite *AppointmentBookingTestSuite) TestConcurrentBooking_SameSlot() {
+	dateTime := suite.slot(12, 0)
+
+	var wg sync.WaitGroup
+	results := make(chan error, len(suite.clients))
+	start := make(chan struct{})
+
+	for _, client := range suite.clients {
+		wg.Add(1)
+		go func(clientID uint) {
+			defer wg.Done()
+			<-start
+			_, err := suite.appointmentService.BookAppointment(clientID, models.AppointmentCreateRequest{
+				ServiceID: suite.service.ID,
+				DateTime:  dateTime,
+			})
+			results <- err
+		}(client.ID)
+	}
+
+	close(start)
+	wg.Wait()
+	close(results)
+
+	succeeded, conflicts := 0, 0
+	for err := range results {
+		switch {
+		case err == nil:
+			succeeded++
+		case err == services.ErrSlotUnavailable:
+			conflicts++
+		default:
+			suite.Failf("неожиданная ошибка", "%v", err)
+		}
+	}
+
+	suite.Equal(1, succeeded)
+	suite.Equal(len(suite.clients)-1, conflicts)
+
+	var count int64
+	suite.db.DB.Model(&models.Appointment{}).Where("barber_id = ?", suite.barber.ID).Count(&count)
+	suite.Equal(int64(1), count)
+}
+
+// TestConcurrentBookingAndTimeOff тестирует, что выходной, создаваемый параллельно с бронированиями,
+// либо закрывает день целиком, либо отклоняется из-за уже созданных записей
+func (suite *AppointmentBookingTestSuite) TestConcurrentBookingAndTimeOff() {
+	hours := []int{9, 10, 11, 12, 13, 14, 15, 16, 17, 19}
+
+	var wg sync.WaitGroup
+	results := make(chan error, len(hours))
+	start := make(chan struct{})
+
+	for i, hour := range hours {
+		wg.Add(1)
+		go func(clientID uint, dateTime time.Time) {
+			defer wg.Done()
+			<-start
+			_, err := suite.appointmentService.BookAppointment(clientID, models.AppointmentCreateRequest{
+				ServiceID: suite.service.ID,
+				DateTime:  dateTime,
+			})
+			results <- err
+		}(suite.clients[i].ID, suite.slot(hour, 0))
+	}
+
+	var exceptionErr error
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		<-start
+		_, exceptionErr = suite.exceptionService.CreateException(suite.barber.ID, models.ScheduleExceptionRequest{
+			StartDate: suite.slot(0, 0).Format(models.DateLayout),
+			IsFullDay: true,
+		})
+	}()
+
+	close(start)
+	wg.Wait()
+	close(results)
+
+	booked := 0
+	for err := range results {
+		switch {
+		case err == nil:
+			booked++
+		case errors.Is(err, services.ErrSlotUnavailable):
+		default:
+			suite.Failf("неожиданная ошибка", "%v", err)
+		}
+	}
+
+	var exceptions, appointments int64
+	suite.db.DB.Model(&models.ScheduleException{}).Count(&exceptions)
+	suite.db.DB.Model(&models.Appointment{}).Count(&appointments)
+	suite.Equal(int64(booked), appointments)
+	if exceptionErr == nil {
+		suite.Equal(int64(1), exceptions)
+		suite.Zero(booked)
+	} else {
+		suite.ErrorIs(exceptionErr, services.ErrScheduleConflict)
+		suite.Zero(exceptions)
+		suite.Equal(len(hours), booked)
+	}
+}
+
+// TestBooking_PartialOverlap тестирует, что частично пересекающиеся записи отклоняются
+func (suite *AppointmentBookingTestSuite) TestBooking_PartialOverlap() {
+	_, err := suite.appointmentService.BookAppointment(suite.clients[0].ID, models.AppointmentCreateRequest{
+		ServiceID: suite.service.ID,
+		DateTime:  suite.slot(12, 0),
+	})
+	suite.Require().NoError(err)
+
+	// 12:30 пересекается с записью 12:00-13:00
+	_, err = suite.appointmentService.BookAppointment(suite.clients[1].ID, models.AppointmentCreateRequest{
+		ServiceID: suite.service.ID,
+		DateTime:  suite.slot(12, 30),
+	})
+	suite.ErrorIs(err, services.ErrSlotUnavailable)
+
+	// 13:00 начинается сразу после окончания
+	_, err = suite.appointmentService.BookAppointment(suite.clients[1].ID, models.AppointmentCreateRequest{
+		ServiceID: suite.service.ID,
+		DateTime:  suite.slot(13, 0),
+	})
+	suite.NoError(err)
+}
+
+// TestBooking_CancelledFreesSlot тестирует, что отмененная запись освобождает время
+func (suite *AppointmentBookingTestSuite) TestBooking_CancelledFreesSlot() {
+	appointment, err := suite.appointmentService.BookAppointment(suite.clients[0].ID, models.AppointmentCreateRequest{
+		ServiceID: suite.service.ID,
+		DateTime:  suite.slot(15, 0),
+	})
+	suite.Require().NoError(err)
+
+	_, err = suite.appointmentService.CancelByClient(suite.clients[0].ID, appointment.ID)
+	suite.Require().NoError(err)
+
+	_, err = suite.appointmentService.BookAppointment(suite.clients[1].ID, models.AppointmentCreateRequest{
+		ServiceID: suite.service.ID,
+		DateTime:  suite.slot(15, 0),
+	})
+	suite.NoError(err)
+}
+
+// TestBooking_OutsideWorkingHours тестирует, что нельзя записаться на время, которое не предлагается как свободное
+func (suite *AppointmentBookingTestSuite) TestBooking_OutsideWorkingHours() {
+	book := func(dateTime time.Time) error {
+		_, err := suite.appointmentService.BookAppointment(suite.clients[0].ID, models.AppointmentCreateRequest{
+			ServiceID: suite.service.ID,
+			DateTime:  dateTime,
+		})
+		return err
+	}
+
+	suite.ErrorIs(book(suite.slot(8, 0)), services.ErrOutsideWorkingHours)   // до начала работы
+	suite.ErrorIs(book(suite.slot(20, 30)), services.ErrOutsideWorkingHours) // заканчивается после конца работы
+	suite.ErrorIs(book(suite.slot(17, 30)), services.ErrOutsideWorkingHours) // захватывает перерыв
+
+	// Выходной день: рабочих часов нет
+	suite.db.DB.Where("barber_id = ? AND day_of_week = ?", suite.barber.ID, models.ISODayOfWeek(suite.slot(12, 0))).Delete(&models.WorkingHours{})
+	suite.ErrorIs(book(suite.slot(12, 0)), services.ErrOutsideWorkingHours)
+
+	var count int64
+	suite.db.DB.Model(&models.Appointment{}).Count(&count)
+	suite.Zero(count)
+}
+
+// TestBookingAPI_Conflict тестирует, что API возвращает 409 при занятом времени
+func (suite *AppointmentBookingTestSuite) TestBookingAPI_Conflict() {
+	book := func(clientID uint) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(models.AppointmentCreateRequest{
+			ServiceID: suite.service.ID,
+			DateTime:  suite.slot(10, 0),
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/appointments", bytes.NewBuffer(body))
+		req = req.WithContext(context.WithValue(req.Context(), "userID", clientID))
+		w := httptest.NewRecorder()
+		suite.appointmentHandler.ClientBook(w, req)
+		return w
+	}
+
+	suite.Equal(http.StatusCreated, book(suite.clients[0].ID).Code)
+	suite.Equal(http.StatusConflict, book(suite.clients[1].ID).Code)
+}
+
+// TestAppointmentBookingTestSuite запускает все тесты
+func TestAppointmentBookingTestSuite(t *testing.T) {
+	suite.Run(t, new(AppointmentBookingTestSuite))
+}
@@ -0,0 +1,292 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"garage-barbershop/internal/database"
+	"garage-barbershop/internal/handlers"
+	"garage-barbershop/internal/middleware"
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/repositories"
+	"garage-barbershop/internal/services"
+
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// AuditTestSuite набор тестов журнала аудита
+type AuditTestSuite struct {
+	suite.Suite
+	db           *database.Database
+	userRepo     repositories.UserRepository
+	roleRepo     repositories.RoleRepository
+	authService  services.AuthService
+	auditService services.AuditService
+	mux          *http.ServeMux
+	admin        *models.User
+	barber       *models.User
+}
+
+// SetupSuite инициализирует тестовую среду и маршруты
+func (suite *AuditTestSuite) SetupSuite() {
+	db, err := gorm.Open(sqlite.Open("file:audit?mode=memory&cache=shared"), &gorm.Config{})
+	suite.Require().NoError(err)
+
+	suite.db = &database.Database{DB: db}
+	err = suite.db.Migrate(&models.User{}, &models.Role{}, &models.UserRole{}, &models.AuditEvent{}, &models.Session{})
+	suite.Require().NoError(err)
+
+	suite.userRepo = repositories.NewUserRepository(db)
+	suite.roleRepo = repositories.NewRoleRepository(db)
+	authServices := newAuthTestServices(db, authTestConfig{})
+	suite.authService = authServices.auth
+	suite.auditService = authServices.audit
+
+	barberHandler := handlers.NewBarberHandler(services.NewBarberService(suite.userRepo, suite.roleRepo, suite.auditService, authServices.revocation))
+	roleHandler := handlers.NewRoleHandler(services.NewRoleService(suite.roleRepo), services.NewPermissionService(suite.roleRepo), suite.auditService, authServices.revocation)
+	authHandler := authServices.handler
+	auditHandler := handlers.NewAuditHandler(suite.auditService)
+
+	suite.mux = http.NewServeMux()
+	suite.mux.HandleFunc("/api/admin/barbers/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			barberHandler.AdminDeleteBarber(w, r)
+			return
+		}
+		barberHandler.AdminUpdateBarber(w, r)
+	})
+	suite.mux.HandleFunc("/api/admin/roles/assign", roleHandler.AssignRole)
+	suite.mux.HandleFunc("/api/admin/roles/revoke", roleHandler.RevokeRole)
+	suite.mux.HandleFunc("/api/auth/login", authHandler.LoginDirect)
+	suite.mux.HandleFunc("/api/admin/audit", auditHandler.ListEvents)
+}
+
+// TearDownSuite очищает тестовую среду
+func (suite *AuditTestSuite) TearDownSuite() {
+	sqlDB, err := suite.db.DB.DB()
+	suite.Require().NoError(err)
+	sqlDB.Close()
+}
+
+// SetupTest создает администратора и барбера
+func (suite *AuditTestSuite) SetupTest() {
+	suite.db.DB.Exec("DELETE FROM audit_events")
+	suite.db.DB.Exec("DELETE FROM user_roles")
+	suite.db.DB.Exec("DELETE FROM users")
+
+	suite.admin = &models.User{TelegramID: 101, Email: "audit-admin@example.com", IsActive: true}
+	suite.Require().NoError(suite.userRepo.Create(suite.admin))
+	suite.barber = &models.User{TelegramID: 102, Email: "audit-barber@example.com", FirstName: "Иван", IsActive: true}
+	suite.Require().NoError(suite.userRepo.Create(suite.barber))
+
+	adminRole, err := suite.roleRepo.GetRoleByName("admin")
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.roleRepo.AssignRoleToUser(suite.admin.ID, adminRole.ID, suite.admin.ID))
+	barberRole, err := suite.roleRepo.GetRoleByName("barber")
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.roleRepo.AssignRoleToUser(suite.barber.ID, barberRole.ID, suite.admin.ID))
+}
+
+// request выполняет запрос; userID = 0 означает анонимный запрос
+func (suite *AuditTestSuite) request(method, path string, body interface{}, userID uint) *httptest.ResponseRecorder {
+	var payload bytes.Buffer
+	if body != nil {
+		suite.Require().NoError(json.NewEncoder(&payload).Encode(body))
+	}
+	req := httptest.NewRequest(method, path, &payload)
+	req.RemoteAddr = "192.0.2.10:54321"
+	req.Header.Set("User-Agent", "audit-test/1.0")
+	if userID != 0 {
+		req = req.WithContext(context.WithValue(req.Context(), "userID", userID))
+	}
+
+	w := httptest.NewRecorder()
+	suite.mux.ServeHTTP(w, req)
+	return w
+}
+
+// events возвращает события журнала по фильтру
+func (suite *AuditTestSuite) events(filter models.AuditFilter) []models.AuditEvent {
+	events, _, err := suite.auditService.List(filter)
+	suite.Require().NoError(err)
+	return events
+}
+
+// TestBarberUpdateRecordsDiff тестирует запись изменений барбера с разницей состояний
+func (suite *AuditTestSuite) TestBarberUpdateRecordsDiff() {
+	// Act
+	path := "/api/admin/barbers/" + strconv.FormatUint(uint64(suite.barber.ID), 10)
+	w := suite.request(http.MethodPut, path, models.BarberUpdateRequest{FirstName: "Пётр", Experience: 5}, suite.admin.ID)
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+
+	// Assert
+	events := suite.events(models.AuditFilter{Action: models.AuditActionBarberUpdate})
+	suite.Require().Len(events, 1)
+	event := events[0]
+	suite.Require().NotNil(event.ActorID)
+	suite.Equal(suite.admin.ID, *event.ActorID)
+	suite.Require().NotNil(event.TargetID)
+	suite.Equal(suite.barber.ID, *event.TargetID)
+	suite.Equal(models.AuditTargetUser, event.TargetType)
+	suite.Equal("192.0.2.10", event.IP)
+	suite.Equal("audit-test/1.0", event.UserAgent)
+	suite.True(event.Success)
+
+	var changes map[string]models.AuditChange
+	suite.Require().NoError(json.Unmarshal([]byte(event.Changes), &changes))
+	suite.Len(changes, 2)
+	suite.Equal("Иван", changes["first_name"].Before)
+	suite.Equal("Пётр", changes["first_name"].After)
+	suite.EqualValues(5, changes["experience"].After)
+
+	// Удаление записывает состояние до удаления
+	w = suite.request(http.MethodDelete, path, nil, suite.admin.ID)
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+
+	events = suite.events(models.AuditFilter{Action: models.AuditActionBarberDelete, TargetID: suite.barber.ID})
+	suite.Require().Len(events, 1)
+	suite.Contains(events[0].Changes, `"email":{"before":"audit-barber@example.com","after":null}`)
+}
+
+// TestBarberUpdateKeepsRating тестирует, что рейтинг считается только по отзывам и не задается вручную
+func (suite *AuditTestSuite) TestBarberUpdateKeepsRating() {
+	path := "/api/admin/barbers/" + strconv.FormatUint(uint64(suite.barber.ID), 10)
+	w := suite.request(http.MethodPut, path, map[string]interface{}{"first_name": "Пётр", "rating": 1.5}, suite.admin.ID)
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+
+	barber, err := suite.userRepo.GetByID(suite.barber.ID)
+	suite.Require().NoError(err)
+	suite.Equal("Пётр", barber.FirstName)
+	suite.Equal(suite.barber.Rating, barber.Rating)
+}
+
+// TestAuditIPFromTrustedProxyOnly тестирует, что заголовки прокси учитываются только от доверенного прокси
+func (suite *AuditTestSuite) TestAuditIPFromTrustedProxyOnly() {
+	handler := middleware.HTTPClientIPMiddleware([]string{"10.0.0.0/8"})(suite.mux.ServeHTTP)
+	path := "/api/admin/barbers/" + strconv.FormatUint(uint64(suite.barber.ID), 10)
+	update := func(remoteAddr, forwarded, name string) {
+		body, _ := json.Marshal(models.BarberUpdateRequest{FirstName: name})
+		req := httptest.NewRequest(http.MethodPut, path, bytes.NewReader(body))
+		req.RemoteAddr = remoteAddr
+		req.Header.Set("X-Forwarded-For", forwarded)
+		req.Header.Set("X-Real-IP", "203.0.113.99")
+		req = req.WithContext(context.WithValue(req.Context(), "userID", suite.admin.ID))
+		w := httptest.NewRecorder()
+		handler(w, req)
+		suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	}
+
+	// Клиент напрямую: заголовки подделаны и игнорируются
+	update("192.0.2.10:54321", "203.0.113.1", "Пётр")
+	// Через доверенный прокси: берется последний адрес до прокси, подставленный клиентом адрес слева не учитывается
+	update("10.0.0.5:443", "203.0.113.1, 198.51.100.7, 10.0.0.9", "Павел")
+
+	events := suite.events(models.AuditFilter{Action: models.AuditActionBarberUpdate})
+	suite.Require().Len(events, 2)
+	ips := []string{events[0].IP, events[1].IP}
+	suite.ElementsMatch([]string{"192.0.2.10", "198.51.100.7"}, ips)
+}
+
+// TestRoleChangesAreRecorded тестирует запись назначения и снятия ролей
+func (suite *AuditTestSuite) TestRoleChangesAreRecorded() {
+	clientRole, err := suite.roleRepo.GetRoleByName("client")
+	suite.Require().NoError(err)
+
+	// Act
+	w := suite.request(http.MethodPost, "/api/admin/roles/assign", models.RoleAssignmentRequest{UserID: suite.barber.ID, RoleID: clientRole.ID, Reason: "пробная запись"}, suite.admin.ID)
+	suite.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+	w = suite.request(http.MethodPost, "/api/admin/roles/revoke", models.RoleRemovalRequest{UserID: suite.barber.ID, RoleID: clientRole.ID, Reason: "ошибка"}, suite.admin.ID)
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+
+	// Assert
+	assigned := suite.events(models.AuditFilter{Action: models.AuditActionRoleAssign})
+	suite.Require().Len(assigned, 1)
+	suite.Equal("пробная запись", assigned[0].Details)
+	suite.JSONEq(`{"roles": {"before": ["barber"], "after": ["barber", "client"]}}`, assigned[0].Changes)
+
+	revoked := suite.events(models.AuditFilter{Action: models.AuditActionRoleRevoke})
+	suite.Require().Len(revoked, 1)
+	suite.Equal("ошибка", revoked[0].Details)
+	suite.JSONEq(`{"roles": {"before": ["barber", "client"], "after": ["barber"]}}`, revoked[0].Changes)
+}
+
+// TestLoginAttemptsAreRecorded тестирует запись успешных и неудачных входов
+func (suite *AuditTestSuite) TestLoginAttemptsAreRecorded() {
+	// Arrange
+	_, err := suite.authService.RegisterUserDirect(models.DirectRegisterRequest{
+		Email:     "audit-login@example.com",
+		Password:  "fresh-fade-42",
+		FirstName: "Анна",
+		Role:      "client",
+	})
+	suite.Require().NoError(err)
+
+	// Act
+	w := suite.request(http.MethodPost, "/api/auth/login", models.DirectLoginRequest{Email: "audit-login@example.com", Password: "wrong"}, 0)
+	suite.Equal(http.StatusUnauthorized, w.Code)
+	w = suite.request(http.MethodPost, "/api/auth/login", models.DirectLoginRequest{Email: "audit-login@example.com", Password: "fresh-fade-42"}, 0)
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+
+	// Assert
+	failed := suite.events(models.AuditFilter{Action: models.AuditActionLoginFailure})
+	suite.Require().Len(failed, 1)
+	suite.False(failed[0].Success)
+	suite.Nil(failed[0].ActorID)
+	suite.Equal("email: audit-login@example.com", failed[0].Details)
+
+	succeeded := suite.events(models.AuditFilter{Action: models.AuditActionLoginSuccess})
+	suite.Require().Len(succeeded, 1)
+	suite.True(succeeded[0].Success)
+	suite.Require().NotNil(succeeded[0].ActorID)
+	suite.Equal(*succeeded[0].ActorID, *succeeded[0].TargetID)
+}
+
+// TestListEventsFiltersAndPaginates тестирует фильтрацию и пагинацию журнала
+func (suite *AuditTestSuite) TestListEventsFiltersAndPaginates() {
+	// Arrange
+	for i := 0; i < 3; i++ {
+		suite.auditService.Record(models.AuditActor{UserID: suite.admin.ID}, models.AuditEntry{
+			Action:     models.AuditActionBarberUpdate,
+			TargetType: models.AuditTargetUser,
+			TargetID:   suite.barber.ID,
+		})
+	}
+	suite.auditService.Record(models.AuditActor{}, models.AuditEntry{Action: models.AuditActionLoginFailure, Failed: true})
+
+	// Act
+	w := suite.request(http.MethodGet, "/api/admin/audit?actor_id="+strconv.FormatUint(uint64(suite.admin.ID), 10)+"&page=2&page_size=2", nil, suite.admin.ID)
+
+	// Assert
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	var page struct {
+		Events   []models.AuditEvent `json:"events"`
+		Count    int                 `json:"count"`
+		Total    int64               `json:"total"`
+		Page     int                 `json:"page"`
+		PageSize int                 `json:"page_size"`
+	}
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &page))
+	suite.Equal(1, page.Count)
+	suite.EqualValues(3, page.Total)
+	suite.Equal(2, page.Page)
+	suite.Equal(2, page.PageSize)
+
+	w = suite.request(http.MethodGet, "/api/admin/audit?action=auth.login_failed", nil, suite.admin.ID)
+	suite.Require().Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), `"total":1`)
+
+	w = suite.request(http.MethodGet, "/api/admin/audit?from=yesterday", nil, suite.admin.ID)
+	suite.Equal(http.StatusBadRequest, w.Code)
+}
+
+// TestAuditTestSuite запускает набор тестов
+func TestAuditTestSuite(t *testing.T) {
+	suite.Run(t, new(AuditTestSuite))
+}
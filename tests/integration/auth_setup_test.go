@@ -0,0 +1,132 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"garage-barbershop/internal/handlers"
+	"garage-barbershop/internal/mailer"
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/repositories"
+	"garage-barbershop/internal/services"
+
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+// authTestConfig отличия тестовой сборки аутентификации от настроек по умолчанию;
+// нулевое значение поля означает значение по умолчанию
+type authTestConfig struct {
+	botToken              string                        // по умолчанию "test_bot_token"
+	sessionLifetime       time.Duration                 // по умолчанию services.DefaultSessionMaxLifetime
+	mailer                mailer.Mailer                 // по умолчанию письма пишутся в лог
+	restrictions          []string                      // действия, недоступные без подтверждения email
+	throttle              services.LoginThrottleService // по умолчанию политики DefaultEmailLoginPolicy и DefaultIPLoginPolicy
+	requireAdminTwoFactor bool
+}
+
+// authTestServices сервисы аутентификации и HTTP обработчик входа, связанные так же, как в main.go
+type authTestServices struct {
+	userRepo     repositories.UserRepository
+	roleRepo     repositories.RoleRepository
+	sessionRepo  repositories.SessionRepository
+	revocation   services.TokenRevocationService
+	auth         services.AuthService
+	audit        services.AuditService
+	verification services.EmailVerificationService
+	throttle     services.LoginThrottleService
+	twoFactor    services.TwoFactorService
+	handler      *handlers.AuthHTTPHandler
+}
+
+// newAuthTestServices собирает сервисы аутентификации поверх тестовой БД
+func newAuthTestServices(db *gorm.DB, config authTestConfig) *authTestServices {
+	if config.botToken == "" {
+		config.botToken = "test_bot_token"
+	}
+	if config.mailer == nil {
+		config.mailer = mailer.NewLogMailer()
+	}
+	if config.throttle == nil {
+		config.throttle = services.NewLoginThrottleService(nil, services.DefaultEmailLoginPolicy, services.DefaultIPLoginPolicy)
+	}
+
+	s := &authTestServices{
+		userRepo:    repositories.NewUserRepository(db),
+		roleRepo:    repositories.NewRoleRepository(db),
+		sessionRepo: repositories.NewSessionRepository(db),
+		revocation:  services.NewTokenRevocationService(nil),
+		audit:       services.NewAuditService(repositories.NewAuditRepository(db)),
+		throttle:    config.throttle,
+	}
+	s.auth = services.NewAuthService(s.userRepo, s.roleRepo, s.sessionRepo, s.revocation, "test_secret", config.botToken, config.sessionLifetime, nil)
+	s.verification = services.NewEmailVerificationService(s.userRepo, config.mailer, "test_secret", "https://barbershop.example/verify", config.restrictions)
+	s.twoFactor = services.NewTwoFactorService(s.userRepo, repositories.NewTwoFactorRepository(db), s.roleRepo, "test_secret", config.requireAdminTwoFactor)
+	s.handler = handlers.NewAuthHTTPHandler(s.auth, s.audit, s.verification, s.throttle, s.twoFactor)
+	return s
+}
+
+// testPassword пароль тестовых пользователей, зарегистрированных по email
+const testPassword = "fresh-fade-42"
+
+// apiTestSuite основа наборов тестов, которые обращаются к API через маршруты mux
+type apiTestSuite struct {
+	suite.Suite
+	mux *http.ServeMux
+}
+
+// newRequest создает запрос с JSON телом; accessToken может быть пустым
+func (suite *apiTestSuite) newRequest(method, path, accessToken string, body interface{}) *http.Request {
+	var payload bytes.Buffer
+	if body != nil {
+		suite.Require().NoError(json.NewEncoder(&payload).Encode(body))
+	}
+	req := httptest.NewRequest(method, path, &payload)
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	return req
+}
+
+// serve выполняет запрос через маршруты набора
+func (suite *apiTestSuite) serve(req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	suite.mux.ServeHTTP(w, req)
+	return w
+}
+
+// request выполняет запрос; accessToken может быть пустым
+func (suite *apiTestSuite) request(method, path, accessToken string, body interface{}) *httptest.ResponseRecorder {
+	return suite.serve(suite.newRequest(method, path, accessToken, body))
+}
+
+// decode разбирает JSON ответ успешного запроса
+func (suite *apiTestSuite) decode(w *httptest.ResponseRecorder, v interface{}) {
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), v))
+}
+
+// loginRequest входит по email и паролю и возвращает ответ сервера
+func (suite *apiTestSuite) loginRequest(email, password string) *httptest.ResponseRecorder {
+	return suite.request(http.MethodPost, "/api/auth/login", "", models.DirectLoginRequest{Email: email, Password: password})
+}
+
+// login входит по email и паролю и возвращает код ответа; токены заполнены только при успешном входе
+func (suite *apiTestSuite) login(email, password string) (int, models.AuthResponse) {
+	w := suite.loginRequest(email, password)
+	var response models.AuthResponse
+	if w.Code == http.StatusOK {
+		suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	}
+	return w.Code, response
+}
+
+// mustLogin входит с паролем тестовых пользователей и возвращает токены
+func (suite *apiTestSuite) mustLogin(email string) models.AuthResponse {
+	var response models.AuthResponse
+	suite.decode(suite.loginRequest(email, testPassword), &response)
+	return response
+}
@@ -10,7 +10,6 @@ import (
 	"garage-barbershop/internal/database"
 	"garage-barbershop/internal/handlers"
 	"garage-barbershop/internal/models"
-	"garage-barbershop/internal/repositories"
 	"garage-barbershop/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -38,16 +37,15 @@ func (suite *DirectAuthTestSuite) SetupSuite() {
 	testDB := &database.Database{DB: db}
 
 	// Выполняем миграции
-	err = testDB.Migrate(&models.User{}, &models.Role{}, &models.UserRole{})
+	err = testDB.Migrate(&models.User{}, &models.Role{}, &models.UserRole{}, &models.AuditEvent{}, &models.Session{})
 	suite.Require().NoError(err)
 
 	suite.db = testDB
 
 	// Создаем сервисы (Redis = nil для упрощения)
-	userRepo := repositories.NewUserRepository(suite.db.DB)
-	roleRepo := repositories.NewRoleRepository(suite.db.DB)
-	suite.authService = services.NewAuthService(userRepo, roleRepo, nil, "test_secret", "test_bot_token")
-	suite.authHandler = handlers.NewAuthHTTPHandler(suite.authService)
+	authServices := newAuthTestServices(suite.db.DB, authTestConfig{})
+	suite.authService = authServices.auth
+	suite.authHandler = authServices.handler
 
 	// Настраиваем Gin роутер
 	gin.SetMode(gin.TestMode)
@@ -80,7 +78,7 @@ func (suite *DirectAuthTestSuite) TestDirectRegister_Success() {
 	// Arrange
 	registerData := models.DirectRegisterRequest{
 		Email:     "test@example.com",
-		Password:  "password123",
+		Password:  "fresh-fade-42",
 		FirstName: "John",
 		LastName:  "Doe",
 		Role:      "client",
@@ -140,7 +138,7 @@ func (suite *DirectAuthTestSuite) TestDirectRegister_DuplicateEmail() {
 	// Пытаемся зарегистрировать с тем же email
 	registerData := models.DirectRegisterRequest{
 		Email:     "existing@example.com",
-		Password:  "password123",
+		Password:  "fresh-fade-42",
 		FirstName: "New",
 		LastName:  "User",
 	}
@@ -171,7 +169,7 @@ func (suite *DirectAuthTestSuite) TestDirectLogin_Success() {
 	}
 
 	// Хешируем пароль
-	passwordHash, err := suite.authService.HashPassword("password123")
+	passwordHash, err := suite.authService.HashPassword("fresh-fade-42")
 	suite.Require().NoError(err)
 	user.PasswordHash = passwordHash
 
@@ -181,7 +179,7 @@ func (suite *DirectAuthTestSuite) TestDirectLogin_Success() {
 	// Данные для авторизации
 	loginData := models.DirectLoginRequest{
 		Email:    "login@example.com",
-		Password: "password123",
+		Password: "fresh-fade-42",
 	}
 
 	jsonData, err := json.Marshal(loginData)
@@ -0,0 +1,187 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"garage-barbershop/internal/database"
+	"garage-barbershop/internal/handlers"
+	"garage-barbershop/internal/mailer"
+	"garage-barbershop/internal/middleware"
+	"garage-barbershop/internal/models"
+
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// verificationTokenPattern извлекает токен из ссылки подтверждения в письме
+var verificationTokenPattern = regexp.MustCompile(`token=([A-Za-z0-9_.-]+)`)
+
+// EmailVerificationTestSuite набор тестов подтверждения email
+type EmailVerificationTestSuite struct {
+	apiTestSuite
+	db     *database.Database
+	mailer *mailer.FileMailer
+}
+
+// SetupSuite инициализирует тестовую среду и маршруты
+func (suite *EmailVerificationTestSuite) SetupSuite() {
+	db, err := gorm.Open(sqlite.Open("file:email_verification?mode=memory&cache=shared"), &gorm.Config{})
+	suite.Require().NoError(err)
+
+	suite.db = &database.Database{DB: db}
+	err = suite.db.Migrate(&models.User{}, &models.Role{}, &models.UserRole{}, &models.AuditEvent{}, &models.Session{})
+	suite.Require().NoError(err)
+
+	suite.mailer, err = mailer.NewFileMailer(suite.T().TempDir())
+	suite.Require().NoError(err)
+
+	authServices := newAuthTestServices(db, authTestConfig{mailer: suite.mailer, restrictions: []string{"appointments:create"}})
+	authService := authServices.auth
+	auditService := authServices.audit
+	verificationService := authServices.verification
+
+	authRolesHandler := handlers.NewAuthRolesHandler(authService, verificationService)
+	verificationHandler := handlers.NewEmailVerificationHandler(verificationService, auditService)
+	auth := middleware.HTTPAuthMiddleware(authService)
+
+	suite.mux = http.NewServeMux()
+	suite.mux.HandleFunc("/api/auth/register/client", authRolesHandler.RegisterClient)
+	suite.mux.HandleFunc("/api/auth/email/verify", verificationHandler.VerifyEmail)
+	suite.mux.HandleFunc("/api/auth/email/resend", auth(verificationHandler.ResendVerification))
+
+	// Записи клиента: просмотр доступен всем, запись - только с подтвержденным email
+	suite.mux.HandleFunc("/api/appointments", auth(middleware.HTTPRequireVerifiedEmailMiddleware(verificationService, "appointments")(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodPost {
+				w.WriteHeader(http.StatusCreated)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		},
+	)))
+}
+
+// TearDownSuite очищает тестовую среду
+func (suite *EmailVerificationTestSuite) TearDownSuite() {
+	sqlDB, err := suite.db.DB.DB()
+	suite.Require().NoError(err)
+	sqlDB.Close()
+}
+
+// SetupTest очищает пользователей
+func (suite *EmailVerificationTestSuite) SetupTest() {
+	suite.db.DB.Exec("DELETE FROM sessions")
+	suite.db.DB.Exec("DELETE FROM user_roles")
+	suite.db.DB.Exec("DELETE FROM users")
+}
+
+// register регистрирует клиента и возвращает токены
+func (suite *EmailVerificationTestSuite) register() models.AuthResponse {
+	w := suite.request(http.MethodPost, "/api/auth/register/client", "", models.ClientRegisterRequest{
+		Email:     "newbie@example.com",
+		Password:  "fresh-fade-42",
+		FirstName: "Ольга",
+		LastName:  "Белова",
+	})
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+
+	var response models.AuthResponse
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	suite.Nil(response.User.EmailVerifiedAt)
+	return response
+}
+
+// lastToken возвращает токен из последнего отправленного письма
+func (suite *EmailVerificationTestSuite) lastToken() string {
+	content, err := os.ReadFile(suite.mailer.LastMessagePath())
+	suite.Require().NoError(err)
+	suite.Contains(string(content), "To: newbie@example.com")
+
+	match := verificationTokenPattern.FindStringSubmatch(string(content))
+	suite.Require().Len(match, 2)
+	return match[1]
+}
+
+// verify переходит по ссылке подтверждения и возвращает код ответа
+func (suite *EmailVerificationTestSuite) verify(token string) int {
+	return suite.request(http.MethodGet, "/api/auth/email/verify?token="+url.QueryEscape(token), "", nil).Code
+}
+
+// TestUnverifiedClientCanBrowseButNotBook тестирует политику для неподтвержденного email
+func (suite *EmailVerificationTestSuite) TestUnverifiedClientCanBrowseButNotBook() {
+	// Arrange
+	tokens := suite.register()
+
+	// Act & Assert
+	suite.Equal(http.StatusOK, suite.request(http.MethodGet, "/api/appointments", tokens.AccessToken, nil).Code)
+	suite.Equal(http.StatusForbidden, suite.request(http.MethodPost, "/api/appointments", tokens.AccessToken, nil).Code)
+}
+
+// TestVerifyLinkUnlocksBooking тестирует подтверждение email по ссылке из письма
+func (suite *EmailVerificationTestSuite) TestVerifyLinkUnlocksBooking() {
+	// Arrange
+	tokens := suite.register()
+	token := suite.lastToken()
+
+	// Act
+	code := suite.verify(token)
+
+	// Assert
+	suite.Require().Equal(http.StatusOK, code)
+	suite.Equal(http.StatusCreated, suite.request(http.MethodPost, "/api/appointments", tokens.AccessToken, nil).Code)
+
+	var user models.User
+	suite.Require().NoError(suite.db.DB.First(&user, tokens.User.ID).Error)
+	suite.NotNil(user.EmailVerifiedAt)
+
+	// Повторный переход по ссылке не ошибка
+	w := suite.request(http.MethodPost, "/api/auth/email/verify", "", models.EmailVerifyRequest{Token: token})
+	suite.Equal(http.StatusOK, w.Code)
+}
+
+// TestInvalidVerificationLinks тестирует отклонение подделанных и устаревших ссылок
+func (suite *EmailVerificationTestSuite) TestInvalidVerificationLinks() {
+	tokens := suite.register()
+	token := suite.lastToken()
+
+	suite.Equal(http.StatusBadRequest, suite.verify(token+"x"))
+	suite.Equal(http.StatusBadRequest, suite.verify("garbage"))
+
+	// После смены email ссылка на прежний адрес не действует
+	suite.db.DB.Model(&models.User{}).Where("id = ?", tokens.User.ID).Update("email", "other@example.com")
+	suite.Equal(http.StatusBadRequest, suite.verify(token))
+	suite.Equal(http.StatusForbidden, suite.request(http.MethodPost, "/api/appointments", tokens.AccessToken, nil).Code)
+}
+
+// TestResendVerification тестирует повторную отправку письма
+func (suite *EmailVerificationTestSuite) TestResendVerification() {
+	tokens := suite.register()
+	first := suite.mailer.LastMessagePath()
+
+	// Сразу после регистрации письмо уже отправлено
+	w := suite.request(http.MethodPost, "/api/auth/email/resend", tokens.AccessToken, nil)
+	suite.Equal(http.StatusTooManyRequests, w.Code)
+
+	suite.db.DB.Model(&models.User{}).Where("id = ?", tokens.User.ID).Update("email_verification_sent_at", time.Now().Add(-2*time.Minute))
+	w = suite.request(http.MethodPost, "/api/auth/email/resend", tokens.AccessToken, nil)
+	suite.Require().Equal(http.StatusAccepted, w.Code, w.Body.String())
+	suite.NotEqual(first, suite.mailer.LastMessagePath())
+
+	suite.Require().Equal(http.StatusOK, suite.verify(suite.lastToken()))
+	w = suite.request(http.MethodPost, "/api/auth/email/resend", tokens.AccessToken, nil)
+	suite.Equal(http.StatusConflict, w.Code)
+
+	suite.Equal(http.StatusUnauthorized, suite.request(http.MethodPost, "/api/auth/email/resend", "", nil).Code)
+}
+
+// TestEmailVerificationTestSuite запускает набор тестов
+func TestEmailVerificationTestSuite(t *testing.T) {
+	suite.Run(t, new(EmailVerificationTestSuite))
+}
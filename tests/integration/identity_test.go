@@ -0,0 +1,339 @@
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"garage-barbershop/internal/database"
+	"garage-barbershop/internal/handlers"
+	"garage-barbershop/internal/middleware"
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/repositories"
+	"garage-barbershop/internal/services"
+
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// IdentityTestSuite набор тестов привязки способов входа и объединения аккаунтов
+type IdentityTestSuite struct {
+	apiTestSuite
+	db          *database.Database
+	authService services.AuthService
+}
+
+// SetupSuite инициализирует тестовую среду и маршруты
+func (suite *IdentityTestSuite) SetupSuite() {
+	db, err := gorm.Open(sqlite.Open("file:identity?mode=memory&cache=shared"), &gorm.Config{})
+	suite.Require().NoError(err)
+
+	suite.db = &database.Database{DB: db}
+	err = suite.db.Migrate(&models.User{}, &models.Role{}, &models.UserRole{}, &models.AuditEvent{}, &models.Session{},
+		&models.Service{}, &models.Appointment{}, &models.Review{}, &models.WorkingHours{}, &models.ScheduleException{})
+	suite.Require().NoError(err)
+
+	authServices := newAuthTestServices(db, authTestConfig{botToken: webAppBotToken})
+	suite.authService = authServices.auth
+	authHandler := authServices.handler
+	identityHandler := handlers.NewIdentityHandler(
+		services.NewIdentityService(authServices.userRepo, repositories.NewUserMergeRepository(db), suite.authService, authServices.revocation),
+		suite.authService, authServices.verification, authServices.audit,
+	)
+	auth := middleware.HTTPAuthMiddleware(suite.authService)
+
+	suite.mux = http.NewServeMux()
+	suite.mux.HandleFunc("/api/auth/login", authHandler.LoginDirect)
+	suite.mux.HandleFunc("/api/auth/telegram/webapp", authHandler.TelegramWebAppAuth)
+	suite.mux.HandleFunc("/api/auth/identities", auth(identityHandler.ListIdentities))
+	suite.mux.HandleFunc("/api/auth/identities/telegram", auth(identityHandler.Telegram))
+	suite.mux.HandleFunc("/api/auth/identities/password", auth(identityHandler.SetPassword))
+	suite.mux.HandleFunc("/api/admin/users/{id}/merge", auth(identityHandler.MergeUsers))
+}
+
+// TearDownSuite очищает тестовую среду
+func (suite *IdentityTestSuite) TearDownSuite() {
+	sqlDB, err := suite.db.DB.DB()
+	suite.Require().NoError(err)
+	sqlDB.Close()
+}
+
+// SetupTest очищает данные между тестами
+func (suite *IdentityTestSuite) SetupTest() {
+	suite.db.DB.Exec("DELETE FROM schedule_exceptions")
+	suite.db.DB.Exec("DELETE FROM working_hours")
+	suite.db.DB.Exec("DELETE FROM reviews")
+	suite.db.DB.Exec("DELETE FROM appointments")
+	suite.db.DB.Exec("DELETE FROM services")
+	suite.db.DB.Exec("DELETE FROM audit_events")
+	suite.db.DB.Exec("DELETE FROM sessions")
+	suite.db.DB.Exec("DELETE FROM user_roles")
+	suite.db.DB.Exec("DELETE FROM users")
+}
+
+// register создает клиента с паролем
+func (suite *IdentityTestSuite) register(email string) *models.User {
+	user, err := suite.authService.RegisterUserDirect(models.DirectRegisterRequest{
+		Email:     email,
+		Password:  "fresh-fade-42",
+		FirstName: "Олег",
+		LastName:  "Смирнов",
+		Role:      "client",
+	})
+	suite.Require().NoError(err)
+	return user
+}
+
+// telegramLogin входит из Telegram WebApp и возвращает ответ сервера
+func (suite *IdentityTestSuite) telegramLogin(telegramID int64) models.AuthResponse {
+	w := suite.request(http.MethodPost, "/api/auth/telegram/webapp", "",
+		models.TelegramWebAppAuthRequest{InitData: signInitData(initDataFields(telegramID, "Иван", time.Now()), webAppBotToken)})
+	var response models.AuthResponse
+	suite.decode(w, &response)
+	return response
+}
+
+// accessToken открывает сессию пользователя и возвращает access token
+func (suite *IdentityTestSuite) accessToken(user *models.User) string {
+	response, err := suite.authService.CreateSession(user, models.DeviceInfo{})
+	suite.Require().NoError(err)
+	return response.AccessToken
+}
+
+// identities возвращает способы входа пользователя по ответу сервера
+func (suite *IdentityTestSuite) identities(w *httptest.ResponseRecorder) []models.UserIdentity {
+	var response struct {
+		Identities []models.UserIdentity `json:"identities"`
+	}
+	suite.decode(w, &response)
+	return response.Identities
+}
+
+// TestLinkTelegram тестирует привязку Telegram к аккаунту с паролем и вход через него
+func (suite *IdentityTestSuite) TestLinkTelegram() {
+	user := suite.register("oleg@example.com")
+	token := suite.accessToken(user)
+
+	identities := suite.identities(suite.request(http.MethodGet, "/api/auth/identities", token, nil))
+	suite.Require().Len(identities, 1)
+	suite.Equal(models.IdentityPassword, identities[0].Provider)
+
+	initData := signInitData(initDataFields(279058397, "Иван", time.Now()), webAppBotToken)
+	identities = suite.identities(suite.request(http.MethodPost, "/api/auth/identities/telegram", token, models.LinkTelegramRequest{InitData: initData}))
+	suite.Require().Len(identities, 2)
+	suite.Equal(models.IdentityTelegram, identities[0].Provider)
+	suite.Equal("279058397", identities[0].Subject)
+
+	// Вход через Telegram попадает в тот же аккаунт, имя из профиля не перезаписывается
+	response := suite.telegramLogin(279058397)
+	suite.Equal(user.ID, response.User.ID)
+	suite.Equal("Олег", response.User.FirstName)
+
+	var users int64
+	suite.db.DB.Model(&models.User{}).Count(&users)
+	suite.Equal(int64(1), users)
+
+	// Неверная подпись и Telegram, уже привязанный к другому аккаунту
+	suite.Equal(http.StatusUnauthorized, suite.request(http.MethodPost, "/api/auth/identities/telegram", token,
+		models.LinkTelegramRequest{InitData: signInitData(initDataFields(279058397, "Иван", time.Now()), "654321:OTHER")}).Code)
+	other := suite.register("other@example.com")
+	suite.Equal(http.StatusConflict, suite.request(http.MethodPost, "/api/auth/identities/telegram", suite.accessToken(other),
+		models.LinkTelegramRequest{InitData: initData}).Code)
+
+	var links int64
+	suite.db.DB.Model(&models.AuditEvent{}).Where("action = ?", models.AuditActionIdentityLink).Count(&links)
+	suite.Equal(int64(1), links)
+}
+
+// TestUnlinkTelegram тестирует отвязку Telegram и запрет отвязать единственный способ входа
+func (suite *IdentityTestSuite) TestUnlinkTelegram() {
+	telegramUser := suite.telegramLogin(279058397)
+	suite.Equal(http.StatusConflict, suite.request(http.MethodDelete, "/api/auth/identities/telegram", telegramUser.AccessToken, nil).Code)
+
+	user := suite.register("oleg@example.com")
+	token := suite.accessToken(user)
+	suite.Equal(http.StatusConflict, suite.request(http.MethodDelete, "/api/auth/identities/telegram", token, nil).Code)
+
+	linkData := signInitData(initDataFields(111222333, "Иван", time.Now()), webAppBotToken)
+	suite.Require().Equal(http.StatusOK, suite.request(http.MethodPost, "/api/auth/identities/telegram", token, models.LinkTelegramRequest{InitData: linkData}).Code)
+
+	identities := suite.identities(suite.request(http.MethodDelete, "/api/auth/identities/telegram", token, nil))
+	suite.Require().Len(identities, 1)
+	suite.Equal(models.IdentityPassword, identities[0].Provider)
+
+	// После отвязки вход через этот Telegram создает новый аккаунт
+	suite.NotEqual(user.ID, suite.telegramLogin(111222333).User.ID)
+}
+
+// TestSetPassword тестирует добавление входа по паролю пользователю Telegram
+func (suite *IdentityTestSuite) TestSetPassword() {
+	telegramUser := suite.telegramLogin(279058397)
+	token := telegramUser.AccessToken
+
+	// Пароль по-прежнему проверяется политикой, email обязателен
+	suite.Equal(http.StatusBadRequest, suite.request(http.MethodPost, "/api/auth/identities/password", token,
+		models.SetPasswordRequest{Password: "fresh-fade-42"}).Code)
+	suite.Equal(http.StatusBadRequest, suite.request(http.MethodPost, "/api/auth/identities/password", token,
+		models.SetPasswordRequest{Email: "ivan@example.com", Password: "123"}).Code)
+	suite.register("taken@example.com")
+	suite.Equal(http.StatusConflict, suite.request(http.MethodPost, "/api/auth/identities/password", token,
+		models.SetPasswordRequest{Email: "taken@example.com", Password: "fresh-fade-42"}).Code)
+
+	identities := suite.identities(suite.request(http.MethodPost, "/api/auth/identities/password", token,
+		models.SetPasswordRequest{Email: "ivan@example.com", Password: "fresh-fade-42"}))
+	suite.Require().Len(identities, 2)
+	suite.Equal(models.IdentityPassword, identities[1].Provider)
+	suite.False(identities[1].Verified)
+
+	// Повторно задать пароль нельзя, для этого есть смена пароля
+	suite.Equal(http.StatusConflict, suite.request(http.MethodPost, "/api/auth/identities/password", token,
+		models.SetPasswordRequest{Email: "ivan@example.com", Password: "fresh-fade-43"}).Code)
+
+	var response models.AuthResponse
+	suite.decode(suite.request(http.MethodPost, "/api/auth/login", "", models.DirectLoginRequest{Email: "ivan@example.com", Password: "fresh-fade-42"}), &response)
+	suite.Equal(telegramUser.User.ID, response.User.ID)
+	suite.Equal(int64(279058397), response.User.TelegramID)
+}
+
+// TestMergeUsers тестирует объединение аккаунта Telegram с аккаунтом с паролем
+func (suite *IdentityTestSuite) TestMergeUsers() {
+	primary := suite.register("oleg@example.com")
+	duplicate := suite.telegramLogin(279058397)
+	barber := suite.register("barber@example.com")
+	duplicateToken := duplicate.AccessToken
+
+	service := &models.Service{Name: "Стрижка", Price: 1500, Duration: 60, BarberID: barber.ID}
+	suite.Require().NoError(suite.db.DB.Create(service).Error)
+	appointments := []models.Appointment{
+		{DateTime: time.Now().AddDate(0, 0, -7), Duration: 60, Status: "completed", ClientID: duplicate.User.ID, BarberID: barber.ID, ServiceID: service.ID},
+		{DateTime: time.Now().AddDate(0, 0, 7), Duration: 60, Status: "scheduled", ClientID: duplicate.User.ID, BarberID: barber.ID, ServiceID: service.ID},
+	}
+	suite.Require().NoError(suite.db.DB.Create(&appointments).Error)
+	suite.Require().NoError(suite.db.DB.Create(&models.Review{Rating: 5, ClientID: duplicate.User.ID, BarberID: barber.ID, AppointmentID: appointments[0].ID}).Error)
+
+	// Роль барбера дубликата переходит к основному аккаунту, роль клиента у него уже есть
+	barberRole, err := repositories.NewRoleRepository(suite.db.DB).GetRoleByName("barber")
+	suite.Require().NoError(err)
+	suite.Require().NoError(repositories.NewRoleRepository(suite.db.DB).AssignRoleToUser(duplicate.User.ID, barberRole.ID, primary.ID))
+
+	path := "/api/admin/users/" + strconv.FormatUint(uint64(primary.ID), 10) + "/merge"
+	adminToken := suite.accessToken(barber)
+	suite.Equal(http.StatusBadRequest, suite.request(http.MethodPost, path, adminToken, models.UserMergeRequest{DuplicateUserID: primary.ID}).Code)
+
+	var result models.UserMergeResult
+	suite.decode(suite.request(http.MethodPost, path, adminToken, models.UserMergeRequest{DuplicateUserID: duplicate.User.ID}), &result)
+	suite.Equal(primary.ID, result.User.ID)
+	suite.Equal(int64(279058397), result.User.TelegramID)
+	suite.Equal(int64(2), result.Appointments)
+	suite.Equal(int64(1), result.Reviews)
+	suite.Equal(int64(1), result.Roles)
+
+	var moved int64
+	suite.db.DB.Model(&models.Appointment{}).Where("client_id = ?", primary.ID).Count(&moved)
+	suite.Equal(int64(2), moved)
+	suite.db.DB.Model(&models.Review{}).Where("client_id = ?", primary.ID).Count(&moved)
+	suite.Equal(int64(1), moved)
+	roles, err := repositories.NewRoleRepository(suite.db.DB).GetUserRoles(primary.ID)
+	suite.Require().NoError(err)
+	suite.Len(roles, 2)
+
+	// Дубликат удален, его токены отозваны; вход по паролю и через Telegram ведет в основной аккаунт
+	suite.Equal(http.StatusUnauthorized, suite.request(http.MethodGet, "/api/auth/identities", duplicateToken, nil).Code)
+	suite.Equal(primary.ID, suite.telegramLogin(279058397).User.ID)
+	var response models.AuthResponse
+	suite.decode(suite.request(http.MethodPost, "/api/auth/login", "", models.DirectLoginRequest{Email: "oleg@example.com", Password: "fresh-fade-42"}), &response)
+	suite.Equal(primary.ID, response.User.ID)
+
+	var merges int64
+	suite.db.DB.Model(&models.AuditEvent{}).Where("action = ? AND target_id = ?", models.AuditActionUserMerge, primary.ID).Count(&merges)
+	suite.Equal(int64(1), merges)
+}
+
+// TestMergeBarberDuplicate тестирует объединение с аккаунтом барбера: рейтинг пересчитывается,
+// а дубликат с действующими услугами, расписанием или 2FA не объединяется
+func (suite *IdentityTestSuite) TestMergeBarberDuplicate() {
+	primary := suite.register("oleg@example.com")
+	duplicate := suite.telegramLogin(279058397)
+	client := suite.register("client@example.com")
+	path := "/api/admin/users/" + strconv.FormatUint(uint64(primary.ID), 10) + "/merge"
+	adminToken := suite.accessToken(client)
+	merge := func() *httptest.ResponseRecorder {
+		return suite.request(http.MethodPost, path, adminToken, models.UserMergeRequest{DuplicateUserID: duplicate.User.ID})
+	}
+
+	service := &models.Service{Name: "Стрижка", Price: 1500, Duration: 60, BarberID: duplicate.User.ID}
+	suite.Require().NoError(suite.db.DB.Create(service).Error)
+	appointment := &models.Appointment{DateTime: time.Now().AddDate(0, 0, -7), Duration: 60, Status: "completed", ClientID: client.ID, BarberID: duplicate.User.ID, ServiceID: service.ID}
+	suite.Require().NoError(suite.db.DB.Create(appointment).Error)
+	suite.Require().NoError(suite.db.DB.Create(&models.Review{Rating: 1, ClientID: client.ID, BarberID: duplicate.User.ID, AppointmentID: appointment.ID}).Error)
+	hours := &models.WorkingHours{DayOfWeek: 1, StartTime: "09:00", EndTime: "18:00", IsActive: true, BarberID: duplicate.User.ID}
+	suite.Require().NoError(suite.db.DB.Create(hours).Error)
+
+	suite.Equal(http.StatusConflict, merge().Code)
+	suite.Require().NoError(suite.db.DB.Delete(hours).Error)
+	suite.Equal(http.StatusConflict, merge().Code)
+	suite.Require().NoError(suite.db.DB.Delete(service).Error)
+
+	suite.Require().NoError(suite.db.DB.Model(&models.User{}).Where("id = ?", duplicate.User.ID).Update("totp_enabled_at", time.Now()).Error)
+	suite.Equal(http.StatusConflict, merge().Code)
+	suite.Require().NoError(suite.db.DB.Model(&models.User{}).Where("id = ?", duplicate.User.ID).Update("totp_enabled_at", nil).Error)
+
+	// Отказ не меняет данные дубликата
+	var unchanged models.User
+	suite.Require().NoError(suite.db.DB.First(&unchanged, duplicate.User.ID).Error)
+	suite.Equal(int64(279058397), unchanged.TelegramID)
+
+	var result models.UserMergeResult
+	suite.decode(merge(), &result)
+	suite.Equal(models.BayesianRating(1, 1), result.User.Rating)
+
+	var merged models.User
+	suite.Require().NoError(suite.db.DB.First(&merged, primary.ID).Error)
+	suite.Equal(models.BayesianRating(1, 1), merged.Rating)
+
+	// Удаленная услуга остается доступной в истории записей основного аккаунта
+	var deleted models.Service
+	suite.Require().NoError(suite.db.DB.Unscoped().First(&deleted, service.ID).Error)
+	suite.Equal(primary.ID, deleted.BarberID)
+}
+
+// TestMergeConflict тестирует отказ объединить аккаунты с разными Telegram
+func (suite *IdentityTestSuite) TestMergeConflict() {
+	first := suite.telegramLogin(279058397)
+	second := suite.telegramLogin(111222333)
+
+	path := "/api/admin/users/" + strconv.FormatUint(uint64(first.User.ID), 10) + "/merge"
+	suite.Equal(http.StatusConflict, suite.request(http.MethodPost, path, first.AccessToken, models.UserMergeRequest{DuplicateUserID: second.User.ID}).Code)
+	suite.Equal(http.StatusNotFound, suite.request(http.MethodPost, path, first.AccessToken, models.UserMergeRequest{DuplicateUserID: 999999}).Code)
+}
+
+// TestLegacyUniqueIndexes тестирует, что миграция снимает прежние уникальные индексы,
+// из-за которых второй пользователь без Telegram или без email не сохранялся
+func (suite *IdentityTestSuite) TestLegacyUniqueIndexes() {
+	db, err := gorm.Open(sqlite.Open("file:identity_legacy?mode=memory&cache=shared"), &gorm.Config{})
+	suite.Require().NoError(err)
+	legacy := &database.Database{DB: db}
+	defer func() {
+		sqlDB, _ := db.DB()
+		sqlDB.Close()
+	}()
+
+	suite.Require().NoError(db.Exec("CREATE TABLE users (id integer PRIMARY KEY AUTOINCREMENT, telegram_id integer, email text)").Error)
+	suite.Require().NoError(db.Exec("CREATE UNIQUE INDEX idx_users_telegram_id ON users(telegram_id)").Error)
+	suite.Require().NoError(db.Exec("CREATE UNIQUE INDEX idx_users_email ON users(email)").Error)
+	suite.Require().NoError(legacy.Migrate(&models.User{}))
+
+	suite.NoError(db.Create(&models.User{Email: "first@example.com"}).Error)
+	suite.NoError(db.Create(&models.User{Email: "second@example.com"}).Error)
+	suite.NoError(db.Create(&models.User{TelegramID: 279058397}).Error)
+	suite.Error(db.Create(&models.User{TelegramID: 279058397}).Error)
+	suite.Error(db.Create(&models.User{Email: "first@example.com"}).Error)
+}
+
+// TestIdentityTestSuite запускает набор тестов
+func TestIdentityTestSuite(t *testing.T) {
+	suite.Run(t, new(IdentityTestSuite))
+}
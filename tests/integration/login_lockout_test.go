@@ -0,0 +1,208 @@
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"garage-barbershop/internal/database"
+	"garage-barbershop/internal/handlers"
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/services"
+
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// LoginLockoutTestSuite набор тестов защиты входа по паролю от подбора
+type LoginLockoutTestSuite struct {
+	apiTestSuite
+	db          *database.Database
+	authService services.AuthService
+	user        *models.User
+}
+
+// SetupSuite инициализирует тестовую среду
+func (suite *LoginLockoutTestSuite) SetupSuite() {
+	db, err := gorm.Open(sqlite.Open("file:login_lockout?mode=memory&cache=shared"), &gorm.Config{})
+	suite.Require().NoError(err)
+
+	suite.db = &database.Database{DB: db}
+	err = suite.db.Migrate(&models.User{}, &models.Role{}, &models.UserRole{}, &models.AuditEvent{}, &models.Session{})
+	suite.Require().NoError(err)
+
+	suite.authService = newAuthTestServices(db, authTestConfig{}).auth
+}
+
+// TearDownSuite очищает тестовую среду
+func (suite *LoginLockoutTestSuite) TearDownSuite() {
+	sqlDB, err := suite.db.DB.DB()
+	suite.Require().NoError(err)
+	sqlDB.Close()
+}
+
+// SetupTest создает пользователя и маршруты с новыми счетчиками попыток: после двух неудач
+// по email задержка начинается с 300 мс, после пяти вход блокируется; с одного IP - после четырех неудач
+func (suite *LoginLockoutTestSuite) SetupTest() {
+	suite.db.DB.Exec("DELETE FROM audit_events")
+	suite.db.DB.Exec("DELETE FROM sessions")
+	suite.db.DB.Exec("DELETE FROM user_roles")
+	suite.db.DB.Exec("DELETE FROM users")
+
+	var err error
+	suite.user, err = suite.authService.RegisterUserDirect(models.DirectRegisterRequest{
+		Email:     "target@example.com",
+		Password:  "fresh-fade-42",
+		FirstName: "Денис",
+		LastName:  "Егоров",
+		Role:      "client",
+	})
+	suite.Require().NoError(err)
+
+	authServices := newAuthTestServices(suite.db.DB, authTestConfig{throttle: services.NewLoginThrottleService(nil,
+		services.LoginThrottlePolicy{FreeAttempts: 2, BaseDelay: 300 * time.Millisecond, MaxAttempts: 5, LockoutDuration: time.Hour},
+		services.LoginThrottlePolicy{FreeAttempts: 4, BaseDelay: time.Hour, MaxAttempts: 10, LockoutDuration: time.Hour},
+	)})
+	lockoutHandler := handlers.NewLoginLockoutHandler(authServices.auth, authServices.throttle, authServices.audit)
+
+	suite.mux = http.NewServeMux()
+	suite.mux.HandleFunc("/api/auth/login", authServices.handler.LoginDirect)
+	suite.mux.HandleFunc("/api/admin/users/{id}/unlock", lockoutHandler.UnlockUser)
+}
+
+// loginFrom выполняет вход с указанного IP адреса
+func (suite *LoginLockoutTestSuite) loginFrom(ip, email, password string) *httptest.ResponseRecorder {
+	req := suite.newRequest(http.MethodPost, "/api/auth/login", "", models.DirectLoginRequest{Email: email, Password: password})
+	req.RemoteAddr = ip + ":40000"
+	return suite.serve(req)
+}
+
+// unlock снимает блокировку входа пользователя от имени администратора
+func (suite *LoginLockoutTestSuite) unlock(userID uint) int {
+	req := httptest.NewRequest(http.MethodPost, "/api/admin/users/"+strconv.FormatUint(uint64(userID), 10)+"/unlock", nil)
+	w := httptest.NewRecorder()
+	suite.mux.ServeHTTP(w, req)
+	return w.Code
+}
+
+// TestUniformFailure тестирует одинаковый ответ для неизвестного email и неверного пароля
+func (suite *LoginLockoutTestSuite) TestUniformFailure() {
+	unknown := suite.loginFrom("198.51.100.1", "nobody@example.com", "fresh-fade-42")
+	wrong := suite.loginFrom("198.51.100.2", "target@example.com", "wrong-password")
+
+	suite.Equal(http.StatusUnauthorized, unknown.Code)
+	suite.Equal(http.StatusUnauthorized, wrong.Code)
+	suite.Equal(unknown.Body.String(), wrong.Body.String())
+
+	// Деактивированный пользователь получает тот же ответ
+	suite.db.DB.Model(suite.user).Update("is_active", false)
+	inactive := suite.loginFrom("198.51.100.3", "target@example.com", "fresh-fade-42")
+	suite.Equal(http.StatusUnauthorized, inactive.Code)
+	suite.Equal(wrong.Body.String(), inactive.Body.String())
+}
+
+// TestBackoffAndLockout тестирует растущую задержку, блокировку и снятие ее администратором
+func (suite *LoginLockoutTestSuite) TestBackoffAndLockout() {
+	// Первые неудачные попытки без задержки
+	for i := 0; i < 3; i++ {
+		suite.Equal(http.StatusUnauthorized, suite.loginFrom("198.51.100.1", "Target@example.com", "wrong-password").Code)
+	}
+
+	// Сразу после третьей неудачи вход недоступен даже с верным паролем и с другого IP
+	w := suite.loginFrom("198.51.100.2", "target@example.com", "fresh-fade-42")
+	suite.Equal(http.StatusTooManyRequests, w.Code)
+	suite.Equal("1", w.Header().Get("Retry-After"))
+
+	// Задержка удваивается: после четвертой неудачи 300 мс уже недостаточно
+	time.Sleep(400 * time.Millisecond)
+	suite.Equal(http.StatusUnauthorized, suite.loginFrom("198.51.100.1", "target@example.com", "wrong-password").Code)
+	time.Sleep(400 * time.Millisecond)
+	suite.Equal(http.StatusTooManyRequests, suite.loginFrom("198.51.100.1", "target@example.com", "wrong-password").Code)
+	time.Sleep(300 * time.Millisecond)
+
+	// Пятая неудача блокирует вход на срок блокировки
+	suite.Equal(http.StatusUnauthorized, suite.loginFrom("198.51.100.1", "target@example.com", "wrong-password").Code)
+	w = suite.loginFrom("198.51.100.2", "target@example.com", "fresh-fade-42")
+	suite.Equal(http.StatusTooManyRequests, w.Code)
+	retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+	suite.Require().NoError(err)
+	suite.Greater(retryAfter, 3500)
+
+	// Администратор снимает блокировку
+	suite.Equal(http.StatusOK, suite.unlock(suite.user.ID))
+	suite.Equal(http.StatusOK, suite.loginFrom("198.51.100.2", "target@example.com", "fresh-fade-42").Code)
+
+	var events int64
+	suite.db.DB.Model(&models.AuditEvent{}).Where("action = ?", models.AuditActionLoginUnlock).Count(&events)
+	suite.Equal(int64(1), events)
+
+	suite.Equal(http.StatusNotFound, suite.unlock(suite.user.ID+100))
+}
+
+// TestSuccessResetsEmailCounter тестирует сброс счетчика email после успешного входа
+func (suite *LoginLockoutTestSuite) TestSuccessResetsEmailCounter() {
+	for i := 0; i < 2; i++ {
+		suite.Equal(http.StatusUnauthorized, suite.loginFrom("198.51.100.1", "target@example.com", "wrong-password").Code)
+	}
+	suite.Equal(http.StatusOK, suite.loginFrom("198.51.100.1", "target@example.com", "fresh-fade-42").Code)
+
+	for i := 0; i < 2; i++ {
+		suite.Equal(http.StatusUnauthorized, suite.loginFrom("198.51.100.2", "target@example.com", "wrong-password").Code)
+	}
+	suite.Equal(http.StatusOK, suite.loginFrom("198.51.100.2", "target@example.com", "fresh-fade-42").Code)
+}
+
+// TestIPThrottle тестирует ограничение попыток с одного IP адреса для разных email
+func (suite *LoginLockoutTestSuite) TestIPThrottle() {
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com", "d@example.com", "e@example.com"} {
+		suite.Equal(http.StatusUnauthorized, suite.loginFrom("203.0.113.7", email, "wrong-password").Code)
+	}
+
+	// С этого IP вход недоступен даже с верным паролем, с другого - доступен
+	suite.Equal(http.StatusTooManyRequests, suite.loginFrom("203.0.113.7", "target@example.com", "fresh-fade-42").Code)
+	suite.Equal(http.StatusOK, suite.loginFrom("203.0.113.8", "target@example.com", "fresh-fade-42").Code)
+}
+
+// TestConcurrentAttempts тестирует, что параллельные запросы не проверяют больше паролей, чем разрешает политика
+func (suite *LoginLockoutTestSuite) TestConcurrentAttempts() {
+	var wg sync.WaitGroup
+	codes := make(chan int, 20)
+	start := make(chan struct{})
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(ip string) {
+			defer wg.Done()
+			<-start
+			codes <- suite.loginFrom(ip, "target@example.com", "wrong-password").Code
+		}("198.51.100." + strconv.Itoa(10+i))
+	}
+	close(start)
+	wg.Wait()
+	close(codes)
+
+	checked := 0
+	for code := range codes {
+		if code == http.StatusUnauthorized {
+			checked++
+			continue
+		}
+		suite.Equal(http.StatusTooManyRequests, code)
+	}
+	suite.Equal(3, checked)
+}
+
+// TestSuccessNotCountedForIP тестирует, что успешные входы не расходуют попытки IP адреса
+func (suite *LoginLockoutTestSuite) TestSuccessNotCountedForIP() {
+	for i := 0; i < 6; i++ {
+		suite.Equal(http.StatusOK, suite.loginFrom("203.0.113.9", "target@example.com", "fresh-fade-42").Code)
+	}
+}
+
+// TestLoginLockoutTestSuite запускает набор тестов
+func TestLoginLockoutTestSuite(t *testing.T) {
+	suite.Run(t, new(LoginLockoutTestSuite))
+}
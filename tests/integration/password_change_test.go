@@ -0,0 +1,186 @@
+package integration
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"garage-barbershop/internal/database"
+	"garage-barbershop/internal/handlers"
+	"garage-barbershop/internal/mailer"
+	"garage-barbershop/internal/middleware"
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/repositories"
+	"garage-barbershop/internal/services"
+
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// PasswordChangeTestSuite набор тестов политики паролей и смены пароля
+type PasswordChangeTestSuite struct {
+	apiTestSuite
+	db          *database.Database
+	authService services.AuthService
+}
+
+// SetupSuite инициализирует тестовую среду и маршруты
+func (suite *PasswordChangeTestSuite) SetupSuite() {
+	db, err := gorm.Open(sqlite.Open("file:password_change?mode=memory&cache=shared"), &gorm.Config{})
+	suite.Require().NoError(err)
+
+	suite.db = &database.Database{DB: db}
+	err = suite.db.Migrate(&models.User{}, &models.Role{}, &models.UserRole{}, &models.AuditEvent{}, &models.Session{}, &models.PasswordResetToken{})
+	suite.Require().NoError(err)
+
+	sender := mailer.NewLogMailer()
+	authServices := newAuthTestServices(db, authTestConfig{mailer: sender})
+	suite.authService = authServices.auth
+	passwordService := services.NewPasswordService(authServices.userRepo, repositories.NewPasswordResetRepository(db), authServices.sessionRepo, suite.authService, authServices.revocation, sender, "https://barbershop.example/reset")
+
+	authHandler := authServices.handler
+	authRolesHandler := handlers.NewAuthRolesHandler(suite.authService, authServices.verification)
+	passwordHandler := handlers.NewPasswordHandler(passwordService, suite.authService, authServices.audit)
+	auth := middleware.HTTPAuthMiddleware(suite.authService)
+
+	suite.mux = http.NewServeMux()
+	suite.mux.HandleFunc("/api/auth/register/client", authRolesHandler.RegisterClient)
+	suite.mux.HandleFunc("/api/auth/login", authHandler.LoginDirect)
+	suite.mux.HandleFunc("/api/auth/refresh", authHandler.RefreshToken)
+	suite.mux.HandleFunc("/api/auth/profile", auth(authHandler.GetProfile))
+	suite.mux.HandleFunc("/api/auth/password/change", auth(passwordHandler.ChangePassword))
+}
+
+// TearDownSuite очищает тестовую среду
+func (suite *PasswordChangeTestSuite) TearDownSuite() {
+	sqlDB, err := suite.db.DB.DB()
+	suite.Require().NoError(err)
+	sqlDB.Close()
+}
+
+// SetupTest очищает пользователей
+func (suite *PasswordChangeTestSuite) SetupTest() {
+	suite.db.DB.Exec("DELETE FROM audit_events")
+	suite.db.DB.Exec("DELETE FROM sessions")
+	suite.db.DB.Exec("DELETE FROM user_roles")
+	suite.db.DB.Exec("DELETE FROM users")
+}
+
+// registerClient регистрирует клиента с паролем и возвращает код ответа
+func (suite *PasswordChangeTestSuite) registerClient(email, password string) *httptest.ResponseRecorder {
+	return suite.request(http.MethodPost, "/api/auth/register/client", "", models.ClientRegisterRequest{
+		Email:     email,
+		Password:  password,
+		FirstName: "Кирилл",
+		LastName:  "Зайцев",
+	})
+}
+
+// TestRegistrationPasswordPolicy тестирует отклонение слабых паролей при регистрации
+func (suite *PasswordChangeTestSuite) TestRegistrationPasswordPolicy() {
+	for _, password := range []string{"", "Sh0rt", "Password123", "QWERTY123", "kirill@example.com"} {
+		w := suite.registerClient("kirill@example.com", password)
+		suite.Equal(http.StatusBadRequest, w.Code, "пароль %q", password)
+	}
+
+	var count int64
+	suite.db.DB.Model(&models.User{}).Count(&count)
+	suite.Equal(int64(0), count)
+
+	w := suite.registerClient("kirill@example.com", "fresh-fade-42")
+	suite.Equal(http.StatusOK, w.Code, w.Body.String())
+
+	// Прямая регистрация и регистрация барбера проверяются той же политикой
+	_, err := suite.authService.RegisterUserDirect(models.DirectRegisterRequest{
+		Email: "weak@example.com", Password: "password", FirstName: "Иван", LastName: "Иванов", Role: "client",
+	})
+	suite.True(errors.Is(err, services.ErrWeakPassword))
+	_, err = suite.authService.RegisterBarber(models.BarberRegisterRequest{
+		Email: "barber@example.com", Password: "12345678", FirstName: "Игорь", LastName: "Козлов",
+	})
+	suite.True(errors.Is(err, services.ErrWeakPassword))
+}
+
+// TestChangePasswordEndsOtherSessions тестирует смену пароля и завершение сессий на других устройствах
+func (suite *PasswordChangeTestSuite) TestChangePasswordEndsOtherSessions() {
+	// Arrange
+	suite.Require().Equal(http.StatusOK, suite.registerClient("kirill@example.com", "fresh-fade-42").Code)
+	_, laptop := suite.login("kirill@example.com", "fresh-fade-42")
+	_, phone := suite.login("kirill@example.com", "fresh-fade-42")
+
+	// Act
+	w := suite.request(http.MethodPost, "/api/auth/password/change", laptop.AccessToken, models.PasswordChangeRequest{
+		CurrentPassword: "fresh-fade-42",
+		NewPassword:     "classic-taper-17",
+	})
+
+	// Assert: текущее устройство получает новые токены, прежние отозваны
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	var changed models.AuthResponse
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &changed))
+	suite.Equal(http.StatusOK, suite.request(http.MethodGet, "/api/auth/profile", changed.AccessToken, nil).Code)
+
+	for _, tokens := range []models.AuthResponse{laptop, phone} {
+		suite.Equal(http.StatusUnauthorized, suite.request(http.MethodGet, "/api/auth/profile", tokens.AccessToken, nil).Code)
+		w = suite.request(http.MethodPost, "/api/auth/refresh", "", models.RefreshTokenRequest{RefreshToken: tokens.RefreshToken})
+		suite.Equal(http.StatusUnauthorized, w.Code)
+	}
+
+	code, _ := suite.login("kirill@example.com", "fresh-fade-42")
+	suite.Equal(http.StatusUnauthorized, code)
+	code, _ = suite.login("kirill@example.com", "classic-taper-17")
+	suite.Equal(http.StatusOK, code)
+
+	var events int64
+	suite.db.DB.Model(&models.AuditEvent{}).Where("action = ? AND success = ?", models.AuditActionPasswordChange, true).Count(&events)
+	suite.Equal(int64(1), events)
+}
+
+// TestChangePasswordValidation тестирует отклонение неверного текущего и слабого нового пароля
+func (suite *PasswordChangeTestSuite) TestChangePasswordValidation() {
+	suite.Require().Equal(http.StatusOK, suite.registerClient("kirill@example.com", "fresh-fade-42").Code)
+	_, tokens := suite.login("kirill@example.com", "fresh-fade-42")
+
+	change := func(current, next string) int {
+		return suite.request(http.MethodPost, "/api/auth/password/change", tokens.AccessToken, models.PasswordChangeRequest{
+			CurrentPassword: current,
+			NewPassword:     next,
+		}).Code
+	}
+
+	suite.Equal(http.StatusForbidden, change("wrong-password", "classic-taper-17"))
+	suite.Equal(http.StatusBadRequest, change("fresh-fade-42", "qwerty123"))
+	suite.Equal(http.StatusBadRequest, change("fresh-fade-42", "fresh-fade-42"))
+	suite.Equal(http.StatusBadRequest, change("fresh-fade-42", "KIRILL@example.com"))
+
+	// Пароль не изменился, сессия действует
+	suite.Equal(http.StatusOK, suite.request(http.MethodGet, "/api/auth/profile", tokens.AccessToken, nil).Code)
+	code, _ := suite.login("kirill@example.com", "fresh-fade-42")
+	suite.Equal(http.StatusOK, code)
+
+	// Без токена сменить пароль нельзя
+	w := suite.request(http.MethodPost, "/api/auth/password/change", "", models.PasswordChangeRequest{CurrentPassword: "fresh-fade-42", NewPassword: "classic-taper-17"})
+	suite.Equal(http.StatusUnauthorized, w.Code)
+}
+
+// TestConfiguredPasswordPolicy тестирует настройку длины и дополнительного списка паролей
+func (suite *PasswordChangeTestSuite) TestConfiguredPasswordPolicy() {
+	policy := services.NewPasswordPolicy(12, []string{" GarageBarber2024 "})
+
+	suite.NoError(policy.Validate("fresh-fade-4", "kirill@example.com"))
+	suite.ErrorIs(policy.Validate("fresh-fade", "kirill@example.com"), services.ErrWeakPassword)
+	suite.ErrorIs(policy.Validate("garagebarber2024", "kirill@example.com"), services.ErrWeakPassword)
+	suite.ErrorIs(policy.Validate("password1234", ""), services.ErrWeakPassword)
+
+	// Длина считается в символах, а не в байтах
+	suite.NoError(policy.Validate("стрижкабород", ""))
+	suite.ErrorIs(policy.Validate("стрижка", ""), services.ErrWeakPassword)
+}
+
+// TestPasswordChangeTestSuite запускает набор тестов
+func TestPasswordChangeTestSuite(t *testing.T) {
+	suite.Run(t, new(PasswordChangeTestSuite))
+}
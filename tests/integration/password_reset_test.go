@@ -0,0 +1,173 @@
+package integration
+
+import (
+	"net/http"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"garage-barbershop/internal/database"
+	"garage-barbershop/internal/handlers"
+	"garage-barbershop/internal/mailer"
+	"garage-barbershop/internal/middleware"
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/repositories"
+	"garage-barbershop/internal/services"
+
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// resetTokenPattern извлекает токен из ссылки в письме
+var resetTokenPattern = regexp.MustCompile(`token=([A-Za-z0-9_-]+)`)
+
+// PasswordResetTestSuite набор тестов восстановления пароля
+type PasswordResetTestSuite struct {
+	apiTestSuite
+	db          *database.Database
+	authService services.AuthService
+	mailer      *mailer.FileMailer
+}
+
+// SetupSuite инициализирует тестовую среду и маршруты
+func (suite *PasswordResetTestSuite) SetupSuite() {
+	db, err := gorm.Open(sqlite.Open("file:password_reset?mode=memory&cache=shared"), &gorm.Config{})
+	suite.Require().NoError(err)
+
+	suite.db = &database.Database{DB: db}
+	err = suite.db.Migrate(&models.User{}, &models.Role{}, &models.UserRole{}, &models.AuditEvent{}, &models.Session{}, &models.PasswordResetToken{})
+	suite.Require().NoError(err)
+
+	suite.mailer, err = mailer.NewFileMailer(suite.T().TempDir())
+	suite.Require().NoError(err)
+
+	authServices := newAuthTestServices(db, authTestConfig{mailer: suite.mailer})
+	suite.authService = authServices.auth
+	passwordService := services.NewPasswordService(authServices.userRepo, repositories.NewPasswordResetRepository(db), authServices.sessionRepo, suite.authService, authServices.revocation, suite.mailer, "https://barbershop.example/reset")
+
+	authHandler := authServices.handler
+	passwordHandler := handlers.NewPasswordHandler(passwordService, suite.authService, authServices.audit)
+
+	suite.mux = http.NewServeMux()
+	suite.mux.HandleFunc("/api/auth/login", authHandler.LoginDirect)
+	suite.mux.HandleFunc("/api/auth/refresh", authHandler.RefreshToken)
+	suite.mux.HandleFunc("/api/auth/profile", middleware.HTTPAuthMiddleware(suite.authService)(authHandler.GetProfile))
+	suite.mux.HandleFunc("/api/auth/password/forgot", passwordHandler.ForgotPassword)
+	suite.mux.HandleFunc("/api/auth/password/reset", passwordHandler.ResetPassword)
+}
+
+// TearDownSuite очищает тестовую среду
+func (suite *PasswordResetTestSuite) TearDownSuite() {
+	sqlDB, err := suite.db.DB.DB()
+	suite.Require().NoError(err)
+	sqlDB.Close()
+}
+
+// SetupTest создает пользователя с паролем
+func (suite *PasswordResetTestSuite) SetupTest() {
+	suite.db.DB.Exec("DELETE FROM password_reset_tokens")
+	suite.db.DB.Exec("DELETE FROM sessions")
+	suite.db.DB.Exec("DELETE FROM user_roles")
+	suite.db.DB.Exec("DELETE FROM users")
+
+	_, err := suite.authService.RegisterUserDirect(models.DirectRegisterRequest{
+		Email:     "forgetful@example.com",
+		Password:  "old-password",
+		FirstName: "Павел",
+		LastName:  "Орлов",
+		Role:      "client",
+	})
+	suite.Require().NoError(err)
+}
+
+// forgot запрашивает ссылку и возвращает токен из отправленного письма
+func (suite *PasswordResetTestSuite) forgot() string {
+	previous := suite.mailer.LastMessagePath()
+	w := suite.request(http.MethodPost, "/api/auth/password/forgot", "", models.PasswordForgotRequest{Email: "forgetful@example.com"})
+	suite.Require().Equal(http.StatusAccepted, w.Code, w.Body.String())
+
+	path := suite.mailer.LastMessagePath()
+	suite.Require().NotEqual(previous, path, "письмо не отправлено")
+	content, err := os.ReadFile(path)
+	suite.Require().NoError(err)
+	suite.Contains(string(content), "To: forgetful@example.com")
+	suite.Contains(string(content), "https://barbershop.example/reset?token=")
+
+	match := resetTokenPattern.FindStringSubmatch(string(content))
+	suite.Require().Len(match, 2)
+	return match[1]
+}
+
+// reset устанавливает новый пароль по токену и возвращает код ответа
+func (suite *PasswordResetTestSuite) reset(token, password string) int {
+	return suite.request(http.MethodPost, "/api/auth/password/reset", "", models.PasswordResetRequest{Token: token, NewPassword: password}).Code
+}
+
+// TestResetPasswordEndsSessions тестирует сброс пароля и завершение всех сессий
+func (suite *PasswordResetTestSuite) TestResetPasswordEndsSessions() {
+	// Arrange
+	_, laptop := suite.login("forgetful@example.com", "old-password")
+	_, phone := suite.login("forgetful@example.com", "old-password")
+	token := suite.forgot()
+
+	// Act
+	code := suite.reset(token, "new-password")
+
+	// Assert
+	suite.Require().Equal(http.StatusOK, code)
+
+	for _, tokens := range []models.AuthResponse{laptop, phone} {
+		w := suite.request(http.MethodPost, "/api/auth/refresh", "", models.RefreshTokenRequest{RefreshToken: tokens.RefreshToken})
+		suite.Equal(http.StatusUnauthorized, w.Code)
+		suite.Equal(http.StatusUnauthorized, suite.request(http.MethodGet, "/api/auth/profile", tokens.AccessToken, nil).Code)
+	}
+
+	code, _ = suite.login("forgetful@example.com", "old-password")
+	suite.Equal(http.StatusUnauthorized, code)
+	code, _ = suite.login("forgetful@example.com", "new-password")
+	suite.Equal(http.StatusOK, code)
+
+	// Токен одноразовый
+	suite.Equal(http.StatusBadRequest, suite.reset(token, "another-password"))
+}
+
+// TestForgotUnknownEmail тестирует одинаковый ответ для незарегистрированного адреса
+func (suite *PasswordResetTestSuite) TestForgotUnknownEmail() {
+	previous := suite.mailer.LastMessagePath()
+
+	w := suite.request(http.MethodPost, "/api/auth/password/forgot", "", models.PasswordForgotRequest{Email: "nobody@example.com"})
+
+	suite.Equal(http.StatusAccepted, w.Code)
+	suite.Equal(previous, suite.mailer.LastMessagePath())
+}
+
+// TestInvalidResetTokens тестирует отклонение замененных, истекших и подобранных токенов
+func (suite *PasswordResetTestSuite) TestInvalidResetTokens() {
+	first := suite.forgot()
+	second := suite.forgot()
+
+	// Действует только последняя ссылка
+	suite.Equal(http.StatusBadRequest, suite.reset(first, "new-password"))
+	suite.Equal(http.StatusBadRequest, suite.reset("guessed-token", "new-password"))
+
+	// Слабый пароль не расходует токен
+	suite.Equal(http.StatusBadRequest, suite.reset(second, "123"))
+	var usable int64
+	suite.db.DB.Model(&models.PasswordResetToken{}).Where("used_at IS NULL").Count(&usable)
+	suite.Equal(int64(1), usable)
+
+	// Истекший токен
+
+	suite.db.DB.Model(&models.PasswordResetToken{}).Where("used_at IS NULL").Update("expires_at", time.Now().Add(-time.Minute))
+	suite.Equal(http.StatusBadRequest, suite.reset(second, "new-password"))
+
+	code, _ := suite.login("forgetful@example.com", "old-password")
+	suite.Equal(http.StatusOK, code)
+}
+
+// TestPasswordResetTestSuite запускает набор тестов
+func TestPasswordResetTestSuite(t *testing.T) {
+	suite.Run(t, new(PasswordResetTestSuite))
+}
@@ -0,0 +1,264 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"garage-barbershop/internal/database"
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/payments"
+	"garage-barbershop/internal/repositories"
+	"garage-barbershop/internal/services"
+
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// PaymentTestSuite набор тестов для оплаты записей
+type PaymentTestSuite struct {
+	suite.Suite
+	db              *database.Database
+	appointmentRepo repositories.AppointmentRepository
+	paymentService  services.PaymentService
+	cardProvider    *payments.FakeCardProvider
+	barber          *models.User
+	client          *models.User
+	appointment     *models.Appointment
+}
+
+// SetupSuite инициализирует тестовую среду
+func (suite *PaymentTestSuite) SetupSuite() {
+	db, err := gorm.Open(sqlite.Open("file:payments?mode=memory&cache=shared"), &gorm.Config{})
+	suite.Require().NoError(err)
+
+	suite.db = &database.Database{DB: db}
+	err = suite.db.Migrate(&models.User{}, &models.Service{}, &models.Appointment{}, &models.Payment{})
+	suite.Require().NoError(err)
+
+	suite.appointmentRepo = repositories.NewAppointmentRepository(db)
+	suite.cardProvider = payments.NewFakeCardProvider("webhook-secret")
+	suite.paymentService = services.NewPaymentService(
+		repositories.NewPaymentRepository(db),
+		suite.appointmentRepo,
+		"RUB",
+		payments.NewCashProvider(),
+		suite.cardProvider,
+	)
+}
+
+// TearDownSuite очищает тестовую среду
+func (suite *PaymentTestSuite) TearDownSuite() {
+	sqlDB, err := suite.db.DB.DB()
+	suite.Require().NoError(err)
+	sqlDB.Close()
+}
+
+// SetupTest создает барбера, клиента и запись
+func (suite *PaymentTestSuite) SetupTest() {
+	suite.db.DB.Exec("DELETE FROM payments")
+	suite.db.DB.Exec("DELETE FROM appointments")
+	suite.db.DB.Exec("DELETE FROM services")
+	suite.db.DB.Exec("DELETE FROM users")
+	suite.cardProvider.FailCapture = false
+
+	suite.barber = &models.User{TelegramID: 1, Email: "barber@example.com", IsActive: true}
+	suite.Require().NoError(suite.db.DB.Create(suite.barber).Error)
+	suite.client = &models.User{TelegramID: 2, Email: "client@example.com", IsActive: true}
+	suite.Require().NoError(suite.db.DB.Create(suite.client).Error)
+
+	service := &models.Service{Name: "Стрижка", Price: 1500, Duration: 60, IsActive: true, BarberID: suite.barber.ID}
+	suite.Require().NoError(suite.db.DB.Create(service).Error)
+
+	suite.appointment = &models.Appointment{
+		DateTime:      time.Now().Add(24 * time.Hour).UTC(),
+		Duration:      60,
+		Status:        models.AppointmentStatusConfirmed,
+		ClientID:      suite.client.ID,
+		BarberID:      suite.barber.ID,
+		ServiceID:     service.ID,
+		Price:         service.Price,
+		PaymentStatus: models.PaymentStatusPending,
+	}
+	suite.Require().NoError(suite.appointmentRepo.Create(suite.appointment))
+}
+
+// appointmentPaymentStatus перечитывает статус оплаты записи из БД
+func (suite *PaymentTestSuite) appointmentPaymentStatus() string {
+	appointment, err := suite.appointmentRepo.GetByID(suite.appointment.ID)
+	suite.Require().NoError(err)
+	return appointment.PaymentStatus
+}
+
+// TestCashPayment_CaptureAndRefund тестирует полный цикл оплаты наличными
+func (suite *PaymentTestSuite) TestCashPayment_CaptureAndRefund() {
+	payment, err := suite.paymentService.PayForAppointment(suite.client.ID, suite.appointment.ID, models.PaymentCreateRequest{Method: models.PaymentMethodCash})
+	suite.Require().NoError(err)
+	suite.Equal(models.PaymentPending, payment.Status)
+	suite.Equal(1500.0, payment.Amount)
+	suite.Equal("RUB", payment.Currency)
+	suite.Equal(models.PaymentStatusPending, suite.appointmentPaymentStatus())
+
+	payment, err = suite.paymentService.CapturePayment(suite.barber.ID, payment.ID)
+	suite.Require().NoError(err)
+	suite.Equal(models.PaymentCompleted, payment.Status)
+	suite.Equal(models.PaymentStatusPaid, suite.appointmentPaymentStatus())
+
+	// Повторно оплатить нельзя
+	_, err = suite.paymentService.PayForAppointment(suite.client.ID, suite.appointment.ID, models.PaymentCreateRequest{Method: models.PaymentMethodCash})
+	suite.ErrorIs(err, services.ErrAppointmentAlreadyPaid)
+
+	payment, err = suite.paymentService.RefundPayment(suite.barber.ID, payment.ID)
+	suite.Require().NoError(err)
+	suite.Equal(models.PaymentRefunded, payment.Status)
+	suite.Equal(models.PaymentStatusRefunded, suite.appointmentPaymentStatus())
+
+	_, err = suite.paymentService.RefundPayment(suite.barber.ID, payment.ID)
+	suite.ErrorIs(err, services.ErrInvalidPaymentTransition)
+}
+
+// TestCardPayment_Webhook тестирует подтверждение карточной оплаты уведомлением
+func (suite *PaymentTestSuite) TestCardPayment_Webhook() {
+	payment, err := suite.paymentService.PayForAppointment(suite.client.ID, suite.appointment.ID, models.PaymentCreateRequest{Method: models.PaymentMethodCard})
+	suite.Require().NoError(err)
+	suite.NotEmpty(payment.ExternalID)
+	suite.NotEmpty(payment.PaymentURL)
+
+	payload := []byte(`{"external_id":"` + payment.ExternalID + `","status":"completed"}`)
+
+	// Уведомление с неверной подписью отклоняется
+	header := http.Header{}
+	header.Set(payments.FakeSignatureHeader, "00")
+	_, err = suite.paymentService.HandleWebhook(models.PaymentMethodCard, payload, header)
+	suite.ErrorIs(err, payments.ErrInvalidSignature)
+	suite.Equal(models.PaymentStatusPending, suite.appointmentPaymentStatus())
+
+	header.Set(payments.FakeSignatureHeader, suite.cardProvider.SignWebhook(payload))
+	payment, err = suite.paymentService.HandleWebhook(models.PaymentMethodCard, payload, header)
+	suite.Require().NoError(err)
+	suite.Equal(models.PaymentCompleted, payment.Status)
+	suite.Equal(models.PaymentStatusPaid, suite.appointmentPaymentStatus())
+
+	// Повторное уведомление не ошибка
+	_, err = suite.paymentService.HandleWebhook(models.PaymentMethodCard, payload, header)
+	suite.NoError(err)
+}
+
+// TestCardPayment_DeclinedCapture тестирует отказ в списании
+func (suite *PaymentTestSuite) TestCardPayment_DeclinedCapture() {
+	payment, err := suite.paymentService.PayForAppointment(suite.client.ID, suite.appointment.ID, models.PaymentCreateRequest{Method: models.PaymentMethodCard})
+	suite.Require().NoError(err)
+
+	suite.cardProvider.FailCapture = true
+	_, err = suite.paymentService.CapturePayment(suite.barber.ID, payment.ID)
+	suite.Error(err)
+
+	list, err := suite.paymentService.GetClientPayments(suite.client.ID, suite.appointment.ID)
+	suite.Require().NoError(err)
+	suite.Require().Len(list, 1)
+	suite.Equal(models.PaymentFailed, list[0].Status)
+	suite.Equal(models.PaymentStatusPending, suite.appointmentPaymentStatus())
+}
+
+// TestPayment_NewPaymentReplacesPending тестирует, что незавершенный платеж заменяется новым
+func (suite *PaymentTestSuite) TestPayment_NewPaymentReplacesPending() {
+	first, err := suite.paymentService.PayForAppointment(suite.client.ID, suite.appointment.ID, models.PaymentCreateRequest{Method: models.PaymentMethodCard})
+	suite.Require().NoError(err)
+	_, err = suite.paymentService.PayForAppointment(suite.client.ID, suite.appointment.ID, models.PaymentCreateRequest{Method: models.PaymentMethodCash})
+	suite.Require().NoError(err)
+
+	_, err = suite.paymentService.CapturePayment(suite.barber.ID, first.ID)
+	suite.ErrorIs(err, services.ErrInvalidPaymentTransition)
+}
+
+// TestPayment_CaptureAfterOtherPaid тестирует, что второй платеж по уже оплаченной записи не списывается
+func (suite *PaymentTestSuite) TestPayment_CaptureAfterOtherPaid() {
+	// Два незавершенных платежа, как при одновременной оплате с двух устройств
+	first := &models.Payment{Amount: 1500, Currency: "RUB", Status: models.PaymentPending, PaymentMethod: models.PaymentMethodCash, AppointmentID: suite.appointment.ID}
+	second := &models.Payment{Amount: 1500, Currency: "RUB", Status: models.PaymentPending, PaymentMethod: models.PaymentMethodCash, AppointmentID: suite.appointment.ID}
+	suite.Require().NoError(suite.db.DB.Create(first).Error)
+	suite.Require().NoError(suite.db.DB.Create(second).Error)
+
+	_, err := suite.paymentService.CapturePayment(suite.barber.ID, first.ID)
+	suite.Require().NoError(err)
+
+	_, err = suite.paymentService.CapturePayment(suite.barber.ID, second.ID)
+	suite.ErrorIs(err, services.ErrAppointmentAlreadyPaid)
+
+	// Даже если статус записи не успел обновиться, проведенный платеж блокирует списание
+	suite.Require().NoError(suite.db.DB.Model(&models.Appointment{}).Where("id = ?", suite.appointment.ID).Update("payment_status", models.PaymentStatusPending).Error)
+	_, err = suite.paymentService.CapturePayment(suite.barber.ID, second.ID)
+	suite.ErrorIs(err, services.ErrAppointmentAlreadyPaid)
+
+	list, err := suite.paymentService.GetClientPayments(suite.client.ID, suite.appointment.ID)
+	suite.Require().NoError(err)
+	suite.Require().Len(list, 2)
+	suite.Equal(models.PaymentPending, list[1].Status)
+}
+
+// TestPayment_LateCompletionFlaggedForRefund тестирует уведомление о списании по отмененному платежу
+// после оплаты записи другим платежом
+func (suite *PaymentTestSuite) TestPayment_LateCompletionFlaggedForRefund() {
+	card, err := suite.paymentService.PayForAppointment(suite.client.ID, suite.appointment.ID, models.PaymentCreateRequest{Method: models.PaymentMethodCard})
+	suite.Require().NoError(err)
+	cash, err := suite.paymentService.PayForAppointment(suite.client.ID, suite.appointment.ID, models.PaymentCreateRequest{Method: models.PaymentMethodCash})
+	suite.Require().NoError(err)
+	_, err = suite.paymentService.CapturePayment(suite.barber.ID, cash.ID)
+	suite.Require().NoError(err)
+
+	// Платежная система все же провела отмененный карточный платеж
+	payload := []byte(`{"external_id":"` + card.ExternalID + `","status":"completed"}`)
+	header := http.Header{}
+	header.Set(payments.FakeSignatureHeader, suite.cardProvider.SignWebhook(payload))
+	charged, err := suite.paymentService.HandleWebhook(models.PaymentMethodCard, payload, header)
+	suite.Require().NoError(err)
+	suite.Equal(models.PaymentCompleted, charged.Status)
+	suite.True(charged.RefundRequired)
+	suite.Equal(models.PaymentStatusPaid, suite.appointmentPaymentStatus())
+
+	// Возврат лишнего списания не снимает оплату записи
+	refunded, err := suite.paymentService.AdminRefundPayment(card.ID)
+	suite.Require().NoError(err)
+	suite.Equal(models.PaymentRefunded, refunded.Status)
+	suite.False(refunded.RefundRequired)
+	suite.Equal(models.PaymentStatusPaid, suite.appointmentPaymentStatus())
+}
+
+// TestPayment_StaleStatusRejected тестирует, что смена статуса по устаревшим данным платежа отклоняется
+func (suite *PaymentTestSuite) TestPayment_StaleStatusRejected() {
+	payment, err := suite.paymentService.PayForAppointment(suite.client.ID, suite.appointment.ID, models.PaymentCreateRequest{Method: models.PaymentMethodCash})
+	suite.Require().NoError(err)
+
+	stale := *payment
+	stale.Status = models.PaymentCompleted
+	paymentRepo := repositories.NewPaymentRepository(suite.db.DB)
+	suite.Require().NoError(paymentRepo.Save(&stale, models.PaymentPending))
+
+	stale.Status = models.PaymentFailed
+	suite.ErrorIs(paymentRepo.Save(&stale, models.PaymentPending), repositories.ErrPaymentStatusChanged)
+	suite.Equal(models.PaymentStatusPaid, suite.appointmentPaymentStatus())
+}
+
+// TestPayment_Access тестирует доступ к чужим записям и платежам
+func (suite *PaymentTestSuite) TestPayment_Access() {
+	_, err := suite.paymentService.PayForAppointment(suite.barber.ID, suite.appointment.ID, models.PaymentCreateRequest{Method: models.PaymentMethodCash})
+	suite.ErrorIs(err, services.ErrAppointmentNotFound)
+
+	_, err = suite.paymentService.PayForAppointment(suite.client.ID, suite.appointment.ID, models.PaymentCreateRequest{Method: "bitcoin"})
+	suite.ErrorIs(err, services.ErrUnsupportedPaymentMethod)
+
+	payment, err := suite.paymentService.PayForAppointment(suite.client.ID, suite.appointment.ID, models.PaymentCreateRequest{Method: models.PaymentMethodCash})
+	suite.Require().NoError(err)
+
+	_, err = suite.paymentService.CapturePayment(suite.client.ID, payment.ID)
+	suite.ErrorIs(err, services.ErrPaymentNotFound)
+
+	_, err = suite.paymentService.GetBarberPayments(suite.client.ID, suite.appointment.ID)
+	suite.ErrorIs(err, services.ErrAppointmentNotFound)
+}
+
+// TestPaymentTestSuite запускает набор тестов
+func TestPaymentTestSuite(t *testing.T) {
+	suite.Run(t, new(PaymentTestSuite))
+}
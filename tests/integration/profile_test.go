@@ -0,0 +1,174 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"garage-barbershop/internal/database"
+	"garage-barbershop/internal/middleware"
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/services"
+
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// ProfileTestSuite набор тестов выхода и профиля текущего пользователя
+type ProfileTestSuite struct {
+	apiTestSuite
+	db          *database.Database
+	authService services.AuthService
+}
+
+// SetupSuite инициализирует тестовую среду и маршруты
+func (suite *ProfileTestSuite) SetupSuite() {
+	db, err := gorm.Open(sqlite.Open("file:profile?mode=memory&cache=shared"), &gorm.Config{})
+	suite.Require().NoError(err)
+
+	suite.db = &database.Database{DB: db}
+	err = suite.db.Migrate(&models.User{}, &models.Role{}, &models.UserRole{}, &models.AuditEvent{}, &models.Session{})
+	suite.Require().NoError(err)
+
+	authServices := newAuthTestServices(db, authTestConfig{})
+	suite.authService = authServices.auth
+	authHandler := authServices.handler
+	auth := middleware.HTTPAuthMiddleware(suite.authService)
+
+	suite.mux = http.NewServeMux()
+	suite.mux.HandleFunc("/api/auth/login", authHandler.LoginDirect)
+	suite.mux.HandleFunc("/api/auth/refresh", authHandler.RefreshToken)
+	suite.mux.HandleFunc("/api/auth/logout", auth(authHandler.Logout))
+	suite.mux.HandleFunc("/api/auth/profile", auth(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPatch {
+			authHandler.UpdateProfile(w, r)
+			return
+		}
+		authHandler.GetProfile(w, r)
+	}))
+}
+
+// TearDownSuite очищает тестовую среду
+func (suite *ProfileTestSuite) TearDownSuite() {
+	sqlDB, err := suite.db.DB.DB()
+	suite.Require().NoError(err)
+	sqlDB.Close()
+}
+
+// SetupTest создает клиента и барбера с паролями
+func (suite *ProfileTestSuite) SetupTest() {
+	suite.db.DB.Exec("DELETE FROM sessions")
+	suite.db.DB.Exec("DELETE FROM user_roles")
+	suite.db.DB.Exec("DELETE FROM users")
+
+	for _, req := range []models.DirectRegisterRequest{
+		{Email: "client@example.com", Password: "fresh-fade-42", FirstName: "Анна", LastName: "Смирнова", Role: "client"},
+		{Email: "barber@example.com", Password: "fresh-fade-42", FirstName: "Игорь", LastName: "Козлов", Role: "barber"},
+	} {
+		user, err := suite.authService.RegisterUserDirect(req)
+		suite.Require().NoError(err)
+		// telegram_id уникален, а у пользователей прямой регистрации он нулевой
+		suite.db.DB.Model(user).Update("telegram_id", user.ID)
+	}
+}
+
+// TestLogoutEndsSession тестирует завершение сессии устройства при выходе
+func (suite *ProfileTestSuite) TestLogoutEndsSession() {
+	// Arrange
+	tokens := suite.mustLogin("client@example.com")
+
+	// Act
+	w := suite.request(http.MethodPost, "/api/auth/logout", tokens.AccessToken, nil)
+
+	// Assert
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	suite.Equal(http.StatusUnauthorized, suite.request(http.MethodGet, "/api/auth/profile", tokens.AccessToken, nil).Code)
+
+	w = suite.request(http.MethodPost, "/api/auth/refresh", "", models.RefreshTokenRequest{RefreshToken: tokens.RefreshToken})
+	suite.Equal(http.StatusUnauthorized, w.Code)
+
+	// Без токена выйти нельзя
+	suite.Equal(http.StatusUnauthorized, suite.request(http.MethodPost, "/api/auth/logout", "", nil).Code)
+}
+
+// TestGetProfile тестирует профиль аутентифицированного пользователя
+func (suite *ProfileTestSuite) TestGetProfile() {
+	// Arrange
+	tokens := suite.mustLogin("client@example.com")
+
+	// Act
+	w := suite.request(http.MethodGet, "/api/auth/profile", tokens.AccessToken, nil)
+
+	// Assert
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	var profile models.User
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &profile))
+	suite.Equal(tokens.User.ID, profile.ID)
+	suite.Equal("client@example.com", profile.Email)
+	suite.Equal("Анна", profile.FirstName)
+	suite.Require().Len(profile.Roles, 1)
+	suite.Equal("client", profile.Roles[0].Name)
+	suite.NotContains(w.Body.String(), "password")
+
+	suite.Equal(http.StatusUnauthorized, suite.request(http.MethodGet, "/api/auth/profile", "", nil).Code)
+}
+
+// TestUpdateProfile тестирует изменение телефона, имени и предпочтений
+func (suite *ProfileTestSuite) TestUpdateProfile() {
+	// Arrange
+	tokens := suite.mustLogin("client@example.com")
+	phone := "+7 (999) 123-45-67"
+	firstName := " Анастасия "
+	preferences := "Короткая стрижка, без укладки"
+
+	// Act
+	w := suite.request(http.MethodPatch, "/api/auth/profile", tokens.AccessToken, models.ProfileUpdateRequest{
+		FirstName:   &firstName,
+		Phone:       &phone,
+		Preferences: &preferences,
+	})
+
+	// Assert
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	var profile models.User
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &profile))
+	suite.Equal("Анастасия", profile.FirstName)
+	suite.Equal("Смирнова", profile.LastName)
+	suite.Equal(phone, profile.Phone)
+	suite.Equal(preferences, profile.Preferences)
+
+	stored, err := suite.authService.GetUserByID(tokens.User.ID)
+	suite.Require().NoError(err)
+	suite.Equal(phone, stored.Phone)
+	suite.Equal(preferences, stored.Preferences)
+}
+
+// TestUpdateProfileValidation тестирует отклонение неверных изменений профиля
+func (suite *ProfileTestSuite) TestUpdateProfileValidation() {
+	client := suite.mustLogin("client@example.com")
+	barber := suite.mustLogin("barber@example.com")
+	invalidPhone := "звоните вечером"
+	emptyName := "  "
+	preferences := "Кофе без сахара"
+
+	w := suite.request(http.MethodPatch, "/api/auth/profile", client.AccessToken, models.ProfileUpdateRequest{Phone: &invalidPhone})
+	suite.Equal(http.StatusBadRequest, w.Code)
+
+	w = suite.request(http.MethodPatch, "/api/auth/profile", client.AccessToken, models.ProfileUpdateRequest{FirstName: &emptyName})
+	suite.Equal(http.StatusBadRequest, w.Code)
+
+	// Предпочтения есть только у клиентов
+	w = suite.request(http.MethodPatch, "/api/auth/profile", barber.AccessToken, models.ProfileUpdateRequest{Preferences: &preferences})
+	suite.Equal(http.StatusForbidden, w.Code)
+
+	stored, err := suite.authService.GetUserByID(client.User.ID)
+	suite.Require().NoError(err)
+	suite.Equal("Анна", stored.FirstName)
+	suite.Empty(stored.Phone)
+}
+
+// TestProfileTestSuite запускает набор тестов
+func TestProfileTestSuite(t *testing.T) {
+	suite.Run(t, new(ProfileTestSuite))
+}
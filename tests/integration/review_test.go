@@ -0,0 +1,250 @@
+package integration
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"garage-barbershop/internal/database"
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/repositories"
+	"garage-barbershop/internal/services"
+
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// ReviewTestSuite набор тестов для отзывов и рейтинга барбера
+type ReviewTestSuite struct {
+	suite.Suite
+	db              *database.Database
+	userRepo        repositories.UserRepository
+	roleRepo        repositories.RoleRepository
+	appointmentRepo repositories.AppointmentRepository
+	reviewService   services.ReviewService
+	barber          *models.User
+	client          *models.User
+	service         *models.Service
+}
+
+// SetupSuite инициализирует тестовую среду
+func (suite *ReviewTestSuite) SetupSuite() {
+	// Файловая БД: параллельные горутины используют разные соединения
+	dsn := filepath.Join(suite.T().TempDir(), "reviews.db") + "?_busy_timeout=5000"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	suite.Require().NoError(err)
+
+	suite.db = &database.Database{DB: db}
+	err = suite.db.Migrate(&models.User{}, &models.Role{}, &models.UserRole{}, &models.Service{}, &models.Appointment{}, &models.Review{})
+	suite.Require().NoError(err)
+
+	suite.userRepo = repositories.NewUserRepository(db)
+	suite.roleRepo = repositories.NewRoleRepository(db)
+	suite.appointmentRepo = repositories.NewAppointmentRepository(db)
+	suite.reviewService = services.NewReviewService(repositories.NewReviewRepository(db), suite.appointmentRepo, suite.roleRepo)
+}
+
+// TearDownSuite очищает тестовую среду
+func (suite *ReviewTestSuite) TearDownSuite() {
+	sqlDB, err := suite.db.DB.DB()
+	suite.Require().NoError(err)
+	sqlDB.Close()
+}
+
+// SetupTest создает барбера, клиента и услугу
+func (suite *ReviewTestSuite) SetupTest() {
+	suite.db.DB.Exec("DELETE FROM reviews")
+	suite.db.DB.Exec("DELETE FROM appointments")
+	suite.db.DB.Exec("DELETE FROM services")
+	suite.db.DB.Exec("DELETE FROM user_roles")
+	suite.db.DB.Exec("DELETE FROM users")
+
+	suite.barber = &models.User{TelegramID: 1, Email: "barber@example.com", FirstName: "Barber", IsActive: true, Rating: 5.0}
+	suite.Require().NoError(suite.userRepo.Create(suite.barber))
+	role, err := suite.roleRepo.GetRoleByName("barber")
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.roleRepo.AssignRoleToUser(suite.barber.ID, role.ID, suite.barber.ID))
+
+	suite.client = &models.User{TelegramID: 2, Email: "client@example.com", Phone: "+79990000000", FirstName: "Иван", IsActive: true}
+	suite.Require().NoError(suite.userRepo.Create(suite.client))
+
+	suite.service = &models.Service{Name: "Стрижка", Price: 1500, Duration: 60, IsActive: true, BarberID: suite.barber.ID}
+	suite.Require().NoError(suite.db.DB.Create(suite.service).Error)
+}
+
+// createAppointment создает запись клиента в указанном статусе
+func (suite *ReviewTestSuite) createAppointment(clientID uint, status string, daysAgo int) *models.Appointment {
+	appointment := &models.Appointment{
+		DateTime:  time.Now().AddDate(0, 0, -daysAgo).UTC(),
+		Duration:  60,
+		Status:    status,
+		ClientID:  clientID,
+		BarberID:  suite.barber.ID,
+		ServiceID: suite.service.ID,
+		Price:     suite.service.Price,
+	}
+	suite.Require().NoError(suite.appointmentRepo.Create(appointment))
+	return appointment
+}
+
+// barberRating перечитывает рейтинг барбера из БД
+func (suite *ReviewTestSuite) barberRating() float64 {
+	barber, err := suite.userRepo.GetByID(suite.barber.ID)
+	suite.Require().NoError(err)
+	return barber.Rating
+}
+
+// TestCreateReview_Rules тестирует, какие записи можно оценить
+func (suite *ReviewTestSuite) TestCreateReview_Rules() {
+	// Arrange
+	completed := suite.createAppointment(suite.client.ID, models.AppointmentStatusCompleted, 1)
+	confirmed := suite.createAppointment(suite.client.ID, models.AppointmentStatusConfirmed, 2)
+	other := &models.User{TelegramID: 3, Email: "other@example.com", IsActive: true}
+	suite.Require().NoError(suite.userRepo.Create(other))
+
+	// Act & Assert
+	_, err := suite.reviewService.CreateReview(suite.client.ID, confirmed.ID, models.ReviewRequest{Rating: 5})
+	suite.ErrorIs(err, services.ErrAppointmentNotCompleted)
+
+	_, err = suite.reviewService.CreateReview(other.ID, completed.ID, models.ReviewRequest{Rating: 5})
+	suite.ErrorIs(err, services.ErrAppointmentNotFound)
+
+	_, err = suite.reviewService.CreateReview(suite.client.ID, completed.ID, models.ReviewRequest{Rating: 6})
+	suite.Error(err)
+
+	review, err := suite.reviewService.CreateReview(suite.client.ID, completed.ID, models.ReviewRequest{Rating: 4, Comment: "  Хорошо  "})
+	suite.Require().NoError(err)
+	suite.Equal(suite.barber.ID, review.BarberID)
+	suite.Equal("Хорошо", review.Comment)
+
+	_, err = suite.reviewService.CreateReview(suite.client.ID, completed.ID, models.ReviewRequest{Rating: 5})
+	suite.ErrorIs(err, services.ErrReviewAlreadyExists)
+}
+
+// TestRating_RecalculatedOnChanges тестирует пересчет рейтинга при добавлении, изменении и удалении
+func (suite *ReviewTestSuite) TestRating_RecalculatedOnChanges() {
+	first := suite.createAppointment(suite.client.ID, models.AppointmentStatusCompleted, 1)
+	second := suite.createAppointment(suite.client.ID, models.AppointmentStatusCompleted, 2)
+
+	// (5*5 + 1) / 6 = 4.33
+	_, err := suite.reviewService.CreateReview(suite.client.ID, first.ID, models.ReviewRequest{Rating: 1})
+	suite.Require().NoError(err)
+	suite.Equal(4.33, suite.barberRating())
+
+	// (5*5 + 1 + 3) / 7 = 4.14
+	_, err = suite.reviewService.CreateReview(suite.client.ID, second.ID, models.ReviewRequest{Rating: 3})
+	suite.Require().NoError(err)
+	suite.Equal(4.14, suite.barberRating())
+
+	// (5*5 + 5 + 3) / 7 = 4.71
+	_, err = suite.reviewService.UpdateReview(suite.client.ID, first.ID, models.ReviewRequest{Rating: 5})
+	suite.Require().NoError(err)
+	suite.Equal(4.71, suite.barberRating())
+
+	// (5*5 + 5) / 6 = 5.0
+	suite.Require().NoError(suite.reviewService.DeleteReview(suite.client.ID, second.ID))
+	suite.Equal(5.0, suite.barberRating())
+
+	// После удаления можно оставить отзыв заново
+	_, err = suite.reviewService.CreateReview(suite.client.ID, second.ID, models.ReviewRequest{Rating: 5})
+	suite.NoError(err)
+}
+
+// TestRating_ConcurrentReviews тестирует, что параллельные отзывы учитываются в рейтинге все до одного
+func (suite *ReviewTestSuite) TestRating_ConcurrentReviews() {
+	var appointments []*models.Appointment
+	for i := 0; i < 20; i++ {
+		client := &models.User{TelegramID: int64(100 + i), Email: fmt.Sprintf("client%d@example.com", i), IsActive: true}
+		suite.Require().NoError(suite.userRepo.Create(client))
+		appointments = append(appointments, suite.createAppointment(client.ID, models.AppointmentStatusCompleted, 1))
+	}
+
+	var wg sync.WaitGroup
+	results := make(chan error, len(appointments))
+	start := make(chan struct{})
+
+	var sum int64
+	for i, appointment := range appointments {
+		rating := i%5 + 1
+		sum += int64(rating)
+		wg.Add(1)
+		go func(clientID, appointmentID uint, rating int) {
+			defer wg.Done()
+			<-start
+			_, err := suite.reviewService.CreateReview(clientID, appointmentID, models.ReviewRequest{Rating: rating})
+			results <- err
+		}(appointment.ClientID, appointment.ID, rating)
+	}
+
+	close(start)
+	wg.Wait()
+	close(results)
+
+	for err := range results {
+		suite.NoError(err)
+	}
+	suite.Equal(models.BayesianRating(int64(len(appointments)), sum), suite.barberRating())
+}
+
+// TestHideReview тестирует скрытие отзыва администратором
+func (suite *ReviewTestSuite) TestHideReview() {
+	appointment := suite.createAppointment(suite.client.ID, models.AppointmentStatusCompleted, 1)
+	review, err := suite.reviewService.CreateReview(suite.client.ID, appointment.ID, models.ReviewRequest{Rating: 1, Comment: "оскорбление"})
+	suite.Require().NoError(err)
+
+	hidden, err := suite.reviewService.HideReview(review.ID, models.ReviewHideRequest{Reason: "оскорбления"})
+	suite.Require().NoError(err)
+	suite.True(hidden.IsHidden)
+	suite.NotNil(hidden.HiddenAt)
+	suite.Equal(5.0, suite.barberRating())
+
+	public, total, err := suite.reviewService.GetBarberReviews(suite.barber.ID, models.ReviewFilter{})
+	suite.Require().NoError(err)
+	suite.Empty(public)
+	suite.Zero(total)
+
+	all, total, err := suite.reviewService.ListReviews(models.ReviewFilter{IncludeHidden: true})
+	suite.Require().NoError(err)
+	suite.Len(all, 1)
+	suite.EqualValues(1, total)
+
+	_, err = suite.reviewService.UnhideReview(review.ID)
+	suite.Require().NoError(err)
+	suite.Equal(4.33, suite.barberRating())
+
+	_, err = suite.reviewService.HideReview(review.ID+100, models.ReviewHideRequest{})
+	suite.ErrorIs(err, services.ErrReviewNotFound)
+}
+
+// TestGetBarberReviews_Pagination тестирует публичный список с пагинацией
+func (suite *ReviewTestSuite) TestGetBarberReviews_Pagination() {
+	for i := 1; i <= 5; i++ {
+		appointment := suite.createAppointment(suite.client.ID, models.AppointmentStatusCompleted, i)
+		_, err := suite.reviewService.CreateReview(suite.client.ID, appointment.ID, models.ReviewRequest{Rating: i})
+		suite.Require().NoError(err)
+	}
+
+	page, total, err := suite.reviewService.GetBarberReviews(suite.barber.ID, models.ReviewFilter{Page: 2, PageSize: 2})
+	suite.Require().NoError(err)
+	suite.EqualValues(5, total)
+	suite.Require().Len(page, 2)
+	suite.Equal(3, page[0].Rating)
+	suite.Equal(2, page[1].Rating)
+	suite.Equal("Иван", page[0].ClientName)
+
+	last, _, err := suite.reviewService.GetBarberReviews(suite.barber.ID, models.ReviewFilter{Page: 3, PageSize: 2})
+	suite.Require().NoError(err)
+	suite.Len(last, 1)
+
+	_, _, err = suite.reviewService.GetBarberReviews(suite.client.ID, models.ReviewFilter{})
+	suite.ErrorIs(err, services.ErrNotBarber)
+}
+
+// TestReviewTestSuite запускает набор тестов
+func TestReviewTestSuite(t *testing.T) {
+	suite.Run(t, new(ReviewTestSuite))
+}
@@ -0,0 +1,263 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"garage-barbershop/internal/database"
+	"garage-barbershop/internal/handlers"
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/repositories"
+	"garage-barbershop/internal/services"
+
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// RoleAdminTestSuite набор тестов администрирования ролей
+type RoleAdminTestSuite struct {
+	suite.Suite
+	db                *database.Database
+	userRepo          repositories.UserRepository
+	roleRepo          repositories.RoleRepository
+	roleService       services.RoleService
+	permissionService services.PermissionService
+	mux               *http.ServeMux
+	admin             *models.User
+	user              *models.User
+	adminRole         *models.Role
+}
+
+// SetupSuite инициализирует тестовую среду и маршруты
+func (suite *RoleAdminTestSuite) SetupSuite() {
+	db, err := gorm.Open(sqlite.Open("file:role_admin?mode=memory&cache=shared"), &gorm.Config{})
+	suite.Require().NoError(err)
+
+	suite.db = &database.Database{DB: db}
+	err = suite.db.Migrate(&models.User{}, &models.Role{}, &models.UserRole{}, &models.AuditEvent{})
+	suite.Require().NoError(err)
+
+	suite.userRepo = repositories.NewUserRepository(db)
+	suite.roleRepo = repositories.NewRoleRepository(db)
+	suite.roleService = services.NewRoleService(suite.roleRepo)
+	suite.permissionService = services.NewPermissionService(suite.roleRepo)
+
+	auditService := services.NewAuditService(repositories.NewAuditRepository(db))
+	roleHandler := handlers.NewRoleHandler(suite.roleService, suite.permissionService, auditService, services.NewTokenRevocationService(nil))
+	suite.mux = http.NewServeMux()
+	suite.mux.HandleFunc("/api/admin/roles", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			roleHandler.CreateRole(w, r)
+			return
+		}
+		roleHandler.ListRoles(w, r)
+	})
+	suite.mux.HandleFunc("/api/admin/roles/{id}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			roleHandler.UpdateRole(w, r)
+		case http.MethodDelete:
+			roleHandler.DeactivateRole(w, r)
+		default:
+			roleHandler.GetRole(w, r)
+		}
+	})
+	suite.mux.HandleFunc("/api/admin/roles/assign", roleHandler.AssignRole)
+	suite.mux.HandleFunc("/api/admin/roles/revoke", roleHandler.RevokeRole)
+	suite.mux.HandleFunc("/api/admin/users/{id}/roles", roleHandler.GetUserRoles)
+}
+
+// TearDownSuite очищает тестовую среду
+func (suite *RoleAdminTestSuite) TearDownSuite() {
+	sqlDB, err := suite.db.DB.DB()
+	suite.Require().NoError(err)
+	sqlDB.Close()
+}
+
+// SetupTest создает администратора и обычного пользователя
+func (suite *RoleAdminTestSuite) SetupTest() {
+	suite.db.DB.Exec("DELETE FROM user_roles")
+	suite.db.DB.Exec("DELETE FROM users")
+	suite.db.DB.Exec("DELETE FROM roles WHERE name NOT IN ('admin', 'barber', 'client')")
+	suite.db.DB.Exec("UPDATE roles SET is_active = true")
+	suite.permissionService.InvalidateAll()
+
+	suite.admin = &models.User{TelegramID: 1, Email: "admin@example.com", IsActive: true}
+	suite.Require().NoError(suite.userRepo.Create(suite.admin))
+	suite.user = &models.User{TelegramID: 2, Email: "user@example.com", IsActive: true}
+	suite.Require().NoError(suite.userRepo.Create(suite.user))
+
+	var err error
+	suite.adminRole, err = suite.roleRepo.GetRoleByName("admin")
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.roleRepo.AssignRoleToUser(suite.admin.ID, suite.adminRole.ID, suite.admin.ID))
+}
+
+// request выполняет запрос от имени администратора
+func (suite *RoleAdminTestSuite) request(method, path string, body interface{}) *httptest.ResponseRecorder {
+	var payload bytes.Buffer
+	if body != nil {
+		suite.Require().NoError(json.NewEncoder(&payload).Encode(body))
+	}
+	req := httptest.NewRequest(method, path, &payload)
+	req = req.WithContext(context.WithValue(req.Context(), "userID", suite.admin.ID))
+
+	w := httptest.NewRecorder()
+	suite.mux.ServeHTTP(w, req)
+	return w
+}
+
+// TestCreateUpdateDeactivateRole тестирует жизненный цикл пользовательской роли
+func (suite *RoleAdminTestSuite) TestCreateUpdateDeactivateRole() {
+	// Arrange & Act
+	w := suite.request(http.MethodPost, "/api/admin/roles", models.RoleCreateRequest{
+		Name:        "Moderator",
+		DisplayName: "Модератор",
+		Permissions: models.PermissionSet{"reviews": {"read", "update"}},
+	})
+
+	// Assert
+	suite.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+	var role models.Role
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &role))
+	suite.Equal("moderator", role.Name)
+
+	w = suite.request(http.MethodPost, "/api/admin/roles", models.RoleCreateRequest{Name: "moderator"})
+	suite.Equal(http.StatusConflict, w.Code)
+
+	w = suite.request(http.MethodPost, "/api/admin/roles/assign", models.RoleAssignmentRequest{UserID: suite.user.ID, RoleID: role.ID, Reason: "модерация отзывов"})
+	suite.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+	suite.True(suite.permissionService.Can(suite.user.ID, "reviews", "update"))
+
+	description := "Модерация отзывов"
+	w = suite.request(http.MethodPut, "/api/admin/roles/"+strconv.FormatUint(uint64(role.ID), 10), models.RoleUpdateRequest{
+		Description: &description,
+		Permissions: models.PermissionSet{"reviews": {"read"}},
+	})
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	suite.False(suite.permissionService.Can(suite.user.ID, "reviews", "update"))
+
+	w = suite.request(http.MethodDelete, "/api/admin/roles/"+strconv.FormatUint(uint64(role.ID), 10), nil)
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	suite.False(suite.permissionService.Can(suite.user.ID, "reviews", "read"))
+
+	stored, err := suite.roleRepo.GetRoleByID(role.ID)
+	suite.Require().NoError(err)
+	suite.False(stored.IsActive)
+}
+
+// TestProtectedRoles тестирует защиту системных ролей
+func (suite *RoleAdminTestSuite) TestProtectedRoles() {
+	w := suite.request(http.MethodDelete, "/api/admin/roles/"+strconv.FormatUint(uint64(suite.adminRole.ID), 10), nil)
+	suite.Equal(http.StatusForbidden, w.Code)
+
+	suite.ErrorIs(suite.roleService.DeleteRole(suite.adminRole.ID), services.ErrProtectedRole)
+
+	// Администратор не может потерять право управлять ролями
+	w = suite.request(http.MethodPut, "/api/admin/roles/"+strconv.FormatUint(uint64(suite.adminRole.ID), 10), models.RoleUpdateRequest{
+		Permissions: models.PermissionSet{"users": {"read"}},
+	})
+	suite.Equal(http.StatusForbidden, w.Code)
+
+	role, err := suite.roleRepo.GetRoleByID(suite.adminRole.ID)
+	suite.Require().NoError(err)
+	suite.True(role.IsActive)
+	suite.JSONEq(`{"*": ["*"]}`, role.Permissions)
+}
+
+// TestAssignAndRevoke тестирует назначение и снятие ролей с причиной
+func (suite *RoleAdminTestSuite) TestAssignAndRevoke() {
+	barberRole, err := suite.roleRepo.GetRoleByName("barber")
+	suite.Require().NoError(err)
+
+	w := suite.request(http.MethodPost, "/api/admin/roles/assign", models.RoleAssignmentRequest{UserID: suite.user.ID, RoleID: barberRole.ID, Reason: "новый мастер"})
+	suite.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+
+	var userRole models.UserRole
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &userRole))
+	suite.Equal(suite.admin.ID, userRole.AssignedBy)
+	suite.Equal("новый мастер", userRole.Reason)
+
+	w = suite.request(http.MethodPost, "/api/admin/roles/assign", models.RoleAssignmentRequest{UserID: suite.user.ID, RoleID: barberRole.ID})
+	suite.Equal(http.StatusConflict, w.Code)
+
+	w = suite.request(http.MethodPost, "/api/admin/roles/assign", models.RoleAssignmentRequest{UserID: 9999, RoleID: barberRole.ID})
+	suite.Equal(http.StatusNotFound, w.Code)
+
+	w = suite.request(http.MethodGet, "/api/admin/users/"+strconv.FormatUint(uint64(suite.user.ID), 10)+"/roles", nil)
+	suite.Require().Equal(http.StatusOK, w.Code)
+	suite.Contains(w.Body.String(), `"name":"barber"`)
+
+	w = suite.request(http.MethodPost, "/api/admin/roles/revoke", models.RoleRemovalRequest{UserID: suite.user.ID, RoleID: barberRole.ID, Reason: "уволился"})
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	suite.False(suite.roleRepo.HasUserRole(suite.user.ID, "barber"))
+
+	var revoked models.UserRole
+	suite.Require().NoError(suite.db.DB.Unscoped().Where("user_id = ? AND role_id = ?", suite.user.ID, barberRole.ID).First(&revoked).Error)
+	suite.Equal(suite.admin.ID, revoked.RevokedBy)
+	suite.Equal("уволился", revoked.RevokedReason)
+	suite.NotNil(revoked.RevokedAt)
+
+	w = suite.request(http.MethodPost, "/api/admin/roles/revoke", models.RoleRemovalRequest{UserID: suite.user.ID, RoleID: barberRole.ID})
+	suite.Equal(http.StatusConflict, w.Code)
+
+	// Роль можно назначить повторно
+	w = suite.request(http.MethodPost, "/api/admin/roles/assign", models.RoleAssignmentRequest{UserID: suite.user.ID, RoleID: barberRole.ID})
+	suite.Equal(http.StatusCreated, w.Code)
+}
+
+// TestLastAdminCannotBeRemoved тестирует запрет снятия роли с последнего администратора
+func (suite *RoleAdminTestSuite) TestLastAdminCannotBeRemoved() {
+	w := suite.request(http.MethodPost, "/api/admin/roles/revoke", models.RoleRemovalRequest{UserID: suite.admin.ID, RoleID: suite.adminRole.ID})
+	suite.Equal(http.StatusForbidden, w.Code)
+	suite.ErrorIs(suite.roleService.RemoveRoleFromUser(suite.admin.ID, suite.adminRole.ID), services.ErrLastAdmin)
+
+	// Со вторым администратором роль снять можно
+	w = suite.request(http.MethodPost, "/api/admin/roles/assign", models.RoleAssignmentRequest{UserID: suite.user.ID, RoleID: suite.adminRole.ID})
+	suite.Require().Equal(http.StatusCreated, w.Code)
+
+	w = suite.request(http.MethodPost, "/api/admin/roles/revoke", models.RoleRemovalRequest{UserID: suite.admin.ID, RoleID: suite.adminRole.ID, Reason: "передал дела"})
+	suite.Equal(http.StatusOK, w.Code, w.Body.String())
+	suite.False(suite.roleRepo.HasUserRole(suite.admin.ID, "admin"))
+}
+
+// TestConcurrentAdminRevokes тестирует, что два администратора не могут одновременно снять роль друг с друга
+func (suite *RoleAdminTestSuite) TestConcurrentAdminRevokes() {
+	w := suite.request(http.MethodPost, "/api/admin/roles/assign", models.RoleAssignmentRequest{UserID: suite.user.ID, RoleID: suite.adminRole.ID})
+	suite.Require().Equal(http.StatusCreated, w.Code)
+
+	var wg sync.WaitGroup
+	results := make(chan error, 2)
+	for _, pair := range [][2]uint{{suite.admin.ID, suite.user.ID}, {suite.user.ID, suite.admin.ID}} {
+		wg.Add(1)
+		go func(userID, revokedBy uint) {
+			defer wg.Done()
+			results <- suite.roleService.RevokeRoleWithReason(models.RoleRemovalRequest{UserID: userID, RoleID: suite.adminRole.ID}, revokedBy)
+		}(pair[0], pair[1])
+	}
+	wg.Wait()
+	close(results)
+
+	succeeded := 0
+	for err := range results {
+		if err == nil {
+			succeeded++
+			continue
+		}
+		suite.ErrorIs(err, services.ErrLastAdmin)
+	}
+	suite.Equal(1, succeeded)
+	suite.NotEqual(suite.roleRepo.HasUserRole(suite.admin.ID, "admin"), suite.roleRepo.HasUserRole(suite.user.ID, "admin"))
+}
+
+// TestRoleAdminTestSuite запускает набор тестов
+func TestRoleAdminTestSuite(t *testing.T) {
+	suite.Run(t, new(RoleAdminTestSuite))
+}
@@ -163,7 +163,7 @@ func TestRoleRepository_RemoveRoleFromUser(t *testing.T) {
 	require.NoError(t, err)
 
 	// Act
-	err = roleRepo.RemoveRoleFromUser(user.ID, role.ID)
+	err = roleRepo.RemoveRoleFromUser(user.ID, role.ID, false)
 
 	// Assert
 	require.NoError(t, err)
@@ -0,0 +1,214 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"garage-barbershop/internal/database"
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/repositories"
+	"garage-barbershop/internal/services"
+
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// ScheduleExceptionTestSuite набор тестов для исключений из расписания
+type ScheduleExceptionTestSuite struct {
+	suite.Suite
+	db                 *database.Database
+	userRepo           repositories.UserRepository
+	roleRepo           repositories.RoleRepository
+	appointmentRepo    repositories.AppointmentRepository
+	exceptionService   services.ScheduleExceptionService
+	appointmentService services.AppointmentService
+	barber             *models.User
+	client             *models.User
+	service            *models.Service
+	day                time.Time
+}
+
+// SetupSuite инициализирует тестовую среду
+func (suite *ScheduleExceptionTestSuite) SetupSuite() {
+	db, err := gorm.Open(sqlite.Open("file:schedule_exceptions?mode=memory&cache=shared"), &gorm.Config{})
+	suite.Require().NoError(err)
+
+	suite.db = &database.Database{DB: db}
+	err = suite.db.Migrate(&models.User{}, &models.Role{}, &models.UserRole{}, &models.Service{}, &models.Appointment{}, &models.WorkingHours{}, &models.ScheduleException{})
+	suite.Require().NoError(err)
+
+	suite.userRepo = repositories.NewUserRepository(db)
+	suite.roleRepo = repositories.NewRoleRepository(db)
+	suite.appointmentRepo = repositories.NewAppointmentRepository(db)
+	serviceRepo := repositories.NewServiceRepository(db)
+	exceptionRepo := repositories.NewScheduleExceptionRepository(db)
+	timezoneService := services.NewTimezoneService(suite.userRepo, time.Local)
+	suite.exceptionService = services.NewScheduleExceptionService(exceptionRepo, suite.roleRepo, timezoneService)
+	suite.appointmentService = services.NewAppointmentService(suite.appointmentRepo, serviceRepo, suite.userRepo, repositories.NewWorkingHoursRepository(db), exceptionRepo, timezoneService)
+}
+
+// TearDownSuite очищает тестовую среду
+func (suite *ScheduleExceptionTestSuite) TearDownSuite() {
+	sqlDB, err := suite.db.DB.DB()
+	suite.Require().NoError(err)
+	sqlDB.Close()
+}
+
+// SetupTest создает барбера, клиента и услугу
+func (suite *ScheduleExceptionTestSuite) SetupTest() {
+	suite.db.DB.Exec("DELETE FROM schedule_exceptions")
+	suite.db.DB.Exec("DELETE FROM appointments")
+	suite.db.DB.Exec("DELETE FROM working_hours")
+	suite.db.DB.Exec("DELETE FROM services")
+	suite.db.DB.Exec("DELETE FROM user_roles")
+	suite.db.DB.Exec("DELETE FROM users")
+
+	suite.barber = &models.User{TelegramID: 1, Email: "barber@example.com", FirstName: "Barber", IsActive: true}
+	suite.Require().NoError(suite.userRepo.Create(suite.barber))
+	role, err := suite.roleRepo.GetRoleByName("barber")
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.roleRepo.AssignRoleToUser(suite.barber.ID, role.ID, suite.barber.ID))
+
+	suite.client = &models.User{TelegramID: 2, Email: "client@example.com", FirstName: "Client", IsActive: true}
+	suite.Require().NoError(suite.userRepo.Create(suite.client))
+
+	suite.service = &models.Service{Name: "Стрижка", Price: 1000, Duration: 60, IsActive: true, BarberID: suite.barber.ID}
+	suite.Require().NoError(suite.db.DB.Create(suite.service).Error)
+
+	for day := 1; day <= 7; day++ {
+		suite.Require().NoError(suite.db.DB.Create(&models.WorkingHours{
+			DayOfWeek: day, StartTime: "09:00", EndTime: "21:00", IsActive: true, BarberID: suite.barber.ID,
+		}).Error)
+	}
+
+	tomorrow := time.Now().AddDate(0, 0, 1)
+	suite.day = time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// book создает запись клиента на указанный час дня
+func (suite *ScheduleExceptionTestSuite) book(hour int) *models.Appointment {
+	appointment, err := suite.appointmentService.BookAppointment(suite.client.ID, models.AppointmentCreateRequest{
+		ServiceID: suite.service.ID,
+		DateTime:  suite.day.Add(time.Duration(hour) * time.Hour),
+	})
+	suite.Require().NoError(err)
+	return appointment
+}
+
+// TestCreateException_ReportsAffectedAppointments тестирует, что исключение не создается молча поверх записей
+func (suite *ScheduleExceptionTestSuite) TestCreateException_ReportsAffectedAppointments() {
+	affected := suite.book(12)
+	suite.book(16)
+
+	req := models.ScheduleExceptionRequest{
+		StartDate: suite.day.Format(models.DateLayout),
+		StartTime: "11:30",
+		EndTime:   "14:00",
+		Reason:    "Врач",
+	}
+
+	result, err := suite.exceptionService.CreateException(suite.barber.ID, req)
+	suite.ErrorIs(err, services.ErrScheduleConflict)
+	suite.Require().Len(result.AffectedAppointments, 1)
+	suite.Equal(affected.ID, result.AffectedAppointments[0].ID)
+	suite.Nil(result.Exception)
+
+	exceptions, err := suite.exceptionService.GetExceptions(suite.barber.ID, "", "")
+	suite.Require().NoError(err)
+	suite.Empty(exceptions)
+
+	// С force исключение создается, затронутые записи по-прежнему возвращаются
+	req.Force = true
+	result, err = suite.exceptionService.CreateException(suite.barber.ID, req)
+	suite.Require().NoError(err)
+	suite.NotNil(result.Exception)
+	suite.Len(result.AffectedAppointments, 1)
+}
+
+// TestBooking_RejectedDuringVacation тестирует, что на время отпуска нельзя записаться
+func (suite *ScheduleExceptionTestSuite) TestBooking_RejectedDuringVacation() {
+	_, err := suite.exceptionService.CreateException(suite.barber.ID, models.ScheduleExceptionRequest{
+		StartDate: suite.day.Format(models.DateLayout),
+		EndDate:   suite.day.AddDate(0, 0, 13).Format(models.DateLayout),
+		IsFullDay: true,
+		Reason:    "Отпуск",
+	})
+	suite.Require().NoError(err)
+
+	_, err = suite.appointmentService.BookAppointment(suite.client.ID, models.AppointmentCreateRequest{
+		ServiceID: suite.service.ID,
+		DateTime:  suite.day.AddDate(0, 0, 5).Add(12 * time.Hour),
+	})
+	suite.ErrorIs(err, services.ErrSlotUnavailable)
+
+	// После отпуска запись доступна
+	_, err = suite.appointmentService.BookAppointment(suite.client.ID, models.AppointmentCreateRequest{
+		ServiceID: suite.service.ID,
+		DateTime:  suite.day.AddDate(0, 0, 14).Add(12 * time.Hour),
+	})
+	suite.NoError(err)
+}
+
+// TestCreateException_Validation тестирует проверки исключения
+func (suite *ScheduleExceptionTestSuite) TestCreateException_Validation() {
+	date := suite.day.Format(models.DateLayout)
+	cases := map[string]models.ScheduleExceptionRequest{
+		"неизвестный тип":        {Type: "holiday", StartDate: date, IsFullDay: true},
+		"неверная дата":          {StartDate: "15.01.2024", IsFullDay: true},
+		"конец раньше начала":    {StartDate: date, EndDate: suite.day.AddDate(0, 0, -1).Format(models.DateLayout), IsFullDay: true},
+		"в прошлом":              {StartDate: "2020-01-01", IsFullDay: true},
+		"частичный без времени":  {StartDate: date},
+		"рабочий день без часов": {Type: models.ScheduleExceptionExtraWorkingDay, StartDate: date, IsFullDay: true},
+	}
+
+	for name, req := range cases {
+		_, err := suite.exceptionService.CreateException(suite.barber.ID, req)
+		suite.Error(err, name)
+	}
+}
+
+// TestCreateException_RejectsOverlap тестирует, что исключения барбера не пересекаются
+func (suite *ScheduleExceptionTestSuite) TestCreateException_RejectsOverlap() {
+	date := suite.day.Format(models.DateLayout)
+	nextDate := suite.day.AddDate(0, 0, 1).Format(models.DateLayout)
+
+	_, err := suite.exceptionService.CreateException(suite.barber.ID, models.ScheduleExceptionRequest{StartDate: date, EndDate: nextDate, IsFullDay: true})
+	suite.Require().NoError(err)
+
+	overlapping := map[string]models.ScheduleExceptionRequest{
+		"тот же отпуск":          {StartDate: date, IsFullDay: true},
+		"частичный в отпуске":    {StartDate: nextDate, StartTime: "12:00", EndTime: "13:00"},
+		"рабочий день в отпуске": {Type: models.ScheduleExceptionExtraWorkingDay, StartDate: date, StartTime: "10:00", EndTime: "18:00"},
+	}
+	for name, req := range overlapping {
+		_, err := suite.exceptionService.CreateException(suite.barber.ID, req)
+		suite.ErrorIs(err, services.ErrScheduleExceptionOverlap, name)
+	}
+
+	// Частичные исключения одного дня не пересекаются, если не пересекается время
+	laterDate := suite.day.AddDate(0, 0, 2).Format(models.DateLayout)
+	_, err = suite.exceptionService.CreateException(suite.barber.ID, models.ScheduleExceptionRequest{StartDate: laterDate, StartTime: "10:00", EndTime: "12:00"})
+	suite.Require().NoError(err)
+	_, err = suite.exceptionService.CreateException(suite.barber.ID, models.ScheduleExceptionRequest{StartDate: laterDate, StartTime: "12:00", EndTime: "14:00"})
+	suite.NoError(err)
+	_, err = suite.exceptionService.CreateException(suite.barber.ID, models.ScheduleExceptionRequest{StartDate: laterDate, StartTime: "13:00", EndTime: "15:00"})
+	suite.ErrorIs(err, services.ErrScheduleExceptionOverlap)
+}
+
+// TestDeleteException_OnlyOwn тестирует, что барбер удаляет только свои исключения
+func (suite *ScheduleExceptionTestSuite) TestDeleteException_OnlyOwn() {
+	result, err := suite.exceptionService.CreateException(suite.barber.ID, models.ScheduleExceptionRequest{
+		StartDate: suite.day.Format(models.DateLayout),
+		IsFullDay: true,
+	})
+	suite.Require().NoError(err)
+
+	suite.ErrorIs(suite.exceptionService.DeleteException(suite.client.ID, result.Exception.ID), services.ErrScheduleExceptionNotFound)
+	suite.NoError(suite.exceptionService.DeleteException(suite.barber.ID, result.Exception.ID))
+}
+
+// TestScheduleExceptionTestSuite запускает набор тестов
+func TestScheduleExceptionTestSuite(t *testing.T) {
+	suite.Run(t, new(ScheduleExceptionTestSuite))
+}
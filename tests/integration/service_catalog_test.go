@@ -0,0 +1,197 @@
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"garage-barbershop/internal/database"
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/repositories"
+	"garage-barbershop/internal/services"
+
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// ServiceCatalogTestSuite набор тестов для каталога услуг
+type ServiceCatalogTestSuite struct {
+	suite.Suite
+	db             *database.Database
+	userRepo       repositories.UserRepository
+	roleRepo       repositories.RoleRepository
+	catalogService services.ServiceCatalogService
+}
+
+// SetupSuite инициализирует тестовую среду
+func (suite *ServiceCatalogTestSuite) SetupSuite() {
+	db, err := gorm.Open(sqlite.Open("file:catalog?mode=memory&cache=shared"), &gorm.Config{})
+	suite.Require().NoError(err)
+
+	suite.db = &database.Database{DB: db}
+	err = suite.db.Migrate(&models.User{}, &models.Role{}, &models.UserRole{}, &models.Service{})
+	suite.Require().NoError(err)
+
+	suite.userRepo = repositories.NewUserRepository(db)
+	suite.roleRepo = repositories.NewRoleRepository(db)
+	serviceRepo := repositories.NewServiceRepository(db)
+	suite.catalogService = services.NewServiceCatalogService(serviceRepo, suite.roleRepo)
+}
+
+// TearDownSuite очищает тестовую среду
+func (suite *ServiceCatalogTestSuite) TearDownSuite() {
+	sqlDB, err := suite.db.DB.DB()
+	suite.Require().NoError(err)
+	sqlDB.Close()
+}
+
+// SetupTest очищает данные перед каждым тестом
+func (suite *ServiceCatalogTestSuite) SetupTest() {
+	suite.db.DB.Exec("DELETE FROM services")
+	suite.db.DB.Exec("DELETE FROM user_roles")
+	suite.db.DB.Exec("DELETE FROM users")
+}
+
+// createBarber создает пользователя с ролью барбера
+func (suite *ServiceCatalogTestSuite) createBarber(telegramID int64, email string) *models.User {
+	barber := &models.User{TelegramID: telegramID, Email: email, FirstName: "Barber", IsActive: true}
+	suite.Require().NoError(suite.userRepo.Create(barber))
+
+	role, err := suite.roleRepo.GetRoleByName("barber")
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.roleRepo.AssignRoleToUser(barber.ID, role.ID, barber.ID))
+
+	return barber
+}
+
+// TestCreateService_RequiresBarberRole тестирует, что клиент не может создать услугу
+func (suite *ServiceCatalogTestSuite) TestCreateService_RequiresBarberRole() {
+	client := &models.User{TelegramID: 100, Email: "client@example.com", IsActive: true}
+	suite.Require().NoError(suite.userRepo.Create(client))
+
+	_, err := suite.catalogService.CreateService(client.ID, models.ServiceCreateRequest{
+		Name:     "Стрижка",
+		Price:    1000,
+		Duration: 30,
+	})
+
+	suite.Error(err)
+	suite.Contains(err.Error(), "не является барбером")
+}
+
+// TestUpdateService_OtherBarber тестирует, что барбер не может менять чужие услуги
+func (suite *ServiceCatalogTestSuite) TestUpdateService_OtherBarber() {
+	owner := suite.createBarber(101, "owner@example.com")
+	other := suite.createBarber(102, "other@example.com")
+
+	service, err := suite.catalogService.CreateService(owner.ID, models.ServiceCreateRequest{
+		Name:     "Стрижка",
+		Price:    1000,
+		Duration: 30,
+	})
+	suite.Require().NoError(err)
+
+	price := 1.0
+	_, err = suite.catalogService.UpdateService(other.ID, service.ID, models.ServiceUpdateRequest{Price: &price})
+	suite.ErrorIs(err, services.ErrServiceNotFound)
+
+	err = suite.catalogService.DeleteService(other.ID, service.ID)
+	suite.ErrorIs(err, services.ErrServiceNotFound)
+}
+
+// TestService_DurationLimit тестирует, что услуга не может быть длиннее записи, которую проверяет поиск пересечений
+func (suite *ServiceCatalogTestSuite) TestService_DurationLimit() {
+	barber := suite.createBarber(110, "long@example.com")
+	maxMinutes := int(models.MaxAppointmentDuration / time.Minute)
+
+	_, err := suite.catalogService.CreateService(barber.ID, models.ServiceCreateRequest{
+		Name:     "Марафон",
+		Price:    1000,
+		Duration: maxMinutes + 1,
+	})
+	suite.Error(err)
+
+	service, err := suite.catalogService.CreateService(barber.ID, models.ServiceCreateRequest{
+		Name:     "Стрижка",
+		Price:    1000,
+		Duration: maxMinutes,
+	})
+	suite.Require().NoError(err)
+
+	duration := maxMinutes + 1
+	_, err = suite.catalogService.UpdateService(barber.ID, service.ID, models.ServiceUpdateRequest{Duration: &duration})
+	suite.Error(err)
+}
+
+// TestListServices_Filters тестирует фильтрацию публичного каталога
+func (suite *ServiceCatalogTestSuite) TestListServices_Filters() {
+	first := suite.createBarber(103, "first@example.com")
+	second := suite.createBarber(104, "second@example.com")
+
+	inactive := false
+	requests := []struct {
+		barberID uint
+		req      models.ServiceCreateRequest
+	}{
+		{first.ID, models.ServiceCreateRequest{Name: "Стрижка", Price: 1000, Duration: 30}},
+		{first.ID, models.ServiceCreateRequest{Name: "Борода", Price: 500, Duration: 15}},
+		{first.ID, models.ServiceCreateRequest{Name: "Архив", Price: 700, Duration: 20, IsActive: &inactive}},
+		{second.ID, models.ServiceCreateRequest{Name: "Комплекс", Price: 2000, Duration: 60}},
+	}
+	for _, item := range requests {
+		_, err := suite.catalogService.CreateService(item.barberID, item.req)
+		suite.Require().NoError(err)
+	}
+
+	// Неактивные услуги не попадают в публичный каталог
+	all, err := suite.catalogService.ListServices(models.ServiceFilter{IncludeInactive: true})
+	suite.NoError(err)
+	suite.Len(all, 3)
+
+	byBarber, err := suite.catalogService.ListServices(models.ServiceFilter{BarberID: first.ID})
+	suite.NoError(err)
+	suite.Len(byBarber, 2)
+
+	minPrice, maxPrice := 600.0, 1500.0
+	byPrice, err := suite.catalogService.ListServices(models.ServiceFilter{MinPrice: &minPrice, MaxPrice: &maxPrice})
+	suite.NoError(err)
+	suite.Require().Len(byPrice, 1)
+	suite.Equal("Стрижка", byPrice[0].Name)
+
+	maxDuration := 30
+	byDuration, err := suite.catalogService.ListServices(models.ServiceFilter{MaxDuration: &maxDuration})
+	suite.NoError(err)
+	suite.Len(byDuration, 2)
+
+	// Барбер видит все свои услуги, включая неактивные
+	own, err := suite.catalogService.GetBarberServices(first.ID)
+	suite.NoError(err)
+	suite.Len(own, 3)
+}
+
+// TestDeletedService_StaysResolvable тестирует, что удаленная услуга доступна для истории записей
+func (suite *ServiceCatalogTestSuite) TestDeletedService_StaysResolvable() {
+	barber := suite.createBarber(105, "barber@example.com")
+
+	service, err := suite.catalogService.CreateService(barber.ID, models.ServiceCreateRequest{
+		Name:     "Стрижка",
+		Price:    1000,
+		Duration: 30,
+	})
+	suite.Require().NoError(err)
+
+	suite.Require().NoError(suite.catalogService.DeleteService(barber.ID, service.ID))
+
+	_, err = suite.catalogService.GetService(service.ID)
+	suite.ErrorIs(err, services.ErrServiceNotFound)
+
+	resolved, err := suite.catalogService.ResolveService(service.ID)
+	suite.NoError(err)
+	suite.Equal("Стрижка", resolved.Name)
+	suite.True(resolved.DeletedAt.Valid)
+}
+
+// TestServiceCatalogTestSuite запускает все тесты
+func TestServiceCatalogTestSuite(t *testing.T) {
+	suite.Run(t, new(ServiceCatalogTestSuite))
+}
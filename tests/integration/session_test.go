@@ -0,0 +1,268 @@
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"garage-barbershop/internal/database"
+	"garage-barbershop/internal/handlers"
+	"garage-barbershop/internal/middleware"
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// SessionTestSuite набор тестов сессий на нескольких устройствах
+type SessionTestSuite struct {
+	apiTestSuite
+	db          *database.Database
+	authService services.AuthService
+	ginRouter   *gin.Engine
+}
+
+// SetupSuite инициализирует тестовую среду и маршруты
+func (suite *SessionTestSuite) SetupSuite() {
+	db, err := gorm.Open(sqlite.Open("file:sessions?mode=memory&cache=shared"), &gorm.Config{})
+	suite.Require().NoError(err)
+
+	suite.db = &database.Database{DB: db}
+	err = suite.db.Migrate(&models.User{}, &models.Role{}, &models.UserRole{}, &models.AuditEvent{}, &models.Session{})
+	suite.Require().NoError(err)
+
+	authServices := newAuthTestServices(db, authTestConfig{})
+	suite.authService = authServices.auth
+	authHandler := authServices.handler
+	sessionHandler := handlers.NewSessionHandler(suite.authService)
+	auth := middleware.HTTPAuthMiddleware(suite.authService)
+
+	suite.mux = http.NewServeMux()
+	suite.mux.HandleFunc("/api/auth/login", authHandler.LoginDirect)
+	suite.mux.HandleFunc("/api/auth/refresh", authHandler.RefreshToken)
+	suite.mux.HandleFunc("/api/auth/sessions", auth(sessionHandler.ListSessions))
+	suite.mux.HandleFunc("/api/auth/sessions/{id}", auth(sessionHandler.RevokeSession))
+	suite.mux.HandleFunc("/api/auth/sessions/revoke-others", auth(sessionHandler.RevokeOtherSessions))
+
+	gin.SetMode(gin.TestMode)
+	suite.ginRouter = gin.New()
+	ginAuthHandler := handlers.NewAuthHandler(suite.authService, authServices.twoFactor)
+	suite.ginRouter.POST("/api/auth/logout", middleware.JWTMiddleware(suite.authService), ginAuthHandler.Logout)
+}
+
+// TearDownSuite очищает тестовую среду
+func (suite *SessionTestSuite) TearDownSuite() {
+	sqlDB, err := suite.db.DB.DB()
+	suite.Require().NoError(err)
+	sqlDB.Close()
+}
+
+// SetupTest создает пользователя с паролем
+func (suite *SessionTestSuite) SetupTest() {
+	suite.db.DB.Exec("DELETE FROM sessions")
+	suite.db.DB.Exec("DELETE FROM user_roles")
+	suite.db.DB.Exec("DELETE FROM users")
+
+	_, err := suite.authService.RegisterUserDirect(models.DirectRegisterRequest{
+		Email:     "sessions@example.com",
+		Password:  "fresh-fade-42",
+		FirstName: "Мария",
+		LastName:  "Иванова",
+		Role:      "client",
+	})
+	suite.Require().NoError(err)
+}
+
+// deviceRequest выполняет запрос с устройства; accessToken может быть пустым
+func (suite *SessionTestSuite) deviceRequest(method, path, device, accessToken string, body interface{}) *httptest.ResponseRecorder {
+	req := suite.newRequest(method, path, accessToken, body)
+	req.Header.Set("X-Device-Name", device)
+	req.Header.Set("User-Agent", device+"-agent")
+	return suite.serve(req)
+}
+
+// deviceLogin входит с устройства и возвращает токены
+func (suite *SessionTestSuite) deviceLogin(device string) models.AuthResponse {
+	var response models.AuthResponse
+	suite.decode(suite.deviceRequest(http.MethodPost, "/api/auth/login", device, "", models.DirectLoginRequest{Email: "sessions@example.com", Password: testPassword}), &response)
+	return response
+}
+
+// refresh обновляет токены и возвращает код ответа и новые токены
+func (suite *SessionTestSuite) refresh(refreshToken string) (int, models.AuthResponse) {
+	w := suite.deviceRequest(http.MethodPost, "/api/auth/refresh", "any", "", models.RefreshTokenRequest{RefreshToken: refreshToken})
+	var response models.AuthResponse
+	if w.Code == http.StatusOK {
+		suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	}
+	return w.Code, response
+}
+
+// sessions возвращает список сессий от имени access token
+func (suite *SessionTestSuite) sessions(accessToken string) []models.Session {
+	w := suite.deviceRequest(http.MethodGet, "/api/auth/sessions", "any", accessToken, nil)
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+
+	var response struct {
+		Sessions []models.Session `json:"sessions"`
+	}
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	return response.Sessions
+}
+
+// TestLoginOnSecondDeviceKeepsFirstSession тестирует независимость сессий устройств и ротацию токенов
+func (suite *SessionTestSuite) TestLoginOnSecondDeviceKeepsFirstSession() {
+	// Arrange
+	laptop := suite.deviceLogin("laptop")
+	phone := suite.deviceLogin("phone")
+
+	// Act: ноутбук обновляет токены после входа с телефона
+	code, rotated := suite.refresh(laptop.RefreshToken)
+
+	// Assert
+	suite.Require().Equal(http.StatusOK, code)
+	suite.NotEqual(laptop.RefreshToken, rotated.RefreshToken)
+
+	// Новый refresh token ноутбука действует
+	code, _ = suite.refresh(rotated.RefreshToken)
+	suite.Equal(http.StatusOK, code)
+
+	// Сессия телефона не затронута
+	code, _ = suite.refresh(phone.RefreshToken)
+	suite.Equal(http.StatusOK, code)
+
+	sessions := suite.sessions(phone.AccessToken)
+	suite.Require().Len(sessions, 2)
+	devices := map[string]bool{}
+	for _, session := range sessions {
+		devices[session.DeviceName] = session.Current
+	}
+	suite.Equal(map[string]bool{"laptop": false, "phone": true}, devices)
+}
+
+// TestRevokeSingleAndOtherSessions тестирует завершение одной и всех остальных сессий
+func (suite *SessionTestSuite) TestRevokeSingleAndOtherSessions() {
+	// Arrange
+	laptop := suite.deviceLogin("laptop")
+	phone := suite.deviceLogin("phone")
+	tablet := suite.deviceLogin("tablet")
+
+	var phoneSessionID uint
+	for _, session := range suite.sessions(laptop.AccessToken) {
+		if session.DeviceName == "phone" {
+			phoneSessionID = session.ID
+		}
+	}
+	suite.Require().NotZero(phoneSessionID)
+
+	// Act: завершаем сессию телефона с ноутбука
+	w := suite.deviceRequest(http.MethodDelete, "/api/auth/sessions/"+strconv.FormatUint(uint64(phoneSessionID), 10), "laptop", laptop.AccessToken, nil)
+
+	// Assert
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	code, _ := suite.refresh(phone.RefreshToken)
+	suite.Equal(http.StatusUnauthorized, code)
+
+	w = suite.deviceRequest(http.MethodDelete, "/api/auth/sessions/"+strconv.FormatUint(uint64(phoneSessionID), 10), "laptop", laptop.AccessToken, nil)
+	suite.Equal(http.StatusNotFound, w.Code)
+
+	// Завершаем все остальные сессии: остается только ноутбук
+	w = suite.deviceRequest(http.MethodPost, "/api/auth/sessions/revoke-others", "laptop", laptop.AccessToken, nil)
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	suite.Contains(w.Body.String(), `"revoked":1`)
+
+	code, _ = suite.refresh(tablet.RefreshToken)
+	suite.Equal(http.StatusUnauthorized, code)
+	code, _ = suite.refresh(laptop.RefreshToken)
+	suite.Equal(http.StatusOK, code)
+	suite.Len(suite.sessions(laptop.AccessToken), 1)
+}
+
+// TestRotatedTokenReuseRevokesFamily тестирует отзыв семейства токенов при повторном использовании
+func (suite *SessionTestSuite) TestRotatedTokenReuseRevokesFamily() {
+	// Arrange: злоумышленник получил refresh token ноутбука, пользователь успел его обновить
+	laptop := suite.deviceLogin("laptop")
+	phone := suite.deviceLogin("phone")
+	code, rotated := suite.refresh(laptop.RefreshToken)
+	suite.Require().Equal(http.StatusOK, code)
+
+	// Act: предъявляется уже замененный токен
+	code, _ = suite.refresh(laptop.RefreshToken)
+
+	// Assert: отклонен, и вся сессия ноутбука отозвана, включая новый токен
+	suite.Equal(http.StatusUnauthorized, code)
+	code, _ = suite.refresh(rotated.RefreshToken)
+	suite.Equal(http.StatusUnauthorized, code)
+
+	var session models.Session
+	suite.Require().NoError(suite.db.DB.Where("device_name = ?", "laptop").First(&session).Error)
+	suite.NotNil(session.RevokedAt)
+	suite.Equal(models.SessionRevokedTokenReused, session.RevokedReason)
+
+	// Сессии других устройств не затронуты
+	code, _ = suite.refresh(phone.RefreshToken)
+	suite.Equal(http.StatusOK, code)
+
+	// Событие безопасности записано в журнал аудита
+	var events []models.AuditEvent
+	suite.Require().NoError(suite.db.DB.Where("action = ?", models.AuditActionTokenReuse).Find(&events).Error)
+	suite.Require().Len(events, 1)
+	suite.False(events[0].Success)
+	suite.Require().NotNil(events[0].TargetID)
+	suite.Equal(rotated.User.ID, *events[0].TargetID)
+}
+
+// TestSessionAbsoluteLifetime тестирует абсолютный срок жизни сессии
+func (suite *SessionTestSuite) TestSessionAbsoluteLifetime() {
+	// Arrange: сессия живет час, refresh token не может пережить сессию
+	shortLived := newAuthTestServices(suite.db.DB, authTestConfig{sessionLifetime: time.Hour}).auth
+	user, err := shortLived.LoginDirect(models.DirectLoginRequest{Email: "sessions@example.com", Password: "fresh-fade-42"})
+	suite.Require().NoError(err)
+
+	response, err := shortLived.CreateSession(user, models.DeviceInfo{Name: "kiosk"})
+	suite.Require().NoError(err)
+
+	claims, err := shortLived.ParseJWT(response.RefreshToken)
+	suite.Require().NoError(err)
+	suite.LessOrEqual(claims.Exp, time.Now().Add(time.Hour).Unix())
+
+	// Act: срок жизни сессии истек, хотя refresh token еще не просрочен
+	suite.Require().NoError(suite.db.DB.Model(&models.Session{}).
+		Where("id = ?", claims.SessionID).
+		Update("expires_at", time.Now().Add(-time.Minute)).Error)
+	_, err = shortLived.RefreshSession(response.RefreshToken, models.DeviceInfo{})
+
+	// Assert
+	suite.ErrorIs(err, services.ErrInvalidRefreshToken)
+}
+
+// TestGinLogoutKeepsOtherSessions тестирует, что выход через gin обработчик завершает только текущую сессию
+func (suite *SessionTestSuite) TestGinLogoutKeepsOtherSessions() {
+	// Arrange
+	laptop := suite.deviceLogin("laptop")
+	phone := suite.deviceLogin("phone")
+
+	// Act
+	req := suite.newRequest(http.MethodPost, "/api/auth/logout", laptop.AccessToken, nil)
+	w := httptest.NewRecorder()
+	suite.ginRouter.ServeHTTP(w, req)
+
+	// Assert
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	code, _ := suite.refresh(laptop.RefreshToken)
+	suite.Equal(http.StatusUnauthorized, code)
+	code, _ = suite.refresh(phone.RefreshToken)
+	suite.Equal(http.StatusOK, code)
+	suite.Len(suite.sessions(phone.AccessToken), 1)
+}
+
+// TestSessionTestSuite запускает набор тестов
+func TestSessionTestSuite(t *testing.T) {
+	suite.Run(t, new(SessionTestSuite))
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
 	"time"
 
@@ -13,6 +14,7 @@ import (
 	"garage-barbershop/internal/handlers"
 	"garage-barbershop/internal/models"
 	"garage-barbershop/internal/repositories"
+	"garage-barbershop/internal/services"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
@@ -27,6 +29,7 @@ type TelegramAuthTestSuite struct {
 	suite.Suite
 	db          *database.Database
 	authService *TestAuthService
+	roleRepo    repositories.RoleRepository
 	authHandler *handlers.AuthHandler
 	router      *gin.Engine
 }
@@ -51,7 +54,10 @@ func (suite *TelegramAuthTestSuite) SetupSuite() {
 	roleRepo := repositories.NewRoleRepository(suite.db.DB)
 	testAuthService := NewTestAuthService(userRepo, roleRepo, nil, "test_secret", "test_bot_token")
 	suite.authService = testAuthService
-	suite.authHandler = handlers.NewAuthHandler(testAuthService)
+	suite.roleRepo = roleRepo
+	// 2FA обязательна для администраторов
+	twoFactor := services.NewTwoFactorService(userRepo, repositories.NewTwoFactorRepository(suite.db.DB), roleRepo, "test_secret", true)
+	suite.authHandler = handlers.NewAuthHandler(testAuthService, twoFactor)
 
 	// Настраиваем Gin роутер
 	gin.SetMode(gin.TestMode)
@@ -70,7 +76,8 @@ func (suite *TelegramAuthTestSuite) TearDownSuite() {
 
 // SetupTest очищает данные перед каждым тестом
 func (suite *TelegramAuthTestSuite) SetupTest() {
-	// Очищаем таблицу пользователей
+	// Очищаем таблицы пользователей
+	suite.db.DB.Exec("DELETE FROM user_roles")
 	suite.db.DB.Exec("DELETE FROM users")
 }
 
@@ -203,6 +210,56 @@ func (suite *TelegramAuthTestSuite) TestTelegramAuth_ExistingUser() {
 	suite.Equal("Name", user.LastName)
 }
 
+// telegramAuth отправляет данные Telegram Login Widget пользователя и возвращает ответ
+func (suite *TelegramAuthTestSuite) telegramAuth(telegramID int64) *httptest.ResponseRecorder {
+	jsonData, err := json.Marshal(models.TelegramAuthData{
+		ID:        telegramID,
+		FirstName: "John",
+		AuthDate:  time.Now().Unix(),
+		Hash:      "test_hash",
+	})
+	suite.Require().NoError(err)
+
+	req := httptest.NewRequest("POST", "/api/auth/telegram", bytes.NewBuffer(jsonData))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	return w
+}
+
+// TestTelegramAuth_TwoFactor тестирует, что при включенной или обязательной 2FA токены не выдаются
+func (suite *TelegramAuthTestSuite) TestTelegramAuth_TwoFactor() {
+	// Arrange
+	enabledAt := time.Now()
+	withTOTP := &models.User{TelegramID: 111, FirstName: "John", IsActive: true, TOTPEnabledAt: &enabledAt}
+	suite.Require().NoError(suite.db.DB.Create(withTOTP).Error)
+
+	admin := &models.User{TelegramID: 222, FirstName: "John", IsActive: true}
+	suite.Require().NoError(suite.db.DB.Create(admin).Error)
+	role, err := suite.roleRepo.GetRoleByName("admin")
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.roleRepo.AssignRoleToUser(admin.ID, role.ID, admin.ID))
+
+	// Act & Assert - у пользователя включена 2FA: нужен код из приложения
+	w := suite.telegramAuth(withTOTP.TelegramID)
+	suite.Require().Equal(http.StatusOK, w.Code)
+	var challenge models.MFAChallengeResponse
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &challenge))
+	suite.True(challenge.MFARequired)
+	suite.False(challenge.EnrollmentRequired)
+	suite.NotEmpty(challenge.ChallengeToken)
+	suite.NotContains(w.Body.String(), "access_token")
+
+	// Администратору без 2FA нужно сначала ее подключить
+	w = suite.telegramAuth(admin.TelegramID)
+	suite.Require().Equal(http.StatusOK, w.Code)
+	challenge = models.MFAChallengeResponse{}
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &challenge))
+	suite.True(challenge.MFARequired)
+	suite.True(challenge.EnrollmentRequired)
+	suite.NotContains(w.Body.String(), "access_token")
+}
+
 // TestTelegramAuthTestSuite запускает все тесты
 func TestTelegramAuthTestSuite(t *testing.T) {
 	suite.Run(t, new(TelegramAuthTestSuite))
@@ -229,9 +286,18 @@ func NewTestAuthService(userRepo repositories.UserRepository, roleRepo repositor
 }
 
 // ValidateTelegramAuth упрощенная валидация для тестов
-func (s *TestAuthService) ValidateTelegramAuth(authData models.TelegramAuthData, botToken string) bool {
+func (s *TestAuthService) ValidateTelegramAuth(data models.TelegramLoginData) (*models.TelegramAuthData, error) {
 	// Для тестов проверяем, что ID не равен 0 и есть имя
-	return authData.ID != 0 && authData.FirstName != ""
+	id, _ := strconv.ParseInt(data["id"], 10, 64)
+	if id == 0 || data["first_name"] == "" {
+		return nil, fmt.Errorf("невалидные данные")
+	}
+	return &models.TelegramAuthData{
+		ID:        id,
+		Username:  data["username"],
+		FirstName: data["first_name"],
+		LastName:  data["last_name"],
+	}, nil
 }
 
 // AuthenticateUser находит или создает пользователя
@@ -265,6 +331,11 @@ func (s *TestAuthService) AuthenticateUser(authData models.TelegramAuthData) (*m
 	return newUser, nil
 }
 
+// ValidateWebAppInitData проверяет initData Telegram WebApp (для тестов не реализовано)
+func (s *TestAuthService) ValidateWebAppInitData(initData string) (*models.TelegramWebAppInitData, error) {
+	return nil, fmt.Errorf("не реализовано в тестах")
+}
+
 // GenerateAccessToken генерирует access token
 func (s *TestAuthService) GenerateAccessToken(user *models.User) (string, error) {
 	claims := models.TokenClaims{
@@ -342,6 +413,59 @@ func (s *TestAuthService) RevokeRefreshToken(userID uint) error {
 	return nil
 }
 
+// CreateSession выдает токены без сохранения сессии
+func (s *TestAuthService) CreateSession(user *models.User, device models.DeviceInfo) (*models.AuthResponse, error) {
+	accessToken, err := s.GenerateAccessToken(user)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := s.GenerateRefreshToken(user)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AuthResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    15 * 60,
+		User:         *user,
+	}, nil
+}
+
+// RefreshSession обновляет токены сессии (для тестов не реализовано)
+func (s *TestAuthService) RefreshSession(refreshToken string, device models.DeviceInfo) (*models.AuthResponse, error) {
+	return nil, nil
+}
+
+// ListSessions возвращает сессии пользователя (для тестов не реализовано)
+func (s *TestAuthService) ListSessions(userID, currentSessionID uint) ([]models.Session, error) {
+	return nil, nil
+}
+
+// RevokeSession отзывает сессию (для тестов не реализовано)
+func (s *TestAuthService) RevokeSession(userID, sessionID uint) error {
+	return nil
+}
+
+// RevokeOtherSessions отзывает остальные сессии (для тестов не реализовано)
+func (s *TestAuthService) RevokeOtherSessions(userID, currentSessionID uint) (int64, error) {
+	return 0, nil
+}
+
+// IsTokenRevoked проверяет отзыв токена (в тестах токены не отзываются)
+func (s *TestAuthService) IsTokenRevoked(claims *models.TokenClaims) bool {
+	return false
+}
+
+// RevokeAccessToken отзывает access token (для тестов не реализовано)
+func (s *TestAuthService) RevokeAccessToken(claims *models.TokenClaims) error {
+	return nil
+}
+
+// RevokeUserTokens отзывает токены пользователя (для тестов не реализовано)
+func (s *TestAuthService) RevokeUserTokens(userID uint) error {
+	return nil
+}
+
 // HashPassword хеширует пароль (для тестов не реализовано)
 func (s *TestAuthService) HashPassword(password string) (string, error) {
 	return "hashed_" + password, nil
@@ -352,6 +476,11 @@ func (s *TestAuthService) CheckPassword(password, hash string) bool {
 	return hash == "hashed_"+password
 }
 
+// ValidatePassword проверяет пароль по политике (для тестов любой пароль допустим)
+func (s *TestAuthService) ValidatePassword(password, email string) error {
+	return nil
+}
+
 // RegisterUserDirect регистрирует пользователя (для тестов не реализовано)
 func (s *TestAuthService) RegisterUserDirect(req models.DirectRegisterRequest) (*models.User, error) {
 	return nil, fmt.Errorf("не реализовано в тестах")
@@ -376,3 +505,13 @@ func (s *TestAuthService) RegisterBarber(req models.BarberRegisterRequest) (*mod
 func (s *TestAuthService) GetUserByID(userID uint) (*models.User, error) {
 	return s.userRepo.GetByID(userID)
 }
+
+// GetProfile возвращает профиль пользователя (без ролей)
+func (s *TestAuthService) GetProfile(userID uint) (*models.User, error) {
+	return s.userRepo.GetByID(userID)
+}
+
+// UpdateProfile обновляет профиль (для тестов не реализовано)
+func (s *TestAuthService) UpdateProfile(userID uint, req models.ProfileUpdateRequest) (*models.User, error) {
+	return s.userRepo.GetByID(userID)
+}
@@ -0,0 +1,321 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"garage-barbershop/internal/database"
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/payments"
+	"garage-barbershop/internal/repositories"
+	"garage-barbershop/internal/services"
+	"garage-barbershop/internal/telegram"
+
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// fakeBotAPI локальный сервер, имитирующий Telegram Bot API
+type fakeBotAPI struct {
+	server *httptest.Server
+
+	mu    sync.Mutex
+	calls map[string][]map[string]interface{}
+	fail  map[string]string
+}
+
+// newFakeBotAPI запускает сервер, принимающий запросы бота с токеном token
+func newFakeBotAPI(token string) *fakeBotAPI {
+	api := &fakeBotAPI{calls: map[string][]map[string]interface{}{}, fail: map[string]string{}}
+	api.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method := strings.TrimPrefix(r.URL.Path, "/bot"+token+"/")
+		if method == r.URL.Path {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "error_code": 401, "description": "Unauthorized"})
+			return
+		}
+
+		var params map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&params)
+
+		api.mu.Lock()
+		api.calls[method] = append(api.calls[method], params)
+		description, fail := api.fail[method]
+		api.mu.Unlock()
+
+		if fail {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]interface{}{"ok": false, "error_code": 400, "description": description})
+			return
+		}
+
+		var result interface{} = true
+		if method == "sendInvoice" {
+			result = map[string]interface{}{"message_id": 42, "chat": map[string]interface{}{"id": params["chat_id"]}}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"ok": true, "result": result})
+	}))
+	return api
+}
+
+// lastCall возвращает параметры последнего вызова метода
+func (api *fakeBotAPI) lastCall(method string) map[string]interface{} {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	calls := api.calls[method]
+	if len(calls) == 0 {
+		return nil
+	}
+	return calls[len(calls)-1]
+}
+
+// reset очищает записанные вызовы и сбои
+func (api *fakeBotAPI) reset() {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	api.calls = map[string][]map[string]interface{}{}
+	api.fail = map[string]string{}
+}
+
+// TelegramPaymentTestSuite набор тестов оплаты через Telegram Payments
+type TelegramPaymentTestSuite struct {
+	suite.Suite
+	db              *database.Database
+	botAPI          *fakeBotAPI
+	appointmentRepo repositories.AppointmentRepository
+	paymentService  services.PaymentService
+	client          *models.User
+	barber          *models.User
+	appointment     *models.Appointment
+}
+
+const telegramTestSecret = "webhook-secret"
+
+// SetupSuite инициализирует тестовую среду
+func (suite *TelegramPaymentTestSuite) SetupSuite() {
+	db, err := gorm.Open(sqlite.Open("file:telegram_payments?mode=memory&cache=shared"), &gorm.Config{})
+	suite.Require().NoError(err)
+
+	suite.db = &database.Database{DB: db}
+	err = suite.db.Migrate(&models.User{}, &models.Service{}, &models.Appointment{}, &models.Payment{})
+	suite.Require().NoError(err)
+
+	suite.botAPI = newFakeBotAPI("123:ABC")
+	bot := telegram.NewBotClient(suite.botAPI.server.URL, "123:ABC", suite.botAPI.server.Client())
+
+	suite.appointmentRepo = repositories.NewAppointmentRepository(db)
+	suite.paymentService = services.NewPaymentService(
+		repositories.NewPaymentRepository(db),
+		suite.appointmentRepo,
+		"RUB",
+		payments.NewTelegramProvider(bot, "provider-token", telegramTestSecret, repositories.NewUserRepository(db)),
+		payments.NewCashProvider(),
+	)
+}
+
+// TearDownSuite очищает тестовую среду
+func (suite *TelegramPaymentTestSuite) TearDownSuite() {
+	suite.botAPI.server.Close()
+	sqlDB, err := suite.db.DB.DB()
+	suite.Require().NoError(err)
+	sqlDB.Close()
+}
+
+// SetupTest создает барбера, клиента и запись
+func (suite *TelegramPaymentTestSuite) SetupTest() {
+	suite.db.DB.Exec("DELETE FROM payments")
+	suite.db.DB.Exec("DELETE FROM appointments")
+	suite.db.DB.Exec("DELETE FROM services")
+	suite.db.DB.Exec("DELETE FROM users")
+	suite.botAPI.reset()
+
+	suite.barber = &models.User{TelegramID: 1001, Email: "barber@example.com", IsActive: true}
+	suite.Require().NoError(suite.db.DB.Create(suite.barber).Error)
+	suite.client = &models.User{TelegramID: 1002, Email: "client@example.com", IsActive: true}
+	suite.Require().NoError(suite.db.DB.Create(suite.client).Error)
+
+	service := &models.Service{Name: "Стрижка", Price: 1500.5, Duration: 60, IsActive: true, BarberID: suite.barber.ID}
+	suite.Require().NoError(suite.db.DB.Create(service).Error)
+
+	suite.appointment = &models.Appointment{
+		DateTime:      time.Now().Add(24 * time.Hour).UTC(),
+		Duration:      60,
+		Status:        models.AppointmentStatusConfirmed,
+		ClientID:      suite.client.ID,
+		BarberID:      suite.barber.ID,
+		ServiceID:     service.ID,
+		Price:         service.Price,
+		PaymentStatus: models.PaymentStatusPending,
+	}
+	suite.Require().NoError(suite.appointmentRepo.Create(suite.appointment))
+}
+
+// sendUpdate передает обновление бота в сервис платежей
+func (suite *TelegramPaymentTestSuite) sendUpdate(update string) (*models.Payment, error) {
+	header := http.Header{}
+	header.Set(payments.TelegramSecretHeader, telegramTestSecret)
+	return suite.paymentService.HandleWebhook(models.PaymentMethodTelegram, []byte(update), header)
+}
+
+// preCheckoutUpdate формирует pre_checkout_query
+func preCheckoutUpdate(paymentID uint, amount int64) string {
+	return fmt.Sprintf(`{"update_id":1,"pre_checkout_query":{"id":"pcq-1","from":{"id":1002,"first_name":"Иван"},"currency":"RUB","total_amount":%d,"invoice_payload":"payment:%d"}}`, amount, paymentID)
+}
+
+// successfulPaymentUpdate формирует сообщение successful_payment
+func successfulPaymentUpdate(paymentID uint) string {
+	return fmt.Sprintf(`{"update_id":2,"message":{"message_id":43,"chat":{"id":1002},"successful_payment":{"currency":"RUB","total_amount":150050,"invoice_payload":"payment:%d","telegram_payment_charge_id":"tg-charge-1","provider_payment_charge_id":"prov-charge-1"}}}`, paymentID)
+}
+
+// TestTelegramPayment_FullFlow тестирует счет, подтверждение и проведение оплаты
+func (suite *TelegramPaymentTestSuite) TestTelegramPayment_FullFlow() {
+	// Arrange & Act
+	payment, err := suite.paymentService.PayForAppointment(suite.client.ID, suite.appointment.ID, models.PaymentCreateRequest{Method: models.PaymentMethodTelegram})
+	suite.Require().NoError(err)
+	suite.Equal(models.PaymentPending, payment.Status)
+	suite.Empty(payment.ExternalID)
+
+	// Assert: счет ушел в чат клиента в копейках
+	invoice := suite.botAPI.lastCall("sendInvoice")
+	suite.Require().NotNil(invoice)
+	suite.Equal(float64(suite.client.TelegramID), invoice["chat_id"])
+	suite.Equal(fmt.Sprintf("payment:%d", payment.ID), invoice["payload"])
+	suite.Equal("provider-token", invoice["provider_token"])
+	suite.Equal("RUB", invoice["currency"])
+	prices := invoice["prices"].([]interface{})
+	suite.Equal(float64(150050), prices[0].(map[string]interface{})["amount"])
+
+	// pre_checkout_query подтверждается
+	_, err = suite.sendUpdate(preCheckoutUpdate(payment.ID, 150050))
+	suite.Require().NoError(err)
+	answer := suite.botAPI.lastCall("answerPreCheckoutQuery")
+	suite.Equal("pcq-1", answer["pre_checkout_query_id"])
+	suite.Equal(true, answer["ok"])
+
+	// successful_payment проводит платеж
+	payment, err = suite.sendUpdate(successfulPaymentUpdate(payment.ID))
+	suite.Require().NoError(err)
+	suite.Equal(models.PaymentCompleted, payment.Status)
+	suite.Equal("tg-charge-1", payment.ExternalID)
+
+	appointment, err := suite.appointmentRepo.GetByID(suite.appointment.ID)
+	suite.Require().NoError(err)
+	suite.Equal(models.PaymentStatusPaid, appointment.PaymentStatus)
+
+	// Повторная доставка обновления не ошибка
+	_, err = suite.sendUpdate(successfulPaymentUpdate(payment.ID))
+	suite.NoError(err)
+}
+
+// TestTelegramPayment_PreCheckoutRejected тестирует отказ при несовпадении суммы и устаревшем счете
+func (suite *TelegramPaymentTestSuite) TestTelegramPayment_PreCheckoutRejected() {
+	payment, err := suite.paymentService.PayForAppointment(suite.client.ID, suite.appointment.ID, models.PaymentCreateRequest{Method: models.PaymentMethodTelegram})
+	suite.Require().NoError(err)
+
+	_, err = suite.sendUpdate(preCheckoutUpdate(payment.ID, 100))
+	suite.Require().NoError(err)
+	answer := suite.botAPI.lastCall("answerPreCheckoutQuery")
+	suite.Equal(false, answer["ok"])
+	suite.NotEmpty(answer["error_message"])
+
+	// Счет Telegram нельзя отозвать: повторный запрос оплаты присылает счет того же платежа
+	again, err := suite.paymentService.PayForAppointment(suite.client.ID, suite.appointment.ID, models.PaymentCreateRequest{Method: models.PaymentMethodTelegram})
+	suite.Require().NoError(err)
+	suite.Equal(payment.ID, again.ID)
+	suite.Equal(fmt.Sprintf("payment:%d", payment.ID), suite.botAPI.lastCall("sendInvoice")["payload"])
+	_, err = suite.sendUpdate(preCheckoutUpdate(payment.ID, 150050))
+	suite.Require().NoError(err)
+	suite.Equal(true, suite.botAPI.lastCall("answerPreCheckoutQuery")["ok"])
+
+	// Запись, оплаченная другим способом, больше не принимает оплату по счету
+	cash, err := suite.paymentService.PayForAppointment(suite.client.ID, suite.appointment.ID, models.PaymentCreateRequest{Method: models.PaymentMethodCash})
+	suite.Require().NoError(err)
+	_, err = suite.paymentService.CapturePayment(suite.barber.ID, cash.ID)
+	suite.Require().NoError(err)
+	_, err = suite.sendUpdate(preCheckoutUpdate(payment.ID, 150050))
+	suite.Require().NoError(err)
+	suite.Equal(false, suite.botAPI.lastCall("answerPreCheckoutQuery")["ok"])
+}
+
+// TestTelegramPayment_LateDuplicateCharge тестирует, что списание Telegram по уже оплаченной записи
+// записывается и помечается к возврату
+func (suite *TelegramPaymentTestSuite) TestTelegramPayment_LateDuplicateCharge() {
+	payment, err := suite.paymentService.PayForAppointment(suite.client.ID, suite.appointment.ID, models.PaymentCreateRequest{Method: models.PaymentMethodTelegram})
+	suite.Require().NoError(err)
+	_, err = suite.sendUpdate(preCheckoutUpdate(payment.ID, 150050))
+	suite.Require().NoError(err)
+
+	// Пока Telegram проводит списание, клиент оплачивает наличными; счет Telegram остается ожидать
+	cash, err := suite.paymentService.PayForAppointment(suite.client.ID, suite.appointment.ID, models.PaymentCreateRequest{Method: models.PaymentMethodCash})
+	suite.Require().NoError(err)
+	_, err = suite.paymentService.CapturePayment(suite.barber.ID, cash.ID)
+	suite.Require().NoError(err)
+
+	charged, err := suite.sendUpdate(successfulPaymentUpdate(payment.ID))
+	suite.Require().NoError(err)
+	suite.Equal(models.PaymentCompleted, charged.Status)
+	suite.True(charged.RefundRequired)
+
+	list, err := suite.paymentService.GetClientPayments(suite.client.ID, suite.appointment.ID)
+	suite.Require().NoError(err)
+	suite.Require().Len(list, 2)
+	suite.True(list[0].RefundRequired)
+	suite.False(list[1].RefundRequired)
+
+	appointment, err := suite.appointmentRepo.GetByID(suite.appointment.ID)
+	suite.Require().NoError(err)
+	suite.Equal(models.PaymentStatusPaid, appointment.PaymentStatus)
+}
+
+// TestTelegramPayment_Webhook тестирует проверку секрета и посторонние обновления
+func (suite *TelegramPaymentTestSuite) TestTelegramPayment_Webhook() {
+	payment, err := suite.paymentService.PayForAppointment(suite.client.ID, suite.appointment.ID, models.PaymentCreateRequest{Method: models.PaymentMethodTelegram})
+	suite.Require().NoError(err)
+
+	header := http.Header{}
+	header.Set(payments.TelegramSecretHeader, "wrong")
+	_, err = suite.paymentService.HandleWebhook(models.PaymentMethodTelegram, []byte(successfulPaymentUpdate(payment.ID)), header)
+	suite.ErrorIs(err, payments.ErrInvalidSignature)
+
+	// Обычные сообщения боту не относятся к платежам
+	result, err := suite.sendUpdate(`{"update_id":3,"message":{"message_id":1,"chat":{"id":1002}}}`)
+	suite.NoError(err)
+	suite.Nil(result)
+
+	// Чужой ID платежа
+	_, err = suite.sendUpdate(successfulPaymentUpdate(payment.ID + 100))
+	suite.ErrorIs(err, services.ErrPaymentNotFound)
+
+	// Барбер не подтверждает оплату через Telegram вручную
+	_, err = suite.paymentService.CapturePayment(suite.barber.ID, payment.ID)
+	suite.ErrorIs(err, services.ErrInvalidPaymentTransition)
+	list, err := suite.paymentService.GetClientPayments(suite.client.ID, suite.appointment.ID)
+	suite.Require().NoError(err)
+	suite.Equal(models.PaymentPending, list[0].Status)
+}
+
+// TestTelegramPayment_BotAPIError тестирует ошибку Bot API при отправке счета
+func (suite *TelegramPaymentTestSuite) TestTelegramPayment_BotAPIError() {
+	suite.botAPI.fail["sendInvoice"] = "Bad Request: chat not found"
+
+	_, err := suite.paymentService.PayForAppointment(suite.client.ID, suite.appointment.ID, models.PaymentCreateRequest{Method: models.PaymentMethodTelegram})
+	suite.Require().Error(err)
+	suite.Contains(err.Error(), "chat not found")
+
+	list, err := suite.paymentService.GetClientPayments(suite.client.ID, suite.appointment.ID)
+	suite.Require().NoError(err)
+	suite.Require().Len(list, 1)
+	suite.Equal(models.PaymentFailed, list[0].Status)
+}
+
+// TestTelegramPaymentTestSuite запускает набор тестов
+func TestTelegramPaymentTestSuite(t *testing.T) {
+	suite.Run(t, new(TelegramPaymentTestSuite))
+}
@@ -0,0 +1,246 @@
+package integration
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"garage-barbershop/internal/database"
+	"garage-barbershop/internal/models"
+
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// webAppBotToken токен бота, которым подписываются initData в тестах
+const webAppBotToken = "123456:TEST-webapp-token"
+
+// TelegramWebAppTestSuite набор тестов входа из Telegram WebApp и через Telegram Login Widget
+type TelegramWebAppTestSuite struct {
+	suite.Suite
+	db  *database.Database
+	mux *http.ServeMux
+}
+
+// SetupSuite инициализирует тестовую среду и маршруты
+func (suite *TelegramWebAppTestSuite) SetupSuite() {
+	db, err := gorm.Open(sqlite.Open("file:telegram_webapp?mode=memory&cache=shared"), &gorm.Config{})
+	suite.Require().NoError(err)
+
+	suite.db = &database.Database{DB: db}
+	err = suite.db.Migrate(&models.User{}, &models.Role{}, &models.UserRole{}, &models.AuditEvent{}, &models.Session{})
+	suite.Require().NoError(err)
+
+	authHandler := newAuthTestServices(db, authTestConfig{botToken: webAppBotToken}).handler
+
+	suite.mux = http.NewServeMux()
+	suite.mux.HandleFunc("/api/auth/telegram", authHandler.TelegramAuth)
+	suite.mux.HandleFunc("/api/auth/telegram/webapp", authHandler.TelegramWebAppAuth)
+}
+
+// TearDownSuite очищает тестовую среду
+func (suite *TelegramWebAppTestSuite) TearDownSuite() {
+	sqlDB, err := suite.db.DB.DB()
+	suite.Require().NoError(err)
+	sqlDB.Close()
+}
+
+// SetupTest очищает данные между тестами
+func (suite *TelegramWebAppTestSuite) SetupTest() {
+	suite.db.DB.Exec("DELETE FROM audit_events")
+	suite.db.DB.Exec("DELETE FROM sessions")
+	suite.db.DB.Exec("DELETE FROM user_roles")
+	suite.db.DB.Exec("DELETE FROM users")
+}
+
+// initDataFields поля initData, как их передает Telegram, без подписи
+func initDataFields(userID int64, firstName string, authDate time.Time) url.Values {
+	user, _ := json.Marshal(map[string]interface{}{
+		"id":            userID,
+		"first_name":    firstName,
+		"last_name":     "Петров",
+		"username":      "ivan_petrov",
+		"language_code": "ru",
+		"photo_url":     "https://t.me/i/userpic/320/ivan.jpg",
+	})
+	return url.Values{
+		"query_id":  {"AAHdF6IQAAAAAN0XohDhrOrc"},
+		"user":      {string(user)},
+		"auth_date": {strconv.FormatInt(authDate.Unix(), 10)},
+	}
+}
+
+// signInitData подписывает поля по алгоритму Telegram WebApp и возвращает строку initData
+func signInitData(values url.Values, botToken string) string {
+	fields := make([]string, 0, len(values))
+	for key := range values {
+		fields = append(fields, key+"="+values.Get(key))
+	}
+	sort.Strings(fields)
+
+	secretKey := hmac.New(sha256.New, []byte("WebAppData"))
+	secretKey.Write([]byte(botToken))
+	mac := hmac.New(sha256.New, secretKey.Sum(nil))
+	mac.Write([]byte(strings.Join(fields, "\n")))
+
+	signed := url.Values{}
+	for key, value := range values {
+		signed[key] = value
+	}
+	signed.Set("hash", hex.EncodeToString(mac.Sum(nil)))
+	return signed.Encode()
+}
+
+// auth выполняет вход с initData
+func (suite *TelegramWebAppTestSuite) auth(initData string) *httptest.ResponseRecorder {
+	body, err := json.Marshal(models.TelegramWebAppAuthRequest{InitData: initData})
+	suite.Require().NoError(err)
+	req := httptest.NewRequest(http.MethodPost, "/api/auth/telegram/webapp", bytes.NewReader(body))
+
+	w := httptest.NewRecorder()
+	suite.mux.ServeHTTP(w, req)
+	return w
+}
+
+// TestValidInitData тестирует вход нового и существующего пользователя
+func (suite *TelegramWebAppTestSuite) TestValidInitData() {
+	w := suite.auth(signInitData(initDataFields(279058397, "Иван", time.Now()), webAppBotToken))
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+
+	var response models.AuthResponse
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	suite.NotEmpty(response.AccessToken)
+	suite.NotEmpty(response.RefreshToken)
+	suite.Equal(int64(279058397), response.User.TelegramID)
+	suite.Equal("ivan_petrov", response.User.Username)
+
+	// Повторный вход обновляет данные того же пользователя
+	w = suite.auth(signInitData(initDataFields(279058397, "Ваня", time.Now()), webAppBotToken))
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+
+	var users []models.User
+	suite.db.DB.Find(&users)
+	suite.Require().Len(users, 1)
+	suite.Equal("Ваня", users[0].FirstName)
+
+	// Без query_id (запуск с обычной кнопки клавиатуры) вход тоже разрешен
+	fields := initDataFields(279058397, "Иван", time.Now())
+	fields.Del("query_id")
+	suite.Equal(http.StatusOK, suite.auth(signInitData(fields, webAppBotToken)).Code)
+}
+
+// TestInvalidInitData тестирует отказ при неверной подписи и содержимом
+func (suite *TelegramWebAppTestSuite) TestInvalidInitData() {
+	valid := signInitData(initDataFields(279058397, "Иван", time.Now()), webAppBotToken)
+
+	// Измененное поле
+	tampered := strings.Replace(valid, "279058397", "279058398", 1)
+	suite.Equal(http.StatusUnauthorized, suite.auth(tampered).Code)
+
+	// Подпись другим ботом
+	suite.Equal(http.StatusUnauthorized, suite.auth(signInitData(initDataFields(279058397, "Иван", time.Now()), "654321:OTHER")).Code)
+
+	// Подпись по схеме Login Widget (ключ - SHA256 токена) для WebApp недействительна
+	values, err := url.ParseQuery(valid)
+	suite.Require().NoError(err)
+	values.Del("hash")
+	fields := make([]string, 0, len(values))
+	for key := range values {
+		fields = append(fields, key+"="+values.Get(key))
+	}
+	sort.Strings(fields)
+	widgetKey := sha256.Sum256([]byte(webAppBotToken))
+	mac := hmac.New(sha256.New, widgetKey[:])
+	mac.Write([]byte(strings.Join(fields, "\n")))
+	values.Set("hash", hex.EncodeToString(mac.Sum(nil)))
+	suite.Equal(http.StatusUnauthorized, suite.auth(values.Encode()).Code)
+
+	// Без подписи
+	values.Del("hash")
+	suite.Equal(http.StatusUnauthorized, suite.auth(values.Encode()).Code)
+
+	// Поле передано дважды
+	suite.Equal(http.StatusUnauthorized, suite.auth(valid+"&query_id=other").Code)
+
+	// Устаревшие и выданные в будущем данные
+	suite.Equal(http.StatusUnauthorized, suite.auth(signInitData(initDataFields(279058397, "Иван", time.Now().Add(-2*time.Hour)), webAppBotToken)).Code)
+	suite.Equal(http.StatusUnauthorized, suite.auth(signInitData(initDataFields(279058397, "Иван", time.Now().Add(time.Hour)), webAppBotToken)).Code)
+
+	// Нет пользователя, неверный JSON пользователя, пустой query_id
+	fieldsWithout := initDataFields(279058397, "Иван", time.Now())
+	fieldsWithout.Del("user")
+	suite.Equal(http.StatusUnauthorized, suite.auth(signInitData(fieldsWithout, webAppBotToken)).Code)
+	fieldsWithout = initDataFields(279058397, "Иван", time.Now())
+	fieldsWithout.Set("user", `{"id":"abc"}`)
+	suite.Equal(http.StatusUnauthorized, suite.auth(signInitData(fieldsWithout, webAppBotToken)).Code)
+	fieldsWithout = initDataFields(279058397, "Иван", time.Now())
+	fieldsWithout.Set("query_id", "")
+	suite.Equal(http.StatusUnauthorized, suite.auth(signInitData(fieldsWithout, webAppBotToken)).Code)
+
+	var users, failures int64
+	suite.db.DB.Model(&models.User{}).Count(&users)
+	suite.Zero(users)
+	suite.db.DB.Model(&models.AuditEvent{}).Where("action = ?", models.AuditActionLoginFailure).Count(&failures)
+	suite.Equal(int64(10), failures)
+}
+
+// TestInactiveUser тестирует отказ деактивированному пользователю
+func (suite *TelegramWebAppTestSuite) TestInactiveUser() {
+	initData := signInitData(initDataFields(279058397, "Иван", time.Now()), webAppBotToken)
+	suite.Require().Equal(http.StatusOK, suite.auth(initData).Code)
+
+	suite.db.DB.Model(&models.User{}).Where("telegram_id = ?", 279058397).Update("is_active", false)
+	suite.Equal(http.StatusForbidden, suite.auth(initData).Code)
+}
+
+// TestLoginWidget тестирует вход через Login Widget с подписью токеном бота из конфигурации
+func (suite *TelegramWebAppTestSuite) TestLoginWidget() {
+	authDate := strconv.FormatInt(time.Now().Unix(), 10)
+	lines := []string{
+		"auth_date=" + authDate,
+		"first_name=Иван",
+		"id=279058397",
+		"photo_url=https://t.me/i/userpic/320/ivan.jpg",
+		"username=ivan_petrov",
+	}
+	key := sha256.Sum256([]byte(webAppBotToken))
+	mac := hmac.New(sha256.New, key[:])
+	mac.Write([]byte(strings.Join(lines, "\n")))
+	hash := hex.EncodeToString(mac.Sum(nil))
+
+	// Виджет передает id и auth_date числами
+	login := func(photoURL string) *httptest.ResponseRecorder {
+		body := `{"id":279058397,"first_name":"Иван","username":"ivan_petrov","photo_url":"` + photoURL +
+			`","auth_date":` + authDate + `,"hash":"` + hash + `"}`
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/telegram", strings.NewReader(body))
+		w := httptest.NewRecorder()
+		suite.mux.ServeHTTP(w, req)
+		return w
+	}
+
+	w := login("https://t.me/i/userpic/320/ivan.jpg")
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	var response models.AuthResponse
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &response))
+	suite.NotEmpty(response.AccessToken)
+	suite.Equal(int64(279058397), response.User.TelegramID)
+
+	// Подмена photo_url нарушает подпись
+	suite.Equal(http.StatusUnauthorized, login("https://example.com/evil.jpg").Code)
+}
+
+// TestTelegramWebAppTestSuite запускает набор тестов
+func TestTelegramWebAppTestSuite(t *testing.T) {
+	suite.Run(t, new(TelegramWebAppTestSuite))
+}
@@ -0,0 +1,175 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"garage-barbershop/internal/database"
+	"garage-barbershop/internal/handlers"
+	"garage-barbershop/internal/middleware"
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/repositories"
+	"garage-barbershop/internal/services"
+
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// TokenRevocationTestSuite набор тестов отзыва access token
+type TokenRevocationTestSuite struct {
+	apiTestSuite
+	db          *database.Database
+	roleRepo    repositories.RoleRepository
+	authService services.AuthService
+	admin       *models.User
+}
+
+// SetupSuite инициализирует тестовую среду и маршруты
+func (suite *TokenRevocationTestSuite) SetupSuite() {
+	db, err := gorm.Open(sqlite.Open("file:token_revocation?mode=memory&cache=shared"), &gorm.Config{})
+	suite.Require().NoError(err)
+
+	suite.db = &database.Database{DB: db}
+	err = suite.db.Migrate(&models.User{}, &models.Role{}, &models.UserRole{}, &models.AuditEvent{}, &models.Session{})
+	suite.Require().NoError(err)
+
+	authServices := newAuthTestServices(db, authTestConfig{})
+	suite.roleRepo = authServices.roleRepo
+	suite.authService = authServices.auth
+	permissionService := services.NewPermissionService(suite.roleRepo)
+
+	authHandler := authServices.handler
+	permissionHandler := handlers.NewPermissionHandler(permissionService)
+	roleHandler := handlers.NewRoleHandler(services.NewRoleService(suite.roleRepo), permissionService, authServices.audit, authServices.revocation)
+	barberHandler := handlers.NewBarberHandler(services.NewBarberService(authServices.userRepo, suite.roleRepo, authServices.audit, authServices.revocation))
+	auth := middleware.HTTPAuthMiddleware(suite.authService)
+
+	suite.mux = http.NewServeMux()
+	suite.mux.HandleFunc("/api/auth/login", authHandler.LoginDirect)
+	suite.mux.HandleFunc("/api/auth/refresh", authHandler.RefreshToken)
+	suite.mux.HandleFunc("/api/auth/logout", auth(authHandler.Logout))
+	suite.mux.HandleFunc("/api/auth/permissions", auth(permissionHandler.GetMyPermissions))
+	suite.mux.HandleFunc("/api/admin/roles/assign", roleHandler.AssignRole)
+	suite.mux.HandleFunc("/api/admin/barbers/", barberHandler.AdminUpdateBarber)
+}
+
+// TearDownSuite очищает тестовую среду
+func (suite *TokenRevocationTestSuite) TearDownSuite() {
+	sqlDB, err := suite.db.DB.DB()
+	suite.Require().NoError(err)
+	sqlDB.Close()
+}
+
+// SetupTest создает администратора
+func (suite *TokenRevocationTestSuite) SetupTest() {
+	suite.db.DB.Exec("DELETE FROM sessions")
+	suite.db.DB.Exec("DELETE FROM user_roles")
+	suite.db.DB.Exec("DELETE FROM users")
+
+	suite.admin = &models.User{TelegramID: 1, Email: "admin@example.com", IsActive: true}
+	suite.Require().NoError(suite.db.DB.Create(suite.admin).Error)
+	adminRole, err := suite.roleRepo.GetRoleByName("admin")
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.roleRepo.AssignRoleToUser(suite.admin.ID, adminRole.ID, suite.admin.ID))
+}
+
+// register создает пользователя с паролем и ролью
+func (suite *TokenRevocationTestSuite) register(email, role string) *models.User {
+	user, err := suite.authService.RegisterUserDirect(models.DirectRegisterRequest{
+		Email:     email,
+		Password:  "fresh-fade-42",
+		FirstName: "Олег",
+		LastName:  "Петров",
+		Role:      role,
+	})
+	suite.Require().NoError(err)
+	return user
+}
+
+// adminRequest выполняет запрос от имени администратора, добавленного в контекст
+func (suite *TokenRevocationTestSuite) adminRequest(method, path string, adminID uint, body interface{}) *httptest.ResponseRecorder {
+	req := suite.newRequest(method, path, "", body)
+	return suite.serve(req.WithContext(context.WithValue(req.Context(), "userID", adminID)))
+}
+
+// permissionsStatus возвращает код ответа защищенного endpoint для access token
+func (suite *TokenRevocationTestSuite) permissionsStatus(accessToken string) int {
+	return suite.request(http.MethodGet, "/api/auth/permissions", accessToken, nil).Code
+}
+
+// TestLogoutRevokesAccessToken тестирует отзыв текущего access token при выходе
+func (suite *TokenRevocationTestSuite) TestLogoutRevokesAccessToken() {
+	// Arrange
+	suite.register("client@example.com", "client")
+	laptop := suite.mustLogin("client@example.com")
+	phone := suite.mustLogin("client@example.com")
+	suite.Require().Equal(http.StatusOK, suite.permissionsStatus(laptop.AccessToken))
+
+	// Act
+	w := suite.request(http.MethodPost, "/api/auth/logout", laptop.AccessToken, nil)
+
+	// Assert
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	suite.Equal(http.StatusUnauthorized, suite.permissionsStatus(laptop.AccessToken))
+	suite.Equal(http.StatusUnauthorized, suite.request(http.MethodPost, "/api/auth/logout", laptop.AccessToken, nil).Code)
+
+	// Токены других устройств не затронуты
+	suite.Equal(http.StatusOK, suite.permissionsStatus(phone.AccessToken))
+}
+
+// TestRoleChangeRevokesIssuedTokens тестирует отзыв токенов со старым набором ролей
+func (suite *TokenRevocationTestSuite) TestRoleChangeRevokesIssuedTokens() {
+	// Arrange
+	user := suite.register("client@example.com", "client")
+	tokens := suite.mustLogin("client@example.com")
+	barberRole, err := suite.roleRepo.GetRoleByName("barber")
+	suite.Require().NoError(err)
+
+	// Act
+	w := suite.adminRequest(http.MethodPost, "/api/admin/roles/assign", suite.admin.ID, models.RoleAssignmentRequest{UserID: user.ID, RoleID: barberRole.ID})
+	suite.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+
+	// Assert: старый токен отозван, а выпущенный сразу после изменения (в ту же секунду) действует
+	suite.Equal(http.StatusUnauthorized, suite.permissionsStatus(tokens.AccessToken))
+
+	w = suite.request(http.MethodPost, "/api/auth/refresh", "", models.RefreshTokenRequest{RefreshToken: tokens.RefreshToken})
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	var refreshed models.AuthResponse
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &refreshed))
+	suite.Equal(http.StatusOK, suite.permissionsStatus(refreshed.AccessToken))
+
+	claims, err := suite.authService.ParseJWT(refreshed.AccessToken)
+	suite.Require().NoError(err)
+	suite.Contains(claims.Roles, "barber")
+}
+
+// TestDeactivationRevokesAccess тестирует потерю доступа деактивированным барбером
+func (suite *TokenRevocationTestSuite) TestDeactivationRevokesAccess() {
+	// Arrange
+	barber := suite.register("barber@example.com", "barber")
+	tokens := suite.mustLogin("barber@example.com")
+	inactive := false
+
+	// Act
+	w := suite.adminRequest(http.MethodPut, "/api/admin/barbers/"+strconv.FormatUint(uint64(barber.ID), 10), suite.admin.ID, models.BarberUpdateRequest{IsActive: &inactive})
+
+	// Assert
+	suite.Require().Equal(http.StatusOK, w.Code, w.Body.String())
+	suite.Equal(http.StatusUnauthorized, suite.permissionsStatus(tokens.AccessToken))
+
+	w = suite.request(http.MethodPost, "/api/auth/refresh", "", models.RefreshTokenRequest{RefreshToken: tokens.RefreshToken})
+	suite.Equal(http.StatusUnauthorized, w.Code)
+
+	w = suite.request(http.MethodPost, "/api/auth/login", "", models.DirectLoginRequest{Email: "barber@example.com", Password: "fresh-fade-42"})
+	suite.Equal(http.StatusUnauthorized, w.Code)
+}
+
+// TestTokenRevocationTestSuite запускает набор тестов
+func TestTokenRevocationTestSuite(t *testing.T) {
+	suite.Run(t, new(TokenRevocationTestSuite))
+}
@@ -0,0 +1,300 @@
+package integration
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"garage-barbershop/internal/database"
+	"garage-barbershop/internal/handlers"
+	"garage-barbershop/internal/middleware"
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/services"
+	"garage-barbershop/internal/totp"
+
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// TwoFactorTestSuite набор тестов двухфакторной аутентификации
+type TwoFactorTestSuite struct {
+	apiTestSuite
+	db          *database.Database
+	authService services.AuthService
+}
+
+// SetupSuite инициализирует тестовую среду и маршруты; 2FA обязательна для администраторов
+func (suite *TwoFactorTestSuite) SetupSuite() {
+	db, err := gorm.Open(sqlite.Open("file:two_factor?mode=memory&cache=shared"), &gorm.Config{})
+	suite.Require().NoError(err)
+
+	suite.db = &database.Database{DB: db}
+	err = suite.db.Migrate(&models.User{}, &models.Role{}, &models.UserRole{}, &models.AuditEvent{}, &models.Session{}, &models.RecoveryCode{})
+	suite.Require().NoError(err)
+
+	authServices := newAuthTestServices(db, authTestConfig{requireAdminTwoFactor: true})
+	suite.authService = authServices.auth
+	authHandler := authServices.handler
+	twoFactorHandler := handlers.NewTwoFactorHandler(authServices.twoFactor, authServices.audit)
+	auth := middleware.HTTPAuthMiddleware(suite.authService)
+
+	suite.mux = http.NewServeMux()
+	suite.mux.HandleFunc("/api/auth/login", authHandler.LoginDirect)
+	suite.mux.HandleFunc("/api/auth/refresh", authHandler.RefreshToken)
+	suite.mux.HandleFunc("/api/auth/login/2fa", authHandler.LoginTwoFactor)
+	suite.mux.HandleFunc("/api/auth/login/2fa/setup", authHandler.LoginTwoFactorSetup)
+	suite.mux.HandleFunc("/api/auth/login/2fa/confirm", authHandler.LoginTwoFactorConfirm)
+	suite.mux.HandleFunc("/api/auth/2fa", auth(twoFactorHandler.GetStatus))
+	suite.mux.HandleFunc("/api/auth/2fa/setup", auth(twoFactorHandler.Setup))
+	suite.mux.HandleFunc("/api/auth/2fa/confirm", auth(twoFactorHandler.Confirm))
+	suite.mux.HandleFunc("/api/auth/2fa/disable", auth(twoFactorHandler.Disable))
+	suite.mux.HandleFunc("/api/auth/2fa/recovery-codes", auth(twoFactorHandler.RegenerateRecoveryCodes))
+}
+
+// TearDownSuite очищает тестовую среду
+func (suite *TwoFactorTestSuite) TearDownSuite() {
+	sqlDB, err := suite.db.DB.DB()
+	suite.Require().NoError(err)
+	sqlDB.Close()
+}
+
+// SetupTest очищает данные между тестами
+func (suite *TwoFactorTestSuite) SetupTest() {
+	suite.db.DB.Exec("DELETE FROM recovery_codes")
+	suite.db.DB.Exec("DELETE FROM audit_events")
+	suite.db.DB.Exec("DELETE FROM sessions")
+	suite.db.DB.Exec("DELETE FROM user_roles")
+	suite.db.DB.Exec("DELETE FROM users")
+}
+
+// register создает пользователя с паролем и ролью
+func (suite *TwoFactorTestSuite) register(email, role string) *models.User {
+	user, err := suite.authService.RegisterUserDirect(models.DirectRegisterRequest{
+		Email:     email,
+		Password:  "fresh-fade-42",
+		FirstName: "Олег",
+		LastName:  "Смирнов",
+		Role:      role,
+	})
+	suite.Require().NoError(err)
+	return user
+}
+
+// code возвращает код приложения-аутентификатора со сдвигом на offset шагов от текущего
+func (suite *TwoFactorTestSuite) code(secret string, offset int64) string {
+	code, err := totp.Code(secret, totp.Step(time.Now())+offset)
+	suite.Require().NoError(err)
+	return code
+}
+
+// enroll включает 2FA пользователю и возвращает секрет и коды восстановления
+func (suite *TwoFactorTestSuite) enroll(email string) (string, []string) {
+	var tokens models.AuthResponse
+	suite.decode(suite.loginRequest(email, testPassword), &tokens)
+
+	var setup models.TwoFactorSetup
+	suite.decode(suite.request(http.MethodPost, "/api/auth/2fa/setup", tokens.AccessToken, nil), &setup)
+
+	var codes models.RecoveryCodesResponse
+	suite.decode(suite.request(http.MethodPost, "/api/auth/2fa/confirm", tokens.AccessToken, models.TwoFactorCodeRequest{Code: suite.code(setup.Secret, 0)}), &codes)
+	return setup.Secret, codes.RecoveryCodes
+}
+
+// TestEnrollment тестирует подключение приложения-аутентификатора
+func (suite *TwoFactorTestSuite) TestEnrollment() {
+	suite.register("barber@example.com", "barber")
+
+	var tokens models.AuthResponse
+	suite.decode(suite.loginRequest("barber@example.com", testPassword), &tokens)
+
+	// Подтверждение без начатого подключения невозможно
+	w := suite.request(http.MethodPost, "/api/auth/2fa/confirm", tokens.AccessToken, models.TwoFactorCodeRequest{Code: "123456"})
+	suite.Equal(http.StatusConflict, w.Code)
+
+	var setup models.TwoFactorSetup
+	suite.decode(suite.request(http.MethodPost, "/api/auth/2fa/setup", tokens.AccessToken, nil), &setup)
+	suite.Contains(setup.URI, "otpauth://totp/")
+	suite.Contains(setup.URI, "secret="+setup.Secret)
+
+	// Секрет хранится зашифрованным
+	var stored models.User
+	suite.db.DB.First(&stored, tokens.User.ID)
+	suite.NotEmpty(stored.TOTPSecret)
+	suite.NotContains(stored.TOTPSecret, setup.Secret)
+
+	w = suite.request(http.MethodPost, "/api/auth/2fa/confirm", tokens.AccessToken, models.TwoFactorCodeRequest{Code: "000000"})
+	suite.Equal(http.StatusBadRequest, w.Code)
+
+	var codes models.RecoveryCodesResponse
+	suite.decode(suite.request(http.MethodPost, "/api/auth/2fa/confirm", tokens.AccessToken, models.TwoFactorCodeRequest{Code: suite.code(setup.Secret, 0)}), &codes)
+	suite.Len(codes.RecoveryCodes, 10)
+
+	// Коды восстановления хранятся только в виде хешей
+	var hashes []string
+	suite.db.DB.Model(&models.RecoveryCode{}).Pluck("code_hash", &hashes)
+	suite.Len(hashes, 10)
+	suite.NotContains(hashes, codes.RecoveryCodes[0])
+
+	var status models.TwoFactorStatus
+	suite.decode(suite.request(http.MethodGet, "/api/auth/2fa", tokens.AccessToken, nil), &status)
+	suite.True(status.Enabled)
+	suite.False(status.Required)
+	suite.Equal(int64(10), status.RecoveryCodesCount)
+
+	w = suite.request(http.MethodPost, "/api/auth/2fa/setup", tokens.AccessToken, nil)
+	suite.Equal(http.StatusConflict, w.Code)
+}
+
+// TestTwoStepLogin тестирует вход с кодом из приложения и кодом восстановления
+func (suite *TwoFactorTestSuite) TestTwoStepLogin() {
+	suite.register("barber@example.com", "barber")
+	secret, recoveryCodes := suite.enroll("barber@example.com")
+
+	// Вместо токенов выдается токен второго шага
+	w := suite.loginRequest("barber@example.com", testPassword)
+	suite.Require().Equal(http.StatusOK, w.Code)
+	suite.NotContains(w.Body.String(), "access_token")
+	var challenge models.MFAChallengeResponse
+	suite.decode(w, &challenge)
+	suite.True(challenge.MFARequired)
+	suite.False(challenge.EnrollmentRequired)
+	suite.Equal(300, challenge.ExpiresIn)
+
+	w = suite.request(http.MethodPost, "/api/auth/login/2fa", "", models.MFAVerifyRequest{ChallengeToken: challenge.ChallengeToken, Code: "000000"})
+	suite.Equal(http.StatusUnauthorized, w.Code)
+	w = suite.request(http.MethodPost, "/api/auth/login/2fa", "", models.MFAVerifyRequest{ChallengeToken: challenge.ChallengeToken + "x", Code: suite.code(secret, 1)})
+	suite.Equal(http.StatusUnauthorized, w.Code)
+
+	// Код для шага, уже использованного при подключении, не принимается; следующий - принимается
+	w = suite.request(http.MethodPost, "/api/auth/login/2fa", "", models.MFAVerifyRequest{ChallengeToken: challenge.ChallengeToken, Code: suite.code(secret, -1)})
+	suite.Equal(http.StatusUnauthorized, w.Code)
+
+	code := suite.code(secret, 1)
+	var tokens models.AuthResponse
+	suite.decode(suite.request(http.MethodPost, "/api/auth/login/2fa", "", models.MFAVerifyRequest{ChallengeToken: challenge.ChallengeToken, Code: code}), &tokens)
+	suite.NotEmpty(tokens.AccessToken)
+
+	// Токен второго шага одноразовый, даже с другим верным кодом
+	w = suite.request(http.MethodPost, "/api/auth/login/2fa", "", models.MFAVerifyRequest{ChallengeToken: challenge.ChallengeToken, Code: recoveryCodes[0]})
+	suite.Equal(http.StatusUnauthorized, w.Code)
+
+	// Повторное использование того же кода отклоняется и с новым токеном
+	suite.decode(suite.loginRequest("barber@example.com", testPassword), &challenge)
+	w = suite.request(http.MethodPost, "/api/auth/login/2fa", "", models.MFAVerifyRequest{ChallengeToken: challenge.ChallengeToken, Code: code})
+	suite.Equal(http.StatusUnauthorized, w.Code)
+
+	// Код восстановления одноразовый
+	suite.decode(suite.request(http.MethodPost, "/api/auth/login/2fa", "", models.MFAVerifyRequest{ChallengeToken: challenge.ChallengeToken, Code: recoveryCodes[0]}), &tokens)
+	suite.decode(suite.loginRequest("barber@example.com", testPassword), &challenge)
+	w = suite.request(http.MethodPost, "/api/auth/login/2fa", "", models.MFAVerifyRequest{ChallengeToken: challenge.ChallengeToken, Code: recoveryCodes[0]})
+	suite.Equal(http.StatusUnauthorized, w.Code)
+
+	var status models.TwoFactorStatus
+	suite.decode(suite.request(http.MethodGet, "/api/auth/2fa", tokens.AccessToken, nil), &status)
+	suite.Equal(int64(9), status.RecoveryCodesCount)
+
+	// Токен второго шага входа не подходит для подключения 2FA
+	w = suite.request(http.MethodPost, "/api/auth/login/2fa/setup", "", models.MFAChallengeRequest{ChallengeToken: challenge.ChallengeToken})
+	suite.Equal(http.StatusUnauthorized, w.Code)
+}
+
+// TestCodeAttemptsSurvivePasswordLogin тестирует, что верный пароль не сбрасывает счетчик неверных кодов второго шага
+func (suite *TwoFactorTestSuite) TestCodeAttemptsSurvivePasswordLogin() {
+	suite.register("guess@example.com", "client")
+	secret, _ := suite.enroll("guess@example.com")
+
+	var challenge models.MFAChallengeResponse
+	// Бесплатные попытки и первая попытка с задержкой
+	for i := 0; i <= services.DefaultEmailLoginPolicy.FreeAttempts; i++ {
+		// Каждый раз новый токен второго шага после верного пароля
+		suite.decode(suite.loginRequest("guess@example.com", testPassword), &challenge)
+		w := suite.request(http.MethodPost, "/api/auth/login/2fa", "", models.MFAVerifyRequest{ChallengeToken: challenge.ChallengeToken, Code: "000000"})
+		suite.Equal(http.StatusUnauthorized, w.Code)
+	}
+
+	suite.decode(suite.loginRequest("guess@example.com", testPassword), &challenge)
+	w := suite.request(http.MethodPost, "/api/auth/login/2fa", "", models.MFAVerifyRequest{ChallengeToken: challenge.ChallengeToken, Code: suite.code(secret, 1)})
+	suite.Equal(http.StatusTooManyRequests, w.Code)
+	suite.NotEmpty(w.Header().Get("Retry-After"))
+}
+
+// TestDisableAndRegenerate тестирует новые коды восстановления и отключение 2FA
+func (suite *TwoFactorTestSuite) TestDisableAndRegenerate() {
+	suite.register("client@example.com", "client")
+	_, recoveryCodes := suite.enroll("client@example.com")
+
+	var challenge models.MFAChallengeResponse
+	suite.decode(suite.loginRequest("client@example.com", testPassword), &challenge)
+	var tokens models.AuthResponse
+	suite.decode(suite.request(http.MethodPost, "/api/auth/login/2fa", "", models.MFAVerifyRequest{ChallengeToken: challenge.ChallengeToken, Code: recoveryCodes[0]}), &tokens)
+
+	// Новые коды заменяют прежние
+	var regenerated models.RecoveryCodesResponse
+	suite.decode(suite.request(http.MethodPost, "/api/auth/2fa/recovery-codes", tokens.AccessToken, models.TwoFactorCodeRequest{Code: recoveryCodes[1]}), &regenerated)
+	suite.Len(regenerated.RecoveryCodes, 10)
+	w := suite.request(http.MethodPost, "/api/auth/2fa/disable", tokens.AccessToken, models.TwoFactorCodeRequest{Code: recoveryCodes[2]})
+	suite.Equal(http.StatusBadRequest, w.Code)
+
+	w = suite.request(http.MethodPost, "/api/auth/2fa/disable", tokens.AccessToken, models.TwoFactorCodeRequest{Code: regenerated.RecoveryCodes[0]})
+	suite.Equal(http.StatusOK, w.Code, w.Body.String())
+
+	// После отключения токены выдаются сразу
+	suite.decode(suite.loginRequest("client@example.com", testPassword), &tokens)
+	suite.NotEmpty(tokens.AccessToken)
+
+	var count int64
+	suite.db.DB.Model(&models.RecoveryCode{}).Count(&count)
+	suite.Zero(count)
+	suite.db.DB.Model(&models.AuditEvent{}).Where("action IN ?", []string{models.AuditActionTwoFactorEnable, models.AuditActionTwoFactorDisable}).Count(&count)
+	suite.Equal(int64(2), count)
+}
+
+// TestAdminPolicy тестирует обязательную 2FA для администратора
+func (suite *TwoFactorTestSuite) TestAdminPolicy() {
+	admin := suite.register("admin@example.com", "admin")
+
+	// Сессия, открытая до включения политики, не продлевается
+	oldSession, err := suite.authService.CreateSession(admin, models.DeviceInfo{})
+	suite.Require().NoError(err)
+	w := suite.request(http.MethodPost, "/api/auth/refresh", "", models.RefreshTokenRequest{RefreshToken: oldSession.RefreshToken})
+	suite.Equal(http.StatusForbidden, w.Code)
+
+	// Вход требует сначала подключить 2FA
+	var challenge models.MFAChallengeResponse
+	suite.decode(suite.loginRequest("admin@example.com", testPassword), &challenge)
+	suite.True(challenge.MFARequired)
+	suite.True(challenge.EnrollmentRequired)
+
+	w = suite.request(http.MethodPost, "/api/auth/login/2fa", "", models.MFAVerifyRequest{ChallengeToken: challenge.ChallengeToken, Code: "123456"})
+	suite.Equal(http.StatusUnauthorized, w.Code)
+
+	var setup models.TwoFactorSetup
+	suite.decode(suite.request(http.MethodPost, "/api/auth/login/2fa/setup", "", models.MFAChallengeRequest{ChallengeToken: challenge.ChallengeToken}), &setup)
+
+	var enrollment models.MFAEnrollmentResponse
+	suite.decode(suite.request(http.MethodPost, "/api/auth/login/2fa/confirm", "", models.MFAVerifyRequest{ChallengeToken: challenge.ChallengeToken, Code: suite.code(setup.Secret, 0)}), &enrollment)
+	suite.NotEmpty(enrollment.AccessToken)
+	suite.Len(enrollment.RecoveryCodes, 10)
+
+	// Отключить обязательную 2FA нельзя
+	var status models.TwoFactorStatus
+	suite.decode(suite.request(http.MethodGet, "/api/auth/2fa", enrollment.AccessToken, nil), &status)
+	suite.True(status.Enabled)
+	suite.True(status.Required)
+	w = suite.request(http.MethodPost, "/api/auth/2fa/disable", enrollment.AccessToken, models.TwoFactorCodeRequest{Code: enrollment.RecoveryCodes[0]})
+	suite.Equal(http.StatusForbidden, w.Code)
+
+	// Следующий вход проходит через обычный второй шаг
+	var next models.MFAChallengeResponse
+	suite.decode(suite.loginRequest("admin@example.com", testPassword), &next)
+	suite.False(next.EnrollmentRequired)
+	var tokens models.AuthResponse
+	suite.decode(suite.request(http.MethodPost, "/api/auth/login/2fa", "", models.MFAVerifyRequest{ChallengeToken: next.ChallengeToken, Code: suite.code(setup.Secret, 1)}), &tokens)
+	suite.NotEmpty(tokens.AccessToken)
+}
+
+// TestTwoFactorTestSuite запускает набор тестов
+func TestTwoFactorTestSuite(t *testing.T) {
+	suite.Run(t, new(TwoFactorTestSuite))
+}
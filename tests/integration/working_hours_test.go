@@ -0,0 +1,226 @@
+package integration
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"garage-barbershop/internal/database"
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/repositories"
+	"garage-barbershop/internal/services"
+
+	"github.com/stretchr/testify/suite"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+// WorkingHoursTestSuite набор тестов для управления рабочими часами
+type WorkingHoursTestSuite struct {
+	suite.Suite
+	db                  *database.Database
+	userRepo            repositories.UserRepository
+	roleRepo            repositories.RoleRepository
+	workingHoursRepo    repositories.WorkingHoursRepository
+	workingHoursService services.WorkingHoursService
+}
+
+// SetupSuite инициализирует тестовую среду
+func (suite *WorkingHoursTestSuite) SetupSuite() {
+	db, err := gorm.Open(sqlite.Open("file:working_hours?mode=memory&cache=shared"), &gorm.Config{})
+	suite.Require().NoError(err)
+
+	suite.db = &database.Database{DB: db}
+	err = suite.db.Migrate(&models.User{}, &models.Role{}, &models.UserRole{}, &models.WorkingHours{})
+	suite.Require().NoError(err)
+
+	suite.userRepo = repositories.NewUserRepository(db)
+	suite.roleRepo = repositories.NewRoleRepository(db)
+	suite.workingHoursRepo = repositories.NewWorkingHoursRepository(db)
+	suite.workingHoursService = services.NewWorkingHoursService(suite.workingHoursRepo, suite.roleRepo)
+}
+
+// TearDownSuite очищает тестовую среду
+func (suite *WorkingHoursTestSuite) TearDownSuite() {
+	sqlDB, err := suite.db.DB.DB()
+	suite.Require().NoError(err)
+	sqlDB.Close()
+}
+
+// SetupTest очищает данные перед каждым тестом
+func (suite *WorkingHoursTestSuite) SetupTest() {
+	suite.db.DB.Exec("DELETE FROM working_hours")
+	suite.db.DB.Exec("DELETE FROM user_roles")
+	suite.db.DB.Exec("DELETE FROM users")
+}
+
+// createBarber создает пользователя с ролью барбера
+func (suite *WorkingHoursTestSuite) createBarber(telegramID int64, email string) *models.User {
+	barber := &models.User{TelegramID: telegramID, Email: email, FirstName: "Barber", IsActive: true}
+	suite.Require().NoError(suite.userRepo.Create(barber))
+
+	role, err := suite.roleRepo.GetRoleByName("barber")
+	suite.Require().NoError(err)
+	suite.Require().NoError(suite.roleRepo.AssignRoleToUser(barber.ID, role.ID, barber.ID))
+
+	return barber
+}
+
+// TestReplaceWeek_StoresNormalizedSchedule тестирует сохранение недели и нормализацию времени
+func (suite *WorkingHoursTestSuite) TestReplaceWeek_StoresNormalizedSchedule() {
+	barber := suite.createBarber(1, "barber@example.com")
+
+	schedule, err := suite.workingHoursService.ReplaceWeek(barber.ID, models.WeeklyScheduleRequest{Days: []models.WorkingHoursRequest{
+		{DayOfWeek: 1, StartTime: "9:00", EndTime: "18:00", BreakStart: "13:00", BreakEnd: "14:00"},
+		{DayOfWeek: 6, StartTime: "10:00", EndTime: "16:00"},
+	}})
+
+	suite.Require().NoError(err)
+	suite.Require().Len(schedule, 2)
+	suite.Equal(1, schedule[0].DayOfWeek)
+	suite.Equal("09:00", schedule[0].StartTime)
+	suite.Equal("13:00", schedule[0].BreakStart)
+	suite.True(schedule[0].IsActive)
+	suite.Equal(6, schedule[1].DayOfWeek)
+	suite.False(schedule[1].HasBreak())
+}
+
+// TestReplaceWeek_IsAtomic тестирует, что неверный день не меняет сохраненное расписание
+func (suite *WorkingHoursTestSuite) TestReplaceWeek_IsAtomic() {
+	barber := suite.createBarber(1, "barber@example.com")
+	_, err := suite.workingHoursService.ReplaceWeek(barber.ID, models.WeeklyScheduleRequest{Days: []models.WorkingHoursRequest{
+		{DayOfWeek: 1, StartTime: "09:00", EndTime: "18:00"},
+	}})
+	suite.Require().NoError(err)
+
+	_, err = suite.workingHoursService.ReplaceWeek(barber.ID, models.WeeklyScheduleRequest{Days: []models.WorkingHoursRequest{
+		{DayOfWeek: 2, StartTime: "09:00", EndTime: "18:00"},
+		{DayOfWeek: 3, StartTime: "18:00", EndTime: "09:00"},
+	}})
+	suite.Error(err)
+
+	schedule, err := suite.workingHoursRepo.GetByBarberID(barber.ID)
+	suite.Require().NoError(err)
+	suite.Require().Len(schedule, 1)
+	suite.Equal(1, schedule[0].DayOfWeek)
+}
+
+// TestReplaceWeek_Validation тестирует проверки расписания
+func (suite *WorkingHoursTestSuite) TestReplaceWeek_Validation() {
+	barber := suite.createBarber(1, "barber@example.com")
+
+	cases := map[string][]models.WorkingHoursRequest{
+		"повтор дня":           {{DayOfWeek: 1, StartTime: "09:00", EndTime: "12:00"}, {DayOfWeek: 1, StartTime: "13:00", EndTime: "18:00"}},
+		"неверный день":        {{DayOfWeek: 8, StartTime: "09:00", EndTime: "18:00"}},
+		"неверный формат":      {{DayOfWeek: 1, StartTime: "9am", EndTime: "18:00"}},
+		"начало после конца":   {{DayOfWeek: 1, StartTime: "18:00", EndTime: "18:00"}},
+		"перерыв без конца":    {{DayOfWeek: 1, StartTime: "09:00", EndTime: "18:00", BreakStart: "13:00"}},
+		"перерыв вне работы":   {{DayOfWeek: 1, StartTime: "09:00", EndTime: "18:00", BreakStart: "17:30", BreakEnd: "19:00"}},
+		"перерыв наоборот":     {{DayOfWeek: 1, StartTime: "09:00", EndTime: "18:00", BreakStart: "14:00", BreakEnd: "13:00"}},
+		"перерыв с начала дня": {{DayOfWeek: 1, StartTime: "09:00", EndTime: "18:00", BreakStart: "09:00", BreakEnd: "10:00"}},
+	}
+
+	for name, days := range cases {
+		_, err := suite.workingHoursService.ReplaceWeek(barber.ID, models.WeeklyScheduleRequest{Days: days})
+		suite.Error(err, name)
+	}
+}
+
+// TestSetDay_UpdatesExistingDay тестирует, что день недели остается уникальным
+func (suite *WorkingHoursTestSuite) TestSetDay_UpdatesExistingDay() {
+	barber := suite.createBarber(1, "barber@example.com")
+
+	_, err := suite.workingHoursService.SetDay(barber.ID, models.WorkingHoursRequest{DayOfWeek: 2, StartTime: "09:00", EndTime: "18:00"})
+	suite.Require().NoError(err)
+
+	inactive := false
+	_, err = suite.workingHoursService.SetDay(barber.ID, models.WorkingHoursRequest{DayOfWeek: 2, StartTime: "10:00", EndTime: "15:00", IsActive: &inactive})
+	suite.Require().NoError(err)
+
+	schedule, err := suite.workingHoursRepo.GetByBarberID(barber.ID)
+	suite.Require().NoError(err)
+	suite.Require().Len(schedule, 1)
+	suite.Equal("10:00", schedule[0].StartTime)
+	suite.False(schedule[0].IsActive)
+}
+
+// TestSaveDay_ConcurrentSaves тестирует, что параллельные сохранения одного дня не создают дублей
+func (suite *WorkingHoursTestSuite) TestSaveDay_ConcurrentSaves() {
+	barber := suite.createBarber(1, "barber@example.com")
+
+	first := &models.WorkingHours{BarberID: barber.ID, DayOfWeek: 3, StartTime: "09:00", EndTime: "18:00", IsActive: true}
+	suite.Require().NoError(suite.workingHoursRepo.SaveDay(first))
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(hour int) {
+			defer wg.Done()
+			errs <- suite.workingHoursRepo.SaveDay(&models.WorkingHours{
+				BarberID: barber.ID, DayOfWeek: 3, StartTime: fmt.Sprintf("%02d:00", hour), EndTime: "20:00", IsActive: true,
+			})
+		}(8 + i%3)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		suite.NoError(err)
+	}
+
+	schedule, err := suite.workingHoursRepo.GetByBarberID(barber.ID)
+	suite.Require().NoError(err)
+	suite.Require().Len(schedule, 1)
+	suite.Equal(first.ID, schedule[0].ID)
+	suite.Equal("20:00", schedule[0].EndTime)
+}
+
+// TestLegacyDuplicateDays тестирует, что миграция оставляет последнюю строку дня перед созданием уникального индекса
+func (suite *WorkingHoursTestSuite) TestLegacyDuplicateDays() {
+	db, err := gorm.Open(sqlite.Open("file:working_hours_legacy?mode=memory&cache=shared"), &gorm.Config{})
+	suite.Require().NoError(err)
+	legacy := &database.Database{DB: db}
+	defer func() {
+		sqlDB, _ := db.DB()
+		sqlDB.Close()
+	}()
+
+	suite.Require().NoError(db.Exec("CREATE TABLE working_hours (id integer PRIMARY KEY AUTOINCREMENT, created_at datetime, updated_at datetime, deleted_at datetime, " +
+		"day_of_week integer, start_time text, end_time text, break_start text, break_end text, is_active numeric, barber_id integer)").Error)
+	for _, row := range []string{"09:00", "10:00", "11:00"} {
+		suite.Require().NoError(db.Exec("INSERT INTO working_hours (day_of_week, start_time, end_time, is_active, barber_id) VALUES (1, ?, '18:00', 1, 7)", row).Error)
+	}
+	suite.Require().NoError(db.Exec("INSERT INTO working_hours (day_of_week, start_time, end_time, is_active, barber_id) VALUES (2, '09:00', '18:00', 1, 7)").Error)
+	suite.Require().NoError(legacy.Migrate(&models.WorkingHours{}))
+
+	var schedule []models.WorkingHours
+	suite.Require().NoError(db.Order("day_of_week").Find(&schedule).Error)
+	suite.Require().Len(schedule, 2)
+	suite.Equal("11:00", schedule[0].StartTime)
+	suite.Error(db.Exec("INSERT INTO working_hours (day_of_week, start_time, end_time, is_active, barber_id) VALUES (2, '10:00', '18:00', 1, 7)").Error)
+}
+
+// TestDeleteDay тестирует удаление дня из расписания
+func (suite *WorkingHoursTestSuite) TestDeleteDay() {
+	barber := suite.createBarber(1, "barber@example.com")
+	_, err := suite.workingHoursService.SetDay(barber.ID, models.WorkingHoursRequest{DayOfWeek: 5, StartTime: "09:00", EndTime: "18:00"})
+	suite.Require().NoError(err)
+
+	suite.NoError(suite.workingHoursService.DeleteDay(barber.ID, 5))
+	suite.ErrorIs(suite.workingHoursService.DeleteDay(barber.ID, 5), services.ErrWorkingHoursNotFound)
+}
+
+// TestSchedule_RequiresBarberRole тестирует, что расписание есть только у барберов
+func (suite *WorkingHoursTestSuite) TestSchedule_RequiresBarberRole() {
+	client := &models.User{TelegramID: 100, Email: "client@example.com", IsActive: true}
+	suite.Require().NoError(suite.userRepo.Create(client))
+
+	_, err := suite.workingHoursService.SetDay(client.ID, models.WorkingHoursRequest{DayOfWeek: 1, StartTime: "09:00", EndTime: "18:00"})
+
+	suite.ErrorIs(err, services.ErrNotBarber)
+}
+
+// TestWorkingHoursTestSuite запускает набор тестов
+func TestWorkingHoursTestSuite(t *testing.T) {
+	suite.Run(t, new(WorkingHoursTestSuite))
+}
@@ -0,0 +1,187 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newTestAppointmentService создает сервис записей с моками
+func newTestAppointmentService() (services.AppointmentService, *MockAppointmentRepository, *MockServiceRepository, *MockUserRepository) {
+	appointmentRepo := new(MockAppointmentRepository)
+	serviceRepo := new(MockServiceRepository)
+	userRepo := new(MockUserRepository)
+	workingHoursRepo := new(MockWorkingHoursRepository)
+	workingHoursRepo.On("GetActiveByBarberID", mock.Anything).Return(everyDay("09:00", "21:00"), nil).Maybe()
+	exceptionRepo := new(MockScheduleExceptionRepository)
+	exceptionRepo.On("GetByBarberInRange", mock.Anything, mock.Anything, mock.Anything).Return([]models.ScheduleException{}, nil).Maybe()
+	return services.NewAppointmentService(appointmentRepo, serviceRepo, userRepo, workingHoursRepo, exceptionRepo, newFixedTimezoneService(time.Local)), appointmentRepo, serviceRepo, userRepo
+}
+
+// everyDay возвращает одинаковые рабочие часы на всю неделю
+func everyDay(start, end string) []models.WorkingHours {
+	week := make([]models.WorkingHours, 0, 7)
+	for day := 1; day <= 7; day++ {
+		week = append(week, models.WorkingHours{DayOfWeek: day, StartTime: start, EndTime: end, IsActive: true})
+	}
+	return week
+}
+
+// tomorrowAt возвращает время завтрашнего дня в местном часовом поясе
+func tomorrowAt(hour, minute int) time.Time {
+	tomorrow := time.Now().AddDate(0, 0, 1)
+	return time.Date(tomorrow.Year(), tomorrow.Month(), tomorrow.Day(), hour, minute, 0, 0, time.Local)
+}
+
+func TestAppointmentService_BookAppointment_CopiesPriceAndDuration(t *testing.T) {
+	// Arrange
+	appointmentService, appointmentRepo, serviceRepo, userRepo := newTestAppointmentService()
+
+	service := &models.Service{ID: 10, Name: "Стрижка", Price: 1500, Duration: 45, IsActive: true, BarberID: 2}
+	serviceRepo.On("GetByID", uint(10)).Return(service, nil)
+	userRepo.On("GetByID", uint(2)).Return(&models.User{ID: 2, IsActive: true}, nil)
+	appointmentRepo.On("CreateIfAvailable", mock.AnythingOfType("*models.Appointment")).Return(nil)
+
+	dateTime := tomorrowAt(12, 0)
+
+	// Act
+	appointment, err := appointmentService.BookAppointment(1, models.AppointmentCreateRequest{
+		ServiceID: 10,
+		DateTime:  dateTime,
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1500.0, appointment.Price)
+	assert.Equal(t, 45, appointment.Duration)
+	assert.Equal(t, uint(2), appointment.BarberID)
+	assert.Equal(t, models.AppointmentStatusPending, appointment.Status)
+	assert.Equal(t, models.PaymentStatusPending, appointment.PaymentStatus)
+	appointmentRepo.AssertExpectations(t)
+}
+
+func TestAppointmentService_BookAppointment_PastTime(t *testing.T) {
+	// Arrange
+	appointmentService, appointmentRepo, _, _ := newTestAppointmentService()
+
+	// Act
+	_, err := appointmentService.BookAppointment(1, models.AppointmentCreateRequest{
+		ServiceID: 10,
+		DateTime:  time.Now().Add(-time.Hour),
+	})
+
+	// Assert
+	assert.Error(t, err)
+	appointmentRepo.AssertNotCalled(t, "CreateIfAvailable", mock.Anything)
+}
+
+func TestAppointmentService_BookAppointment_WrongBarber(t *testing.T) {
+	// Arrange
+	appointmentService, appointmentRepo, serviceRepo, _ := newTestAppointmentService()
+
+	service := &models.Service{ID: 10, Price: 1500, Duration: 45, IsActive: true, BarberID: 2}
+	serviceRepo.On("GetByID", uint(10)).Return(service, nil)
+
+	// Act
+	_, err := appointmentService.BookAppointment(1, models.AppointmentCreateRequest{
+		BarberID:  3,
+		ServiceID: 10,
+		DateTime:  time.Now().Add(time.Hour),
+	})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "не принадлежит")
+	appointmentRepo.AssertNotCalled(t, "CreateIfAvailable", mock.Anything)
+}
+
+func TestAppointmentService_BookAppointment_InactiveService(t *testing.T) {
+	// Arrange
+	appointmentService, _, serviceRepo, _ := newTestAppointmentService()
+
+	service := &models.Service{ID: 10, IsActive: false, BarberID: 2}
+	serviceRepo.On("GetByID", uint(10)).Return(service, nil)
+
+	// Act
+	_, err := appointmentService.BookAppointment(1, models.AppointmentCreateRequest{
+		ServiceID: 10,
+		DateTime:  time.Now().Add(time.Hour),
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, services.ErrServiceNotFound)
+}
+
+func TestAppointmentService_Lifecycle(t *testing.T) {
+	// Arrange
+	appointmentService, appointmentRepo, _, _ := newTestAppointmentService()
+
+	appointment := &models.Appointment{
+		ID:       5,
+		ClientID: 1,
+		BarberID: 2,
+		Status:   models.AppointmentStatusPending,
+		DateTime: time.Now().Add(-time.Minute), // запись уже началась
+	}
+	appointmentRepo.On("GetByID", uint(5)).Return(appointment, nil)
+	appointmentRepo.On("Update", appointment).Return(nil)
+
+	// Act & Assert - нельзя завершить неподтвержденную запись
+	_, err := appointmentService.CompleteAppointment(2, 5)
+	assert.ErrorIs(t, err, services.ErrInvalidStatusTransition)
+
+	// pending → confirmed
+	result, err := appointmentService.ConfirmAppointment(2, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, models.AppointmentStatusConfirmed, result.Status)
+
+	// confirmed → completed
+	result, err = appointmentService.CompleteAppointment(2, 5)
+	assert.NoError(t, err)
+	assert.Equal(t, models.AppointmentStatusCompleted, result.Status)
+
+	// Из конечного статуса переходов нет
+	_, err = appointmentService.CancelByClient(1, 5)
+	assert.ErrorIs(t, err, services.ErrInvalidStatusTransition)
+}
+
+func TestAppointmentService_CompleteBeforeStart(t *testing.T) {
+	// Arrange
+	appointmentService, appointmentRepo, _, _ := newTestAppointmentService()
+
+	appointment := &models.Appointment{
+		ID:       5,
+		BarberID: 2,
+		Status:   models.AppointmentStatusConfirmed,
+		DateTime: time.Now().Add(time.Hour),
+	}
+	appointmentRepo.On("GetByID", uint(5)).Return(appointment, nil)
+
+	// Act
+	_, err := appointmentService.MarkNoShow(2, 5)
+
+	// Assert
+	assert.ErrorIs(t, err, services.ErrInvalidStatusTransition)
+	appointmentRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestAppointmentService_ForeignAppointment(t *testing.T) {
+	// Arrange
+	appointmentService, appointmentRepo, _, _ := newTestAppointmentService()
+
+	appointment := &models.Appointment{ID: 5, ClientID: 1, BarberID: 2, Status: models.AppointmentStatusPending}
+	appointmentRepo.On("GetByID", uint(5)).Return(appointment, nil)
+
+	// Act
+	_, cancelErr := appointmentService.CancelByClient(99, 5)
+	_, confirmErr := appointmentService.ConfirmAppointment(99, 5)
+
+	// Assert
+	assert.ErrorIs(t, cancelErr, services.ErrAppointmentNotFound)
+	assert.ErrorIs(t, confirmErr, services.ErrAppointmentNotFound)
+}
@@ -1,34 +1,186 @@
 package unit
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/services"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-// TestAuthService_ValidateTelegramAuth тестирует валидацию Telegram аутентификации
+// loginWidgetBotToken токен бота, которым подписаны данные Login Widget в тестах
+const loginWidgetBotToken = "123456789:TEST_login_widget_token"
+
+// newLoginWidgetAuthService создает сервис аутентификации с токеном бота; репозитории для проверки подписи не нужны
+func newLoginWidgetAuthService(botToken string) services.AuthService {
+	return services.NewAuthService(nil, nil, nil, nil, "test_secret", botToken, 0, nil)
+}
+
+// signLoginWidget подписывает поля по алгоритму Telegram Login Widget: HMAC-SHA256 на ключе SHA256(токен)
+func signLoginWidget(fields models.TelegramLoginData, botToken string) models.TelegramLoginData {
+	lines := make([]string, 0, len(fields))
+	for name, value := range fields {
+		lines = append(lines, name+"="+value)
+	}
+	sort.Strings(lines)
+
+	key := sha256.Sum256([]byte(botToken))
+	mac := hmac.New(sha256.New, key[:])
+	mac.Write([]byte(strings.Join(lines, "\n")))
+
+	signed := models.TelegramLoginData{"hash": hex.EncodeToString(mac.Sum(nil))}
+	for name, value := range fields {
+		signed[name] = value
+	}
+	return signed
+}
+
+// freshLoginWidgetFields поля Login Widget с текущим auth_date, включая photo_url
+func freshLoginWidgetFields() models.TelegramLoginData {
+	return models.TelegramLoginData{
+		"id":         "279058397",
+		"first_name": "Ivan",
+		"last_name":  "Petrov",
+		"username":   "ivan_petrov",
+		"photo_url":  "https://t.me/i/userpic/320/ivan_petrov.jpg",
+		"auth_date":  strconv.FormatInt(time.Now().Unix(), 10),
+	}
+}
+
+// TestAuthService_ValidateTelegramAuth тестирует проверку подписанных данных Login Widget
 func TestAuthService_ValidateTelegramAuth(t *testing.T) {
-	// Arrange
-	authData := models.TelegramAuthData{
-		ID:        12345,
-		Username:  "testuser",
-		FirstName: "John",
-		LastName:  "Doe",
-		AuthDate:  time.Now().Unix(),
-		Hash:      "test_hash", // В реальном тесте нужно правильную подпись
+	authService := newLoginWidgetAuthService(loginWidgetBotToken)
+
+	authData, err := authService.ValidateTelegramAuth(signLoginWidget(freshLoginWidgetFields(), loginWidgetBotToken))
+	require.NoError(t, err)
+	assert.Equal(t, int64(279058397), authData.ID)
+	assert.Equal(t, "ivan_petrov", authData.Username)
+	assert.Equal(t, "Ivan", authData.FirstName)
+	assert.Equal(t, "Petrov", authData.LastName)
+
+	// Подпись учитывает все переданные поля, в том числе неизвестные заранее
+	fields := freshLoginWidgetFields()
+	fields["allows_write_to_pm"] = "true"
+	_, err = authService.ValidateTelegramAuth(signLoginWidget(fields, loginWidgetBotToken))
+	assert.NoError(t, err)
+
+	// Без необязательных полей
+	fields = freshLoginWidgetFields()
+	delete(fields, "photo_url")
+	delete(fields, "last_name")
+	delete(fields, "username")
+	_, err = authService.ValidateTelegramAuth(signLoginWidget(fields, loginWidgetBotToken))
+	assert.NoError(t, err)
+}
+
+// TestAuthService_ValidateTelegramAuth_SamplePayload тестирует образец, подписанный независимо от кода сервиса.
+// auth_date образца давно истек, поэтому верная подпись дает ErrTelegramAuthExpired, а неверная - ErrInvalidTelegramAuth
+func TestAuthService_ValidateTelegramAuth_SamplePayload(t *testing.T) {
+	authService := newLoginWidgetAuthService(loginWidgetBotToken)
+	sample := func() models.TelegramLoginData {
+		var data models.TelegramLoginData
+		require.NoError(t, json.Unmarshal([]byte(`{
+			"id": 279058397,
+			"first_name": "Ivan",
+			"last_name": "Petrov",
+			"username": "ivan_petrov",
+			"photo_url": "https://t.me/i/userpic/320/ivan_petrov.jpg",
+			"auth_date": 1700000000,
+			"hash": "b6bf1744c0dbc710e6996b9b1ab47c3a2d8167dbfbeb8ee93bad6078b693f317"
+		}`), &data))
+		return data
 	}
 
-	// Act - тестируем только валидацию времени
-	// Проверяем, что время не старше 5 минут
-	if time.Now().Unix()-authData.AuthDate > 300 {
-		t.Error("Auth date is too old")
+	_, err := authService.ValidateTelegramAuth(sample())
+	assert.ErrorIs(t, err, services.ErrTelegramAuthExpired)
+
+	// Подпись в верхнем регистре тоже принимается
+	data := sample()
+	data["hash"] = strings.ToUpper(data["hash"])
+	_, err = authService.ValidateTelegramAuth(data)
+	assert.ErrorIs(t, err, services.ErrTelegramAuthExpired)
+
+	// photo_url входит в подпись
+	data = sample()
+	data["photo_url"] = "https://example.com/other.jpg"
+	_, err = authService.ValidateTelegramAuth(data)
+	assert.ErrorIs(t, err, services.ErrInvalidTelegramAuth)
+
+	// Поле, которого не было при подписи
+	data = sample()
+	data["is_admin"] = "true"
+	_, err = authService.ValidateTelegramAuth(data)
+	assert.ErrorIs(t, err, services.ErrInvalidTelegramAuth)
+
+	// Другой токен бота
+	_, err = newLoginWidgetAuthService("987654321:OTHER").ValidateTelegramAuth(sample())
+	assert.ErrorIs(t, err, services.ErrInvalidTelegramAuth)
+}
+
+// TestAuthService_ValidateTelegramAuth_Invalid тестирует отказ при неверной подписи и содержимом
+func TestAuthService_ValidateTelegramAuth_Invalid(t *testing.T) {
+	authService := newLoginWidgetAuthService(loginWidgetBotToken)
+
+	// Подпись с самим токеном в качестве ключа (прежняя реализация) недействительна
+	fields := freshLoginWidgetFields()
+	lines := make([]string, 0, len(fields))
+	for name, value := range fields {
+		lines = append(lines, name+"="+value)
 	}
+	sort.Strings(lines)
+	mac := hmac.New(sha256.New, []byte(loginWidgetBotToken))
+	mac.Write([]byte(strings.Join(lines, "\n")))
+	fields["hash"] = hex.EncodeToString(mac.Sum(nil))
+	_, err := authService.ValidateTelegramAuth(fields)
+	assert.ErrorIs(t, err, services.ErrInvalidTelegramAuth)
 
-	// Assert
-	assert.True(t, authData.AuthDate > 0)
+	// Без подписи и с подписью неверной длины
+	fields = freshLoginWidgetFields()
+	_, err = authService.ValidateTelegramAuth(fields)
+	assert.ErrorIs(t, err, services.ErrInvalidTelegramAuth)
+	fields["hash"] = "abcd"
+	_, err = authService.ValidateTelegramAuth(fields)
+	assert.ErrorIs(t, err, services.ErrInvalidTelegramAuth)
+
+	// Устаревшие и выданные в будущем данные
+	fields = freshLoginWidgetFields()
+	fields["auth_date"] = strconv.FormatInt(time.Now().Add(-10*time.Minute).Unix(), 10)
+	_, err = authService.ValidateTelegramAuth(signLoginWidget(fields, loginWidgetBotToken))
+	assert.ErrorIs(t, err, services.ErrTelegramAuthExpired)
+	fields["auth_date"] = strconv.FormatInt(time.Now().Add(10*time.Minute).Unix(), 10)
+	_, err = authService.ValidateTelegramAuth(signLoginWidget(fields, loginWidgetBotToken))
+	assert.ErrorIs(t, err, services.ErrInvalidTelegramAuth)
+
+	// Подписанные, но неверные данные пользователя
+	fields = freshLoginWidgetFields()
+	fields["id"] = "abc"
+	_, err = authService.ValidateTelegramAuth(signLoginWidget(fields, loginWidgetBotToken))
+	assert.ErrorIs(t, err, services.ErrInvalidTelegramAuth)
+
+	// Без токена бота в конфигурации вход невозможен
+	_, err = newLoginWidgetAuthService("").ValidateTelegramAuth(signLoginWidget(freshLoginWidgetFields(), ""))
+	assert.ErrorIs(t, err, services.ErrInvalidTelegramAuth)
+}
+
+// TestTelegramLoginData_UnmarshalJSON тестирует разбор данных виджета с числовыми полями
+func TestTelegramLoginData_UnmarshalJSON(t *testing.T) {
+	var data models.TelegramLoginData
+	require.NoError(t, json.Unmarshal([]byte(`{"id": 279058397, "auth_date": 1700000000, "first_name": "Ivan"}`), &data))
+	assert.Equal(t, "279058397", data["id"])
+	assert.Equal(t, "1700000000", data["auth_date"])
+	assert.Equal(t, "Ivan", data["first_name"])
+
+	assert.Error(t, json.Unmarshal([]byte(`{"id": {"nested": true}}`), &data))
 }
 
 // TestAuthService_AuthenticateUser_ExistingUser тестирует аутентификацию существующего пользователя
@@ -0,0 +1,154 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// nextMonday возвращает понедельник не раньше чем через неделю
+func nextMonday() time.Time {
+	day := time.Now().AddDate(0, 0, 7)
+	for day.Weekday() != time.Monday {
+		day = day.AddDate(0, 0, 1)
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// slotStarts возвращает время начала слотов в формате "HH:MM"
+func slotStarts(slots []models.TimeSlot) []string {
+	starts := make([]string, len(slots))
+	for i, slot := range slots {
+		starts[i] = slot.StartLocal.Format("15:04")
+	}
+	return starts
+}
+
+func newTestAvailabilityService() (services.AvailabilityService, *MockWorkingHoursRepository, *MockAppointmentRepository, *MockServiceRepository) {
+	availabilityService, workingHoursRepo, exceptionRepo, appointmentRepo, serviceRepo := newTestAvailabilityServiceWithExceptions()
+	exceptionRepo.On("GetByBarberInRange", mock.Anything, mock.Anything, mock.Anything).Return([]models.ScheduleException{}, nil).Maybe()
+	return availabilityService, workingHoursRepo, appointmentRepo, serviceRepo
+}
+
+func newTestAvailabilityServiceWithExceptions() (services.AvailabilityService, *MockWorkingHoursRepository, *MockScheduleExceptionRepository, *MockAppointmentRepository, *MockServiceRepository) {
+	workingHoursRepo := new(MockWorkingHoursRepository)
+	exceptionRepo := new(MockScheduleExceptionRepository)
+	appointmentRepo := new(MockAppointmentRepository)
+	serviceRepo := new(MockServiceRepository)
+	return services.NewAvailabilityService(workingHoursRepo, exceptionRepo, appointmentRepo, serviceRepo, newFixedTimezoneService(time.Local)), workingHoursRepo, exceptionRepo, appointmentRepo, serviceRepo
+}
+
+func TestAvailabilityService_ExcludesBreaksAndAppointments(t *testing.T) {
+	// Arrange
+	availabilityService, workingHoursRepo, appointmentRepo, serviceRepo := newTestAvailabilityService()
+	monday := nextMonday()
+
+	serviceRepo.On("GetByID", uint(10)).Return(&models.Service{ID: 10, Duration: 60, IsActive: true, BarberID: 2}, nil)
+	workingHoursRepo.On("GetActiveByBarberID", uint(2)).Return([]models.WorkingHours{
+		{DayOfWeek: 1, StartTime: "10:00", EndTime: "13:00", BreakStart: "11:00", BreakEnd: "11:30", IsActive: true},
+	}, nil)
+	appointmentRepo.On("GetOverlapping", uint(2), mock.Anything, mock.Anything).Return([]models.Appointment{
+		{DateTime: monday.Add(12*time.Hour + 30*time.Minute), Duration: 30, Status: models.AppointmentStatusConfirmed},
+	}, nil)
+
+	// Act - запрашиваем неделю, рабочий только понедельник
+	availability, err := availabilityService.GetAvailableSlots(2, 10, monday.Format(models.DateLayout), monday.AddDate(0, 0, 6).Format(models.DateLayout))
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10:00", "11:30"}, slotStarts(availability.Slots))
+	for _, slot := range availability.Slots {
+		assert.Equal(t, time.Hour, slot.End.Sub(slot.Start))
+	}
+}
+
+func TestAvailabilityService_ExcludesPastTimes(t *testing.T) {
+	// Arrange
+	availabilityService, workingHoursRepo, appointmentRepo, serviceRepo := newTestAvailabilityService()
+	today := time.Now()
+
+	serviceRepo.On("GetByID", uint(10)).Return(&models.Service{ID: 10, Duration: 30, IsActive: true, BarberID: 2}, nil)
+	workingHoursRepo.On("GetActiveByBarberID", uint(2)).Return([]models.WorkingHours{
+		{DayOfWeek: models.ISODayOfWeek(today), StartTime: "00:00", EndTime: "23:59", IsActive: true},
+	}, nil)
+	appointmentRepo.On("GetOverlapping", uint(2), mock.Anything, mock.Anything).Return([]models.Appointment{}, nil)
+
+	// Act
+	availability, err := availabilityService.GetAvailableSlots(2, 10, today.Format(models.DateLayout), today.Format(models.DateLayout))
+
+	// Assert
+	assert.NoError(t, err)
+	for _, slot := range availability.Slots {
+		assert.True(t, slot.Start.After(today))
+	}
+}
+
+func TestAvailabilityService_ServiceOfOtherBarber(t *testing.T) {
+	// Arrange
+	availabilityService, _, _, serviceRepo := newTestAvailabilityService()
+	serviceRepo.On("GetByID", uint(10)).Return(&models.Service{ID: 10, Duration: 30, IsActive: true, BarberID: 3}, nil)
+
+	// Act
+	_, err := availabilityService.GetAvailableSlots(2, 10, "", "")
+
+	// Assert
+	assert.ErrorIs(t, err, services.ErrServiceNotFound)
+}
+
+func TestAvailabilityService_RangeTooLong(t *testing.T) {
+	// Arrange
+	availabilityService, _, _, _ := newTestAvailabilityService()
+
+	// Act
+	_, err := availabilityService.GetAvailableSlots(2, 10, time.Now().Format(models.DateLayout), time.Now().AddDate(0, 2, 0).Format(models.DateLayout))
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestAvailabilityService_HonorsScheduleExceptions(t *testing.T) {
+	// Arrange
+	availabilityService, workingHoursRepo, exceptionRepo, appointmentRepo, serviceRepo := newTestAvailabilityServiceWithExceptions()
+	monday := nextMonday()
+	tuesday := monday.AddDate(0, 0, 1)
+	sunday := monday.AddDate(0, 0, 6)
+
+	serviceRepo.On("GetByID", uint(10)).Return(&models.Service{ID: 10, Duration: 60, IsActive: true, BarberID: 2}, nil)
+	workingHoursRepo.On("GetActiveByBarberID", uint(2)).Return([]models.WorkingHours{
+		{DayOfWeek: 1, StartTime: "10:00", EndTime: "13:00", IsActive: true},
+		{DayOfWeek: 2, StartTime: "10:00", EndTime: "13:00", IsActive: true},
+	}, nil)
+	exceptionRepo.On("GetByBarberInRange", uint(2), monday.Format(models.DateLayout), sunday.Format(models.DateLayout)).Return([]models.ScheduleException{
+		// Во вторник больничный на весь день
+		{Type: models.ScheduleExceptionTimeOff, StartDate: tuesday.Format(models.DateLayout), EndDate: tuesday.Format(models.DateLayout), IsFullDay: true},
+		// В понедельник отошел с 11 до 12
+		{Type: models.ScheduleExceptionTimeOff, StartDate: monday.Format(models.DateLayout), EndDate: monday.Format(models.DateLayout), StartTime: "11:00", EndTime: "12:00"},
+		// В воскресенье дополнительный рабочий день
+		{Type: models.ScheduleExceptionExtraWorkingDay, StartDate: sunday.Format(models.DateLayout), EndDate: sunday.Format(models.DateLayout), StartTime: "12:00", EndTime: "14:00"},
+	}, nil)
+	appointmentRepo.On("GetOverlapping", uint(2), mock.Anything, mock.Anything).Return([]models.Appointment{}, nil)
+
+	// Act
+	availability, err := availabilityService.GetAvailableSlots(2, 10, monday.Format(models.DateLayout), sunday.Format(models.DateLayout))
+
+	// Assert
+	assert.NoError(t, err)
+	var mondaySlots, sundaySlots []models.TimeSlot
+	for _, slot := range availability.Slots {
+		switch slot.StartLocal.Weekday() {
+		case time.Monday:
+			mondaySlots = append(mondaySlots, slot)
+		case time.Sunday:
+			sundaySlots = append(sundaySlots, slot)
+		default:
+			t.Errorf("неожиданный слот %v", slot.StartLocal)
+		}
+	}
+	assert.Equal(t, []string{"10:00", "12:00"}, slotStarts(mondaySlots))
+	assert.Equal(t, []string{"12:00", "12:15", "12:30", "12:45", "13:00"}, slotStarts(sundaySlots))
+}
@@ -1,6 +1,8 @@
 package unit
 
 import (
+	"time"
+
 	"garage-barbershop/internal/models"
 
 	"github.com/stretchr/testify/mock"
@@ -110,8 +112,8 @@ func (m *MockRoleRepository) AssignRoleToUser(userID, roleID, assignedBy uint) e
 	return args.Error(0)
 }
 
-func (m *MockRoleRepository) RemoveRoleFromUser(userID, roleID uint) error {
-	args := m.Called(userID, roleID)
+func (m *MockRoleRepository) RemoveRoleFromUser(userID, roleID uint, keepLast bool) error {
+	args := m.Called(userID, roleID, keepLast)
 	return args.Error(0)
 }
 
@@ -150,3 +152,197 @@ func (m *MockRoleRepository) GetAllUsersWithRoles() ([]models.UserWithRoles, err
 	args := m.Called()
 	return args.Get(0).([]models.UserWithRoles), args.Error(1)
 }
+
+func (m *MockRoleRepository) AssignRole(userRole *models.UserRole) error {
+	args := m.Called(userRole)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) RevokeRole(userID, roleID, revokedBy uint, reason string, keepLast bool) error {
+	args := m.Called(userID, roleID, revokedBy, reason, keepLast)
+	return args.Error(0)
+}
+
+// MockServiceRepository для тестирования
+type MockServiceRepository struct {
+	mock.Mock
+}
+
+func (m *MockServiceRepository) Create(service *models.Service) error {
+	args := m.Called(service)
+	return args.Error(0)
+}
+
+func (m *MockServiceRepository) GetByID(id uint) (*models.Service, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Service), args.Error(1)
+}
+
+func (m *MockServiceRepository) GetByIDUnscoped(id uint) (*models.Service, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Service), args.Error(1)
+}
+
+func (m *MockServiceRepository) Update(service *models.Service) error {
+	args := m.Called(service)
+	return args.Error(0)
+}
+
+func (m *MockServiceRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockServiceRepository) GetByBarberID(barberID uint) ([]models.Service, error) {
+	args := m.Called(barberID)
+	return args.Get(0).([]models.Service), args.Error(1)
+}
+
+func (m *MockServiceRepository) List(filter models.ServiceFilter) ([]models.Service, error) {
+	args := m.Called(filter)
+	return args.Get(0).([]models.Service), args.Error(1)
+}
+
+// MockAppointmentRepository для тестирования
+type MockAppointmentRepository struct {
+	mock.Mock
+}
+
+func (m *MockAppointmentRepository) Create(appointment *models.Appointment) error {
+	args := m.Called(appointment)
+	return args.Error(0)
+}
+
+func (m *MockAppointmentRepository) CreateIfAvailable(appointment *models.Appointment, check func(exceptions []models.ScheduleException) error) error {
+	args := m.Called(appointment)
+	return args.Error(0)
+}
+
+func (m *MockAppointmentRepository) GetByID(id uint) (*models.Appointment, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Appointment), args.Error(1)
+}
+
+func (m *MockAppointmentRepository) Update(appointment *models.Appointment) error {
+	args := m.Called(appointment)
+	return args.Error(0)
+}
+
+func (m *MockAppointmentRepository) GetByClientID(clientID uint) ([]models.Appointment, error) {
+	args := m.Called(clientID)
+	return args.Get(0).([]models.Appointment), args.Error(1)
+}
+
+func (m *MockAppointmentRepository) GetByBarberID(barberID uint) ([]models.Appointment, error) {
+	args := m.Called(barberID)
+	return args.Get(0).([]models.Appointment), args.Error(1)
+}
+
+func (m *MockAppointmentRepository) GetOverlapping(barberID uint, start, end time.Time) ([]models.Appointment, error) {
+	args := m.Called(barberID, start, end)
+	return args.Get(0).([]models.Appointment), args.Error(1)
+}
+
+// MockWorkingHoursRepository для тестирования
+type MockWorkingHoursRepository struct {
+	mock.Mock
+}
+
+func (m *MockWorkingHoursRepository) GetByBarberID(barberID uint) ([]models.WorkingHours, error) {
+	args := m.Called(barberID)
+	return args.Get(0).([]models.WorkingHours), args.Error(1)
+}
+
+func (m *MockWorkingHoursRepository) GetActiveByBarberID(barberID uint) ([]models.WorkingHours, error) {
+	args := m.Called(barberID)
+	return args.Get(0).([]models.WorkingHours), args.Error(1)
+}
+
+func (m *MockWorkingHoursRepository) GetByBarberAndDay(barberID uint, dayOfWeek int) (*models.WorkingHours, error) {
+	args := m.Called(barberID, dayOfWeek)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.WorkingHours), args.Error(1)
+}
+
+func (m *MockWorkingHoursRepository) SaveDay(workingHours *models.WorkingHours) error {
+	args := m.Called(workingHours)
+	return args.Error(0)
+}
+
+func (m *MockWorkingHoursRepository) DeleteDay(barberID uint, dayOfWeek int) error {
+	args := m.Called(barberID, dayOfWeek)
+	return args.Error(0)
+}
+
+func (m *MockWorkingHoursRepository) ReplaceWeek(barberID uint, week []models.WorkingHours) error {
+	args := m.Called(barberID, week)
+	return args.Error(0)
+}
+
+// MockScheduleExceptionRepository для тестирования
+type MockScheduleExceptionRepository struct {
+	mock.Mock
+}
+
+func (m *MockScheduleExceptionRepository) Create(exception *models.ScheduleException, check func(appointments []models.Appointment, exceptions []models.ScheduleException) error) error {
+	args := m.Called(exception)
+	return args.Error(0)
+}
+
+func (m *MockScheduleExceptionRepository) GetByID(id uint) (*models.ScheduleException, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ScheduleException), args.Error(1)
+}
+
+func (m *MockScheduleExceptionRepository) Delete(id uint) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockScheduleExceptionRepository) GetByBarberInRange(barberID uint, from, to string) ([]models.ScheduleException, error) {
+	args := m.Called(barberID, from, to)
+	return args.Get(0).([]models.ScheduleException), args.Error(1)
+}
+
+// MockTimezoneService для тестирования
+type MockTimezoneService struct {
+	mock.Mock
+}
+
+func (m *MockTimezoneService) DefaultLocation() *time.Location {
+	args := m.Called()
+	return args.Get(0).(*time.Location)
+}
+
+func (m *MockTimezoneService) BarberLocation(barberID uint) *time.Location {
+	args := m.Called(barberID)
+	return args.Get(0).(*time.Location)
+}
+
+func (m *MockTimezoneService) UserLocation(user *models.User) *time.Location {
+	args := m.Called(user)
+	return args.Get(0).(*time.Location)
+}
+
+// newFixedTimezoneService возвращает мок, у которого все барберы работают в часовом поясе loc
+func newFixedTimezoneService(loc *time.Location) *MockTimezoneService {
+	timezoneService := new(MockTimezoneService)
+	timezoneService.On("DefaultLocation").Return(loc).Maybe()
+	timezoneService.On("BarberLocation", mock.Anything).Return(loc).Maybe()
+	timezoneService.On("UserLocation", mock.Anything).Return(loc).Maybe()
+	return timezoneService
+}
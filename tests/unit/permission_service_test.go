@@ -0,0 +1,174 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"garage-barbershop/internal/middleware"
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/services"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// Тесты PermissionService и middleware разрешений
+
+func TestParsePermissions(t *testing.T) {
+	// Arrange & Act
+	permissions, err := models.ParsePermissions(`{"Appointments": ["READ", "create", "read"], "*": ["read"]}`)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"create", "read"}, permissions["appointments"])
+	assert.True(t, permissions.Allows("appointments", "create"))
+	assert.False(t, permissions.Allows("appointments", "delete"))
+	assert.True(t, permissions.Allows("reviews", "read"))
+	assert.False(t, permissions.Allows("reviews", "update"))
+
+	_, err = models.ParsePermissions(`["appointments"]`)
+	assert.Error(t, err)
+
+	empty, err := models.ParsePermissions("")
+	require.NoError(t, err)
+	assert.False(t, empty.Allows("appointments", "read"))
+}
+
+func TestPermissionService_Can_MergesActiveRoles(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRoleRepository)
+	permissionService := services.NewPermissionService(mockRepo)
+
+	mockRepo.On("GetUserRoles", uint(1)).Return([]models.Role{
+		{Name: "client", IsActive: true, Permissions: `{"appointments": ["create", "read"]}`},
+		{Name: "barber", IsActive: true, Permissions: `{"appointments": ["update"], "profile": ["read"]}`},
+		{Name: "disabled", IsActive: false, Permissions: `{"*": ["*"]}`},
+		{Name: "broken", IsActive: true, Permissions: `not json`},
+	}, nil).Once()
+
+	// Act & Assert
+	assert.True(t, permissionService.Can(1, "appointments", "create"))
+	assert.True(t, permissionService.Can(1, "appointments", "update"))
+	assert.True(t, permissionService.Can(1, "profile", "read"))
+	assert.False(t, permissionService.Can(1, "barbers", "delete"))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestPermissionService_CachesAndInvalidates(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRoleRepository)
+	permissionService := services.NewPermissionService(mockRepo)
+
+	mockRepo.On("GetUserRoles", uint(1)).Return([]models.Role{
+		{Name: "client", IsActive: true, Permissions: `{"appointments": ["read"]}`},
+	}, nil).Once()
+
+	// Act: повторные проверки берутся из кеша
+	assert.True(t, permissionService.Can(1, "appointments", "read"))
+	assert.False(t, permissionService.Can(1, "appointments", "delete"))
+
+	// Смена ролей видна после сброса кеша
+	mockRepo.On("GetUserRoles", uint(1)).Return([]models.Role{
+		{Name: "admin", IsActive: true, Permissions: `{"*": ["*"]}`},
+	}, nil).Once()
+	permissionService.InvalidateUser(1)
+
+	// Assert
+	assert.True(t, permissionService.Can(1, "appointments", "delete"))
+	mockRepo.AssertNumberOfCalls(t, "GetUserRoles", 2)
+}
+
+func TestPermissionService_SetRolePermissions(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRoleRepository)
+	permissionService := services.NewPermissionService(mockRepo)
+
+	role := &models.Role{ID: 3, Name: "client", IsActive: true, Permissions: `{"appointments": ["read"]}`}
+	mockRepo.On("GetRoleByID", uint(3)).Return(role, nil)
+	mockRepo.On("GetRoleByID", uint(99)).Return(nil, assert.AnError)
+	mockRepo.On("UpdateRole", mock.AnythingOfType("*models.Role")).Return(nil)
+	mockRepo.On("GetUserRoles", uint(1)).Return([]models.Role{*role}, nil).Once()
+
+	assert.False(t, permissionService.Can(1, "reviews", "create"))
+
+	// Act
+	updated, err := permissionService.SetRolePermissions(3, models.PermissionSet{"reviews": {"Create"}, "appointments": {"read"}})
+
+	// Assert
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"appointments": ["read"], "reviews": ["create"]}`, updated.Permissions)
+
+	mockRepo.On("GetUserRoles", uint(1)).Return([]models.Role{*updated}, nil).Once()
+	assert.True(t, permissionService.Can(1, "reviews", "create"))
+
+	_, err = permissionService.SetRolePermissions(99, models.PermissionSet{})
+	assert.ErrorIs(t, err, services.ErrRoleNotFound)
+
+	_, err = permissionService.SetRolePermissions(3, models.PermissionSet{"reviews": {" "}})
+	assert.Error(t, err)
+}
+
+func TestHTTPRequirePermissionMiddleware(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockRoleRepository)
+	permissionService := services.NewPermissionService(mockRepo)
+	mockRepo.On("GetUserRoles", uint(1)).Return([]models.Role{
+		{Name: "barber", IsActive: true, Permissions: `{"barbers": ["read"]}`},
+	}, nil)
+
+	handler := middleware.HTTPRequireResourcePermissionMiddleware(permissionService, "barbers")(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	serve := func(method string, withUser bool) int {
+		req := httptest.NewRequest(method, "/api/admin/barbers", nil)
+		if withUser {
+			req = req.WithContext(context.WithValue(req.Context(), "userID", uint(1)))
+		}
+		w := httptest.NewRecorder()
+		handler(w, req)
+		return w.Code
+	}
+
+	// Act & Assert
+	assert.Equal(t, http.StatusNoContent, serve(http.MethodGet, true))
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodDelete, true))
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, false))
+}
+
+func TestGinRequirePermission(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	mockRepo := new(MockRoleRepository)
+	permissionService := services.NewPermissionService(mockRepo)
+	mockRepo.On("GetUserRoles", uint(1)).Return([]models.Role{
+		{Name: "admin", IsActive: true, Permissions: `{"barbers": ["update"]}`},
+	}, nil)
+	mockRepo.On("GetUserRoles", uint(2)).Return([]models.Role{}, nil)
+
+	router := gin.New()
+	router.PUT("/barbers/:id", func(c *gin.Context) {
+		if userID := c.GetHeader("X-User"); userID != "" {
+			c.Set("user_id", map[string]uint{"1": 1, "2": 2}[userID])
+		}
+	}, middleware.RequirePermission(permissionService, "barbers", models.ActionUpdate), func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	serve := func(user string) int {
+		req := httptest.NewRequest(http.MethodPut, "/barbers/5", nil)
+		req.Header.Set("X-User", user)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Act & Assert
+	assert.Equal(t, http.StatusNoContent, serve("1"))
+	assert.Equal(t, http.StatusForbidden, serve("2"))
+	assert.Equal(t, http.StatusUnauthorized, serve(""))
+}
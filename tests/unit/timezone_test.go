@@ -0,0 +1,146 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"garage-barbershop/internal/models"
+	"garage-barbershop/internal/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// loadLocation загружает часовой пояс для тестов
+func loadLocation(t *testing.T, name string) *time.Location {
+	loc, err := models.LoadTimezone(name)
+	require.NoError(t, err)
+	return loc
+}
+
+func TestTimezoneService_BarberOverride(t *testing.T) {
+	// Arrange
+	userRepo := new(MockUserRepository)
+	moscow := loadLocation(t, "Europe/Moscow")
+	timezoneService := services.NewTimezoneService(userRepo, moscow)
+
+	userRepo.On("GetByID", uint(1)).Return(&models.User{ID: 1}, nil)
+	userRepo.On("GetByID", uint(2)).Return(&models.User{ID: 2, Timezone: "Asia/Yekaterinburg"}, nil)
+
+	// Act & Assert
+	assert.Equal(t, "Europe/Moscow", timezoneService.BarberLocation(1).String())
+	assert.Equal(t, "Asia/Yekaterinburg", timezoneService.BarberLocation(2).String())
+}
+
+func TestAvailabilityService_UsesBarberTimezone(t *testing.T) {
+	// Arrange - сервер может работать в UTC, а барбер в Москве (UTC+3)
+	moscow := loadLocation(t, "Europe/Moscow")
+	workingHoursRepo := new(MockWorkingHoursRepository)
+	exceptionRepo := new(MockScheduleExceptionRepository)
+	appointmentRepo := new(MockAppointmentRepository)
+	serviceRepo := new(MockServiceRepository)
+	availabilityService := services.NewAvailabilityService(workingHoursRepo, exceptionRepo, appointmentRepo, serviceRepo, newFixedTimezoneService(moscow))
+
+	serviceRepo.On("GetByID", uint(10)).Return(&models.Service{ID: 10, Duration: 60, IsActive: true, BarberID: 2}, nil)
+	workingHoursRepo.On("GetActiveByBarberID", uint(2)).Return([]models.WorkingHours{
+		{DayOfWeek: 3, StartTime: "10:00", EndTime: "12:00", IsActive: true},
+	}, nil)
+	exceptionRepo.On("GetByBarberInRange", uint(2), "2030-01-02", "2030-01-02").Return([]models.ScheduleException{}, nil)
+	appointmentRepo.On("GetOverlapping", uint(2), mock.Anything, mock.Anything).Return([]models.Appointment{}, nil)
+
+	// Act - 2 января 2030 года, среда
+	availability, err := availabilityService.GetAvailableSlots(2, 10, "2030-01-02", "2030-01-02")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "Europe/Moscow", availability.Timezone)
+	require.NotEmpty(t, availability.Slots)
+	first := availability.Slots[0]
+	assert.Equal(t, time.Date(2030, 1, 2, 7, 0, 0, 0, time.UTC), first.Start)
+	assert.Equal(t, time.UTC, first.Start.Location())
+	assert.Equal(t, "2030-01-02T10:00:00+03:00", first.StartLocal.Format(time.RFC3339))
+}
+
+func TestAvailabilityService_DaylightSavingTransition(t *testing.T) {
+	// Arrange - 31 марта 2030 года в Берлине часы переводятся с 02:00 на 03:00
+	berlin := loadLocation(t, "Europe/Berlin")
+	workingHoursRepo := new(MockWorkingHoursRepository)
+	exceptionRepo := new(MockScheduleExceptionRepository)
+	appointmentRepo := new(MockAppointmentRepository)
+	serviceRepo := new(MockServiceRepository)
+	availabilityService := services.NewAvailabilityService(workingHoursRepo, exceptionRepo, appointmentRepo, serviceRepo, newFixedTimezoneService(berlin))
+
+	serviceRepo.On("GetByID", uint(10)).Return(&models.Service{ID: 10, Duration: 60, IsActive: true, BarberID: 2}, nil)
+	workingHoursRepo.On("GetActiveByBarberID", uint(2)).Return([]models.WorkingHours{
+		{DayOfWeek: 7, StartTime: "00:00", EndTime: "05:00", IsActive: true},
+	}, nil)
+	exceptionRepo.On("GetByBarberInRange", uint(2), "2030-03-31", "2030-03-31").Return([]models.ScheduleException{}, nil)
+	appointmentRepo.On("GetOverlapping", uint(2), mock.Anything, mock.Anything).Return([]models.Appointment{}, nil)
+
+	// Act
+	availability, err := availabilityService.GetAvailableSlots(2, 10, "2030-03-31", "2030-03-31")
+
+	// Assert - рабочий день длится 4 часа, поэтому стартов с шагом 15 минут ровно 13
+	require.NoError(t, err)
+	require.Len(t, availability.Slots, 13)
+	for _, slot := range availability.Slots {
+		assert.Equal(t, time.Hour, slot.End.Sub(slot.Start))
+		assert.NotEqual(t, 2, slot.StartLocal.Hour(), "02:xx не существует в этот день")
+	}
+	assert.Equal(t, "00:00", availability.Slots[0].StartLocal.Format("15:04"))
+	assert.Equal(t, "04:00", availability.Slots[12].StartLocal.Format("15:04"))
+	assert.Equal(t, "05:00", availability.Slots[12].EndLocal.Format("15:04"))
+}
+
+func TestAppointmentService_BookAppointment_LocalTimeInBarberTimezone(t *testing.T) {
+	// Arrange
+	moscow := loadLocation(t, "Europe/Moscow")
+	appointmentRepo := new(MockAppointmentRepository)
+	serviceRepo := new(MockServiceRepository)
+	userRepo := new(MockUserRepository)
+	workingHoursRepo := new(MockWorkingHoursRepository)
+	exceptionRepo := new(MockScheduleExceptionRepository)
+	appointmentService := services.NewAppointmentService(appointmentRepo, serviceRepo, userRepo, workingHoursRepo, exceptionRepo, newFixedTimezoneService(moscow))
+
+	serviceRepo.On("GetByID", uint(10)).Return(&models.Service{ID: 10, Price: 1000, Duration: 30, IsActive: true, BarberID: 2}, nil)
+	userRepo.On("GetByID", uint(2)).Return(&models.User{ID: 2, IsActive: true}, nil)
+	exceptionRepo.On("GetByBarberInRange", uint(2), "2030-01-02", "2030-01-02").Return([]models.ScheduleException{}, nil)
+	workingHoursRepo.On("GetActiveByBarberID", uint(2)).Return(everyDay("09:00", "18:00"), nil)
+	appointmentRepo.On("CreateIfAvailable", mock.AnythingOfType("*models.Appointment")).Return(nil)
+
+	// Act
+	appointment, err := appointmentService.BookAppointment(1, models.AppointmentCreateRequest{
+		ServiceID: 10,
+		LocalTime: "2030-01-02T10:00",
+	})
+
+	// Assert - хранится UTC, в ответе есть и местное время
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2030, 1, 2, 7, 0, 0, 0, time.UTC), appointment.DateTime)
+	require.NotNil(t, appointment.LocalDateTime)
+	assert.Equal(t, "2030-01-02T10:00:00+03:00", appointment.LocalDateTime.Format(time.RFC3339))
+	assert.Equal(t, "Europe/Moscow", appointment.Timezone)
+}
+
+func TestAppointmentService_BookAppointment_RejectsNonexistentLocalTime(t *testing.T) {
+	// Arrange
+	berlin := loadLocation(t, "Europe/Berlin")
+	appointmentRepo := new(MockAppointmentRepository)
+	serviceRepo := new(MockServiceRepository)
+	userRepo := new(MockUserRepository)
+	appointmentService := services.NewAppointmentService(appointmentRepo, serviceRepo, userRepo, new(MockWorkingHoursRepository), new(MockScheduleExceptionRepository), newFixedTimezoneService(berlin))
+
+	serviceRepo.On("GetByID", uint(10)).Return(&models.Service{ID: 10, Duration: 30, IsActive: true, BarberID: 2}, nil)
+	userRepo.On("GetByID", uint(2)).Return(&models.User{ID: 2, IsActive: true}, nil)
+
+	// Act - 02:30 пропускается при переходе на летнее время
+	_, err := appointmentService.BookAppointment(1, models.AppointmentCreateRequest{
+		ServiceID: 10,
+		LocalTime: "2030-03-31T02:30",
+	})
+
+	// Assert
+	assert.Error(t, err)
+	appointmentRepo.AssertNotCalled(t, "CreateIfAvailable", mock.Anything)
+}
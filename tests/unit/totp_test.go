@@ -0,0 +1,71 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"garage-barbershop/internal/totp"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfc6238Secret секрет из тестовых векторов RFC 6238 ("12345678901234567890" в base32)
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTP_RFC6238Vectors(t *testing.T) {
+	// Последние 6 цифр 8-значных кодов SHA1 из приложения B RFC 6238
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, expected := range vectors {
+		code, err := totp.Code(rfc6238Secret, totp.Step(time.Unix(unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, expected, code, "время %d", unix)
+	}
+}
+
+func TestTOTP_ValidateWithSkew(t *testing.T) {
+	// Arrange
+	now := time.Unix(1234567890, 0)
+	previous, err := totp.Code(rfc6238Secret, totp.Step(now)-1)
+	require.NoError(t, err)
+	stale, err := totp.Code(rfc6238Secret, totp.Step(now)-2)
+	require.NoError(t, err)
+
+	// Act & Assert
+	step, ok := totp.Validate(strings.ToLower(rfc6238Secret), "005 924", now)
+	assert.True(t, ok)
+	assert.Equal(t, totp.Step(now), step)
+
+	step, ok = totp.Validate(rfc6238Secret, previous, now)
+	assert.True(t, ok)
+	assert.Equal(t, totp.Step(now)-1, step)
+
+	_, ok = totp.Validate(rfc6238Secret, stale, now)
+	assert.False(t, ok)
+	_, ok = totp.Validate(rfc6238Secret, "12345", now)
+	assert.False(t, ok)
+	_, ok = totp.Validate("not base32!", "005924", now)
+	assert.False(t, ok)
+}
+
+func TestTOTP_SecretAndURI(t *testing.T) {
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	uri := totp.URI("Garage Barbershop", "admin@example.com", secret)
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/Garage%20Barbershop:admin@example.com?"))
+	assert.Contains(t, uri, "secret="+secret)
+	assert.Contains(t, uri, "issuer=Garage+Barbershop")
+	assert.Contains(t, uri, "digits=6")
+	assert.Contains(t, uri, "period=30")
+}